@@ -0,0 +1,39 @@
+import {AuthenticatedHTTPFactory} from '@/helpers/fetcher'
+
+interface IWebPushSubscription {
+	id: number
+	endpoint: string
+}
+
+/**
+ * Removes the push subscription of this browser, both from the api and the browser itself.
+ * This makes sure a device does not receive notifications of a user who logged out of it.
+ */
+export async function removeWebPushSubscription() {
+	if (!('serviceWorker' in navigator)) {
+		return
+	}
+
+	try {
+		const registration = await navigator.serviceWorker.getRegistration()
+		const subscription = await registration?.pushManager.getSubscription()
+		if (!subscription) {
+			return
+		}
+
+		try {
+			const HTTP = AuthenticatedHTTPFactory()
+			const {data} = await HTTP.get<IWebPushSubscription[]>('user/settings/webpush')
+			const saved = data.find(s => s.endpoint === subscription.endpoint)
+			if (saved) {
+				await HTTP.delete(`user/settings/webpush/${saved.id}`)
+			}
+		} finally {
+			// Even if the api is not reachable anymore, the push service will report the
+			// subscription as gone the next time the api tries to use it.
+			await subscription.unsubscribe()
+		}
+	} catch (e) {
+		console.error('Could not remove the web push subscription', e)
+	}
+}
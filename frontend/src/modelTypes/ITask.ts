@@ -33,16 +33,22 @@ export interface ITask extends IAbstract {
 	repeatAfter: number | IRepeatAfter
 	repeatFromCurrentDate: boolean
 	repeatMode: IRepeatMode
+	repeatRule: string
+	repeatRuleStart: Date | null
 	reminders: ITaskReminder[]
 	parentTaskId: ITask['id']
 	hexColor: string
 	percentDone: number
+	estimatedTime: number
+	timeSpent: number
+	customFields: Record<number, unknown>
 	relatedTasks: Partial<Record<IRelationKind, ITask[]>>
 	attachments: IAttachment[]
 	coverImageAttachmentId: IAttachment['id'] | null
 	identifier: string
 	index: number
 	isFavorite: boolean
+	isBlocked: boolean
 	subscription: ISubscription
 
 	position: number
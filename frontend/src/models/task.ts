@@ -73,16 +73,22 @@ export default class TaskModel extends AbstractModel<ITask> implements ITask {
 	repeatAfter: number | IRepeatAfter = 0
 	repeatFromCurrentDate = false
 	repeatMode: IRepeatMode = TASK_REPEAT_MODES.REPEAT_MODE_DEFAULT
+	repeatRule = ''
+	repeatRuleStart: Date | null = null
 	reminders: ITaskReminder[] = []
 	parentTaskId: ITask['id'] = 0
 	hexColor = ''
 	percentDone = 0
+	estimatedTime = 0
+	timeSpent = 0
+	customFields: Record<number, unknown> = {}
 	relatedTasks:  Partial<Record<IRelationKind, ITask[]>> = {}
 	attachments: IAttachment[] = []
 	coverImageAttachmentId: IAttachment['id'] = null
 	identifier = ''
 	index = 0
 	isFavorite = false
+	isBlocked = false
 	subscription: ISubscription = null
 
 	position = 0
@@ -117,6 +123,7 @@ export default class TaskModel extends AbstractModel<ITask> implements ITask {
 		this.dueDate = parseDateOrNull(this.dueDate)
 		this.startDate = parseDateOrNull(this.startDate)
 		this.endDate = parseDateOrNull(this.endDate)
+		this.repeatRuleStart = parseDateOrNull(this.repeatRuleStart)
 
 		// Parse the repeat after into something usable
 		this.repeatAfter = parseRepeatAfter(this.repeatAfter as number)
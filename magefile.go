@@ -210,7 +210,8 @@ func init() {
 
 // Some variables have external dependencies (like git) which may not always be available.
 func initVars() {
-	Tags = os.Getenv("TAGS")
+	// sqlite_fts5 enables sqlite's FTS5 module which is used for the native full text search
+	Tags = strings.TrimSpace("sqlite_fts5 " + os.Getenv("TAGS"))
 	setVersion()
 	setBinLocation()
 	setPkgVersion()
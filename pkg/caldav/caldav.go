@@ -51,7 +51,10 @@ type Todo struct {
 	Duration    time.Duration
 	RepeatAfter int64
 	RepeatMode  models.TaskRepeatMode
-	Alarms      []Alarm
+	RepeatRule  string
+	// The start of the series of RepeatRule, exported as DTSTART if the todo has no start date.
+	RepeatRuleStart time.Time
+	Alarms          []Alarm
 
 	Created time.Time
 	Updated time.Time // last-mod
@@ -153,6 +156,10 @@ DTSTART:` + makeCalDavTimeFromTimeStamp(t.Start)
 				caldavtodos += `
 DURATION:PT` + formatDuration(t.Duration)
 			}
+		} else if t.RepeatRule != "" && t.RepeatRuleStart.Unix() > 0 {
+			// Clients need DTSTART to know when the series of the rule starts
+			caldavtodos += `
+DTSTART:` + makeCalDavTimeFromTimeStamp(t.RepeatRuleStart)
 		}
 		if t.End.Unix() > 0 {
 			caldavtodos += `
@@ -189,7 +196,11 @@ CREATED:` + makeCalDavTimeFromTimeStamp(t.Created)
 PRIORITY:` + strconv.Itoa(mapPriorityToCaldav(t.Priority))
 		}
 
-		if t.RepeatAfter > 0 || t.RepeatMode == models.TaskRepeatModeMonth {
+		switch {
+		case t.RepeatRule != "":
+			caldavtodos += `
+RRULE:` + t.RepeatRule
+		case t.RepeatAfter > 0 || t.RepeatMode == models.TaskRepeatModeMonth:
 			if t.RepeatMode == models.TaskRepeatModeMonth {
 				caldavtodos += `
 RRULE:FREQ=MONTHLY;BYMONTHDAY=` + t.DueDate.Format("02") // Day of the month
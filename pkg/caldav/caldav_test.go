@@ -214,6 +214,42 @@ DUE:20181201T011204Z
 RRULE:FREQ=SECONDLY;INTERVAL=435
 LAST-MODIFIED:00010101T000000Z
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "with repeat rule",
+			args: args{
+				config: &Config{
+					Name:   "test",
+					ProdID: "RandomProdID which is not random",
+				},
+				todos: []*Todo{
+					{
+						Summary:     "Todo #1",
+						Description: "Lorem Ipsum",
+						UID:         "randommduid",
+						Timestamp:   time.Unix(1543626724, 0).In(config.GetTimeZone()),
+						DueDate:     time.Unix(1543626724, 0).In(config.GetTimeZone()),
+						RepeatAfter: 86400,
+						RepeatRule:  "FREQ=MONTHLY;COUNT=10;BYDAY=-1FR",
+					},
+				},
+			},
+			wantCaldavtasks: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randommduid
+DTSTAMP:20181201T011204Z
+SUMMARY:Todo #1
+DESCRIPTION:Lorem Ipsum
+DUE:20181201T011204Z
+RRULE:FREQ=MONTHLY;COUNT=10;BYDAY=-1FR
+LAST-MODIFIED:00010101T000000Z
+END:VTODO
 END:VCALENDAR`,
 		},
 		{
@@ -221,18 +221,20 @@ func GetCaldavTodosForTasks(project *models.ProjectWithTasksAndBuckets, projectT
 			Description: t.Description,
 			Completed:   t.DoneAt,
 			// Organizer:     &t.CreatedBy, // Disabled until we figure out how this works
-			Categories:  categories,
-			Priority:    t.Priority,
-			Start:       t.StartDate,
-			End:         t.EndDate,
-			Created:     t.Created,
-			Updated:     t.Updated,
-			DueDate:     t.DueDate,
-			Duration:    duration,
-			RepeatAfter: t.RepeatAfter,
-			RepeatMode:  t.RepeatMode,
-			Alarms:      alarms,
-			Relations:   relations,
+			Categories:      categories,
+			Priority:        t.Priority,
+			Start:           t.StartDate,
+			End:             t.EndDate,
+			Created:         t.Created,
+			Updated:         t.Updated,
+			DueDate:         t.DueDate,
+			Duration:        duration,
+			RepeatAfter:     t.RepeatAfter,
+			RepeatMode:      t.RepeatMode,
+			RepeatRule:      t.RepeatRule,
+			RepeatRuleStart: t.RepeatRuleStart,
+			Alarms:          alarms,
+			Relations:       relations,
 		})
 	}
 
@@ -375,6 +377,15 @@ func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 		vTask.Done = true
 	}
 
+	// Rules with parts we don't support are rejected instead of silently changing the series
+	if rrule, has := task["RRULE"]; has {
+		rule, err := models.ParseRepeatRule(rrule.Value)
+		if err != nil {
+			return nil, err
+		}
+		vTask.RepeatRule = rule.String()
+	}
+
 	if duration > 0 && !vTask.StartDate.IsZero() {
 		vTask.EndDate = vTask.StartDate.Add(duration)
 	}
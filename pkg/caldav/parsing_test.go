@@ -455,6 +455,73 @@ END:VCALENDAR`,
 				HexColor: "7b68ee",
 			},
 		},
+		{
+			name: "with repeat rule",
+			args: args{content: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20191231T235959Z
+LAST-MODIFIED:00010101T000000
+END:VTODO
+END:VCALENDAR`,
+			},
+			wantVTask: &models.Task{
+				Title:      "Todo #1",
+				UID:        "randomuid",
+				RepeatRule: "FREQ=WEEKLY;INTERVAL=2;UNTIL=20191231T235959Z;BYDAY=TU,TH",
+				Updated:    time.Unix(1543626724, 0).In(config.GetTimeZone()),
+			},
+		},
+		{
+			name: "with repeat rule until a date",
+			args: args{content: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+RRULE:FREQ=DAILY;UNTIL=20191231
+LAST-MODIFIED:00010101T000000
+END:VTODO
+END:VCALENDAR`,
+			},
+			wantVTask: &models.Task{
+				Title:      "Todo #1",
+				UID:        "randomuid",
+				RepeatRule: "FREQ=DAILY;UNTIL=20191231",
+				Updated:    time.Unix(1543626724, 0).In(config.GetTimeZone()),
+			},
+		},
+		{
+			name: "with unsupported repeat rule",
+			args: args{content: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+RRULE:FREQ=DAILY;BYHOUR=9,17
+LAST-MODIFIED:00010101T000000
+END:VTODO
+END:VCALENDAR`,
+			},
+			wantErr: true,
+		},
 		{
 			name: "with hex color",
 			args: args{content: `BEGIN:VCALENDAR
@@ -710,6 +777,46 @@ CREATED:20181201T011204Z
 LAST-MODIFIED:20181201T011204Z
 RELATED-TO;RELTYPE=PARENT:randomuid_parent
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "Format Task with a repeat rule and without start date as CalDAV",
+			args: args{
+				list: &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						Title: "List title",
+					},
+				},
+				tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							Title:           "Task 1",
+							UID:             "randomuid",
+							Created:         time.Unix(1543626721, 0).In(config.GetTimeZone()),
+							DueDate:         time.Unix(1543626722, 0).In(config.GetTimeZone()),
+							Updated:         time.Unix(1543626725, 0).In(config.GetTimeZone()),
+							RepeatRule:      "FREQ=DAILY;COUNT=3",
+							RepeatRuleStart: time.Unix(1543626720, 0).In(config.GetTimeZone()),
+						},
+					},
+				},
+			},
+			wantCaldav: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:List title
+PRODID:-//Vikunja Todo App//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011205Z
+SUMMARY:Task 1
+DTSTART:20181201T011200Z
+DUE:20181201T011202Z
+CREATED:20181201T011201Z
+RRULE:FREQ=DAILY;COUNT=3
+LAST-MODIFIED:20181201T011205Z
+END:VTODO
 END:VCALENDAR`,
 		},
 	}
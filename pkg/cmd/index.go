@@ -20,6 +20,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/migration"
 	"code.vikunja.io/api/pkg/models"
 
 	"github.com/spf13/cobra"
@@ -33,17 +34,52 @@ var indexPartialFlag bool
 
 var indexCmd = &cobra.Command{
 	Use:   "index",
-	Short: "Reindex all of Vikunja's data into Typesense. This will remove any existing index.",
+	Short: "Reindex all of Vikunja's data into Typesense, bleve and the database's full text search. This will remove any existing index.",
 	PreRun: func(_ *cobra.Command, _ []string) {
 		initialize.FullInitWithoutAsync()
 	},
 	Run: func(_ *cobra.Command, _ []string) {
+		// Attachments uploaded before their text was extracted would otherwise not be searchable
+		log.Infof("Extracting the text of attachments… This may take a while.")
+		err := models.ExtractMissingAttachmentTexts()
+		if err != nil {
+			log.Criticalf("Could not extract the text of attachments: %s", err.Error())
+			return
+		}
+
+		if config.DatabaseFullTextSearch.GetBool() {
+			log.Infof("Rebuilding the full text search index… This may take a while.")
+			err = migration.RecreateFullTextSearchIndex(nil)
+			if err != nil {
+				log.Criticalf("Could not create the full text search index: %s", err.Error())
+				return
+			}
+			err = models.RebuildFullTextSearchIndex()
+			if err != nil {
+				log.Criticalf("Could not rebuild the full text search index: %s", err.Error())
+				return
+			}
+		}
+
+		if config.SearchBackend.GetString() == "bleve" {
+			log.Infof("Rebuilding the bleve search index… This may take a while.")
+			err = models.RebuildBleveIndex()
+			if err != nil {
+				log.Criticalf("Could not rebuild the bleve search index: %s", err.Error())
+				return
+			}
+		}
+
 		if config.TypesenseURL.GetString() == "" {
-			log.Error("Typesense not configured")
+			if !config.DatabaseFullTextSearch.GetBool() && config.SearchBackend.GetString() != "bleve" {
+				log.Error("Neither Typesense, bleve nor the full text search are configured")
+				return
+			}
+			log.Infof("Done!")
 			return
 		}
 
-		err := models.CreateTypesenseCollections()
+		err = models.CreateTypesenseCollections()
 		if err != nil {
 			log.Criticalf("Could not create Typesense collections: %s", err.Error())
 			return
@@ -64,6 +100,13 @@ var indexCmd = &cobra.Command{
 			}
 		}
 
+		log.Infof("Indexing projects, comments, labels, saved filters and teams…")
+		err = models.ReindexGlobalSearchCollections()
+		if err != nil {
+			log.Criticalf("Could not reindex the global search into Typesense: %s", err.Error())
+			return
+		}
+
 		log.Infof("Done!")
 	},
 }
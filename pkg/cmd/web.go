@@ -30,6 +30,8 @@ import (
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/inbound"
 	"code.vikunja.io/api/pkg/plugins"
 	"code.vikunja.io/api/pkg/routes"
 	"code.vikunja.io/api/pkg/utils"
@@ -150,6 +152,9 @@ var webCmd = &cobra.Command{
 			}
 		}()
 
+		// Start receiving mails to create tasks from
+		inbound.Start(models.HandleInboundEmail)
+
 		// Wait for interrupt signal to gracefully shut down the server with
 		// a timeout of 10 seconds.
 		quit := make(chan os.Signal, 1)
@@ -162,6 +167,10 @@ var webCmd = &cobra.Command{
 			e.Logger.Fatal(err)
 		}
 		cron.Stop()
+		inbound.Stop()
 		plugins.Shutdown()
+		if err := models.CloseBleve(); err != nil {
+			log.Errorf("Could not close the bleve search index: %s", err)
+		}
 	},
 }
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	webPushCmd.AddCommand(webPushGenerateKeysCmd)
+	rootCmd.AddCommand(webPushCmd)
+}
+
+var webPushCmd = &cobra.Command{
+	Use:   "webpush",
+	Short: "Manage web push notifications",
+}
+
+var webPushGenerateKeysCmd = &cobra.Command{
+	Use:   "generate-keys",
+	Short: "Generate a VAPID key pair for web push notifications",
+	Run: func(_ *cobra.Command, _ []string) {
+		publicKey, privateKey, err := notifications.GenerateVapidKeys()
+		if err != nil {
+			log.Fatalf("Could not generate VAPID keys: %s", err)
+		}
+
+		fmt.Println("Add these keys to your config:")
+		fmt.Println()
+		fmt.Println("webpush:")
+		fmt.Printf("  vapidpublickey: %s\n", publicKey)
+		fmt.Printf("  vapidprivatekey: %s\n", privateKey)
+	},
+}
@@ -67,6 +67,7 @@ const (
 	ServiceEnablePublicTeams              Key = `service.enablepublicteams`
 	ServiceBcryptRounds                   Key = `service.bcryptrounds`
 	ServiceEnableOpenIDTeamUserOnlySearch Key = `service.enableopenidteamusersearch`
+	ServiceTrashRetentionDays             Key = `service.trashretentiondays`
 
 	SentryEnabled         Key = `sentry.enabled`
 	SentryDsn             Key = `sentry.dsn`
@@ -113,11 +114,16 @@ const (
 	DatabaseSslRootCert           Key = `database.sslrootcert`
 	DatabaseTLS                   Key = `database.tls`
 	DatabaseSchema                Key = `database.schema`
+	DatabaseFullTextSearch        Key = `database.fulltextsearch`
+	DatabaseFullTextLanguage      Key = `database.fulltextlanguage`
 
 	TypesenseEnabled Key = `typesense.enabled`
 	TypesenseURL     Key = `typesense.url`
 	TypesenseAPIKey  Key = `typesense.apikey`
 
+	SearchBackend   Key = `search.backend`
+	SearchBlevePath Key = `search.blevepath`
+
 	MailerEnabled       Key = `mailer.enabled`
 	MailerHost          Key = `mailer.host`
 	MailerPort          Key = `mailer.port`
@@ -199,12 +205,26 @@ const (
 	DefaultSettingsLanguage                    Key = `defaultsettings.language`
 	DefaultSettingsTimezone                    Key = `defaultsettings.timezone`
 	DefaultSettingsOverdueTaskRemindersTime    Key = `defaultsettings.overdue_tasks_reminders_time`
+	DefaultSettingsNotificationPreferences     Key = `defaultsettings.notification_preferences`
 
 	WebhooksEnabled        Key = `webhooks.enabled`
 	WebhooksTimeoutSeconds Key = `webhooks.timeoutseconds`
 	WebhooksProxyURL       Key = `webhooks.proxyurl`
 	WebhooksProxyPassword  Key = `webhooks.proxypassword`
 
+	PushEnabled        Key = `push.enabled`
+	PushTimeoutSeconds Key = `push.timeoutseconds`
+
+	WebPushEnabled         Key = `webpush.enabled`
+	WebPushVapidPublicKey  Key = `webpush.vapidpublickey`
+	WebPushVapidPrivateKey Key = `webpush.vapidprivatekey`
+	WebPushSubject         Key = `webpush.subject`
+
+	InboundEnabled   Key = `inbound.enabled`
+	InboundInterface Key = `inbound.interface`
+	InboundAddress   Key = `inbound.address`
+	InboundMaxSize   Key = `inbound.maxsize`
+
 	AutoTLSEnabled     Key = `autotls.enabled`
 	AutoTLSEmail       Key = `autotls.email`
 	AutoTLSRenewBefore Key = `autotls.renewbefore`
@@ -243,6 +263,11 @@ func (k Key) GetStringSlice() []string {
 	return viper.GetStringSlice(string(k))
 }
 
+// GetStringMap returns a map from a config option
+func (k Key) GetStringMap() map[string]interface{} {
+	return viper.GetStringMap(string(k))
+}
+
 // Get returns the raw value from a config option
 func (k Key) Get() interface{} {
 	return viper.Get(string(k))
@@ -342,6 +367,7 @@ func InitDefaultConfig() {
 	ServiceEnablePublicTeams.setDefault(false)
 	ServiceBcryptRounds.setDefault(11)
 	ServiceEnableOpenIDTeamUserOnlySearch.setDefault(false)
+	ServiceTrashRetentionDays.setDefault(30)
 
 	// Sentry
 	SentryDsn.setDefault("https://440eedc957d545a795c17bbaf477497c@o1047380.ingest.sentry.io/4504254983634944")
@@ -379,10 +405,16 @@ func InitDefaultConfig() {
 	DatabaseSslRootCert.setDefault("")
 	DatabaseTLS.setDefault("false")
 	DatabaseSchema.setDefault("public")
+	DatabaseFullTextSearch.setDefault(false)
+	DatabaseFullTextLanguage.setDefault("english")
 
 	// Typesense
 	TypesenseEnabled.setDefault(false)
 
+	// Search
+	SearchBackend.setDefault("database")
+	SearchBlevePath.setDefault(filepath.Join(ServiceRootpath.GetString(), "search.bleve"))
+
 	// Mailer
 	MailerEnabled.setDefault(false)
 	MailerHost.setDefault("")
@@ -448,6 +480,15 @@ func InitDefaultConfig() {
 	// Webhook
 	WebhooksEnabled.setDefault(true)
 	WebhooksTimeoutSeconds.setDefault(30)
+	// Push
+	PushEnabled.setDefault(true)
+	PushTimeoutSeconds.setDefault(10)
+	// Web Push
+	WebPushEnabled.setDefault(true)
+	// Inbound mail
+	InboundEnabled.setDefault(false)
+	InboundInterface.setDefault(":2525")
+	InboundMaxSize.setDefault("20MB")
 	// AutoTLS
 	AutoTLSRenewBefore.setDefault("720h") // 30days in hours
 	// Plugins
@@ -586,6 +627,23 @@ func InitConfig() {
 		RateLimitStore.Set(KeyvalueType.GetString())
 	}
 
+	switch SearchBackend.GetString() {
+	case "database":
+		// typesense.enabled was the only way to configure the search before search.backend existed.
+		if TypesenseEnabled.GetBool() {
+			SearchBackend.Set("typesense")
+		}
+	case "typesense":
+		TypesenseEnabled.Set(true)
+	case "bleve":
+		if TypesenseEnabled.GetBool() {
+			log.Warning("typesense.enabled is ignored because search.backend is set to bleve")
+			TypesenseEnabled.Set(false)
+		}
+	default:
+		log.Fatalf("search.backend must be one of database, typesense or bleve, got: %s", SearchBackend.GetString())
+	}
+
 	if ServicePublicURL.GetString() != "" {
 		if !strings.HasSuffix(ServicePublicURL.GetString(), "/") {
 			ServicePublicURL.Set(ServicePublicURL.GetString() + "/")
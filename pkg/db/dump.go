@@ -34,6 +34,11 @@ func Dump() (data map[string][]byte, err error) {
 
 	data = make(map[string][]byte, len(tables))
 	for _, table := range tables {
+		// The full text search index is derived from the tasks and rebuilt when it is empty.
+		if strings.HasPrefix(table.Name, "task_search_index") {
+			continue
+		}
+
 		entries := []map[string]interface{}{}
 		err := x.Table(table.Name).Find(&entries)
 		if err != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -32,6 +33,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"xorm.io/xorm"
 )
 
 var pubsub *gochannel.GoChannel
@@ -128,3 +130,56 @@ func Dispatch(event Event) error {
 	msg := message.NewMessage(watermill.NewUUID(), content)
 	return pubsub.Publish(event.Name(), msg)
 }
+
+var (
+	// heldEvents contains the events of all sessions whose events are held back until their transaction is committed
+	heldEvents     = make(map[*xorm.Session][]Event)
+	heldEventsLock sync.Mutex
+)
+
+// HoldUntilCommit holds back all events dispatched with DispatchOnCommit for the session. Call DispatchPending after
+// the transaction was committed or DiscardPending after it was rolled back.
+func HoldUntilCommit(s *xorm.Session) {
+	heldEventsLock.Lock()
+	defer heldEventsLock.Unlock()
+	if _, has := heldEvents[s]; !has {
+		heldEvents[s] = []Event{}
+	}
+}
+
+// DispatchOnCommit dispatches an event which was caused by changes in the session. If the events of the session
+// are held back, it is only dispatched once the transaction was committed.
+func DispatchOnCommit(s *xorm.Session, event Event) error {
+	heldEventsLock.Lock()
+	if pending, has := heldEvents[s]; has {
+		heldEvents[s] = append(pending, event)
+		heldEventsLock.Unlock()
+		return nil
+	}
+	heldEventsLock.Unlock()
+
+	return Dispatch(event)
+}
+
+// DispatchPending dispatches all events held back for the session and stops holding back its events.
+func DispatchPending(s *xorm.Session) error {
+	heldEventsLock.Lock()
+	pending := heldEvents[s]
+	delete(heldEvents, s)
+	heldEventsLock.Unlock()
+
+	for _, event := range pending {
+		err := Dispatch(event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiscardPending drops all events held back for the session and stops holding back its events.
+func DiscardPending(s *xorm.Session) {
+	heldEventsLock.Lock()
+	defer heldEventsLock.Unlock()
+	delete(heldEvents, s)
+}
@@ -126,6 +126,17 @@ func CreateWithMimeAndSession(s *xorm.Session, f io.Reader, realname string, rea
 	return
 }
 
+// RemoveStoredContent removes the content of a file from the file system without touching the DB.
+// Use it when the transaction which created the file is rolled back.
+func (f *File) RemoveStoredContent() (err error) {
+	err = afs.Remove(f.getAbsoluteFilePath())
+	if err != nil {
+		return err
+	}
+
+	return keyvalue.DecrBy(metrics.FilesCountKey, 1)
+}
+
 // Delete removes a file from the DB and the file system
 func (f *File) Delete(s *xorm.Session) (err error) {
 	deleted, err := s.Where("id = ?", f.ID).Delete(&File{})
@@ -33,6 +33,7 @@ import (
 	"code.vikunja.io/api/pkg/modules/auth/openid"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 	migrationHandler "code.vikunja.io/api/pkg/modules/migration/handler"
+	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/plugins"
 	"code.vikunja.io/api/pkg/red"
 	"code.vikunja.io/api/pkg/user"
@@ -73,6 +74,11 @@ func InitEngines() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+
+	err = models.InitFullTextSearch()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 }
 
 // FullInitWithoutAsync does a full init without any async handlers (cron or events)
@@ -91,6 +97,12 @@ func FullInitWithoutAsync() {
 	// Init Typesense
 	models.InitTypesense()
 
+	// Open the bleve search index
+	err := models.InitBleve()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// Start the mail daemon
 	mail.StartMailDaemon()
 
@@ -100,6 +112,9 @@ func FullInitWithoutAsync() {
 	// Load translations
 	i18n.Init()
 
+	// Load the keys for web push notifications
+	notifications.InitWebPush()
+
 	// Initialize plugins
 	plugins.Initialize()
 }
@@ -113,9 +128,11 @@ func FullInit() {
 	cron.Init()
 	models.RegisterReminderCron()
 	models.RegisterOverdueReminderCron()
+	models.RegisterDigestCron()
 	models.RegisterUserDeletionCron()
 	models.RegisterOldExportCleanupCron()
 	models.RegisterAddTaskToFilterViewCron()
+	models.RegisterTrashCleanupCron()
 	user.RegisterTokenCleanupCron()
 	user.RegisterDeletionNotificationCron()
 	openid.CleanupSavedOpenIDProviders()
@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskOccurrences20261016141527 struct {
+	ID        int64     `xorm:"bigint autoincr not null unique pk"`
+	TaskID    int64     `xorm:"bigint not null INDEX"`
+	DueDate   time.Time `xorm:"DATETIME null 'due_date'"`
+	StartDate time.Time `xorm:"DATETIME null 'start_date'"`
+	EndDate   time.Time `xorm:"DATETIME null 'end_date'"`
+	DoneAt    time.Time `xorm:"DATETIME not null INDEX 'done_at'"`
+	DoneByID  int64     `xorm:"bigint not null"`
+}
+
+func (taskOccurrences20261016141527) TableName() string {
+	return "task_occurrences"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261016141527",
+		Description: "add task occurrences table",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(taskOccurrences20261016141527{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
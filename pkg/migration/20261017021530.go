@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type projectCustomFields20261017021530 struct {
+	ID        int64     `xorm:"bigint autoincr not null unique pk"`
+	ProjectID int64     `xorm:"bigint not null INDEX"`
+	Title     string    `xorm:"varchar(250) not null"`
+	Type      int       `xorm:"not null default 0"`
+	Options   []string  `xorm:"json null"`
+	Position  float64   `xorm:"double null"`
+	Created   time.Time `xorm:"created not null"`
+	Updated   time.Time `xorm:"updated not null"`
+}
+
+func (projectCustomFields20261017021530) TableName() string {
+	return "project_custom_fields"
+}
+
+type taskCustomFieldValues20261017021530 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	TaskID      int64     `xorm:"bigint not null INDEX"`
+	FieldID     int64     `xorm:"bigint not null INDEX"`
+	ValueText   string    `xorm:"text null"`
+	ValueNumber float64   `xorm:"double null"`
+	ValueDate   time.Time `xorm:"DATETIME null 'value_date'"`
+}
+
+func (taskCustomFieldValues20261017021530) TableName() string {
+	return "task_custom_field_values"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261017021530",
+		Description: "add custom fields for tasks",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(projectCustomFields20261017021530{}, taskCustomFieldValues20261017021530{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type templates20261017103045 struct {
+	ID          int64       `xorm:"bigint autoincr not null unique pk"`
+	Title       string      `xorm:"varchar(250) not null"`
+	Description string      `xorm:"longtext null"`
+	Kind        int         `xorm:"not null default 0"`
+	AnchorDate  time.Time   `xorm:"DATETIME not null 'anchor_date'"`
+	Content     interface{} `xorm:"json null"`
+	OwnerID     int64       `xorm:"bigint not null INDEX"`
+	Created     time.Time   `xorm:"created not null"`
+	Updated     time.Time   `xorm:"updated not null"`
+}
+
+func (templates20261017103045) TableName() string {
+	return "templates"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261017103045",
+		Description: "add templates",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(templates20261017103045{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type trashItems20261017170000 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	Kind        string    `xorm:"varchar(20) not null"`
+	EntityID    int64     `xorm:"bigint not null INDEX"`
+	Title       string    `xorm:"text not null"`
+	ProjectID   int64     `xorm:"bigint null"`
+	OwnerID     int64     `xorm:"bigint not null INDEX"`
+	DeletedByID int64     `xorm:"bigint not null INDEX"`
+	Content     string    `xorm:"longtext null"`
+	Created     time.Time `xorm:"created not null INDEX"`
+}
+
+func (trashItems20261017170000) TableName() string {
+	return "trash_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261017170000",
+		Description: "add trash items",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(trashItems20261017170000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
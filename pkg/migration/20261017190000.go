@@ -0,0 +1,57 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskReminders20261017190000 struct {
+	RepeatInterval int64 `xorm:"bigint null"`
+}
+
+func (taskReminders20261017190000) TableName() string {
+	return "task_reminders"
+}
+
+type taskReminderSnoozes20261017190000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	TaskID       int64     `xorm:"bigint not null INDEX"`
+	UserID       int64     `xorm:"bigint not null INDEX"`
+	SnoozedUntil time.Time `xorm:"DATETIME not null INDEX 'snoozed_until'"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (taskReminderSnoozes20261017190000) TableName() string {
+	return "task_reminder_snoozes"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261017190000",
+		Description: "add reminder repeat interval and snoozes",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(taskReminders20261017190000{}, taskReminderSnoozes20261017190000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
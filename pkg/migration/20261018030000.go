@@ -0,0 +1,59 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type users20261018030000 struct {
+	DigestMode string `xorm:"varchar(10) null"`
+	DigestTime string `xorm:"varchar(5) null"`
+}
+
+func (users20261018030000) TableName() string {
+	return "users"
+}
+
+type notificationDigestItems20261018030000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	NotifiableID int64     `xorm:"bigint not null INDEX"`
+	Name         string    `xorm:"varchar(250) not null"`
+	Subject      string    `xorm:"text not null"`
+	URL          string    `xorm:"text null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (notificationDigestItems20261018030000) TableName() string {
+	return "notification_digest_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018030000",
+		Description: "add notification digests",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync(users20261018030000{}, notificationDigestItems20261018030000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
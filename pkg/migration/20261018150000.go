@@ -0,0 +1,93 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+const taskSearchIndexTable20261018150000 = "task_search_index"
+
+// createTaskSearchIndex20261018150000 creates the table of the native full text search. How it looks depends
+// on the database, sqlite only supports it when Vikunja was built with FTS5 support.
+func createTaskSearchIndex20261018150000(tx *xorm.Engine) error {
+	switch config.DatabaseType.GetString() {
+	case "postgres":
+		_, err := tx.Exec("CREATE TABLE IF NOT EXISTS " + taskSearchIndexTable20261018150000 + " (task_id BIGINT NOT NULL PRIMARY KEY, document TSVECTOR NOT NULL)")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("CREATE INDEX IF NOT EXISTS IDX_" + taskSearchIndexTable20261018150000 + "_document ON " + taskSearchIndexTable20261018150000 + " USING GIN (document)")
+		return err
+	case "mysql":
+		_, err := tx.Exec("CREATE TABLE IF NOT EXISTS " + taskSearchIndexTable20261018150000 + " (task_id BIGINT NOT NULL PRIMARY KEY, title LONGTEXT, description LONGTEXT, " +
+			"FULLTEXT INDEX IDX_" + taskSearchIndexTable20261018150000 + "_text (title, description)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+		return err
+	case "sqlite":
+		// The stemming of the tokenizer is part of the table, changing the language requires recreating it.
+		tokenizer := "unicode61 remove_diacritics 2"
+		if strings.ToLower(config.DatabaseFullTextLanguage.GetString()) == "english" {
+			tokenizer = "porter " + tokenizer
+		}
+		_, err := tx.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + taskSearchIndexTable20261018150000 + " USING fts5(title, description, tokenize = '" + tokenizer + "')")
+		if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+			log.Warningf("Could not create the full text search index because sqlite was built without FTS5 support. Build Vikunja with the sqlite_fts5 tag and run the index command to create it.")
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func dropTaskSearchIndex20261018150000(tx *xorm.Engine) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS " + taskSearchIndexTable20261018150000)
+	return err
+}
+
+// RecreateFullTextSearchIndex drops the table of the full text search and creates it again with the current
+// configuration. It is empty afterwards and needs to be filled with all tasks.
+func RecreateFullTextSearchIndex(x *xorm.Engine) (err error) {
+	if x == nil {
+		x, err = db.CreateDBEngine()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dropTaskSearchIndex20261018150000(x)
+	if err != nil {
+		return err
+	}
+
+	return createTaskSearchIndex20261018150000(x)
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018150000",
+		Description: "add the full text search index",
+		Migrate:     createTaskSearchIndex20261018150000,
+		Rollback:    dropTaskSearchIndex20261018150000,
+	})
+}
@@ -0,0 +1,122 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"encoding/json"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+type trashItems20261018170000 struct {
+	ID      int64  `xorm:"bigint autoincr not null unique pk"`
+	Content string `xorm:"longtext null"`
+}
+
+func (trashItems20261018170000) TableName() string {
+	return "trash_items"
+}
+
+type trashContent20261018170000 struct {
+	Tasks []struct {
+		Task struct {
+			ID int64 `json:"id"`
+		} `json:"task"`
+	} `json:"tasks"`
+}
+
+// negateTrashedTaskData20261018170000 negates the task id of all data which belongs to the tasks in the trash.
+// Negating it again moves the data back to the tasks.
+func negateTrashedTaskData20261018170000(tx *xorm.Engine, negated bool) error {
+	items := []*trashItems20261018170000{}
+	err := tx.Find(&items)
+	if err != nil {
+		return err
+	}
+
+	taskIDs := []int64{}
+	for _, item := range items {
+		content := &trashContent20261018170000{}
+		if item.Content != "" {
+			err = json.Unmarshal([]byte(item.Content), content)
+			if err != nil {
+				return err
+			}
+		}
+		for _, t := range content.Tasks {
+			if negated {
+				taskIDs = append(taskIDs, -t.Task.ID)
+				continue
+			}
+			taskIDs = append(taskIDs, t.Task.ID)
+		}
+	}
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	columns := []struct {
+		table  string
+		column string
+		cond   builder.Cond
+	}{
+		{table: "task_attachments", column: "task_id"},
+		{table: "task_attachment_texts", column: "task_id"},
+		{table: "task_assignees", column: "task_id"},
+		{table: "task_comments", column: "task_id"},
+		{table: "task_comment_revisions", column: "task_id"},
+		{table: "task_reminders", column: "task_id"},
+		{table: "task_reminder_snoozes", column: "task_id"},
+		{table: "task_occurrences", column: "task_id"},
+		{table: "task_activities", column: "task_id"},
+		{table: "task_time_entries", column: "task_id"},
+		{table: "task_custom_field_values", column: "task_id"},
+		{table: "task_references", column: "task_id"},
+		{table: "task_references", column: "source_task_id"},
+		{table: "favorites", column: "entity_id", cond: builder.Eq{"kind": 1}},
+		{table: "subscriptions", column: "entity_id", cond: builder.Eq{"entity_type": 3}},
+		{table: "reactions", column: "entity_id", cond: builder.Eq{"entity_kind": 0}},
+	}
+
+	for _, c := range columns {
+		cond, args, err := builder.ToSQL(builder.And(builder.In(c.column, taskIDs), c.cond))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(append([]interface{}{"UPDATE " + c.table + " SET " + c.column + " = -" + c.column + " WHERE " + cond}, args...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018170000",
+		Description: "negate the task id of the data of trashed tasks",
+		Migrate: func(tx *xorm.Engine) error {
+			return negateTrashedTaskData20261018170000(tx, false)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return negateTrashedTaskData20261018170000(tx, true)
+		},
+	})
+}
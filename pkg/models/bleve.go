@@ -0,0 +1,414 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"xorm.io/xorm"
+)
+
+// bleveKeywordAnalyzer indexes the whole value of a field as one lowercased term. This allows exact and
+// case-insensitive matches and sorting on string fields.
+const bleveKeywordAnalyzer = "keyword_lowercase"
+
+var bleveIndex bleve.Index
+
+func bleveEnabled() bool {
+	return config.SearchBackend.GetString() == "bleve" && bleveIndex != nil
+}
+
+// InitBleve opens the bleve search index. If the index does not exist yet, it is created and filled with all tasks.
+func InitBleve() (err error) {
+	if config.SearchBackend.GetString() != "bleve" || bleveIndex != nil {
+		return nil
+	}
+
+	path := config.SearchBlevePath.GetString()
+
+	// Only one process can open the index at a time. Without a timeout, opening it would block forever while
+	// another Vikunja process is running.
+	bleveIndex, err = bleve.OpenUsing(path, map[string]interface{}{
+		"bolt_timeout": "10s",
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return fmt.Errorf("could not open bleve index at %s, make sure no other Vikunja process is using it: %w", path, err)
+	}
+
+	indexMapping, err := getBleveIndexMapping()
+	if err != nil {
+		return err
+	}
+
+	bleveIndex, err = bleve.New(path, indexMapping)
+	if err != nil {
+		return fmt.Errorf("could not create bleve index at %s: %w", path, err)
+	}
+
+	log.Infof("Created the bleve search index at %s, indexing all tasks…", path)
+	return ReindexAllTasksIntoBleve()
+}
+
+// CloseBleve closes the bleve search index if it is open.
+func CloseBleve() (err error) {
+	if bleveIndex == nil {
+		return nil
+	}
+
+	err = bleveIndex.Close()
+	bleveIndex = nil
+	return
+}
+
+// RebuildBleveIndex removes the bleve search index from disk and recreates it with all tasks.
+func RebuildBleveIndex() (err error) {
+	if config.SearchBackend.GetString() != "bleve" {
+		return nil
+	}
+
+	err = CloseBleve()
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(config.SearchBlevePath.GetString())
+	if err != nil {
+		return fmt.Errorf("could not remove old bleve index: %w", err)
+	}
+
+	return InitBleve()
+}
+
+func getBleveIndexMapping() (*mapping.IndexMappingImpl, error) {
+	indexMapping := bleve.NewIndexMapping()
+	err := indexMapping.AddCustomAnalyzer(bleveKeywordAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions and custom fields are mapped dynamically since their field names depend on the view or field id.
+	indexMapping.DefaultAnalyzer = bleveKeywordAnalyzer
+	indexMapping.StoreDynamic = false
+
+	textField := bleve.NewTextFieldMapping()
+	textField.Analyzer = en.AnalyzerName
+	textField.Store = false
+	textField.IncludeInAll = false
+
+	keywordField := func(name string) *mapping.FieldMapping {
+		field := bleve.NewTextFieldMapping()
+		field.Name = name
+		field.Analyzer = bleveKeywordAnalyzer
+		field.Store = false
+		field.IncludeInAll = false
+		field.IncludeTermVectors = false
+		return field
+	}
+
+	numericField := bleve.NewNumericFieldMapping()
+	numericField.Store = false
+	numericField.IncludeInAll = false
+
+	booleanField := bleve.NewBooleanFieldMapping()
+	booleanField.Store = false
+	booleanField.IncludeInAll = false
+
+	taskMapping := bleve.NewDocumentMapping()
+	taskMapping.AddFieldMappingsAt("title", textField, keywordField("title_keyword"))
+	taskMapping.AddFieldMappingsAt("description", textField, keywordField("description_keyword"))
+	taskMapping.AddFieldMappingsAt("comments", textField)
+	taskMapping.AddFieldMappingsAt("attachment_texts", textField)
+
+	for _, field := range []string{"identifier", "uid", "hex_color", "created_by", "assignees"} {
+		taskMapping.AddFieldMappingsAt(field, keywordField(""))
+	}
+
+	for _, field := range []string{
+		"id",
+		"done_at",
+		"due_date",
+		"project_id",
+		"repeat_after",
+		"repeat_mode",
+		"priority",
+		"start_date",
+		"end_date",
+		"percent_done",
+		"estimated_time",
+		"time_spent",
+		"index",
+		"cover_image_attachment_id",
+		"created",
+		"updated",
+		"created_by_id",
+		"parent_task_ids",
+		"subtasks_done_percent",
+		"comment_count",
+		"reminders",
+		"occurrences",
+		"labels",
+		"buckets",
+	} {
+		taskMapping.AddFieldMappingsAt(field, numericField)
+	}
+
+	for _, field := range []string{"done", "has_subtasks", "is_blocked", "has_attachments"} {
+		taskMapping.AddFieldMappingsAt(field, booleanField)
+	}
+
+	indexMapping.DefaultMapping = taskMapping
+
+	return indexMapping, nil
+}
+
+// ReindexAllTasksIntoBleve indexes all tasks into the bleve search index, replacing existing documents.
+func ReindexAllTasksIntoBleve() (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	tasks := make(map[int64]*Task)
+	err = s.Find(tasks)
+	if err != nil {
+		return fmt.Errorf("could not get all tasks: %w", err)
+	}
+
+	err = reindexTasksInBleve(s, tasks)
+	if err != nil {
+		return fmt.Errorf("could not reindex all tasks: %w", err)
+	}
+
+	log.Infof("Indexed %d tasks into the bleve search index", len(tasks))
+	return nil
+}
+
+func reindexTasksInBleve(s *xorm.Session, tasks map[int64]*Task) (err error) {
+	if !bleveEnabled() || len(tasks) == 0 {
+		return nil
+	}
+
+	err = addMoreInfoToTasks(s, tasks, &user.User{ID: 1}, nil, []TaskCollectionExpandable{
+		TaskCollectionExpandComments,
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch more task info: %w", err)
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for id := range tasks {
+		taskIDs = append(taskIDs, id)
+	}
+	err = addOccurrencesToTasks(s, taskIDs, tasks)
+	if err != nil {
+		return fmt.Errorf("could not fetch task occurrences: %w", err)
+	}
+
+	positionsByTask, err := getPositionsByTask(s)
+	if err != nil {
+		return err
+	}
+
+	bucketsByTask, err := getBucketsByTask(s)
+	if err != nil {
+		return err
+	}
+
+	attachmentTextsByTask, err := getAttachmentTextsByTask(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	batch := bleveIndex.NewBatch()
+	for _, task := range tasks {
+		err = batch.Index(strconv.FormatInt(task.ID, 10), convertTaskToBleveTask(task, positionsByTask[task.ID], bucketsByTask[task.ID], attachmentTextsByTask[task.ID]))
+		if err != nil {
+			return fmt.Errorf("could not index task %d: %w", task.ID, err)
+		}
+	}
+
+	err = bleveIndex.Batch(batch)
+	if err != nil {
+		return fmt.Errorf("could not write tasks to the bleve index: %w", err)
+	}
+
+	log.Debugf("Indexed %d tasks into bleve", len(tasks))
+	return nil
+}
+
+func reindexTasksByIDsInBleve(s *xorm.Session, taskIDs []int64) error {
+	if !bleveEnabled() || len(taskIDs) == 0 {
+		return nil
+	}
+
+	tasks, err := GetTasksSimpleByIDs(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	taskMap := make(map[int64]*Task, len(tasks))
+	for _, task := range tasks {
+		taskMap[task.ID] = task
+	}
+
+	return reindexTasksInBleve(s, taskMap)
+}
+
+func removeTasksFromBleve(taskIDs []int64) error {
+	if !bleveEnabled() || len(taskIDs) == 0 {
+		return nil
+	}
+
+	batch := bleveIndex.NewBatch()
+	for _, id := range taskIDs {
+		batch.Delete(strconv.FormatInt(id, 10))
+	}
+
+	return bleveIndex.Batch(batch)
+}
+
+// bleveTask is the document stored in the bleve index. Dates are stored as unix timestamps, like in Typesense.
+type bleveTask struct {
+	ID                     int64                  `json:"id"`
+	Title                  string                 `json:"title"`
+	Description            string                 `json:"description"`
+	Done                   bool                   `json:"done"`
+	DoneAt                 *int64                 `json:"done_at"`
+	DueDate                *int64                 `json:"due_date"`
+	ProjectID              int64                  `json:"project_id"`
+	RepeatAfter            int64                  `json:"repeat_after"`
+	RepeatMode             int                    `json:"repeat_mode"`
+	Priority               int64                  `json:"priority"`
+	StartDate              *int64                 `json:"start_date"`
+	EndDate                *int64                 `json:"end_date"`
+	HexColor               string                 `json:"hex_color"`
+	PercentDone            float64                `json:"percent_done"`
+	EstimatedTime          int64                  `json:"estimated_time"`
+	TimeSpent              int64                  `json:"time_spent"`
+	Identifier             string                 `json:"identifier"`
+	Index                  int64                  `json:"index"`
+	UID                    string                 `json:"uid"`
+	CoverImageAttachmentID int64                  `json:"cover_image_attachment_id"`
+	Created                int64                  `json:"created"`
+	Updated                int64                  `json:"updated"`
+	CreatedByID            int64                  `json:"created_by_id"`
+	CreatedBy              string                 `json:"created_by"`
+	ParentTaskIDs          []int64                `json:"parent_task_ids"`
+	HasSubtasks            bool                   `json:"has_subtasks"`
+	SubtasksDonePercent    *float64               `json:"subtasks_done_percent"`
+	IsBlocked              bool                   `json:"is_blocked"`
+	HasAttachments         bool                   `json:"has_attachments"`
+	CommentCount           int64                  `json:"comment_count"`
+	Comments               []string               `json:"comments"`
+	AttachmentTexts        []string               `json:"attachment_texts"`
+	Reminders              []int64                `json:"reminders"`
+	Occurrences            []int64                `json:"occurrences"`
+	Assignees              []string               `json:"assignees"`
+	Labels                 []int64                `json:"labels"`
+	Positions              map[string]float64     `json:"positions"`
+	Buckets                []int64                `json:"buckets"`
+	CustomFields           map[string]interface{} `json:"custom_fields"`
+}
+
+func convertTaskToBleveTask(task *Task, positions []*TaskPositionWithView, buckets []*TaskBucket, attachmentTexts []string) *bleveTask {
+	// The derived fields are the same as in Typesense
+	tt := convertTaskToTypesenseTask(task, positions, buckets, attachmentTexts)
+
+	bt := &bleveTask{
+		ID:                     task.ID,
+		Title:                  tt.Title,
+		Description:            tt.Description,
+		Done:                   tt.Done,
+		DoneAt:                 tt.DoneAt,
+		DueDate:                tt.DueDate,
+		ProjectID:              tt.ProjectID,
+		RepeatAfter:            tt.RepeatAfter,
+		RepeatMode:             tt.RepeatMode,
+		Priority:               tt.Priority,
+		StartDate:              tt.StartDate,
+		EndDate:                tt.EndDate,
+		HexColor:               tt.HexColor,
+		PercentDone:            tt.PercentDone,
+		EstimatedTime:          tt.EstimatedTime,
+		TimeSpent:              tt.TimeSpent,
+		Identifier:             tt.Identifier,
+		Index:                  tt.Index,
+		UID:                    tt.UID,
+		CoverImageAttachmentID: tt.CoverImageAttachmentID,
+		Created:                tt.Created,
+		Updated:                tt.Updated,
+		CreatedByID:            tt.CreatedByID,
+		CreatedBy:              tt.CreatedBy,
+		ParentTaskIDs:          tt.ParentTaskIDs,
+		HasSubtasks:            tt.HasSubtasks,
+		SubtasksDonePercent:    tt.SubtasksDonePercent,
+		IsBlocked:              tt.IsBlocked,
+		HasAttachments:         tt.HasAttachments,
+		CommentCount:           tt.CommentCount,
+		Comments:               make([]string, 0, len(task.Comments)),
+		AttachmentTexts:        tt.AttachmentTexts,
+		Reminders:              make([]int64, 0, len(task.Reminders)),
+		Occurrences:            tt.Occurrences,
+		Assignees:              make([]string, 0, len(task.Assignees)),
+		Labels:                 make([]int64, 0, len(task.Labels)),
+		Positions:              tt.Positions,
+		Buckets:                tt.Buckets,
+		CustomFields:           tt.CustomFields,
+	}
+
+	for _, comment := range task.Comments {
+		bt.Comments = append(bt.Comments, comment.Comment)
+	}
+
+	for _, reminder := range task.Reminders {
+		bt.Reminders = append(bt.Reminders, reminder.Reminder.UTC().Unix())
+	}
+
+	for _, assignee := range task.Assignees {
+		bt.Assignees = append(bt.Assignees, assignee.Username)
+	}
+
+	for _, label := range task.Labels {
+		bt.Labels = append(bt.Labels, label.ID)
+	}
+
+	return bt
+}
+
+func getBleveTimestamp(t time.Time) float64 {
+	return float64(t.UTC().Unix())
+}
@@ -0,0 +1,171 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBleve(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	config.SearchBackend.Set("bleve")
+	config.SearchBlevePath.Set(filepath.Join(t.TempDir(), "search.bleve"))
+	t.Cleanup(func() {
+		require.NoError(t, CloseBleve())
+		config.SearchBackend.Set("database")
+	})
+
+	require.NoError(t, InitBleve())
+}
+
+func readAllTaskIDs(t *testing.T, tc *TaskCollection, search string, page int, perPage int) []int64 {
+	s := db.NewSession()
+	defer s.Close()
+
+	result, _, _, err := tc.ReadAll(s, &user.User{ID: 1}, search, page, perPage)
+	require.NoError(t, err)
+
+	ids := []int64{}
+	for _, task := range result.([]*Task) {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestBleveTaskSearcher(t *testing.T) {
+	t.Run("same results as the db", func(t *testing.T) {
+		collections := map[string]*TaskCollection{
+			"done":                 {Filter: "done = true"},
+			"priority":             {Filter: "priority >= 3"},
+			"due date":             {Filter: "due_date > 2018-12-01"},
+			"labels":               {Filter: "labels in 4, 5"},
+			"not labels":           {Filter: "labels != 4", ProjectID: 1},
+			"assignees":            {Filter: "assignees in user1, user2"},
+			"and":                  {Filter: "done = false && priority >= 3"},
+			"or":                   {Filter: "priority = 4 || priority = 1"},
+			"nested":               {Filter: "done = false && (priority = 4 || priority = 1)"},
+			"like":                 {Filter: "title like BASIC"},
+			"percent done":         {Filter: "percent_done > 0.3"},
+			"parent task":          {Filter: "parent_task = 1"},
+			"has attachments":      {Filter: "has_attachments = true"},
+			"created by":           {Filter: "created_by = user1", ProjectID: 1},
+			"sort by due date":     {SortBy: []string{"due_date"}, OrderBy: []string{"desc"}, ProjectID: 1},
+			"sort by title":        {SortBy: []string{"title"}, OrderBy: []string{"asc"}, ProjectID: 1},
+			"sort by done, id":     {SortBy: []string{"done", "id"}, OrderBy: []string{"desc", "desc"}, ProjectID: 1},
+			"sort by priority asc": {SortBy: []string{"priority"}, OrderBy: []string{"asc"}, Filter: "priority > 0"},
+		}
+
+		for name, tc := range collections {
+			t.Run(name, func(t *testing.T) {
+				db.LoadAndAssertFixtures(t)
+				expected := readAllTaskIDs(t, tc, "", 0, 50)
+				require.NotEmpty(t, expected)
+
+				setupBleve(t)
+				assert.Equal(t, expected, readAllTaskIDs(t, tc, "", 0, 50))
+			})
+		}
+	})
+	t.Run("pagination", func(t *testing.T) {
+		setupBleve(t)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{ProjectID: 1}
+		result, resultCount, totalItems, err := tc.ReadAll(s, &user.User{ID: 1}, "", 2, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 5, resultCount)
+		assert.Len(t, result, 5)
+		assert.Equal(t, int64(6), result.([]*Task)[0].ID)
+		assert.Greater(t, totalItems, int64(10))
+	})
+	t.Run("search", func(t *testing.T) {
+		setupBleve(t)
+
+		tc := &TaskCollection{ProjectID: 1}
+		assert.Equal(t, []int64{6}, readAllTaskIDs(t, tc, "unique", 0, 50))
+		assert.Equal(t, []int64{3}, readAllTaskIDs(t, tc, "high prio", 0, 50))
+		assert.Equal(t, []int64{3}, readAllTaskIDs(t, tc, "#3", 0, 50))
+		// Stemming
+		assert.Contains(t, readAllTaskIDs(t, tc, "dates", 0, 50), int64(7))
+		// Prefix
+		assert.Contains(t, readAllTaskIDs(t, tc, "bas", 0, 50), int64(10))
+	})
+	t.Run("search in comments and attachments", func(t *testing.T) {
+		setupBleve(t)
+
+		tc := &TaskCollection{ProjectID: 1}
+		assert.Empty(t, readAllTaskIDs(t, tc, "dolor", 0, 50))
+		assert.Empty(t, readAllTaskIDs(t, tc, "revenue", 0, 50))
+
+		tc.SearchIn = []TaskSearchField{TaskSearchFieldComments}
+		assert.Equal(t, []int64{1}, readAllTaskIDs(t, tc, "dolor", 0, 50))
+		assert.Empty(t, readAllTaskIDs(t, tc, "revenue", 0, 50))
+
+		tc.SearchIn = []TaskSearchField{TaskSearchFieldAttachments}
+		assert.Equal(t, []int64{1}, readAllTaskIDs(t, tc, "revenue", 0, 50))
+
+		s := db.NewSession()
+		defer s.Close()
+		res, _, _, err := tc.ReadAll(s, &user.User{ID: 1}, "revenue", 0, 50)
+		require.NoError(t, err)
+		tasks := res.([]*Task)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, []TaskSearchField{TaskSearchFieldAttachments}, tasks[0].MatchedIn)
+	})
+	t.Run("listener", func(t *testing.T) {
+		setupBleve(t)
+
+		tc := &TaskCollection{ProjectID: 1}
+
+		s := db.NewSession()
+		_, err := s.Where("id = ?", 12).Cols("title").Update(&Task{Title: "Quarterly report"})
+		require.NoError(t, err)
+		s.Close()
+		assert.Empty(t, readAllTaskIDs(t, tc, "quarterly", 0, 50))
+
+		events.TestListener(t, &TaskUpdatedEvent{Task: &Task{ID: 12}}, &UpdateTaskInBleve{})
+		assert.Equal(t, []int64{12}, readAllTaskIDs(t, tc, "quarterly", 0, 50))
+		// Matches in the title rank before ones in the description
+		s = db.NewSession()
+		_, err = s.Where("id = ?", 2).Cols("description").Update(&Task{Description: "Part of the quarterly report"})
+		require.NoError(t, err)
+		s.Close()
+		events.TestListener(t, &TaskUpdatedEvent{Task: &Task{ID: 2}}, &UpdateTaskInBleve{})
+		assert.Equal(t, []int64{12, 2}, readAllTaskIDs(t, tc, "quarterly", 0, 50))
+
+		events.TestListener(t, &TaskDeletedEvent{Task: &Task{ID: 12}}, &RemoveTaskFromBleve{})
+		assert.Equal(t, []int64{2}, readAllTaskIDs(t, tc, "quarterly", 0, 50))
+	})
+	t.Run("rebuild", func(t *testing.T) {
+		setupBleve(t)
+
+		require.NoError(t, RebuildBleveIndex())
+		assert.Equal(t, []int64{6}, readAllTaskIDs(t, &TaskCollection{ProjectID: 1}, "unique", 0, 50))
+	})
+}
@@ -17,6 +17,9 @@
 package models
 
 import (
+	"slices"
+	"time"
+
 	"code.vikunja.io/api/pkg/web"
 
 	"dario.cat/mergo"
@@ -80,13 +83,39 @@ func (bt *BulkTask) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/bulk [post]
 func (bt *BulkTask) Update(s *xorm.Session, a web.Auth) (err error) {
+	err = bt.Task.normalizeRepeatRule()
+	if err != nil {
+		return err
+	}
+
 	for _, oldtask := range bt.Tasks {
+		original := *oldtask
+
+		if !oldtask.Done && bt.Done {
+			err = checkTaskCanBeDone(s, oldtask)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !oldtask.Done && bt.Done && oldtask.isRepeating() {
+			err = saveTaskOccurrence(s, a, oldtask, time.Now())
+			if err != nil {
+				return err
+			}
+		}
 
 		// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 		updateDone(oldtask, &bt.Task)
 
 		// Update the assignees
-		if err := oldtask.updateTaskAssignees(s, bt.Assignees, a); err != nil {
+		assigneeChanges, err := oldtask.updateTaskAssignees(s, bt.Assignees, a)
+		if err != nil {
+			return err
+		}
+
+		// Update the custom fields
+		if err := oldtask.updateCustomFieldValues(s, bt.CustomFields); err != nil {
 			return err
 		}
 
@@ -102,20 +131,41 @@ func (bt *BulkTask) Update(s *xorm.Session, a web.Auth) (err error) {
 			oldtask.Done = false
 		}
 
+		oldtask.setRepeatRuleStart(&original)
+
+		colsToUpdate := []string{
+			"title",
+			"description",
+			"done",
+			"due_date",
+			"reminders",
+			"repeat_after",
+			"repeat_rule",
+			"repeat_rule_start",
+			"priority",
+			"start_date",
+			"end_date",
+			"estimated_time",
+		}
 		_, err = s.ID(oldtask.ID).
-			Cols("title",
-				"description",
-				"done",
-				"due_date",
-				"reminders",
-				"repeat_after",
-				"priority",
-				"start_date",
-				"end_date").
+			Cols(colsToUpdate...).
 			Update(oldtask)
 		if err != nil {
 			return err
 		}
+
+		// Only the columns which were actually saved changed
+		changes := []*TaskActivityChange{}
+		for _, change := range getTaskFieldChanges(&original, oldtask) {
+			if slices.Contains(colsToUpdate, change.Field) {
+				changes = append(changes, change)
+			}
+		}
+		changes = append(changes, assigneeChanges...)
+		err = recordTaskChanges(s, a, oldtask.ID, changes...)
+		if err != nil {
+			return err
+		}
 	}
 
 	return
@@ -0,0 +1,435 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"errors"
+	"net/http"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/xorm"
+)
+
+// BulkTaskActionMode controls what happens when an action fails for one of the tasks
+type BulkTaskActionMode string
+
+// All available bulk task action modes
+const (
+	// BulkTaskActionModeAtomic rolls back all changes when the actions failed for one task.
+	BulkTaskActionModeAtomic BulkTaskActionMode = `atomic`
+	// BulkTaskActionModeBestEffort applies the actions to all tasks where possible and keeps the successful changes.
+	BulkTaskActionModeBestEffort BulkTaskActionMode = `best_effort`
+)
+
+// BulkTaskActionStatus is the outcome of a bulk task action for a single task
+type BulkTaskActionStatus string
+
+// All possible outcomes of a bulk task action for a single task
+const (
+	BulkTaskActionStatusSuccess    BulkTaskActionStatus = `success`
+	BulkTaskActionStatusFailed     BulkTaskActionStatus = `failed`
+	BulkTaskActionStatusRolledBack BulkTaskActionStatus = `rolled_back`
+	BulkTaskActionStatusSkipped    BulkTaskActionStatus = `skipped`
+)
+
+// BulkTaskAction applies the same actions to many tasks at once. Unlike BulkTask, the tasks can be in different projects.
+type BulkTaskAction struct {
+	// The ids of all tasks to apply the actions to.
+	TaskIDs []int64 `json:"task_ids"`
+	// Either `atomic` (the default) to only apply the actions if they succeed for all tasks or `best_effort` to
+	// apply them to all tasks where possible.
+	Mode BulkTaskActionMode `json:"mode"`
+
+	// If set, the tasks will be moved to this project.
+	ProjectID int64 `json:"project_id"`
+	// If set, the tasks will be moved into this bucket. The bucket must belong to a kanban view of the project the
+	// tasks are in after all other actions were applied.
+	BucketID int64 `json:"bucket_id"`
+	// If set, the tasks will be marked as done or undone.
+	Done *bool `json:"done"`
+	// The ids of labels to add to the tasks.
+	AddLabelIDs []int64 `json:"add_label_ids"`
+	// The ids of labels to remove from the tasks.
+	RemoveLabelIDs []int64 `json:"remove_label_ids"`
+	// The ids of users to assign to the tasks.
+	AddAssigneeIDs []int64 `json:"add_assignee_ids"`
+	// The ids of users to remove as assignees from the tasks.
+	RemoveAssigneeIDs []int64 `json:"remove_assignee_ids"`
+	// If true, the tasks will be deleted. This cannot be combined with any other action.
+	DeleteTasks bool `json:"delete"`
+
+	// The outcome for every task.
+	Results []*BulkTaskActionResult `json:"results"`
+
+	web.CRUDable    `json:"-"`
+	web.Permissions `json:"-"`
+}
+
+// BulkTaskActionResult holds the outcome of a bulk task action for a single task
+type BulkTaskActionResult struct {
+	TaskID int64                `json:"task_id"`
+	Status BulkTaskActionStatus `json:"status"`
+	// The reason why the actions failed for this task.
+	Error *web.HTTPError `json:"error,omitempty"`
+}
+
+func (bta *BulkTaskAction) hasUpdateActions() bool {
+	return bta.ProjectID != 0 ||
+		bta.BucketID != 0 ||
+		bta.Done != nil ||
+		len(bta.AddLabelIDs) > 0 ||
+		len(bta.RemoveLabelIDs) > 0 ||
+		len(bta.AddAssigneeIDs) > 0 ||
+		len(bta.RemoveAssigneeIDs) > 0
+}
+
+func (bta *BulkTaskAction) validate() error {
+	if len(bta.TaskIDs) == 0 {
+		return ErrBulkTasksNeedAtLeastOne{}
+	}
+
+	if bta.Mode == "" {
+		bta.Mode = BulkTaskActionModeAtomic
+	}
+	if bta.Mode != BulkTaskActionModeAtomic && bta.Mode != BulkTaskActionModeBestEffort {
+		return &ErrInvalidBulkTaskActionMode{Mode: bta.Mode}
+	}
+
+	if bta.DeleteTasks && bta.hasUpdateActions() {
+		return &ErrBulkTaskDeleteCannotBeCombined{}
+	}
+	if !bta.DeleteTasks && !bta.hasUpdateActions() {
+		return &ErrBulkTaskActionNeedsAtLeastOneAction{}
+	}
+
+	return nil
+}
+
+// CanCreate checks if a user can do bulk task actions. The permissions are checked for every task when applying the actions.
+func (bta *BulkTaskAction) CanCreate(_ *xorm.Session, _ web.Auth) (bool, error) {
+	return true, nil
+}
+
+// Create applies the actions to all tasks
+// @Summary Apply actions to many tasks at once
+// @Description Moves, labels, assigns, marks as done or deletes many tasks at once. The tasks can be in different projects, the permissions are checked for every task. In `atomic` mode, nothing is changed if the actions fail for one of the tasks. In `best_effort` mode, the actions are applied to all tasks where possible. The response contains the outcome for every task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param actions body models.BulkTaskAction true "The tasks and the actions to apply to them."
+// @Success 201 {object} models.BulkTaskAction "The outcome for every task."
+// @Failure 400 {object} web.HTTPError "Invalid actions provided."
+// @Failure 403 {object} web.HTTPErrorWithDetails "In `atomic` mode, the actions failed for one of the tasks and nothing was changed. The status code is the one of the failed task, the details contain the outcome for every task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/bulk/actions [post]
+func (bta *BulkTaskAction) Create(s *xorm.Session, a web.Auth) (err error) {
+	err = bta.validate()
+	if err != nil {
+		return err
+	}
+
+	taskIDs := make([]int64, 0, len(bta.TaskIDs))
+	seen := make(map[int64]bool, len(bta.TaskIDs))
+	for _, id := range bta.TaskIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		taskIDs = append(taskIDs, id)
+	}
+
+	bta.Results = make([]*BulkTaskActionResult, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		bta.Results = append(bta.Results, &BulkTaskActionResult{
+			TaskID: id,
+			Status: BulkTaskActionStatusSkipped,
+		})
+	}
+
+	if bta.Mode == BulkTaskActionModeBestEffort {
+		for _, result := range bta.Results {
+			err = s.Begin()
+			if err != nil {
+				return err
+			}
+			events.HoldUntilCommit(s)
+
+			err = bta.applyToTask(s, a, result.TaskID)
+			if err != nil {
+				_ = s.Rollback()
+				events.DiscardPending(s)
+				result.Status = BulkTaskActionStatusFailed
+				result.Error = getBulkTaskActionError(err)
+				continue
+			}
+
+			err = s.Commit()
+			if err != nil {
+				events.DiscardPending(s)
+				return err
+			}
+			result.Status = BulkTaskActionStatusSuccess
+
+			err = events.DispatchPending(s)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err = s.Begin()
+	if err != nil {
+		return err
+	}
+	// Nothing must be announced before it is clear that all changes are kept
+	events.HoldUntilCommit(s)
+
+	for i, result := range bta.Results {
+		err = bta.applyToTask(s, a, result.TaskID)
+		if err == nil {
+			result.Status = BulkTaskActionStatusSuccess
+			continue
+		}
+
+		_ = s.Rollback()
+		events.DiscardPending(s)
+		result.Status = BulkTaskActionStatusFailed
+		result.Error = getBulkTaskActionError(err)
+		for _, previous := range bta.Results[:i] {
+			previous.Status = BulkTaskActionStatusRolledBack
+		}
+		return &ErrBulkTaskActionFailed{
+			TaskID:  result.TaskID,
+			Reason:  result.Error,
+			Results: bta.Results,
+		}
+	}
+
+	err = s.Commit()
+	if err != nil {
+		events.DiscardPending(s)
+		return err
+	}
+
+	return events.DispatchPending(s)
+}
+
+func getBulkTaskActionError(err error) *web.HTTPError {
+	var processor web.HTTPErrorProcessor
+	if errors.As(err, &processor) {
+		httpErr := processor.HTTPError()
+		return &httpErr
+	}
+
+	log.Errorf("Could not apply bulk task action: %s", err)
+	return &web.HTTPError{
+		HTTPCode: http.StatusInternalServerError,
+		Message:  http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+func (bta *BulkTaskAction) applyToTask(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	if bta.DeleteTasks {
+		task := &Task{ID: taskID}
+		can, err := task.CanDelete(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return ErrGenericForbidden{}
+		}
+		return task.Delete(s, a)
+	}
+
+	// Also checks the permission to move the task to the new project
+	task := &Task{ID: taskID, ProjectID: bta.ProjectID}
+	can, err := task.CanUpdate(s, a)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	if bta.ProjectID != 0 || bta.Done != nil {
+		err = bta.updateTask(s, a, taskID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if bta.BucketID != 0 {
+		err = bta.moveTaskToBucket(s, a, taskID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = bta.updateTaskLabels(s, a, taskID)
+	if err != nil {
+		return err
+	}
+
+	return bta.updateTaskAssignees(s, a, taskID)
+}
+
+func (bta *BulkTaskAction) updateTask(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	task := &Task{ID: taskID}
+	err = task.ReadOne(s, a)
+	if err != nil {
+		return err
+	}
+
+	if bta.ProjectID != 0 {
+		task.ProjectID = bta.ProjectID
+	}
+	if bta.Done != nil {
+		task.Done = *bta.Done
+	}
+
+	// Only the fields changed here should be updated
+	task.CustomFields = nil
+
+	return task.Update(s, a)
+}
+
+func (bta *BulkTaskAction) moveTaskToBucket(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	bucket, err := getBucketByID(s, bta.BucketID)
+	if err != nil {
+		return err
+	}
+
+	view, err := GetProjectViewByID(s, bucket.ProjectViewID)
+	if err != nil {
+		return err
+	}
+
+	task, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		return err
+	}
+
+	if view.ProjectID != task.ProjectID {
+		return ErrBucketDoesNotBelongToProjectView{
+			BucketID:      bucket.ID,
+			ProjectViewID: view.ID,
+		}
+	}
+
+	tb := &TaskBucket{
+		BucketID:      bucket.ID,
+		TaskID:        taskID,
+		ProjectViewID: view.ID,
+		ProjectID:     view.ProjectID,
+	}
+	can, err := tb.CanUpdate(s, a)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	return tb.Update(s, a)
+}
+
+func (bta *BulkTaskAction) updateTaskLabels(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	for _, labelID := range bta.AddLabelIDs {
+		lt := &LabelTask{TaskID: taskID, LabelID: labelID}
+		exists, err := s.Exist(&LabelTask{TaskID: taskID, LabelID: labelID})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		can, err := lt.CanCreate(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return ErrGenericForbidden{}
+		}
+
+		err = lt.Create(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, labelID := range bta.RemoveLabelIDs {
+		lt := &LabelTask{TaskID: taskID, LabelID: labelID}
+		// Also returns false if the label is not on the task, in which case there is nothing to do
+		can, err := lt.CanDelete(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			continue
+		}
+
+		err = lt.Delete(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bta *BulkTaskAction) updateTaskAssignees(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	for _, userID := range bta.AddAssigneeIDs {
+		exists, err := s.Exist(&TaskAssginee{TaskID: taskID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		la := &TaskAssginee{TaskID: taskID, UserID: userID}
+		err = la.Create(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range bta.RemoveAssigneeIDs {
+		exists, err := s.Exist(&TaskAssginee{TaskID: taskID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		la := &TaskAssginee{TaskID: taskID, UserID: userID}
+		err = la.Delete(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkTaskAction_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	done := true
+
+	t.Run("move and mark done across projects", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bta := &BulkTaskAction{
+			TaskIDs:     []int64{10, 19},
+			ProjectID:   11,
+			Done:        &done,
+			AddLabelIDs: []int64{1},
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		require.Len(t, bta.Results, 2)
+		for _, result := range bta.Results {
+			assert.Equal(t, BulkTaskActionStatusSuccess, result.Status)
+			assert.Nil(t, result.Error)
+
+			db.AssertExists(t, "tasks", map[string]interface{}{
+				"id":         result.TaskID,
+				"project_id": 11,
+				"done":       true,
+			}, false)
+			db.AssertExists(t, "label_tasks", map[string]interface{}{
+				"task_id":  result.TaskID,
+				"label_id": 1,
+			}, false)
+		}
+	})
+	t.Run("atomic rolls back everything", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		events.Fake()
+
+		bta := &BulkTaskAction{
+			TaskIDs:     []int64{10, 14, 11},
+			Done:        &done,
+			AddLabelIDs: []int64{1},
+		}
+		err := bta.Create(s, u)
+		require.Error(t, err)
+		require.True(t, IsErrBulkTaskActionFailed(err))
+		assert.Equal(t, http.StatusForbidden, err.(*ErrBulkTaskActionFailed).HTTPError().HTTPCode)
+		_ = s.Rollback()
+
+		require.Len(t, bta.Results, 3)
+		assert.Equal(t, BulkTaskActionStatusRolledBack, bta.Results[0].Status)
+		assert.Equal(t, BulkTaskActionStatusFailed, bta.Results[1].Status)
+		require.NotNil(t, bta.Results[1].Error)
+		assert.Equal(t, http.StatusForbidden, bta.Results[1].Error.HTTPCode)
+		assert.Equal(t, BulkTaskActionStatusSkipped, bta.Results[2].Status)
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id":  10,
+			"label_id": 1,
+		})
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":   10,
+			"done": false,
+		}, false)
+		events.AssertNotDispatched(t, &TaskUpdatedEvent{})
+	})
+	t.Run("events are dispatched after commit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		events.Fake()
+
+		bta := &BulkTaskAction{
+			TaskIDs: []int64{10, 11},
+			Done:    &done,
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		events.AssertDispatched(t, &TaskUpdatedEvent{})
+	})
+	t.Run("best effort keeps successful changes", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bta := &BulkTaskAction{
+			TaskIDs:     []int64{10, 14, 11},
+			Mode:        BulkTaskActionModeBestEffort,
+			AddLabelIDs: []int64{1},
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		require.Len(t, bta.Results, 3)
+		assert.Equal(t, BulkTaskActionStatusSuccess, bta.Results[0].Status)
+		assert.Equal(t, BulkTaskActionStatusFailed, bta.Results[1].Status)
+		assert.Equal(t, BulkTaskActionStatusSuccess, bta.Results[2].Status)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  10,
+			"label_id": 1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  11,
+			"label_id": 1,
+		}, false)
+	})
+	t.Run("labels and assignees", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bta := &BulkTaskAction{
+			TaskIDs:           []int64{1, 30},
+			RemoveLabelIDs:    []int64{4},
+			AddAssigneeIDs:    []int64{1},
+			RemoveAssigneeIDs: []int64{2},
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		for _, result := range bta.Results {
+			assert.Equal(t, BulkTaskActionStatusSuccess, result.Status)
+			db.AssertMissing(t, "label_tasks", map[string]interface{}{
+				"task_id":  result.TaskID,
+				"label_id": 4,
+			})
+			db.AssertExists(t, "task_assignees", map[string]interface{}{
+				"task_id": result.TaskID,
+				"user_id": 1,
+			}, false)
+			db.AssertMissing(t, "task_assignees", map[string]interface{}{
+				"task_id": result.TaskID,
+				"user_id": 2,
+			})
+		}
+	})
+	t.Run("bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bta := &BulkTaskAction{
+			TaskIDs:  []int64{10, 19},
+			Mode:     BulkTaskActionModeBestEffort,
+			BucketID: 3,
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, BulkTaskActionStatusSuccess, bta.Results[0].Status)
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":         10,
+			"bucket_id":       3,
+			"project_view_id": 4,
+		}, false)
+		// Bucket 3 is the done bucket
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":   10,
+			"done": true,
+		}, false)
+		// Task 19 is in a different project than the bucket
+		assert.Equal(t, BulkTaskActionStatusFailed, bta.Results[1].Status)
+		assert.Equal(t, ErrCodeBucketDoesNotBelongToProject, bta.Results[1].Error.Code)
+	})
+	t.Run("delete", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bta := &BulkTaskAction{
+			TaskIDs:     []int64{10, 19, 10},
+			DeleteTasks: true,
+		}
+		err := bta.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		require.Len(t, bta.Results, 2)
+		db.AssertMissing(t, "tasks", map[string]interface{}{"id": 10})
+		db.AssertMissing(t, "tasks", map[string]interface{}{"id": 19})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&BulkTaskAction{DeleteTasks: true}).Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrBulkTasksNeedAtLeastOne(err))
+
+		err = (&BulkTaskAction{TaskIDs: []int64{10}}).Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrBulkTaskActionNeedsAtLeastOneAction(err))
+
+		err = (&BulkTaskAction{TaskIDs: []int64{10}, DeleteTasks: true, Done: &done}).Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrBulkTaskDeleteCannotBeCombined(err))
+
+		err = (&BulkTaskAction{TaskIDs: []int64{10}, Done: &done, Mode: "sometimes"}).Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidBulkTaskActionMode(err))
+	})
+}
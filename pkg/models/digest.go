@@ -0,0 +1,331 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// DigestNotification summarizes the notifications held back since the last digest and the
+// tasks of a user which are due soon or overdue.
+type DigestNotification struct {
+	User         *user.User
+	Items        []*notifications.DigestItem
+	DueTasks     []*Task
+	OverdueTasks []*Task
+	Projects     map[int64]*Project
+}
+
+// ToMail returns the mail notification for DigestNotification
+func (n *DigestNotification) ToMail(lang string) *notifications.Mail {
+	subjectKey := "notifications.digest.daily_subject"
+	dueKey := "notifications.digest.due_today"
+	if n.User.DigestMode == user.DigestModeWeekly {
+		subjectKey = "notifications.digest.weekly_subject"
+		dueKey = "notifications.digest.due_this_week"
+	}
+
+	mail := notifications.NewMail().
+		IncludeLinkToSettings(lang).
+		Subject(i18n.T(lang, subjectKey)).
+		Greeting(i18n.T(lang, "notifications.greeting", n.User.GetName()))
+
+	if len(n.Items) > 0 {
+		activityLine := ""
+		for _, item := range n.Items {
+			if item.URL == "" {
+				activityLine += "* " + item.Subject + "\n"
+				continue
+			}
+			activityLine += "* [" + item.Subject + "](" + item.URL + ")\n"
+		}
+
+		mail.
+			Line(i18n.T(lang, "notifications.digest.activity")).
+			Line(activityLine)
+	}
+
+	if len(n.DueTasks) > 0 {
+		dueLine := ""
+		for _, task := range n.DueTasks {
+			dueLine += "* " + n.getTaskLink(task) + "\n"
+		}
+
+		mail.
+			Line(i18n.T(lang, dueKey)).
+			Line(dueLine)
+	}
+
+	if len(n.OverdueTasks) > 0 {
+		overdueLine := ""
+		for _, task := range n.OverdueTasks {
+			until := time.Until(task.DueDate).Round(1*time.Hour) * -1
+			overdueLine += "* " + n.getTaskLink(task) + ", " + i18n.T(lang, "notifications.task.overdue.overdue", getOverdueSinceString(until, lang)) + "\n"
+		}
+
+		mail.
+			Line(i18n.T(lang, "notifications.digest.overdue")).
+			Line(overdueLine)
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.common.actions.open_vikunja"), config.ServicePublicURL.GetString()).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
+}
+
+func (n *DigestNotification) getTaskLink(task *Task) string {
+	link := `[` + task.Title + `](` + config.ServicePublicURL.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `)`
+	if project, has := n.Projects[task.ProjectID]; has {
+		link += ` (` + project.Title + `)`
+	}
+	return link
+}
+
+// ToDB returns the DigestNotification notification in a format which can be saved in the db
+func (n *DigestNotification) ToDB() interface{} {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *DigestNotification) Name() string {
+	return "digest"
+}
+
+type userDigest struct {
+	user         *user.User
+	items        []*notifications.DigestItem
+	dueTasks     map[int64]*Task
+	overdueTasks map[int64]*Task
+	// Tasks due before dayStart are overdue, tasks due before windowEnd are due soon.
+	dayStart  time.Time
+	windowEnd time.Time
+}
+
+func (d *userDigest) isEmpty() bool {
+	return len(d.items) == 0 && len(d.dueTasks) == 0 && len(d.overdueTasks) == 0
+}
+
+// getDigestsDue returns the digests of all users whose digest time is in the minute starting at now.
+func getDigestsDue(s *xorm.Session, now time.Time) (digests map[int64]*userDigest, err error) {
+	now = utils.GetTimeWithoutSeconds(now)
+	nextMinute := now.Add(1 * time.Minute)
+
+	users := []*user.User{}
+	err = s.
+		Where("status != ?", user.StatusDisabled).
+		In("digest_mode", user.DigestModeDaily, user.DigestModeWeekly).
+		Find(&users)
+	if err != nil || len(users) == 0 {
+		return
+	}
+
+	digests = make(map[int64]*userDigest)
+	tzs := make(map[string]*time.Location)
+	var latestWindowEnd time.Time
+	for _, u := range users {
+		if u.Timezone == "" {
+			u.Timezone = config.GetTimeZone().String()
+		}
+
+		tz, exists := tzs[u.Timezone]
+		if !exists {
+			tz, err = time.LoadLocation(u.Timezone)
+			if err != nil {
+				return nil, err
+			}
+			tzs[u.Timezone] = tz
+		}
+
+		tm, err := time.Parse("15:04", u.GetDigestTime())
+		if err != nil {
+			log.Errorf("[Digest] Invalid digest time %s of user %d: %s", u.GetDigestTime(), u.ID, err)
+			continue
+		}
+
+		nowInTz := now.In(tz)
+		digestTime := time.Date(nowInTz.Year(), nowInTz.Month(), nowInTz.Day(), tm.Hour(), tm.Minute(), 0, 0, tz)
+		if digestTime.Before(now) || !digestTime.Before(nextMinute) {
+			continue
+		}
+
+		dayStart := time.Date(nowInTz.Year(), nowInTz.Month(), nowInTz.Day(), 0, 0, 0, 0, tz)
+		windowEnd := dayStart.AddDate(0, 0, 1)
+		if u.DigestMode == user.DigestModeWeekly {
+			if int(digestTime.Weekday()) != u.WeekStart {
+				continue
+			}
+			windowEnd = dayStart.AddDate(0, 0, 7)
+		}
+
+		if windowEnd.After(latestWindowEnd) {
+			latestWindowEnd = windowEnd
+		}
+
+		digests[u.ID] = &userDigest{
+			user:         u,
+			dueTasks:     make(map[int64]*Task),
+			overdueTasks: make(map[int64]*Task),
+			dayStart:     dayStart,
+			windowEnd:    windowEnd,
+		}
+	}
+
+	if len(digests) == 0 {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(digests))
+	for id, digest := range digests {
+		userIDs = append(userIDs, id)
+		digest.items, err = notifications.GetDigestItems(s, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tasks []*Task
+	err = s.
+		Where("due_date is not null AND due_date < ? AND projects.is_archived = false", latestWindowEnd.In(config.GetTimeZone()).Format(dbTimeFormat)).
+		Join("LEFT", "projects", "projects.id = tasks.project_id").
+		And("done = false").
+		Find(&tasks)
+	if err != nil || len(tasks) == 0 {
+		return digests, err
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	taskUsers, err := getTaskUsersForTasks(s, taskIDs, builder.In("users.id", userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tu := range taskUsers {
+		digest, has := digests[tu.User.ID]
+		if !has {
+			continue
+		}
+
+		switch {
+		case tu.Task.DueDate.Before(digest.dayStart):
+			digest.overdueTasks[tu.Task.ID] = tu.Task
+		case tu.Task.DueDate.Before(digest.windowEnd):
+			digest.dueTasks[tu.Task.ID] = tu.Task
+		}
+	}
+
+	return digests, nil
+}
+
+func sortTasksByDueDate(tasks map[int64]*Task) []*Task {
+	sorted := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		sorted = append(sorted, task)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].DueDate.Equal(sorted[j].DueDate) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].DueDate.Before(sorted[j].DueDate)
+	})
+
+	return sorted
+}
+
+// RegisterDigestCron registers a function which checks every minute if it is time to send
+// the daily or weekly digest of a user.
+func RegisterDigestCron() {
+	if !config.MailerEnabled.GetBool() {
+		log.Info("Mailer is disabled, not sending digests")
+		return
+	}
+
+	err := cron.Schedule("* * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		digests, err := getDigestsDue(s, time.Now())
+		if err != nil {
+			log.Errorf("[Digest] Could not get digests due in the next minute: %s", err)
+			return
+		}
+
+		taskIDs := []int64{}
+		for _, digest := range digests {
+			for id := range digest.dueTasks {
+				taskIDs = append(taskIDs, id)
+			}
+			for id := range digest.overdueTasks {
+				taskIDs = append(taskIDs, id)
+			}
+		}
+
+		projects, err := GetProjectsMapSimpleByTaskIDs(s, taskIDs)
+		if err != nil {
+			log.Errorf("[Digest] Could not get projects for tasks: %s", err)
+			return
+		}
+
+		for _, digest := range digests {
+			if digest.isEmpty() {
+				continue
+			}
+
+			err = notifications.Notify(digest.user, &DigestNotification{
+				User:         digest.user,
+				Items:        digest.items,
+				DueTasks:     sortTasksByDueDate(digest.dueTasks),
+				OverdueTasks: sortTasksByDueDate(digest.overdueTasks),
+				Projects:     projects,
+			})
+			if err != nil {
+				log.Errorf("[Digest] Could not send digest to user %d: %s", digest.user.ID, err)
+				continue
+			}
+
+			if len(digest.items) > 0 {
+				err = notifications.DeleteDigestItems(s, digest.user.ID, digest.items[len(digest.items)-1].ID)
+				if err != nil {
+					log.Errorf("[Digest] Could not remove sent digest items of user %d: %s", digest.user.ID, err)
+				}
+			}
+
+			log.Debugf("[Digest] Sent digest with %d items and %d tasks to user %d", len(digest.items), len(digest.dueTasks)+len(digest.overdueTasks), digest.user.ID)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register digest cron: %s", err)
+	}
+}
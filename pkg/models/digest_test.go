@@ -0,0 +1,129 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setDigestMode(t *testing.T, userID int64, mode user.DigestMode, weekStart int) {
+	s := db.NewSession()
+	defer s.Close()
+	_, err := s.
+		Where("id = ?", userID).
+		Cols("digest_mode", "digest_time", "week_start").
+		Update(&user.User{DigestMode: mode, DigestTime: "", WeekStart: weekStart})
+	require.NoError(t, err)
+}
+
+func TestGetDigestsDue(t *testing.T) {
+	t.Run("no digest mode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T08:00:00Z")
+		require.NoError(t, err)
+		digests, err := getDigestsDue(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+	t.Run("daily", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setDigestMode(t, 1, user.DigestModeDaily, 0)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&notifications.DigestItem{
+			NotifiableID: 1,
+			Name:         "task.comment",
+			Subject:      "Re: task #1",
+			URL:          "http://localhost/tasks/1",
+		})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T08:00:00Z")
+		require.NoError(t, err)
+		digests, err := getDigestsDue(s, now)
+		require.NoError(t, err)
+		require.Len(t, digests, 1)
+		require.Contains(t, digests, int64(1))
+
+		digest := digests[1]
+		require.Len(t, digest.items, 1)
+		assert.Equal(t, "Re: task #1", digest.items[0].Subject)
+		assert.Contains(t, digest.dueTasks, int64(5))
+		assert.Contains(t, digest.overdueTasks, int64(6))
+		// Archived project
+		assert.NotContains(t, digest.overdueTasks, int64(36))
+
+		mail := (&DigestNotification{
+			User:         digest.user,
+			Items:        digest.items,
+			DueTasks:     sortTasksByDueDate(digest.dueTasks),
+			OverdueTasks: sortTasksByDueDate(digest.overdueTasks),
+		}).ToMail("en")
+		_, err = notifications.RenderMail(mail, "en")
+		require.NoError(t, err)
+	})
+	t.Run("not yet time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setDigestMode(t, 1, user.DigestModeDaily, 0)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T07:59:00Z")
+		require.NoError(t, err)
+		digests, err := getDigestsDue(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+	t.Run("weekly on the first day of the week", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		// 2018-12-01 is a saturday
+		setDigestMode(t, 1, user.DigestModeWeekly, 6)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T08:00:00Z")
+		require.NoError(t, err)
+		digests, err := getDigestsDue(s, now)
+		require.NoError(t, err)
+		require.Len(t, digests, 1)
+		assert.Equal(t, time.Date(2018, 12, 8, 0, 0, 0, 0, digests[1].windowEnd.Location()), digests[1].windowEnd)
+	})
+	t.Run("weekly on another day", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setDigestMode(t, 1, user.DigestModeWeekly, 1)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T08:00:00Z")
+		require.NoError(t, err)
+		digests, err := getDigestsDue(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/web"
@@ -471,6 +472,113 @@ func (err *ErrProjectViewDoesNotExist) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrCustomFieldDoesNotExist represents an error where a custom field does not exist in a project
+type ErrCustomFieldDoesNotExist struct {
+	CustomFieldID int64
+	ProjectID     int64
+}
+
+// IsErrCustomFieldDoesNotExist checks if an error is ErrCustomFieldDoesNotExist.
+func IsErrCustomFieldDoesNotExist(err error) bool {
+	_, ok := err.(*ErrCustomFieldDoesNotExist)
+	return ok
+}
+
+func (err *ErrCustomFieldDoesNotExist) Error() string {
+	return fmt.Sprintf("Custom field does not exist [CustomFieldID: %d, ProjectID: %d]", err.CustomFieldID, err.ProjectID)
+}
+
+// ErrCodeCustomFieldDoesNotExist holds the unique world-error code of this error
+const ErrCodeCustomFieldDoesNotExist = 3015
+
+// HTTPError holds the http error description
+func (err *ErrCustomFieldDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeCustomFieldDoesNotExist,
+		Message:  "This custom field does not exist in the project.",
+	}
+}
+
+// ErrInvalidCustomFieldOptions represents an error where the options of a select custom field are invalid
+type ErrInvalidCustomFieldOptions struct {
+	Title string
+}
+
+// IsErrInvalidCustomFieldOptions checks if an error is ErrInvalidCustomFieldOptions.
+func IsErrInvalidCustomFieldOptions(err error) bool {
+	_, ok := err.(*ErrInvalidCustomFieldOptions)
+	return ok
+}
+
+func (err *ErrInvalidCustomFieldOptions) Error() string {
+	return fmt.Sprintf("Custom field options are invalid [Title: %s]", err.Title)
+}
+
+// ErrCodeInvalidCustomFieldOptions holds the unique world-error code of this error
+const ErrCodeInvalidCustomFieldOptions = 3016
+
+// HTTPError holds the http error description
+func (err *ErrInvalidCustomFieldOptions) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidCustomFieldOptions,
+		Message:  "A select custom field needs at least one option and all options must be unique and not empty.",
+	}
+}
+
+// ErrProjectViewIsNotGantt represents an error where a project view which is not a gantt view is scheduled
+type ErrProjectViewIsNotGantt struct {
+	ProjectViewID int64
+}
+
+// IsErrProjectViewIsNotGantt checks if an error is ErrProjectViewIsNotGantt.
+func IsErrProjectViewIsNotGantt(err error) bool {
+	_, ok := err.(*ErrProjectViewIsNotGantt)
+	return ok
+}
+
+func (err *ErrProjectViewIsNotGantt) Error() string {
+	return fmt.Sprintf("Project view is not a gantt view [ProjectViewID: %d]", err.ProjectViewID)
+}
+
+// ErrCodeProjectViewIsNotGantt holds the unique world-error code of this error
+const ErrCodeProjectViewIsNotGantt = 3017
+
+// HTTPError holds the http error description
+func (err *ErrProjectViewIsNotGantt) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeProjectViewIsNotGantt,
+		Message:  "Only gantt views can be scheduled.",
+	}
+}
+
+// ErrInboundEmailDisabled represents an error where inbound mails are used while they are disabled
+type ErrInboundEmailDisabled struct{}
+
+// IsErrInboundEmailDisabled checks if an error is ErrInboundEmailDisabled.
+func IsErrInboundEmailDisabled(err error) bool {
+	_, ok := err.(*ErrInboundEmailDisabled)
+	return ok
+}
+
+func (err *ErrInboundEmailDisabled) Error() string {
+	return "Inbound mails are disabled"
+}
+
+// ErrCodeInboundEmailDisabled holds the unique world-error code of this error
+const ErrCodeInboundEmailDisabled = 3018
+
+// HTTPError holds the http error description
+func (err *ErrInboundEmailDisabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInboundEmailDisabled,
+		Message:  "Creating tasks from emails is not enabled on this instance.",
+	}
+}
+
 // ==============
 // Task errors
 // ==============
@@ -1165,6 +1273,424 @@ func (err ErrMustHaveProjectViewToSortByPosition) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrInvalidRepeatRule represents an error where a task repeat rule is not a valid recurrence rule
+type ErrInvalidRepeatRule struct {
+	Rule   string
+	Reason string
+}
+
+// IsErrInvalidRepeatRule checks if an error is ErrInvalidRepeatRule.
+func IsErrInvalidRepeatRule(err error) bool {
+	_, ok := err.(ErrInvalidRepeatRule)
+	return ok
+}
+
+func (err ErrInvalidRepeatRule) Error() string {
+	return fmt.Sprintf("Repeat rule is invalid [Rule: %s, Reason: %s]", err.Rule, err.Reason)
+}
+
+// ErrCodeInvalidRepeatRule holds the unique world-error code of this error
+const ErrCodeInvalidRepeatRule = 4027
+
+// HTTPError holds the http error description
+func (err ErrInvalidRepeatRule) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidRepeatRule,
+		Message:  fmt.Sprintf("The repeat rule '%s' is invalid: %s", err.Rule, err.Reason),
+	}
+}
+
+// ErrTimeEntryDoesNotExist represents an error where a time entry does not exist
+type ErrTimeEntryDoesNotExist struct {
+	ID     int64
+	TaskID int64
+}
+
+// IsErrTimeEntryDoesNotExist checks if an error is ErrTimeEntryDoesNotExist.
+func IsErrTimeEntryDoesNotExist(err error) bool {
+	_, ok := err.(ErrTimeEntryDoesNotExist)
+	return ok
+}
+
+func (err ErrTimeEntryDoesNotExist) Error() string {
+	return fmt.Sprintf("Time entry does not exist [ID: %d, TaskID: %d]", err.ID, err.TaskID)
+}
+
+// ErrCodeTimeEntryDoesNotExist holds the unique world-error code of this error
+const ErrCodeTimeEntryDoesNotExist = 4028
+
+// HTTPError holds the http error description
+func (err ErrTimeEntryDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTimeEntryDoesNotExist,
+		Message:  "This time entry does not exist.",
+	}
+}
+
+// ErrInvalidTimeEntryRange represents an error where a time entry ends before it starts
+type ErrInvalidTimeEntryRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsErrInvalidTimeEntryRange checks if an error is ErrInvalidTimeEntryRange.
+func IsErrInvalidTimeEntryRange(err error) bool {
+	_, ok := err.(ErrInvalidTimeEntryRange)
+	return ok
+}
+
+func (err ErrInvalidTimeEntryRange) Error() string {
+	return fmt.Sprintf("Time entry ends before it starts [Start: %s, End: %s]", err.Start, err.End)
+}
+
+// ErrCodeInvalidTimeEntryRange holds the unique world-error code of this error
+const ErrCodeInvalidTimeEntryRange = 4029
+
+// HTTPError holds the http error description
+func (err ErrInvalidTimeEntryRange) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTimeEntryRange,
+		Message:  "A time entry needs a start and must not end before it starts.",
+	}
+}
+
+// ErrNoRunningTimer represents an error where a user tries to stop a timer which is not running
+type ErrNoRunningTimer struct {
+	TaskID int64
+	UserID int64
+}
+
+// IsErrNoRunningTimer checks if an error is ErrNoRunningTimer.
+func IsErrNoRunningTimer(err error) bool {
+	_, ok := err.(ErrNoRunningTimer)
+	return ok
+}
+
+func (err ErrNoRunningTimer) Error() string {
+	return fmt.Sprintf("No timer is running [TaskID: %d, UserID: %d]", err.TaskID, err.UserID)
+}
+
+// ErrCodeNoRunningTimer holds the unique world-error code of this error
+const ErrCodeNoRunningTimer = 4030
+
+// HTTPError holds the http error description
+func (err ErrNoRunningTimer) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeNoRunningTimer,
+		Message:  "There is no running timer for this task.",
+	}
+}
+
+// ErrInvalidCustomFieldValue represents an error where a task value does not match the type of its custom field
+type ErrInvalidCustomFieldValue struct {
+	CustomFieldID int64
+	Value         interface{}
+}
+
+// IsErrInvalidCustomFieldValue checks if an error is ErrInvalidCustomFieldValue.
+func IsErrInvalidCustomFieldValue(err error) bool {
+	_, ok := err.(*ErrInvalidCustomFieldValue)
+	return ok
+}
+
+func (err *ErrInvalidCustomFieldValue) Error() string {
+	return fmt.Sprintf("Custom field value is invalid [CustomFieldID: %d, Value: %v]", err.CustomFieldID, err.Value)
+}
+
+// ErrCodeInvalidCustomFieldValue holds the unique world-error code of this error
+const ErrCodeInvalidCustomFieldValue = 4031
+
+// HTTPError holds the http error description
+func (err *ErrInvalidCustomFieldValue) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidCustomFieldValue,
+		Message:  fmt.Sprintf("The value '%v' is not valid for the custom field %d.", err.Value, err.CustomFieldID),
+	}
+}
+
+// ErrTaskIsBlocked represents an error where a task is marked as done while it is blocked by other tasks which are not done
+type ErrTaskIsBlocked struct {
+	TaskID         int64
+	BlockedByTasks []int64
+}
+
+// IsErrTaskIsBlocked checks if an error is ErrTaskIsBlocked.
+func IsErrTaskIsBlocked(err error) bool {
+	_, ok := err.(*ErrTaskIsBlocked)
+	return ok
+}
+
+func (err *ErrTaskIsBlocked) Error() string {
+	return fmt.Sprintf("Task is blocked [TaskID: %d, BlockedByTasks: %v]", err.TaskID, err.BlockedByTasks)
+}
+
+// ErrCodeTaskIsBlocked holds the unique world-error code of this error
+const ErrCodeTaskIsBlocked = 4032
+
+// HTTPError holds the http error description
+func (err *ErrTaskIsBlocked) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTaskIsBlocked,
+		Message:  "This task cannot be marked as done because it is blocked by tasks which are not done yet.",
+	}
+}
+
+// ErrInvalidBulkTaskActionMode represents an error where an unknown bulk task action mode was provided
+type ErrInvalidBulkTaskActionMode struct {
+	Mode BulkTaskActionMode
+}
+
+// IsErrInvalidBulkTaskActionMode checks if an error is ErrInvalidBulkTaskActionMode.
+func IsErrInvalidBulkTaskActionMode(err error) bool {
+	_, ok := err.(*ErrInvalidBulkTaskActionMode)
+	return ok
+}
+
+func (err *ErrInvalidBulkTaskActionMode) Error() string {
+	return fmt.Sprintf("Invalid bulk task action mode [Mode: %s]", err.Mode)
+}
+
+// ErrCodeInvalidBulkTaskActionMode holds the unique world-error code of this error
+const ErrCodeInvalidBulkTaskActionMode = 4033
+
+// HTTPError holds the http error description
+func (err *ErrInvalidBulkTaskActionMode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidBulkTaskActionMode,
+		Message:  fmt.Sprintf("The mode '%s' is invalid, it must be either 'atomic' or 'best_effort'.", err.Mode),
+	}
+}
+
+// ErrBulkTaskDeleteCannotBeCombined represents an error where deleting tasks in bulk is combined with other actions
+type ErrBulkTaskDeleteCannotBeCombined struct{}
+
+// IsErrBulkTaskDeleteCannotBeCombined checks if an error is ErrBulkTaskDeleteCannotBeCombined.
+func IsErrBulkTaskDeleteCannotBeCombined(err error) bool {
+	_, ok := err.(*ErrBulkTaskDeleteCannotBeCombined)
+	return ok
+}
+
+func (err *ErrBulkTaskDeleteCannotBeCombined) Error() string {
+	return "Deleting tasks in bulk cannot be combined with other actions"
+}
+
+// ErrCodeBulkTaskDeleteCannotBeCombined holds the unique world-error code of this error
+const ErrCodeBulkTaskDeleteCannotBeCombined = 4034
+
+// HTTPError holds the http error description
+func (err *ErrBulkTaskDeleteCannotBeCombined) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeBulkTaskDeleteCannotBeCombined,
+		Message:  "Deleting tasks cannot be combined with other actions.",
+	}
+}
+
+// ErrBulkTaskActionNeedsAtLeastOneAction represents an error where no action was provided for a bulk task action
+type ErrBulkTaskActionNeedsAtLeastOneAction struct{}
+
+// IsErrBulkTaskActionNeedsAtLeastOneAction checks if an error is ErrBulkTaskActionNeedsAtLeastOneAction.
+func IsErrBulkTaskActionNeedsAtLeastOneAction(err error) bool {
+	_, ok := err.(*ErrBulkTaskActionNeedsAtLeastOneAction)
+	return ok
+}
+
+func (err *ErrBulkTaskActionNeedsAtLeastOneAction) Error() string {
+	return "Bulk task action needs at least one action"
+}
+
+// ErrCodeBulkTaskActionNeedsAtLeastOneAction holds the unique world-error code of this error
+const ErrCodeBulkTaskActionNeedsAtLeastOneAction = 4035
+
+// HTTPError holds the http error description
+func (err *ErrBulkTaskActionNeedsAtLeastOneAction) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeBulkTaskActionNeedsAtLeastOneAction,
+		Message:  "At least one action must be provided.",
+	}
+}
+
+// ErrInvalidReminderSnoozePreset represents an error where an unknown snooze preset was provided
+type ErrInvalidReminderSnoozePreset struct {
+	Preset TaskReminderSnoozePreset
+}
+
+// IsErrInvalidReminderSnoozePreset checks if an error is ErrInvalidReminderSnoozePreset.
+func IsErrInvalidReminderSnoozePreset(err error) bool {
+	_, ok := err.(*ErrInvalidReminderSnoozePreset)
+	return ok
+}
+
+func (err *ErrInvalidReminderSnoozePreset) Error() string {
+	return fmt.Sprintf("Invalid reminder snooze preset [Preset: %s]", err.Preset)
+}
+
+// ErrCodeInvalidReminderSnoozePreset holds the unique world-error code of this error
+const ErrCodeInvalidReminderSnoozePreset = 4036
+
+// HTTPError holds the http error description
+func (err *ErrInvalidReminderSnoozePreset) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidReminderSnoozePreset,
+		Message:  fmt.Sprintf("The snooze preset '%s' is invalid, it must be one of '10m', '1h' or 'tomorrow'.", err.Preset),
+	}
+}
+
+// ErrReminderSnoozeInThePast represents an error where a reminder should be snoozed until a time in the past
+type ErrReminderSnoozeInThePast struct {
+	TaskID int64
+}
+
+// IsErrReminderSnoozeInThePast checks if an error is ErrReminderSnoozeInThePast.
+func IsErrReminderSnoozeInThePast(err error) bool {
+	_, ok := err.(*ErrReminderSnoozeInThePast)
+	return ok
+}
+
+func (err *ErrReminderSnoozeInThePast) Error() string {
+	return fmt.Sprintf("Reminder snooze is in the past [TaskID: %d]", err.TaskID)
+}
+
+// ErrCodeReminderSnoozeInThePast holds the unique world-error code of this error
+const ErrCodeReminderSnoozeInThePast = 4037
+
+// HTTPError holds the http error description
+func (err *ErrReminderSnoozeInThePast) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeReminderSnoozeInThePast,
+		Message:  "A reminder can only be snoozed until a time in the future.",
+	}
+}
+
+// ErrInvalidTaskCommentParent represents an error where a comment should be a reply to a comment of another task
+type ErrInvalidTaskCommentParent struct {
+	ParentID int64
+	TaskID   int64
+}
+
+// IsErrInvalidTaskCommentParent checks if an error is ErrInvalidTaskCommentParent.
+func IsErrInvalidTaskCommentParent(err error) bool {
+	_, ok := err.(*ErrInvalidTaskCommentParent)
+	return ok
+}
+
+func (err *ErrInvalidTaskCommentParent) Error() string {
+	return fmt.Sprintf("Invalid task comment parent [ParentID: %d, TaskID: %d]", err.ParentID, err.TaskID)
+}
+
+// ErrCodeInvalidTaskCommentParent holds the unique world-error code of this error
+const ErrCodeInvalidTaskCommentParent = 4038
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTaskCommentParent) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskCommentParent,
+		Message:  "The parent comment does not exist or belongs to another task.",
+	}
+}
+
+// ErrInvalidQuickAddMode represents an error where an unknown quick add magic mode was provided
+type ErrInvalidQuickAddMode struct {
+	Mode string
+}
+
+// IsErrInvalidQuickAddMode checks if an error is ErrInvalidQuickAddMode.
+func IsErrInvalidQuickAddMode(err error) bool {
+	_, ok := err.(*ErrInvalidQuickAddMode)
+	return ok
+}
+
+func (err *ErrInvalidQuickAddMode) Error() string {
+	return fmt.Sprintf("Invalid quick add mode [Mode: %s]", err.Mode)
+}
+
+// ErrCodeInvalidQuickAddMode holds the unique world-error code of this error
+const ErrCodeInvalidQuickAddMode = 4039
+
+// HTTPError holds the http error description
+func (err *ErrInvalidQuickAddMode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidQuickAddMode,
+		Message:  fmt.Sprintf("The quick add mode '%s' is invalid, it must be one of 'vikunja', 'todoist' or 'disabled'.", err.Mode),
+	}
+}
+
+// ErrQuickAddProjectDoesNotExist represents an error where the project given in a quick add text was not found
+type ErrQuickAddProjectDoesNotExist struct {
+	Title string
+}
+
+// IsErrQuickAddProjectDoesNotExist checks if an error is ErrQuickAddProjectDoesNotExist.
+func IsErrQuickAddProjectDoesNotExist(err error) bool {
+	_, ok := err.(*ErrQuickAddProjectDoesNotExist)
+	return ok
+}
+
+func (err *ErrQuickAddProjectDoesNotExist) Error() string {
+	return fmt.Sprintf("Quick add project does not exist [Title: %s]", err.Title)
+}
+
+// ErrCodeQuickAddProjectDoesNotExist holds the unique world-error code of this error
+const ErrCodeQuickAddProjectDoesNotExist = 4040
+
+// HTTPError holds the http error description
+func (err *ErrQuickAddProjectDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeQuickAddProjectDoesNotExist,
+		Message:  fmt.Sprintf("The project '%s' does not exist or you don't have write access to it.", err.Title),
+	}
+}
+
+// ErrBulkTaskActionFailed represents an error where an atomic bulk task action failed for one of the tasks
+type ErrBulkTaskActionFailed struct {
+	TaskID  int64
+	Reason  *web.HTTPError
+	Results []*BulkTaskActionResult
+}
+
+// IsErrBulkTaskActionFailed checks if an error is ErrBulkTaskActionFailed.
+func IsErrBulkTaskActionFailed(err error) bool {
+	_, ok := err.(*ErrBulkTaskActionFailed)
+	return ok
+}
+
+func (err *ErrBulkTaskActionFailed) Error() string {
+	return fmt.Sprintf("Bulk task action failed [TaskID: %d, Reason: %s]", err.TaskID, err.Reason.Message)
+}
+
+// ErrCodeBulkTaskActionFailed holds the unique world-error code of this error
+const ErrCodeBulkTaskActionFailed = 4041
+
+// HTTPError holds the http error description
+func (err *ErrBulkTaskActionFailed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: err.Reason.HTTPCode,
+		Code:     ErrCodeBulkTaskActionFailed,
+		Message:  fmt.Sprintf("The actions failed for task %d, no task was changed: %s", err.TaskID, err.Reason.Message),
+	}
+}
+
+// HTTPErrorWithDetails holds the http error description with the outcome for every task
+func (err *ErrBulkTaskActionFailed) HTTPErrorWithDetails() web.HTTPErrorWithDetails {
+	return web.HTTPErrorWithDetails{
+		HTTPError: err.HTTPError(),
+		Details:   err.Results,
+	}
+}
+
 // ============
 // Team errors
 // ============
@@ -2043,3 +2569,149 @@ type ErrOpenIDBadRequestWithDetails struct {
 func (err *ErrOpenIDBadRequestWithDetails) Error() string {
 	return err.Message
 }
+
+// ===============
+// Template Errors
+// ===============
+
+// ErrTemplateDoesNotExist represents an error where a template does not exist
+type ErrTemplateDoesNotExist struct {
+	TemplateID int64
+}
+
+// IsErrTemplateDoesNotExist checks if an error is ErrTemplateDoesNotExist.
+func IsErrTemplateDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTemplateDoesNotExist)
+	return ok
+}
+
+func (err *ErrTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("Template does not exist [TemplateID: %d]", err.TemplateID)
+}
+
+// ErrCodeTemplateDoesNotExist holds the unique world-error code of this error
+const ErrCodeTemplateDoesNotExist = 16001
+
+// HTTPError holds the http error description
+func (err *ErrTemplateDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTemplateDoesNotExist,
+		Message:  "This template does not exist.",
+	}
+}
+
+// ErrInvalidTemplateSource represents an error where a template should be created without a project or a task
+// or from both at the same time
+type ErrInvalidTemplateSource struct {
+	ProjectID int64
+	TaskID    int64
+}
+
+// IsErrInvalidTemplateSource checks if an error is ErrInvalidTemplateSource.
+func IsErrInvalidTemplateSource(err error) bool {
+	_, ok := err.(*ErrInvalidTemplateSource)
+	return ok
+}
+
+func (err *ErrInvalidTemplateSource) Error() string {
+	return fmt.Sprintf("Invalid template source [ProjectID: %d, TaskID: %d]", err.ProjectID, err.TaskID)
+}
+
+// ErrCodeInvalidTemplateSource holds the unique world-error code of this error
+const ErrCodeInvalidTemplateSource = 16002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTemplateSource) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTemplateSource,
+		Message:  "You need to provide either a project or a task to create the template from.",
+	}
+}
+
+// ErrTemplateNeedsProject represents an error where a task template is used without a project to create the task in
+type ErrTemplateNeedsProject struct {
+	TemplateID int64
+}
+
+// IsErrTemplateNeedsProject checks if an error is ErrTemplateNeedsProject.
+func IsErrTemplateNeedsProject(err error) bool {
+	_, ok := err.(*ErrTemplateNeedsProject)
+	return ok
+}
+
+func (err *ErrTemplateNeedsProject) Error() string {
+	return fmt.Sprintf("Task template needs a project [TemplateID: %d]", err.TemplateID)
+}
+
+// ErrCodeTemplateNeedsProject holds the unique world-error code of this error
+const ErrCodeTemplateNeedsProject = 16003
+
+// HTTPError holds the http error description
+func (err *ErrTemplateNeedsProject) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeTemplateNeedsProject,
+		Message:  "You need to provide a project to create the task of this template in.",
+	}
+}
+
+// ============
+// Trash Errors
+// ============
+
+// ErrTrashItemDoesNotExist represents an error where a trash item does not exist
+type ErrTrashItemDoesNotExist struct {
+	TrashItemID int64
+}
+
+// IsErrTrashItemDoesNotExist checks if an error is ErrTrashItemDoesNotExist.
+func IsErrTrashItemDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTrashItemDoesNotExist)
+	return ok
+}
+
+func (err *ErrTrashItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Trash item does not exist [TrashItemID: %d]", err.TrashItemID)
+}
+
+// ErrCodeTrashItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeTrashItemDoesNotExist = 17001
+
+// HTTPError holds the http error description
+func (err *ErrTrashItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTrashItemDoesNotExist,
+		Message:  "This trash item does not exist.",
+	}
+}
+
+// ErrTrashedTaskProjectDoesNotExist represents an error where a deleted task cannot be restored because its project does not exist anymore
+type ErrTrashedTaskProjectDoesNotExist struct {
+	TrashItemID int64
+	ProjectID   int64
+}
+
+// IsErrTrashedTaskProjectDoesNotExist checks if an error is ErrTrashedTaskProjectDoesNotExist.
+func IsErrTrashedTaskProjectDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTrashedTaskProjectDoesNotExist)
+	return ok
+}
+
+func (err *ErrTrashedTaskProjectDoesNotExist) Error() string {
+	return fmt.Sprintf("The project of the deleted task does not exist [TrashItemID: %d, ProjectID: %d]", err.TrashItemID, err.ProjectID)
+}
+
+// ErrCodeTrashedTaskProjectDoesNotExist holds the unique world-error code of this error
+const ErrCodeTrashedTaskProjectDoesNotExist = 17002
+
+// HTTPError holds the http error description
+func (err *ErrTrashedTaskProjectDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTrashedTaskProjectDoesNotExist,
+		Message:  "The project of this task does not exist anymore. Restore the project first.",
+	}
+}
@@ -58,6 +58,17 @@ func (t *TaskDeletedEvent) Name() string {
 	return "task.deleted"
 }
 
+// TaskRestoredEvent represents an event where a deleted task has been restored from the trash
+type TaskRestoredEvent struct {
+	Task *Task      `json:"task"`
+	Doer *user.User `json:"doer"`
+}
+
+// Name defines the name for TaskRestoredEvent
+func (t *TaskRestoredEvent) Name() string {
+	return "task.restored"
+}
+
 // TaskAssigneeCreatedEvent represents an event where a task has been assigned to a user
 type TaskAssigneeCreatedEvent struct {
 	Task     *Task      `json:"task"`
@@ -213,6 +224,17 @@ func (p *ProjectDeletedEvent) Name() string {
 	return "project.deleted"
 }
 
+// ProjectRestoredEvent represents an event where a deleted project has been restored from the trash
+type ProjectRestoredEvent struct {
+	Project *Project `json:"project"`
+	Doer    web.Auth `json:"doer"`
+}
+
+// Name defines the name for ProjectRestoredEvent
+func (p *ProjectRestoredEvent) Name() string {
+	return "project.restored"
+}
+
 ////////////////////
 // Sharing Events //
 ////////////////////
@@ -279,6 +301,91 @@ func (t *TeamDeletedEvent) Name() string {
 	return "team.deleted"
 }
 
+// TeamUpdatedEvent represents a TeamUpdatedEvent event
+type TeamUpdatedEvent struct {
+	Team *Team    `json:"team"`
+	Doer web.Auth `json:"doer"`
+}
+
+// Name defines the name for TeamUpdatedEvent
+func (t *TeamUpdatedEvent) Name() string {
+	return "team.updated"
+}
+
+//////////////////
+// Label Events //
+//////////////////
+
+// LabelCreatedEvent represents an event where a label has been created
+type LabelCreatedEvent struct {
+	Label *Label   `json:"label"`
+	Doer  web.Auth `json:"doer"`
+}
+
+// Name defines the name for LabelCreatedEvent
+func (l *LabelCreatedEvent) Name() string {
+	return "label.created"
+}
+
+// LabelUpdatedEvent represents an event where a label has been updated
+type LabelUpdatedEvent struct {
+	Label *Label   `json:"label"`
+	Doer  web.Auth `json:"doer"`
+}
+
+// Name defines the name for LabelUpdatedEvent
+func (l *LabelUpdatedEvent) Name() string {
+	return "label.updated"
+}
+
+// LabelDeletedEvent represents an event where a label has been deleted
+type LabelDeletedEvent struct {
+	Label *Label   `json:"label"`
+	Doer  web.Auth `json:"doer"`
+}
+
+// Name defines the name for LabelDeletedEvent
+func (l *LabelDeletedEvent) Name() string {
+	return "label.deleted"
+}
+
+/////////////////////////
+// Saved Filter Events //
+/////////////////////////
+
+// SavedFilterCreatedEvent represents an event where a saved filter has been created
+type SavedFilterCreatedEvent struct {
+	SavedFilter *SavedFilter `json:"saved_filter"`
+	Doer        web.Auth     `json:"doer"`
+}
+
+// Name defines the name for SavedFilterCreatedEvent
+func (sf *SavedFilterCreatedEvent) Name() string {
+	return "saved.filter.created"
+}
+
+// SavedFilterUpdatedEvent represents an event where a saved filter has been updated
+type SavedFilterUpdatedEvent struct {
+	SavedFilter *SavedFilter `json:"saved_filter"`
+	Doer        web.Auth     `json:"doer"`
+}
+
+// Name defines the name for SavedFilterUpdatedEvent
+func (sf *SavedFilterUpdatedEvent) Name() string {
+	return "saved.filter.updated"
+}
+
+// SavedFilterDeletedEvent represents an event where a saved filter has been deleted
+type SavedFilterDeletedEvent struct {
+	SavedFilter *SavedFilter `json:"saved_filter"`
+	Doer        web.Auth     `json:"doer"`
+}
+
+// Name defines the name for SavedFilterDeletedEvent
+func (sf *SavedFilterDeletedEvent) Name() string {
+	return "saved.filter.deleted"
+}
+
 // UserDataExportRequestedEvent represents a UserDataExportRequestedEvent event
 type UserDataExportRequestedEvent struct {
 	User *user.User `json:"user"`
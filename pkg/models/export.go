@@ -69,6 +69,16 @@ func ExportUserData(s *xorm.Session, u *user.User) (err error) {
 	if err != nil {
 		return err
 	}
+	// Time entries
+	err = exportTimeEntries(s, dumpWriter, taskIDs)
+	if err != nil {
+		return err
+	}
+	// Task activities
+	err = exportTaskActivities(s, dumpWriter, taskIDs)
+	if err != nil {
+		return err
+	}
 	// Saved filters
 	err = exportSavedFilters(s, u, dumpWriter)
 	if err != nil {
@@ -170,6 +180,16 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 		return
 	}
 
+	customFields := []*ProjectCustomField{}
+	err = s.In("project_id", projectIDs).OrderBy("position asc, id asc").Find(&customFields)
+	if err != nil {
+		return
+	}
+
+	for _, f := range customFields {
+		projectsMap[f.ProjectID].CustomFields = append(projectsMap[f.ProjectID].CustomFields, f)
+	}
+
 	viewIDs := []int64{}
 	for _, v := range views {
 		if projectsMap[v.ProjectID].Views == nil {
@@ -204,6 +224,7 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 	err = s.
 		Join("LEFT", "tasks", "tasks.id = task_comments.task_id").
 		In("tasks.project_id", projectIDs).
+		OrderBy("task_comments.id asc").
 		Find(&comments)
 	if err != nil {
 		return
@@ -319,6 +340,34 @@ func exportTaskAttachments(s *xorm.Session, wr *zip.Writer, taskIDs []int64) (er
 	return utils.WriteFilesToZip(attachmentFiles, wr)
 }
 
+func exportTimeEntries(s *xorm.Session, wr *zip.Writer, taskIDs []int64) (err error) {
+	entries, err := getTimeEntriesForTasks(s, taskIDs, -1, 0)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteBytesToZip("time_entries.json", data, wr)
+}
+
+func exportTaskActivities(s *xorm.Session, wr *zip.Writer, taskIDs []int64) (err error) {
+	activities, err := getActivitiesForTasks(s, taskIDs, -1, 0)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(activities)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteBytesToZip("task_activities.json", data, wr)
+}
+
 func exportSavedFilters(s *xorm.Session, u *user.User, wr *zip.Writer) (err error) {
 	filters, err := getSavedFiltersForUser(s, u, "")
 	if err != nil {
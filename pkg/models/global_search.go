@@ -0,0 +1,687 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"github.com/typesense/typesense-go/v2/typesense"
+	"github.com/typesense/typesense-go/v2/typesense/api"
+	"github.com/typesense/typesense-go/v2/typesense/api/pointer"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// GlobalSearchResult holds everything found by a global search, grouped by the kind of entity.
+// The entities of each group are ordered by how well they match the search.
+type GlobalSearchResult struct {
+	Projects     []*Project             `json:"projects"`
+	Tasks        []*Task                `json:"tasks"`
+	Comments     []*GlobalSearchComment `json:"comments"`
+	Labels       []*Label               `json:"labels"`
+	SavedFilters []*SavedFilter         `json:"saved_filters"`
+	Teams        []*Team                `json:"teams"`
+}
+
+// GlobalSearchComment is a task comment found by a global search.
+type GlobalSearchComment struct {
+	*TaskComment
+	// The task the comment belongs to.
+	TaskID int64 `json:"task_id"`
+}
+
+// The db search ranks at most this many matches of each kind of entity.
+const globalSearchCandidateLimit = 500
+
+// globalSearchCollection describes a kind of entity which is found with the global search. Tasks are searched with
+// the regular task search instead.
+type globalSearchCollection struct {
+	// The name of the Typesense collection
+	name  string
+	table string
+	// The searched columns of the table, the first one is the most important
+	fields []string
+	// The column restricting the result to what the user has access to. It is stored as filter_id in Typesense.
+	filterColumn string
+	// The join needed when the filter column is not part of the table
+	join []string
+}
+
+var (
+	globalSearchProjects = &globalSearchCollection{
+		name:         "projects",
+		table:        "projects",
+		fields:       []string{"title", "identifier", "description"},
+		filterColumn: "projects.id",
+	}
+	globalSearchComments = &globalSearchCollection{
+		name:         "comments",
+		table:        "task_comments",
+		fields:       []string{"comment"},
+		filterColumn: "tasks.project_id",
+		join:         []string{"INNER", "tasks", "tasks.id = task_comments.task_id"},
+	}
+	globalSearchLabels = &globalSearchCollection{
+		name:         "labels",
+		table:        "labels",
+		fields:       []string{"title", "description"},
+		filterColumn: "labels.id",
+	}
+	globalSearchSavedFilters = &globalSearchCollection{
+		name:         "saved_filters",
+		table:        "saved_filters",
+		fields:       []string{"title", "description"},
+		filterColumn: "saved_filters.id",
+	}
+	globalSearchTeams = &globalSearchCollection{
+		name:         "teams",
+		table:        "teams",
+		fields:       []string{"name", "description"},
+		filterColumn: "teams.id",
+	}
+
+	globalSearchCollections = []*globalSearchCollection{
+		globalSearchProjects,
+		globalSearchComments,
+		globalSearchLabels,
+		globalSearchSavedFilters,
+		globalSearchTeams,
+	}
+)
+
+func (c *globalSearchCollection) query(s *xorm.Session, cond builder.Cond) *xorm.Session {
+	cols := []string{
+		c.table + ".id AS id",
+		c.filterColumn + " AS filter_id",
+	}
+	for _, field := range c.fields {
+		cols = append(cols, c.table+"."+field+" AS "+field)
+	}
+
+	query := s.
+		Table(c.table).
+		Select(strings.Join(cols, ", ")).
+		Where(cond)
+	if len(c.join) == 3 {
+		query = query.Join(c.join[0], c.join[1], c.join[2])
+	}
+	return query
+}
+
+// search returns the ids of all entities matching the search, best matches first. Only entities whose filter
+// column is one of filterIDs are returned.
+func (c *globalSearchCollection) search(s *xorm.Session, search string, filterIDs []int64, limit int) (ids []int64, err error) {
+	if len(filterIDs) == 0 {
+		return nil, nil
+	}
+
+	if config.TypesenseEnabled.GetBool() {
+		ids, err = c.searchTypesense(search, filterIDs, limit)
+		var tsErr = &typesense.HTTPError{}
+		if err == nil {
+			// The index might not be up to date, the db has the final say about what the user has access to.
+			return c.filterIDs(s, ids, filterIDs)
+		}
+		if !errors.As(err, &tsErr) || tsErr.Status != 404 {
+			return nil, err
+		}
+		log.Warningf("Unable to search %s in Typesense, error was '%v'. Falling back to db.", c.name, err)
+	}
+
+	return c.searchDB(s, search, filterIDs, limit)
+}
+
+func (c *globalSearchCollection) searchDB(s *xorm.Session, search string, filterIDs []int64, limit int) (ids []int64, err error) {
+	searchConds := []builder.Cond{}
+	for _, field := range c.fields {
+		searchConds = append(searchConds, db.ILIKE(c.table+"."+field, search))
+	}
+
+	rows, err := c.query(s, builder.And(builder.In(c.filterColumn, filterIDs), builder.Or(searchConds...))).
+		OrderBy(c.table + ".id DESC").
+		Limit(globalSearchCandidateLimit).
+		QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		id, err := strconv.ParseInt(row["id"], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		texts := make([]string, 0, len(c.fields))
+		for _, field := range c.fields {
+			texts = append(texts, row[field])
+		}
+		ranks[id] = getGlobalSearchRank(search, texts...)
+		ids = append(ids, id)
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return ranks[ids[i]] > ranks[ids[j]]
+	})
+
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (c *globalSearchCollection) searchTypesense(search string, filterIDs []int64, limit int) (ids []int64, err error) {
+	filterIDStrings := make([]string, 0, len(filterIDs))
+	for _, id := range filterIDs {
+		filterIDStrings = append(filterIDStrings, strconv.FormatInt(id, 10))
+	}
+
+	// Matches in the first fields are more important
+	weights := make([]string, 0, len(c.fields))
+	for i := range c.fields {
+		weights = append(weights, strconv.Itoa(len(c.fields)-i))
+	}
+
+	if limit > 250 {
+		limit = 250
+	}
+
+	result, err := typesenseClient.Collection(c.name).
+		Documents().
+		Search(context.Background(), &api.SearchCollectionParams{
+			Q:              pointer.String(search),
+			QueryBy:        pointer.String(strings.Join(c.fields, ", ")),
+			QueryByWeights: pointer.String(strings.Join(weights, ", ")),
+			FilterBy:       pointer.String("filter_id: [" + strings.Join(filterIDStrings, ", ") + "]"),
+			PerPage:        pointer.Int(limit),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range *result.Hits {
+		id, err := strconv.ParseInt((*hit.Document)["id"].(string), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// filterIDs returns the ids whose filter column is one of filterIDs, keeping their order.
+func (c *globalSearchCollection) filterIDs(s *xorm.Session, ids []int64, filterIDs []int64) (filtered []int64, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := c.query(s, builder.And(builder.In(c.table+".id", ids), builder.In(c.filterColumn, filterIDs))).
+		QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		allowed[row["id"]] = true
+	}
+
+	for _, id := range ids {
+		if allowed[strconv.FormatInt(id, 10)] {
+			filtered = append(filtered, id)
+		}
+	}
+	return
+}
+
+func (c *globalSearchCollection) getTypesenseDocuments(s *xorm.Session, cond builder.Cond) (documents []interface{}, err error) {
+	rows, err := c.query(s, cond).QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		filterID, err := strconv.ParseInt(row["filter_id"], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		document := map[string]interface{}{
+			"id":        row["id"],
+			"filter_id": filterID,
+		}
+		for _, field := range c.fields {
+			document[field] = row[field]
+		}
+		documents = append(documents, document)
+	}
+	return
+}
+
+func (c *globalSearchCollection) createTypesenseCollection() error {
+	fields := []api.Field{
+		{
+			Name: "filter_id",
+			Type: "int64",
+		},
+	}
+	for _, field := range c.fields {
+		fields = append(fields, api.Field{
+			Name:     field,
+			Type:     "string",
+			Optional: pointer.True(),
+		})
+	}
+
+	// delete any collection which might exist
+	_, _ = typesenseClient.Collection(c.name).Delete(context.Background())
+
+	_, err := typesenseClient.Collections().Create(context.Background(), &api.CollectionSchema{
+		Name:   c.name,
+		Fields: fields,
+	})
+	return err
+}
+
+func (c *globalSearchCollection) upsertTypesenseDocuments(documents []interface{}) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	response, err := typesenseClient.Collection(c.name).
+		Documents().
+		Import(context.Background(), documents, &api.ImportDocumentsParams{
+			Action:    pointer.String("upsert"),
+			BatchSize: pointer.Int(100),
+		})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range response {
+		if !r.Success {
+			log.Errorf("Could not upsert a document into the Typesense collection %s: %s", c.name, r.Error)
+		}
+	}
+	return nil
+}
+
+// syncIntoTypesense updates the documents of the entities in Typesense and removes the ones which do not exist
+// anymore.
+func (c *globalSearchCollection) syncIntoTypesense(s *xorm.Session, ids []int64) error {
+	if !config.TypesenseEnabled.GetBool() || len(ids) == 0 {
+		return nil
+	}
+
+	documents, err := c.getTypesenseDocuments(s, builder.In(c.table+".id", ids))
+	if err != nil {
+		return err
+	}
+
+	err = c.upsertTypesenseDocuments(documents)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(documents))
+	for _, document := range documents {
+		existing[document.(map[string]interface{})["id"].(string)] = true
+	}
+
+	for _, id := range ids {
+		documentID := strconv.FormatInt(id, 10)
+		if existing[documentID] {
+			continue
+		}
+
+		_, err = typesenseClient.Collection(c.name).
+			Document(documentID).
+			Delete(context.Background())
+		var tsErr = &typesense.HTTPError{}
+		if err != nil && (!errors.As(err, &tsErr) || tsErr.Status != 404) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReindexGlobalSearchCollections indexes all projects, comments, labels, saved filters and teams into Typesense.
+func ReindexGlobalSearchCollections() (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	for _, c := range globalSearchCollections {
+		documents, err := c.getTypesenseDocuments(s, builder.NewCond())
+		if err != nil {
+			return err
+		}
+
+		err = c.upsertTypesenseDocuments(documents)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Indexed %d %s into Typesense", len(documents), c.name)
+	}
+
+	return nil
+}
+
+// getGlobalSearchRank returns how well a search matches the texts of an entity. The higher the rank, the better
+// the match. The first text is the most important one, like the title.
+func getGlobalSearchRank(search string, texts ...string) int {
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	for i, text := range texts {
+		text = strings.ToLower(text)
+
+		var rank int
+		switch {
+		case text == search:
+			rank = 4
+		case strings.HasPrefix(text, search):
+			rank = 3
+		case strings.Contains(text, " "+search):
+			rank = 2
+		case isTextMatchingSearch(text, search):
+			rank = 1
+		}
+
+		if rank > 0 {
+			if i == 0 {
+				return rank + 4
+			}
+			return rank
+		}
+	}
+
+	return 0
+}
+
+// GlobalSearch searches all projects, tasks, comments, labels, saved filters and teams the user has access to.
+// Link shares only find the project they belong to as well as its tasks and comments.
+func GlobalSearch(s *xorm.Session, a web.Auth, search string, limit int) (result *GlobalSearchResult, err error) {
+	result = &GlobalSearchResult{
+		Projects:     []*Project{},
+		Tasks:        []*Task{},
+		Comments:     []*GlobalSearchComment{},
+		Labels:       []*Label{},
+		SavedFilters: []*SavedFilter{},
+		Teams:        []*Team{},
+	}
+
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return result, nil
+	}
+
+	result.Projects, err = globalSearchForProjects(s, a, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Tasks, err = globalSearchForTasks(s, a, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Comments, err = globalSearchForComments(s, a, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, is := a.(*LinkSharing); is {
+		return result, nil
+	}
+
+	result.Labels, err = globalSearchForLabels(s, a, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.SavedFilters, err = globalSearchForSavedFilters(s, a, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Teams, err = globalSearchForTeams(s, a, search, limit)
+	return result, err
+}
+
+func getGlobalSearchProjects(s *xorm.Session, a web.Auth) (projects map[int64]*Project, err error) {
+	var all []*Project
+	if share, is := a.(*LinkSharing); is {
+		project, err := GetProjectSimpleByID(s, share.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		all = []*Project{project}
+	} else {
+		all, _, err = getAllProjectsForUser(s, a.GetID(), &projectOptions{
+			user: &user.User{ID: a.GetID()},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	projects = make(map[int64]*Project, len(all))
+	for _, p := range all {
+		// Pseudo projects like saved filters are not searched as projects
+		if p.ID <= 0 {
+			continue
+		}
+		projects[p.ID] = p
+	}
+	return
+}
+
+func globalSearchForProjects(s *xorm.Session, a web.Auth, search string, limit int) (projects []*Project, err error) {
+	allowed, err := getGlobalSearchProjects(s, a)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedIDs := make([]int64, 0, len(allowed))
+	for id := range allowed {
+		allowedIDs = append(allowedIDs, id)
+	}
+
+	ids, err := globalSearchProjects.search(s, search, allowedIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	projects = make([]*Project, 0, len(ids))
+	for _, id := range ids {
+		projects = append(projects, allowed[id])
+	}
+
+	if _, is := a.(*LinkSharing); is {
+		return projects, nil
+	}
+
+	err = addProjectDetails(s, projects, a)
+	return projects, err
+}
+
+func globalSearchForTasks(s *xorm.Session, a web.Auth, search string, limit int) (tasks []*Task, err error) {
+	tc := &TaskCollection{}
+	result, _, _, err := tc.ReadAll(s, a, search, 1, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, is := result.([]*Task)
+	if !is || tasks == nil {
+		return []*Task{}, nil
+	}
+
+	// The task search backends rank their results differently, exact title matches should always come first.
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return getGlobalSearchRank(search, tasks[i].Title, tasks[i].Description) >
+			getGlobalSearchRank(search, tasks[j].Title, tasks[j].Description)
+	})
+
+	return tasks, nil
+}
+
+func globalSearchForComments(s *xorm.Session, a web.Auth, search string, limit int) (comments []*GlobalSearchComment, err error) {
+	projects, err := getGlobalSearchProjects(s, a)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs := make([]int64, 0, len(projects))
+	for id := range projects {
+		projectIDs = append(projectIDs, id)
+	}
+
+	ids, err := globalSearchComments.search(s, search, projectIDs, limit)
+	if err != nil || len(ids) == 0 {
+		return []*GlobalSearchComment{}, err
+	}
+
+	found := make(map[int64]*TaskComment, len(ids))
+	err = s.In("id", ids).Find(&found)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIDs := make([]int64, 0, len(found))
+	for _, comment := range found {
+		authorIDs = append(authorIDs, comment.AuthorID)
+	}
+	authors, err := getUsersOrLinkSharesFromIDs(s, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	comments = make([]*GlobalSearchComment, 0, len(ids))
+	for _, id := range ids {
+		comment, has := found[id]
+		if !has {
+			continue
+		}
+		comment.Author = authors[comment.AuthorID]
+		comments = append(comments, &GlobalSearchComment{
+			TaskComment: comment,
+			TaskID:      comment.TaskID,
+		})
+	}
+
+	return comments, nil
+}
+
+func globalSearchForLabels(s *xorm.Session, a web.Auth, search string, limit int) (labels []*Label, err error) {
+	all, _, _, err := GetLabelsByTaskIDs(s, &LabelByTaskIDsOptions{
+		User:                a,
+		GetUnusedLabels:     true,
+		GroupByLabelIDsOnly: true,
+		GetForUser:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[int64]*Label, len(all))
+	allowedIDs := make([]int64, 0, len(all))
+	for _, l := range all {
+		allowed[l.ID] = &l.Label
+		allowedIDs = append(allowedIDs, l.ID)
+	}
+
+	ids, err := globalSearchLabels.search(s, search, allowedIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	labels = make([]*Label, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, allowed[id])
+	}
+	return labels, nil
+}
+
+func globalSearchForSavedFilters(s *xorm.Session, a web.Auth, search string, limit int) (filters []*SavedFilter, err error) {
+	all, err := getSavedFiltersForUser(s, a, "")
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[int64]*SavedFilter, len(all))
+	allowedIDs := make([]int64, 0, len(all))
+	for _, sf := range all {
+		allowed[sf.ID] = sf
+		allowedIDs = append(allowedIDs, sf.ID)
+	}
+
+	ids, err := globalSearchSavedFilters.search(s, search, allowedIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	filters = make([]*SavedFilter, 0, len(ids))
+	for _, id := range ids {
+		filters = append(filters, allowed[id])
+	}
+	return filters, nil
+}
+
+func globalSearchForTeams(s *xorm.Session, a web.Auth, search string, limit int) (teams []*Team, err error) {
+	allowedIDs := []int64{}
+	err = s.
+		Table("team_members").
+		Where("user_id = ?", a.GetID()).
+		Cols("team_id").
+		Find(&allowedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := globalSearchTeams.search(s, search, allowedIDs, limit)
+	if err != nil || len(ids) == 0 {
+		return []*Team{}, err
+	}
+
+	found := make(map[int64]*Team, len(ids))
+	err = s.In("id", ids).Find(&found)
+	if err != nil {
+		return nil, err
+	}
+
+	teams = make([]*Team, 0, len(ids))
+	for _, id := range ids {
+		if team, has := found[id]; has {
+			teams = append(teams, team)
+		}
+	}
+
+	err = addMoreInfoToTeams(s, teams)
+	return teams, err
+}
@@ -0,0 +1,154 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalSearch(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("empty search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "  ", 10)
+		require.NoError(t, err)
+		assert.Empty(t, result.Projects)
+		assert.NotNil(t, result.Projects)
+		assert.NotNil(t, result.Tasks)
+		assert.NotNil(t, result.Comments)
+		assert.NotNil(t, result.Labels)
+		assert.NotNil(t, result.SavedFilters)
+		assert.NotNil(t, result.Teams)
+	})
+	t.Run("projects", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "test1", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Projects)
+		assert.Equal(t, int64(1), result.Projects[0].ID)
+		for _, p := range result.Projects {
+			can, _, err := p.CanRead(s, u)
+			require.NoError(t, err)
+			assert.Truef(t, can, "project %d should not be found", p.ID)
+		}
+	})
+	t.Run("tasks and comments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "Lorem Ipsum Dolor", 10)
+		require.NoError(t, err)
+		require.Len(t, result.Comments, 1)
+		assert.Equal(t, int64(1), result.Comments[0].ID)
+		assert.Equal(t, int64(1), result.Comments[0].TaskID)
+		assert.NotNil(t, result.Comments[0].Author)
+
+		result, err = GlobalSearch(s, u, "task #1", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Tasks)
+		assert.Equal(t, int64(1), result.Tasks[0].ID)
+	})
+	t.Run("labels", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "label", 10)
+		require.NoError(t, err)
+		ids := []int64{}
+		for _, l := range result.Labels {
+			ids = append(ids, l.ID)
+		}
+		assert.Contains(t, ids, int64(1))
+		assert.Contains(t, ids, int64(2))
+		assert.NotContains(t, ids, int64(3))
+	})
+	t.Run("saved filters", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "testfilter1", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.SavedFilters)
+		assert.Equal(t, int64(1), result.SavedFilters[0].ID)
+	})
+	t.Run("teams", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "testteam1", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Teams)
+		assert.Equal(t, int64(1), result.Teams[0].ID)
+
+		result, err = GlobalSearch(s, u, "testteam9", 10)
+		require.NoError(t, err)
+		assert.Empty(t, result.Teams)
+	})
+	t.Run("limit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, err := GlobalSearch(s, u, "test", 2)
+		require.NoError(t, err)
+		assert.Len(t, result.Projects, 2)
+
+		result, err = GlobalSearch(s, u, "task", 2)
+		require.NoError(t, err)
+		assert.Len(t, result.Tasks, 2)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{ID: 1, ProjectID: 1, Permission: PermissionRead}
+		result, err := GlobalSearch(s, share, "test", 10)
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 1)
+		assert.Equal(t, int64(1), result.Projects[0].ID)
+		for _, task := range result.Tasks {
+			assert.Equal(t, int64(1), task.ProjectID)
+		}
+		assert.Empty(t, result.Labels)
+		assert.Empty(t, result.SavedFilters)
+		assert.Empty(t, result.Teams)
+	})
+}
+
+func TestGetGlobalSearchRank(t *testing.T) {
+	assert.Greater(t, getGlobalSearchRank("test", "Test"), getGlobalSearchRank("test", "Testing"))
+	assert.Greater(t, getGlobalSearchRank("test", "Testing"), getGlobalSearchRank("test", "A test"))
+	assert.Greater(t, getGlobalSearchRank("test", "A test"), getGlobalSearchRank("test", "Other", "Test"))
+	assert.Equal(t, 0, getGlobalSearchRank("test", "Other", "Nothing"))
+}
@@ -41,6 +41,10 @@ type TaskBucket struct {
 	ProjectID     int64 `xorm:"-" json:"-" param:"project"`
 	Task          *Task `xorm:"-" json:"task"`
 
+	// Set when the bucket is changed as a side effect of another change to the task
+	// which already records its own activity.
+	skipActivity bool
+
 	web.Permissions `xorm:"-" json:"-"`
 	web.CRUDable    `xorm:"-" json:"-"`
 }
@@ -136,6 +140,7 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
+	originalTask := *task
 
 	// Check the bucket limit
 	// Only check the bucket limit if the task is being moved between buckets, allow reordering the task within a bucket
@@ -152,14 +157,31 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 	// mark task done if moved into the done bucket
 	var doneChanged bool
 	if view.DoneBucketID == b.BucketID {
+		if !task.Done {
+			err = checkTaskCanBeDone(s, task)
+			if err != nil {
+				return err
+			}
+		}
+
 		doneChanged = true
 		task.Done = true
 		if task.isRepeating() {
-			oldTask := task
+			oldTask := *task
 			oldTask.Done = false
-			updateDone(oldTask, task)
-			updateBucket = false
-			b.BucketID = oldTaskBucket.BucketID
+			err = saveTaskOccurrence(s, a, &oldTask, time.Now())
+			if err != nil {
+				return err
+			}
+			updateDone(&oldTask, task)
+			// A task stays done in the done bucket when the series of its repeat rule ended
+			if task.Done {
+				task.RepeatRule = ""
+				task.RepeatRuleStart = time.Time{}
+			} else {
+				updateBucket = false
+				b.BucketID = oldTaskBucket.BucketID
+			}
 		}
 	}
 
@@ -181,6 +203,8 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 				"start_date",
 				"end_date",
 				"done_at",
+				"repeat_rule",
+				"repeat_rule_start",
 			).
 			Update(task)
 		if err != nil {
@@ -227,8 +251,23 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 	b.Task = task
 	b.Bucket = bucket
 
+	if !b.skipActivity {
+		changes := getTaskFieldChanges(&originalTask, task)
+		if updateBucket {
+			changes = append(changes, &TaskActivityChange{
+				Field:    "bucket_id",
+				OldValue: oldTaskBucket.BucketID,
+				NewValue: b.BucketID,
+			})
+		}
+		err = recordTaskChanges(s, a, task.ID, changes...)
+		if err != nil {
+			return err
+		}
+	}
+
 	doer, _ := user.GetFromAuth(a)
-	return events.Dispatch(&TaskUpdatedEvent{
+	return events.DispatchOnCommit(s, &TaskUpdatedEvent{
 		Task: task,
 		Doer: doer,
 	})
@@ -19,6 +19,7 @@ package models
 import (
 	"time"
 
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
 
@@ -79,7 +80,14 @@ func (l *Label) Create(s *xorm.Session, a web.Auth) (err error) {
 	l.CreatedByID = u.ID
 
 	_, err = s.Insert(l)
-	return
+	if err != nil {
+		return
+	}
+
+	return events.DispatchOnCommit(s, &LabelCreatedEvent{
+		Label: l,
+		Doer:  a,
+	})
 }
 
 // Update updates a label
@@ -114,7 +122,14 @@ func (l *Label) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	err = l.ReadOne(s, a)
-	return
+	if err != nil {
+		return
+	}
+
+	return events.DispatchOnCommit(s, &LabelUpdatedEvent{
+		Label: l,
+		Doer:  a,
+	})
 }
 
 // Delete deletes a label
@@ -130,9 +145,16 @@ func (l *Label) Update(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "Label not found."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /labels/{id} [delete]
-func (l *Label) Delete(s *xorm.Session, _ web.Auth) (err error) {
+func (l *Label) Delete(s *xorm.Session, a web.Auth) (err error) {
 	_, err = s.ID(l.ID).Delete(&Label{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return events.DispatchOnCommit(s, &LabelDeletedEvent{
+		Label: l,
+		Doer:  a,
+	})
 }
 
 // ReadAll gets all labels a user can use
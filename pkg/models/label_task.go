@@ -69,6 +69,20 @@ func (lt *LabelTask) Delete(s *xorm.Session, auth web.Auth) (err error) {
 		return err
 	}
 
+	label, err := getLabelByIDSimple(s, lt.LabelID)
+	if IsErrLabelDoesNotExist(err) {
+		label = &Label{ID: lt.LabelID}
+	} else if err != nil {
+		return err
+	}
+	err = recordTaskChanges(s, auth, lt.TaskID, &TaskActivityChange{
+		Field:    "labels",
+		OldValue: getLabelActivityValue(label),
+	})
+	if err != nil {
+		return err
+	}
+
 	return triggerTaskUpdatedEventForTaskID(s, auth, lt.TaskID)
 }
 
@@ -103,6 +117,20 @@ func (lt *LabelTask) Create(s *xorm.Session, auth web.Auth) (err error) {
 		return err
 	}
 
+	label, err := getLabelByIDSimple(s, lt.LabelID)
+	if IsErrLabelDoesNotExist(err) {
+		label = &Label{ID: lt.LabelID}
+	} else if err != nil {
+		return err
+	}
+	err = recordTaskChanges(s, auth, lt.TaskID, &TaskActivityChange{
+		Field:    "labels",
+		NewValue: getLabelActivityValue(label),
+	})
+	if err != nil {
+		return err
+	}
+
 	err = triggerTaskUpdatedEventForTaskID(s, auth, lt.TaskID)
 	if err != nil {
 		return err
@@ -316,7 +344,18 @@ func (t *Task) UpdateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 	if len(labels) == 0 && len(t.Labels) > 0 {
 		_, err = s.Where("task_id = ?", t.ID).
 			Delete(LabelTask{})
-		return err
+		if err != nil {
+			return err
+		}
+
+		changes := make([]*TaskActivityChange, 0, len(t.Labels))
+		for _, oldLabel := range t.Labels {
+			changes = append(changes, &TaskActivityChange{
+				Field:    "labels",
+				OldValue: getLabelActivityValue(oldLabel),
+			})
+		}
+		return recordTaskChanges(s, creator, t.ID, changes...)
 	}
 
 	// If we didn't change anything (from 0 to zero) don't do anything.
@@ -333,6 +372,7 @@ func (t *Task) UpdateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 	// Get old labels to delete
 	var found bool
 	var labelsToDelete []int64
+	changes := []*TaskActivityChange{}
 	oldLabels := make(map[int64]*Label, len(t.Labels))
 	allLabels := t.Labels
 	t.Labels = []*Label{} // We re-empty our labels struct here because we want it to be fully empty so we can put in all the actual labels.
@@ -345,6 +385,10 @@ func (t *Task) UpdateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 		// Put all labels which are only on the old project to the trash
 		if !found {
 			labelsToDelete = append(labelsToDelete, oldLabel.ID)
+			changes = append(changes, &TaskActivityChange{
+				Field:    "labels",
+				OldValue: getLabelActivityValue(oldLabel),
+			})
 		} else {
 			t.Labels = append(t.Labels, oldLabel)
 		}
@@ -396,6 +440,15 @@ func (t *Task) UpdateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 			return err
 		}
 		t.Labels = append(t.Labels, label)
+		changes = append(changes, &TaskActivityChange{
+			Field:    "labels",
+			NewValue: getLabelActivityValue(label),
+		})
+	}
+
+	err = recordTaskChanges(s, creator, t.ID, changes...)
+	if err != nil {
+		return
 	}
 
 	err = triggerTaskUpdatedEventForTaskID(s, creator, t.ID)
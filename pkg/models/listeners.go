@@ -43,6 +43,8 @@ func RegisterListeners() {
 		events.RegisterListener((&ProjectDeletedEvent{}).Name(), &DecreaseProjectCounter{})
 		events.RegisterListener((&TaskCreatedEvent{}).Name(), &IncreaseTaskCounter{})
 		events.RegisterListener((&TaskDeletedEvent{}).Name(), &DecreaseTaskCounter{})
+		events.RegisterListener((&ProjectRestoredEvent{}).Name(), &IncreaseProjectCounter{})
+		events.RegisterListener((&TaskRestoredEvent{}).Name(), &IncreaseTaskCounter{})
 		events.RegisterListener((&TeamDeletedEvent{}).Name(), &DecreaseTeamCounter{})
 		events.RegisterListener((&TeamCreatedEvent{}).Name(), &IncreaseTeamCounter{})
 		events.RegisterListener((&TaskAttachmentCreatedEvent{}).Name(), &IncreaseAttachmentCounter{})
@@ -56,6 +58,11 @@ func RegisterListeners() {
 	events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), &HandleTaskCommentEditMentions{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &HandleTaskCreateMentions{})
 	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &HandleTaskUpdatedMentions{})
+	events.RegisterListener((&TaskCreatedEvent{}).Name(), &UpdateTaskReferences{})
+	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &UpdateTaskReferences{})
+	events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &UpdateTaskCommentReferences{})
+	events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), &UpdateTaskCommentReferences{})
+	events.RegisterListener((&TaskAttachmentCreatedEvent{}).Name(), &ExtractTaskAttachmentText{})
 	events.RegisterListener((&UserDataExportRequestedEvent{}).Name(), &HandleUserDataExport{})
 	events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
 	events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
@@ -68,16 +75,69 @@ func RegisterListeners() {
 	events.RegisterListener((&TaskRelationDeletedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &UpdateTaskInSavedFilterViews{})
 	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &UpdateTaskInSavedFilterViews{})
+	events.RegisterListener((&TaskRestoredEvent{}).Name(), &UpdateTaskInSavedFilterViews{})
 	if config.TypesenseEnabled.GetBool() {
 		events.RegisterListener((&TaskDeletedEvent{}).Name(), &RemoveTaskFromTypesense{})
 		events.RegisterListener((&TaskCreatedEvent{}).Name(), &AddTaskToTypesense{})
+		events.RegisterListener((&TaskRestoredEvent{}).Name(), &AddTaskToTypesense{})
 		events.RegisterListener((&TaskUpdatedEvent{}).Name(), &UpdateTaskInTypesense{})
 		events.RegisterListener((&TaskPositionsRecalculatedEvent{}).Name(), &UpdateTaskPositionsInTypesense{})
+		events.RegisterListener((&TaskRelationCreatedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+		events.RegisterListener((&TaskRelationDeletedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+		events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+		events.RegisterListener((&TaskCommentDeletedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+		events.RegisterListener((&TaskAttachmentCreatedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+		events.RegisterListener((&TaskAttachmentDeletedEvent{}).Name(), &UpdateRelatedTaskStateInTypesense{})
+
+		updateProjects := &UpdateGlobalSearchInTypesense{collection: globalSearchProjects, payloadKey: "project"}
+		events.RegisterListener((&ProjectCreatedEvent{}).Name(), updateProjects)
+		events.RegisterListener((&ProjectUpdatedEvent{}).Name(), updateProjects)
+		events.RegisterListener((&ProjectDeletedEvent{}).Name(), updateProjects)
+		events.RegisterListener((&ProjectRestoredEvent{}).Name(), updateProjects)
+		updateComments := &UpdateGlobalSearchInTypesense{collection: globalSearchComments, payloadKey: "comment"}
+		events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), updateComments)
+		events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), updateComments)
+		events.RegisterListener((&TaskCommentDeletedEvent{}).Name(), updateComments)
+		updateLabels := &UpdateGlobalSearchInTypesense{collection: globalSearchLabels, payloadKey: "label"}
+		events.RegisterListener((&LabelCreatedEvent{}).Name(), updateLabels)
+		events.RegisterListener((&LabelUpdatedEvent{}).Name(), updateLabels)
+		events.RegisterListener((&LabelDeletedEvent{}).Name(), updateLabels)
+		updateSavedFilters := &UpdateGlobalSearchInTypesense{collection: globalSearchSavedFilters, payloadKey: "saved_filter"}
+		events.RegisterListener((&SavedFilterCreatedEvent{}).Name(), updateSavedFilters)
+		events.RegisterListener((&SavedFilterUpdatedEvent{}).Name(), updateSavedFilters)
+		events.RegisterListener((&SavedFilterDeletedEvent{}).Name(), updateSavedFilters)
+		updateTeams := &UpdateGlobalSearchInTypesense{collection: globalSearchTeams, payloadKey: "team"}
+		events.RegisterListener((&TeamCreatedEvent{}).Name(), updateTeams)
+		events.RegisterListener((&TeamUpdatedEvent{}).Name(), updateTeams)
+		events.RegisterListener((&TeamDeletedEvent{}).Name(), updateTeams)
+	}
+	if config.SearchBackend.GetString() == "bleve" {
+		events.RegisterListener((&TaskCreatedEvent{}).Name(), &UpdateTaskInBleve{})
+		events.RegisterListener((&TaskUpdatedEvent{}).Name(), &UpdateTaskInBleve{})
+		events.RegisterListener((&TaskRestoredEvent{}).Name(), &UpdateTaskInBleve{})
+		events.RegisterListener((&TaskDeletedEvent{}).Name(), &RemoveTaskFromBleve{})
+		events.RegisterListener((&TaskPositionsRecalculatedEvent{}).Name(), &UpdateTaskPositionsInBleve{})
+		events.RegisterListener((&TaskRelationCreatedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskRelationDeletedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskCommentDeletedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskAttachmentCreatedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskAttachmentDeletedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskAssigneeCreatedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+		events.RegisterListener((&TaskAssigneeDeletedEvent{}).Name(), &UpdateRelatedTaskStateInBleve{})
+	}
+	if config.DatabaseFullTextSearch.GetBool() {
+		events.RegisterListener((&TaskCreatedEvent{}).Name(), &UpdateTaskInFullTextSearch{})
+		events.RegisterListener((&TaskUpdatedEvent{}).Name(), &UpdateTaskInFullTextSearch{})
+		events.RegisterListener((&TaskRestoredEvent{}).Name(), &UpdateTaskInFullTextSearch{})
+		events.RegisterListener((&TaskDeletedEvent{}).Name(), &RemoveTaskFromFullTextSearch{})
 	}
 	if config.WebhooksEnabled.GetBool() {
 		RegisterEventForWebhook(&TaskCreatedEvent{})
 		RegisterEventForWebhook(&TaskUpdatedEvent{})
 		RegisterEventForWebhook(&TaskDeletedEvent{})
+		RegisterEventForWebhook(&TaskRestoredEvent{})
 		RegisterEventForWebhook(&TaskAssigneeCreatedEvent{})
 		RegisterEventForWebhook(&TaskAssigneeDeletedEvent{})
 		RegisterEventForWebhook(&TaskCommentCreatedEvent{})
@@ -89,6 +149,7 @@ func RegisterListeners() {
 		RegisterEventForWebhook(&TaskRelationDeletedEvent{})
 		RegisterEventForWebhook(&ProjectUpdatedEvent{})
 		RegisterEventForWebhook(&ProjectDeletedEvent{})
+		RegisterEventForWebhook(&ProjectRestoredEvent{})
 		RegisterEventForWebhook(&ProjectSharedWithUserEvent{})
 		RegisterEventForWebhook(&ProjectSharedWithTeamEvent{})
 	}
@@ -201,6 +262,11 @@ func (s *SendTaskCommentNotification) Handle(msg *message.Message) (err error) {
 		return err
 	}
 
+	parentAuthorID, err := notifyParentCommentAuthor(sess, event, mentionedUsers)
+	if err != nil {
+		return err
+	}
+
 	subscribers, err := GetSubscriptionsForEntity(sess, SubscriptionEntityTask, event.Task.ID)
 	if err != nil {
 		return err
@@ -217,6 +283,10 @@ func (s *SendTaskCommentNotification) Handle(msg *message.Message) (err error) {
 			continue
 		}
 
+		if subscriber.UserID == parentAuthorID {
+			continue
+		}
+
 		n := &TaskCommentNotification{
 			Doer:    event.Doer,
 			Task:    event.Task,
@@ -231,6 +301,51 @@ func (s *SendTaskCommentNotification) Handle(msg *message.Message) (err error) {
 	return
 }
 
+// notifyParentCommentAuthor notifies the author of the comment a new comment is a reply to.
+// It returns the id of the notified user or 0 if nobody was notified.
+func notifyParentCommentAuthor(s *xorm.Session, event *TaskCommentCreatedEvent, mentionedUsers map[int64]*user.User) (notifiedUserID int64, err error) {
+	if event.Comment.ParentID == 0 {
+		return 0, nil
+	}
+
+	parent := &TaskComment{ID: event.Comment.ParentID}
+	err = getTaskCommentSimple(s, parent)
+	if IsErrTaskCommentDoesNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Link shares can't be notified
+	if parent.AuthorID <= 0 || parent.AuthorID == event.Doer.ID {
+		return 0, nil
+	}
+
+	// Mentioned users already got a notification about this comment
+	if _, has := mentionedUsers[parent.AuthorID]; has {
+		return parent.AuthorID, nil
+	}
+
+	author, err := user.GetUserByID(s, parent.AuthorID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The author might have lost access to the task since writing the comment
+	canRead, _, err := (&Task{ID: event.Task.ID}).CanRead(s, author)
+	if err != nil || !canRead {
+		return 0, err
+	}
+
+	err = notifications.Notify(author, &TaskCommentReplyNotification{
+		Doer:    event.Doer,
+		Task:    event.Task,
+		Comment: event.Comment,
+	})
+	return author.ID, err
+}
+
 // HandleTaskCommentEditMentions  represents a listener
 type HandleTaskCommentEditMentions struct {
 }
@@ -432,6 +547,184 @@ func (s *HandleTaskUpdatedMentions) Handle(msg *message.Message) (err error) {
 	return err
 }
 
+// UpdateTaskReferences  represents a listener
+type UpdateTaskReferences struct {
+}
+
+// Name defines the name for the UpdateTaskReferences listener
+func (s *UpdateTaskReferences) Name() string {
+	return "task.references.update"
+}
+
+// Handle is executed when the event UpdateTaskReferences listens on is fired
+func (s *UpdateTaskReferences) Handle(msg *message.Message) (err error) {
+	// Task created and task updated events share the same payload
+	event := &TaskUpdatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	sess := db.NewSession()
+	defer sess.Close()
+
+	task, err := GetTaskByIDSimple(sess, event.Task.ID)
+	if IsErrTaskDoesNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return updateTaskReferencesAndNotify(sess, &task, 0, task.Description, event.Doer)
+}
+
+// UpdateTaskCommentReferences  represents a listener
+type UpdateTaskCommentReferences struct {
+}
+
+// Name defines the name for the UpdateTaskCommentReferences listener
+func (s *UpdateTaskCommentReferences) Name() string {
+	return "task.comment.references.update"
+}
+
+// Handle is executed when the event UpdateTaskCommentReferences listens on is fired
+func (s *UpdateTaskCommentReferences) Handle(msg *message.Message) (err error) {
+	// Comment created and comment updated events share the same payload
+	event := &TaskCommentUpdatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	sess := db.NewSession()
+	defer sess.Close()
+
+	comment := &TaskComment{ID: event.Comment.ID}
+	err = getTaskCommentSimple(sess, comment)
+	if IsErrTaskCommentDoesNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	task, err := GetTaskByIDSimple(sess, comment.TaskID)
+	if IsErrTaskDoesNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return updateTaskReferencesAndNotify(sess, &task, comment.ID, comment.Comment, event.Doer)
+}
+
+func updateTaskReferencesAndNotify(sess *xorm.Session, sourceTask *Task, commentID int64, text string, doer *user.User) (err error) {
+	err = sess.Begin()
+	if err != nil {
+		return err
+	}
+
+	added, err := updateTaskReferences(sess, sourceTask, commentID, text, doer)
+	if err != nil {
+		_ = sess.Rollback()
+		return err
+	}
+
+	err = sess.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range added {
+		err = notifyTaskReferenceSubscribers(sess, doer, task, sourceTask, commentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyTaskReferenceSubscribers notifies everyone subscribed to a task which was referenced by another task.
+// Subscribers who can't see the source task are not notified.
+func notifyTaskReferenceSubscribers(sess *xorm.Session, doer *user.User, task *Task, sourceTask *Task, commentID int64) error {
+	subscribers, err := GetSubscriptionsForEntity(sess, SubscriptionEntityTask, task.ID)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Sending task referenced notifications to %d subscribers for task %d", len(subscribers), task.ID)
+
+	notifiedUsers := make(map[int64]bool)
+	for _, subscriber := range subscribers {
+		if (doer != nil && subscriber.UserID == doer.ID) || notifiedUsers[subscriber.UserID] {
+			continue
+		}
+
+		canRead, _, err := (&Project{ID: sourceTask.ProjectID}).CanRead(sess, subscriber.User)
+		if err != nil {
+			return err
+		}
+		if !canRead {
+			continue
+		}
+
+		err = notifications.Notify(subscriber.User, &TaskReferencedNotification{
+			Doer:       doer,
+			Task:       task,
+			SourceTask: sourceTask,
+			CommentID:  commentID,
+		})
+		if err != nil {
+			return err
+		}
+
+		notifiedUsers[subscriber.UserID] = true
+	}
+
+	return nil
+}
+
+// ExtractTaskAttachmentText  represents a listener
+type ExtractTaskAttachmentText struct {
+}
+
+// Name defines the name for the ExtractTaskAttachmentText listener
+func (s *ExtractTaskAttachmentText) Name() string {
+	return "task.attachment.extract.text"
+}
+
+// Handle is executed when the event ExtractTaskAttachmentText listens on is fired
+func (s *ExtractTaskAttachmentText) Handle(msg *message.Message) (err error) {
+	event := &TaskAttachmentCreatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.Attachment == nil {
+		return nil
+	}
+
+	sess := db.NewSession()
+	defer sess.Close()
+
+	extracted, err := extractAttachmentText(sess, event.Attachment)
+	if err != nil || !extracted {
+		return err
+	}
+
+	// The search indexes need the extracted text which is only available now
+	err = reindexTasksByIDsInBleve(sess, []int64{event.Attachment.TaskID})
+	if err != nil {
+		return err
+	}
+
+	return reindexTasksByIDsInTypesense(sess, []int64{event.Attachment.TaskID})
+}
+
 // HandleTaskUpdateLastUpdated  represents a listener
 type HandleTaskUpdateLastUpdated struct {
 }
@@ -562,7 +855,179 @@ func (l *UpdateTaskInTypesense) Handle(msg *message.Message) (err error) {
 	task := make(map[int64]*Task, 1)
 	task[event.Task.ID] = event.Task // Will be filled with all data by the Typesense connector
 
-	return reindexTasksInTypesense(s, task)
+	err = reindexTasksInTypesense(s, task)
+	if err != nil {
+		return err
+	}
+
+	// Other tasks might be blocked by this one or have it as a subtask, their indexed state changes with it.
+	dependentTaskIDs, err := getTaskIDsDependingOnTask(s, event.Task.ID)
+	if err != nil {
+		return err
+	}
+
+	return reindexTasksByIDsInTypesense(s, dependentTaskIDs)
+}
+
+// UpdateRelatedTaskStateInTypesense represents a listener
+type UpdateRelatedTaskStateInTypesense struct {
+}
+
+// Name defines the name for the UpdateRelatedTaskStateInTypesense listener
+func (l *UpdateRelatedTaskStateInTypesense) Name() string {
+	return "typesense.task.related.update"
+}
+
+// Handle is executed when the event UpdateRelatedTaskStateInTypesense listens on is fired.
+// It reindexes the tasks of relation, comment and attachment events because their filterable state is derived
+// from these.
+func (l *UpdateRelatedTaskStateInTypesense) Handle(msg *message.Message) (err error) {
+	event := &struct {
+		Task     *Task         `json:"task"`
+		Relation *TaskRelation `json:"relation"`
+	}{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.Task == nil {
+		return nil
+	}
+
+	taskIDs := []int64{event.Task.ID}
+	if event.Relation != nil {
+		taskIDs = append(taskIDs, event.Relation.OtherTaskID)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return reindexTasksByIDsInTypesense(s, taskIDs)
+}
+
+// UpdateGlobalSearchInTypesense represents a listener
+type UpdateGlobalSearchInTypesense struct {
+	collection *globalSearchCollection
+	// The key of the entity in the event payload
+	payloadKey string
+}
+
+// Name defines the name for the UpdateGlobalSearchInTypesense listener
+func (l *UpdateGlobalSearchInTypesense) Name() string {
+	return "typesense.global.search." + l.collection.name + ".update"
+}
+
+// Handle is executed when the event UpdateGlobalSearchInTypesense listens on is fired
+func (l *UpdateGlobalSearchInTypesense) Handle(msg *message.Message) (err error) {
+	payload := make(map[string]json.RawMessage)
+	err = json.Unmarshal(msg.Payload, &payload)
+	if err != nil {
+		return err
+	}
+
+	raw, has := payload[l.payloadKey]
+	if !has {
+		return nil
+	}
+
+	entity := &struct {
+		ID int64 `json:"id"`
+	}{}
+	err = json.Unmarshal(raw, entity)
+	if err != nil {
+		return err
+	}
+
+	if entity.ID <= 0 {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return l.collection.syncIntoTypesense(s, []int64{entity.ID})
+}
+
+func reindexTasksByIDsInTypesense(s *xorm.Session, taskIDs []int64) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	tasks, err := GetTasksSimpleByIDs(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	taskMap := make(map[int64]*Task, len(tasks))
+	for _, task := range tasks {
+		taskMap[task.ID] = task
+	}
+
+	return reindexTasksInTypesense(s, taskMap)
+}
+
+// UpdateTaskInFullTextSearch represents a listener
+type UpdateTaskInFullTextSearch struct {
+}
+
+// Name defines the name for the UpdateTaskInFullTextSearch listener
+func (l *UpdateTaskInFullTextSearch) Name() string {
+	return "fulltext.task.update"
+}
+
+// Handle is executed when the event UpdateTaskInFullTextSearch listens on is fired
+func (l *UpdateTaskInFullTextSearch) Handle(msg *message.Message) (err error) {
+	event := &struct {
+		Task *Task `json:"task"`
+	}{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.Task == nil {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = updateTasksInFullTextSearch(s, []int64{event.Task.ID})
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// RemoveTaskFromFullTextSearch represents a listener
+type RemoveTaskFromFullTextSearch struct {
+}
+
+// Name defines the name for the RemoveTaskFromFullTextSearch listener
+func (l *RemoveTaskFromFullTextSearch) Name() string {
+	return "fulltext.task.remove"
+}
+
+// Handle is executed when the event RemoveTaskFromFullTextSearch listens on is fired
+func (l *RemoveTaskFromFullTextSearch) Handle(msg *message.Message) (err error) {
+	event := &TaskDeletedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return removeTasksFromFullTextSearch(s, []int64{event.Task.ID})
 }
 
 // UpdateTaskPositionsInTypesense  represents a listener
@@ -600,6 +1065,126 @@ func (l *UpdateTaskPositionsInTypesense) Handle(msg *message.Message) (err error
 	return reindexTasksInTypesense(s, taskMap)
 }
 
+// UpdateTaskInBleve represents a listener
+type UpdateTaskInBleve struct {
+}
+
+// Name defines the name for the UpdateTaskInBleve listener
+func (l *UpdateTaskInBleve) Name() string {
+	return "bleve.task.update"
+}
+
+// Handle is executed when the event UpdateTaskInBleve listens on is fired
+func (l *UpdateTaskInBleve) Handle(msg *message.Message) (err error) {
+	event := &struct {
+		Task *Task `json:"task"`
+	}{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.Task == nil {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	// Other tasks might be blocked by this one or have it as a subtask, their indexed state changes with it.
+	dependentTaskIDs, err := getTaskIDsDependingOnTask(s, event.Task.ID)
+	if err != nil {
+		return err
+	}
+
+	return reindexTasksByIDsInBleve(s, append(dependentTaskIDs, event.Task.ID))
+}
+
+// RemoveTaskFromBleve represents a listener
+type RemoveTaskFromBleve struct {
+}
+
+// Name defines the name for the RemoveTaskFromBleve listener
+func (l *RemoveTaskFromBleve) Name() string {
+	return "bleve.task.remove"
+}
+
+// Handle is executed when the event RemoveTaskFromBleve listens on is fired
+func (l *RemoveTaskFromBleve) Handle(msg *message.Message) (err error) {
+	event := &TaskDeletedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	return removeTasksFromBleve([]int64{event.Task.ID})
+}
+
+// UpdateTaskPositionsInBleve represents a listener
+type UpdateTaskPositionsInBleve struct {
+}
+
+// Name defines the name for the UpdateTaskPositionsInBleve listener
+func (l *UpdateTaskPositionsInBleve) Name() string {
+	return "bleve.task.position.update"
+}
+
+// Handle is executed when the event UpdateTaskPositionsInBleve listens on is fired
+func (l *UpdateTaskPositionsInBleve) Handle(msg *message.Message) (err error) {
+	event := &TaskPositionsRecalculatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	taskIDs := []int64{}
+	for _, position := range event.NewTaskPositions {
+		taskIDs = append(taskIDs, position.TaskID)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return reindexTasksByIDsInBleve(s, taskIDs)
+}
+
+// UpdateRelatedTaskStateInBleve represents a listener
+type UpdateRelatedTaskStateInBleve struct {
+}
+
+// Name defines the name for the UpdateRelatedTaskStateInBleve listener
+func (l *UpdateRelatedTaskStateInBleve) Name() string {
+	return "bleve.task.related.update"
+}
+
+// Handle is executed when the event UpdateRelatedTaskStateInBleve listens on is fired.
+// It reindexes the tasks of relation, comment, attachment and assignee events because their indexed state is
+// derived from these.
+func (l *UpdateRelatedTaskStateInBleve) Handle(msg *message.Message) (err error) {
+	event := &struct {
+		Task     *Task         `json:"task"`
+		Relation *TaskRelation `json:"relation"`
+	}{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.Task == nil {
+		return nil
+	}
+
+	taskIDs := []int64{event.Task.ID}
+	if event.Relation != nil {
+		taskIDs = append(taskIDs, event.Relation.OtherTaskID)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return reindexTasksByIDsInBleve(s, taskIDs)
+}
+
 // IncreaseAttachmentCounter  represents a listener
 type IncreaseAttachmentCounter struct {
 }
@@ -744,6 +1329,11 @@ func (l *UpdateTaskInSavedFilterViews) Handle(msg *message.Message) (err error)
 			return
 		}
 
+		err = reindexTasksByIDsInBleve(s, []int64{event.Task.ID})
+		if err != nil {
+			return
+		}
+
 		task := make(map[int64]*Task, 1)
 		task[event.Task.ID] = event.Task // Will be filled with all data by the Typesense connector
 
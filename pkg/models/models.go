@@ -49,10 +49,14 @@ func GetTables() []interface{} {
 		&Label{},
 		&LabelTask{},
 		&TaskReminder{},
+		&TaskReminderSnooze{},
 		&LinkSharing{},
 		&TaskRelation{},
 		&TaskAttachment{},
+		&TaskAttachmentText{},
 		&TaskComment{},
+		&TaskCommentRevision{},
+		&TaskReference{},
 		&Bucket{},
 		&UnsplashPhoto{},
 		&SavedFilter{},
@@ -65,6 +69,13 @@ func GetTables() []interface{} {
 		&ProjectView{},
 		&TaskPosition{},
 		&TaskBucket{},
+		&TaskOccurrence{},
+		&TaskActivity{},
+		&TrashItem{},
+		&TaskTimeEntry{},
+		&ProjectCustomField{},
+		&TaskCustomFieldValue{},
+		&Template{},
 	}
 }
 
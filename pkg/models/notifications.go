@@ -28,11 +28,44 @@ import (
 	"code.vikunja.io/api/pkg/utils"
 )
 
+func init() {
+	// Users can choose the channels for these, all other notifications are always sent.
+	notifications.RegisterConfigurableNotification(
+		(&ReminderDueNotification{}).Name(),
+		(&TaskCommentNotification{}).Name(),
+		(&TaskCommentReplyNotification{}).Name(),
+		(&TaskAssignedNotification{}).Name(),
+		(&TaskDeletedNotification{}).Name(),
+		(&ProjectCreatedNotification{}).Name(),
+		(&TeamMemberAddedNotification{}).Name(),
+		(&UndoneTaskOverdueNotification{}).Name(),
+		(&UserMentionedInTaskNotification{}).Name(),
+		(&TaskReferencedNotification{}).Name(),
+	)
+
+	// Reminders are time critical, the rest can wait for the digest of users who opted in.
+	notifications.RegisterDigestibleNotification(
+		(&TaskCommentNotification{}).Name(),
+		(&TaskCommentReplyNotification{}).Name(),
+		(&TaskAssignedNotification{}).Name(),
+		(&TaskDeletedNotification{}).Name(),
+		(&ProjectCreatedNotification{}).Name(),
+		(&TeamMemberAddedNotification{}).Name(),
+		(&UserMentionedInTaskNotification{}).Name(),
+		(&TaskReferencedNotification{}).Name(),
+	)
+}
+
+// pushMessageMaxLength is the maximum length of user content like comments in push messages.
+const pushMessageMaxLength = 250
+
 // ReminderDueNotification represents a ReminderDueNotification notification
 type ReminderDueNotification struct {
 	User    *user.User `json:"user,omitempty"`
 	Task    *Task      `json:"task"`
 	Project *Project   `json:"project"`
+	// The ways to snooze the reminder right from the notification.
+	SnoozeActions []*ReminderSnoozeAction `json:"snooze_actions,omitempty"`
 }
 
 // ToMail returns the mail notification for ReminderDueNotification
@@ -50,8 +83,9 @@ func (n *ReminderDueNotification) ToMail(lang string) *notifications.Mail {
 // ToDB returns the ReminderDueNotification notification in a format which can be saved in the db
 func (n *ReminderDueNotification) ToDB() interface{} {
 	return &ReminderDueNotification{
-		Task:    n.Task,
-		Project: n.Project,
+		Task:          n.Task,
+		Project:       n.Project,
+		SnoozeActions: getReminderSnoozeActions(n.Task.ID),
 	}
 }
 
@@ -60,6 +94,16 @@ func (n *ReminderDueNotification) Name() string {
 	return "task.reminder"
 }
 
+// ToPush returns the push message for ReminderDueNotification
+func (n *ReminderDueNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title:    i18n.T(lang, "notifications.task.reminder.subject", n.Task.Title, n.Project.Title),
+		Message:  i18n.T(lang, "notifications.task.reminder.message", n.Task.Title, n.Project.Title),
+		URL:      n.Task.GetFrontendURL(),
+		Priority: 4,
+	}
+}
+
 // TaskCommentNotification represents a TaskCommentNotification notification
 type TaskCommentNotification struct {
 	Doer      *user.User   `json:"doer"`
@@ -101,6 +145,69 @@ func (n *TaskCommentNotification) Name() string {
 	return "task.comment"
 }
 
+// ReplyTo returns the address replies to the mail are turned into comments from
+func (n *TaskCommentNotification) ReplyTo(notifiable notifications.Notifiable) string {
+	return getTaskReplyAddress(n.Task.ID, notifiable.RouteForDB())
+}
+
+// ToPush returns the push message for TaskCommentNotification. Only mentions are pushed.
+func (n *TaskCommentNotification) ToPush(lang string) *notifications.PushMessage {
+	if !n.Mentioned {
+		return nil
+	}
+
+	return &notifications.PushMessage{
+		Title:   i18n.T(lang, "notifications.task.comment.mentioned_subject", n.Doer.GetName(), n.Task.Title),
+		Message: notifications.PushText(n.Comment.Comment, pushMessageMaxLength),
+		URL:     n.Task.GetFrontendURL(),
+	}
+}
+
+// TaskCommentReplyNotification is sent to the author of a comment when someone replies to it
+type TaskCommentReplyNotification struct {
+	Doer    *user.User   `json:"doer"`
+	Task    *Task        `json:"task"`
+	Comment *TaskComment `json:"comment"`
+}
+
+func (n *TaskCommentReplyNotification) SubjectID() int64 {
+	return n.Comment.ID
+}
+
+// ToMail returns the mail notification for TaskCommentReplyNotification
+func (n *TaskCommentReplyNotification) ToMail(lang string) *notifications.Mail {
+	return notifications.NewMail().
+		From(n.Doer.GetNameAndFromEmail()).
+		Subject(i18n.T(lang, "notifications.task.comment.reply_subject", n.Doer.GetName(), n.Task.Title)).
+		Line(i18n.T(lang, "notifications.task.comment.reply_message", n.Doer.GetName())).
+		HTML(n.Comment.Comment).
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), n.Task.GetFrontendURL())
+}
+
+// ToDB returns the TaskCommentReplyNotification notification in a format which can be saved in the db
+func (n *TaskCommentReplyNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *TaskCommentReplyNotification) Name() string {
+	return "task.comment.reply"
+}
+
+// ReplyTo returns the address replies to the mail are turned into comments from
+func (n *TaskCommentReplyNotification) ReplyTo(notifiable notifications.Notifiable) string {
+	return getTaskReplyAddress(n.Task.ID, notifiable.RouteForDB())
+}
+
+// ToPush returns the push message for TaskCommentReplyNotification
+func (n *TaskCommentReplyNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title:   i18n.T(lang, "notifications.task.comment.reply_subject", n.Doer.GetName(), n.Task.Title),
+		Message: notifications.PushText(n.Comment.Comment, pushMessageMaxLength),
+		URL:     n.Task.GetFrontendURL(),
+	}
+}
+
 // TaskAssignedNotification represents a TaskAssignedNotification notification
 type TaskAssignedNotification struct {
 	Doer     *user.User `json:"doer"`
@@ -134,6 +241,24 @@ func (n *TaskAssignedNotification) Name() string {
 	return "task.assigned"
 }
 
+// ReplyTo returns the address replies to the mail are turned into comments from
+func (n *TaskAssignedNotification) ReplyTo(notifiable notifications.Notifiable) string {
+	return getTaskReplyAddress(n.Task.ID, notifiable.RouteForDB())
+}
+
+// ToPush returns the push message for TaskAssignedNotification. Only the assignee gets a push message.
+func (n *TaskAssignedNotification) ToPush(lang string) *notifications.PushMessage {
+	if n.Target.ID != n.Assignee.ID {
+		return nil
+	}
+
+	return &notifications.PushMessage{
+		Title:   i18n.T(lang, "notifications.task.assigned.subject_to_assignee", n.Task.Title, n.Task.GetFullIdentifier()),
+		Message: i18n.T(lang, "notifications.task.assigned.message_to_assignee", n.Doer.GetName(), n.Task.Title),
+		URL:     n.Task.GetFrontendURL(),
+	}
+}
+
 // TaskDeletedNotification represents a TaskDeletedNotification notification
 type TaskDeletedNotification struct {
 	Doer *user.User `json:"doer"`
@@ -245,6 +370,16 @@ func (n *UndoneTaskOverdueNotification) Name() string {
 	return "task.undone.overdue"
 }
 
+// ToPush returns the push message for UndoneTaskOverdueNotification
+func (n *UndoneTaskOverdueNotification) ToPush(lang string) *notifications.PushMessage {
+	until := time.Until(n.Task.DueDate).Round(1*time.Hour) * -1
+	return &notifications.PushMessage{
+		Title:   i18n.T(lang, "notifications.task.overdue.subject", n.Task.Title, n.Project.Title),
+		Message: i18n.T(lang, "notifications.task.overdue.message", n.Task.Title, n.Project.Title, getOverdueSinceString(until, n.User.Language)),
+		URL:     n.Task.GetFrontendURL(),
+	}
+}
+
 // UndoneTasksOverdueNotification represents a UndoneTasksOverdueNotification notification
 type UndoneTasksOverdueNotification struct {
 	User     *user.User
@@ -330,6 +465,61 @@ func (n *UserMentionedInTaskNotification) Name() string {
 	return "task.mentioned"
 }
 
+// ToPush returns the push message for UserMentionedInTaskNotification
+func (n *UserMentionedInTaskNotification) ToPush(lang string) *notifications.PushMessage {
+	title := i18n.T(lang, "notifications.task.mentioned.subject", n.Doer.GetName(), n.Task.Title)
+	if n.IsNew {
+		title = i18n.T(lang, "notifications.task.mentioned.subject_new", n.Doer.GetName(), n.Task.Title)
+	}
+
+	return &notifications.PushMessage{
+		Title:   title,
+		Message: notifications.PushText(n.Task.Description, pushMessageMaxLength),
+		URL:     n.Task.GetFrontendURL(),
+	}
+}
+
+// TaskReferencedNotification is sent to the subscribers of a task when another task references it in its
+// description or a comment
+type TaskReferencedNotification struct {
+	Doer       *user.User `json:"doer"`
+	Task       *Task      `json:"task"`
+	SourceTask *Task      `json:"source_task"`
+	CommentID  int64      `json:"comment_id"`
+}
+
+func (n *TaskReferencedNotification) SubjectID() int64 {
+	return n.SourceTask.ID
+}
+
+// ToMail returns the mail notification for TaskReferencedNotification
+func (n *TaskReferencedNotification) ToMail(lang string) *notifications.Mail {
+	return notifications.NewMail().
+		From(n.Doer.GetNameAndFromEmail()).
+		Subject(i18n.T(lang, "notifications.task.referenced.subject", n.Doer.GetName(), n.Task.Title, n.SourceTask.Title)).
+		Line(i18n.T(lang, "notifications.task.referenced.message", n.Doer.GetName(), n.Task.Title, n.SourceTask.Title)).
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), n.SourceTask.GetFrontendURL())
+}
+
+// ToDB returns the TaskReferencedNotification notification in a format which can be saved in the db
+func (n *TaskReferencedNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *TaskReferencedNotification) Name() string {
+	return "task.referenced"
+}
+
+// ToPush returns the push message for TaskReferencedNotification
+func (n *TaskReferencedNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title:   i18n.T(lang, "notifications.task.referenced.subject", n.Doer.GetName(), n.Task.Title, n.SourceTask.Title),
+		Message: i18n.T(lang, "notifications.task.referenced.message", n.Doer.GetName(), n.Task.Title, n.SourceTask.Title),
+		URL:     n.SourceTask.GetFrontendURL(),
+	}
+}
+
 // DataExportReadyNotification represents a DataExportReadyNotification notification
 type DataExportReadyNotification struct {
 	User *user.User `json:"user"`
@@ -58,6 +58,12 @@ type Project struct {
 	// Whether a project is archived.
 	IsArchived bool `xorm:"not null default false" json:"is_archived" query:"is_archived"`
 
+	// If true, tasks in this project cannot be marked as done while one of the tasks blocking them is not done yet.
+	EnforceDependencies bool `xorm:"not null default false" json:"enforce_dependencies"`
+
+	// The secret part of the email address tasks can be created with in this project.
+	InboundEmailToken string `xorm:"varchar(32) null INDEX" json:"-"`
+
 	// The id of the file this project has set as background
 	BackgroundFileID int64 `xorm:"null" json:"-"`
 	// Holds extra information about the background set since some background providers require attribution or similar. If not null, the background can be accessed at /projects/{projectID}/background
@@ -100,10 +106,11 @@ type ProjectWithTasksAndBuckets struct {
 	// An array of tasks which belong to the project.
 	Tasks []*TaskWithComments `xorm:"-" json:"tasks"`
 	// Only used for migration.
-	Buckets          []*Bucket       `xorm:"-" json:"buckets"`
-	TaskBuckets      []*TaskBucket   `xorm:"-" json:"task_buckets"`
-	Positions        []*TaskPosition `xorm:"-" json:"positions"`
-	BackgroundFileID int64           `xorm:"null" json:"background_file_id"`
+	Buckets          []*Bucket             `xorm:"-" json:"buckets"`
+	TaskBuckets      []*TaskBucket         `xorm:"-" json:"task_buckets"`
+	Positions        []*TaskPosition       `xorm:"-" json:"positions"`
+	CustomFields     []*ProjectCustomField `xorm:"-" json:"custom_fields"`
+	BackgroundFileID int64                 `xorm:"null" json:"background_file_id"`
 }
 
 // TableName returns a better name for the projects table
@@ -913,7 +920,7 @@ func CreateProject(s *xorm.Session, project *Project, auth web.Auth, createBackl
 		}
 	}
 
-	return events.Dispatch(&ProjectCreatedEvent{
+	return events.DispatchOnCommit(s, &ProjectCreatedEvent{
 		Project: project,
 		Doer:    doer,
 	})
@@ -971,6 +978,7 @@ func UpdateProject(s *xorm.Session, project *Project, auth web.Auth, updateProje
 		"position",
 		"done_bucket_id",
 		"default_bucket_id",
+		"enforce_dependencies",
 	}
 	if project.Description != "" {
 		colsToUpdate = append(colsToUpdate, "description")
@@ -1013,7 +1021,7 @@ func UpdateProject(s *xorm.Session, project *Project, auth web.Auth, updateProje
 		return err
 	}
 
-	err = events.Dispatch(&ProjectUpdatedEvent{
+	err = events.DispatchOnCommit(s, &ProjectUpdatedEvent{
 		Project: project,
 		Doer:    auth,
 	})
@@ -1146,7 +1154,7 @@ func (p *Project) isDefaultProject(s *xorm.Session) (is bool, err error) {
 
 // Delete implements the delete method of CRUDable
 // @Summary Deletes a project
-// @Description Delets a project
+// @Description Delets a project with all of its child projects and tasks. The project is moved to the trash and can be restored until the trash item expires.
 // @tags project
 // @Produce json
 // @Security JWTKeyAuth
@@ -1158,111 +1166,102 @@ func (p *Project) isDefaultProject(s *xorm.Session) (is bool, err error) {
 // @Router /projects/{id} [delete]
 func (p *Project) Delete(s *xorm.Session, a web.Auth) (err error) {
 
-	isDefaultProject, err := p.isDefaultProject(s)
+	// The project is moved to the trash together with all of its child projects
+	projects, err := getProjectWithDescendants(s, p.ID)
 	if err != nil {
 		return err
 	}
-	// Owners should be allowed to delete the default project
-	if isDefaultProject && p.OwnerID != a.GetID() {
-		return &ErrCannotDeleteDefaultProject{ProjectID: p.ID}
-	}
-
-	// Delete all tasks on that project
-	// Using the loop to make sure all related entities to all tasks are properly deleted as well.
-	tasks, _, _, err := getRawTasksForProjects(s, []*Project{p}, a, &taskSearchOptions{})
-	if err != nil {
-		return
-	}
+	fullProject := projects[0]
 
-	for _, task := range tasks {
-		err = task.Delete(s, a)
+	projectIDs := make([]int64, 0, len(projects))
+	for _, project := range projects {
+		isDefaultProject, err := project.isDefaultProject(s)
 		if err != nil {
 			return err
 		}
+		ownerID := project.OwnerID
+		if project.ID == p.ID {
+			ownerID = p.OwnerID
+		}
+		// Owners should be allowed to delete the default project
+		if isDefaultProject && ownerID != a.GetID() {
+			return &ErrCannotDeleteDefaultProject{ProjectID: project.ID}
+		}
+
+		projectIDs = append(projectIDs, project.ID)
 	}
 
-	fullProject, err := GetProjectSimpleByID(s, p.ID)
+	// If we're deleting a default project, remove it as default
+	_, err = s.In("default_project_id", projectIDs).
+		Cols("default_project_id").
+		Update(&user.User{DefaultProjectID: 0})
 	if err != nil {
 		return
 	}
 
-	err = fullProject.DeleteBackgroundFileIfExists()
+	tasks := []*Task{}
+	err = s.In("project_id", projectIDs).OrderBy("id asc").Find(&tasks)
 	if err != nil {
 		return
 	}
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
 
-	// If we're deleting a default project, remove it as default
-	if isDefaultProject {
-		_, err = s.Where("default_project_id = ?", p.ID).
-			Cols("default_project_id").
-			Update(&user.User{DefaultProjectID: 0})
+		err = removeFromFavorite(s, task.ID, a, FavoriteKindTask)
 		if err != nil {
 			return
 		}
 	}
 
-	// Delete related project entities
-	views, err := getViewsForProject(s, p.ID)
-	if err != nil {
-		return
-	}
-	viewIDs := []int64{}
-	for _, v := range views {
-		viewIDs = append(viewIDs, v.ID)
-	}
-
-	_, err = s.In("project_view_id", viewIDs).Delete(&Bucket{})
-	if err != nil {
-		return
-	}
-
-	_, err = s.In("id", viewIDs).Delete(&ProjectView{})
-	if err != nil {
-		return
-	}
-
-	err = removeFromFavorite(s, p.ID, a, FavoriteKindProject)
-	if err != nil {
-		return
-	}
-
-	_, err = s.Where("project_id = ?", p.ID).Delete(&LinkSharing{})
-	if err != nil {
-		return
-	}
-
-	_, err = s.Where("project_id = ?", p.ID).Delete(&ProjectUser{})
-	if err != nil {
-		return
+	for _, project := range projects {
+		err = removeFromFavorite(s, project.ID, a, FavoriteKindProject)
+		if err != nil {
+			return
+		}
 	}
 
-	_, err = s.Where("project_id = ?", p.ID).Delete(&TeamProject{})
+	trashedTasks, err := moveTasksToTrash(s, taskIDs)
 	if err != nil {
 		return
 	}
 
-	// Delete the project
-	_, err = s.ID(p.ID).Delete(&Project{})
+	trashedProjects, err := moveProjectsToTrash(s, projects)
 	if err != nil {
 		return
 	}
 
-	err = events.Dispatch(&ProjectDeletedEvent{
-		Project: fullProject,
-		Doer:    a,
+	err = addToTrash(s, a, &TrashItem{
+		Kind:      TrashItemKindProject,
+		EntityID:  fullProject.ID,
+		Title:     fullProject.Title,
+		ProjectID: fullProject.ParentProjectID,
+		OwnerID:   fullProject.OwnerID,
+		Content: &trashContent{
+			Tasks:    trashedTasks,
+			Projects: trashedProjects,
+		},
 	})
 	if err != nil {
 		return
 	}
 
-	childProjects := []*Project{}
-	err = s.Where("parent_project_id = ?", fullProject.ID).Find(&childProjects)
-	if err != nil {
-		return
+	doer, _ := user.GetFromAuth(a)
+	for _, task := range tasks {
+		err = events.DispatchOnCommit(s, &TaskDeletedEvent{
+			Task: task,
+			Doer: doer,
+		})
+		if err != nil {
+			return
+		}
 	}
 
-	for _, child := range childProjects {
-		err = child.Delete(s, a)
+	for _, project := range projects {
+		err = events.DispatchOnCommit(s, &ProjectDeletedEvent{
+			Project: project,
+			Doer:    a,
+		})
 		if err != nil {
 			return
 		}
@@ -0,0 +1,411 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+type ProjectCustomFieldType int
+
+// NOTE: When adding or changing enum values for ProjectCustomFieldType,
+// make sure to update the corresponding `enums` tag in the ProjectCustomField struct
+// to keep the OpenAPI documentation in sync.
+
+const (
+	ProjectCustomFieldTypeText ProjectCustomFieldType = iota
+	ProjectCustomFieldTypeNumber
+	ProjectCustomFieldTypeDate
+	ProjectCustomFieldTypeSelect
+	ProjectCustomFieldTypeMultiSelect
+	ProjectCustomFieldTypeUser
+)
+
+func (p *ProjectCustomFieldType) MarshalJSON() ([]byte, error) {
+	switch *p {
+	case ProjectCustomFieldTypeText:
+		return []byte(`"text"`), nil
+	case ProjectCustomFieldTypeNumber:
+		return []byte(`"number"`), nil
+	case ProjectCustomFieldTypeDate:
+		return []byte(`"date"`), nil
+	case ProjectCustomFieldTypeSelect:
+		return []byte(`"select"`), nil
+	case ProjectCustomFieldTypeMultiSelect:
+		return []byte(`"multiselect"`), nil
+	case ProjectCustomFieldTypeUser:
+		return []byte(`"user"`), nil
+	}
+
+	return []byte(`null`), nil
+}
+
+func (p *ProjectCustomFieldType) UnmarshalJSON(bytes []byte) error {
+	var value string
+	err := json.Unmarshal(bytes, &value)
+	if err != nil {
+		return err
+	}
+
+	switch value {
+	case "text":
+		*p = ProjectCustomFieldTypeText
+	case "number":
+		*p = ProjectCustomFieldTypeNumber
+	case "date":
+		*p = ProjectCustomFieldTypeDate
+	case "select":
+		*p = ProjectCustomFieldTypeSelect
+	case "multiselect":
+		*p = ProjectCustomFieldTypeMultiSelect
+	case "user":
+		*p = ProjectCustomFieldTypeUser
+	default:
+		return fmt.Errorf("unknown custom field type: %s", value)
+	}
+
+	return nil
+}
+
+func (p ProjectCustomFieldType) hasOptions() bool {
+	return p == ProjectCustomFieldTypeSelect || p == ProjectCustomFieldTypeMultiSelect
+}
+
+// ProjectCustomField is an additional attribute defined for all tasks of a project.
+// The values are stored per task and are set through the task update endpoints.
+type ProjectCustomField struct {
+	// The unique numeric id of this custom field. Use it as `custom_field_<id>` to filter or sort tasks by this field.
+	// Fields of the type `user` can be filtered by username, like `custom_field_<id> in 'jane', 'john'`.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"customfield"`
+	// The project this custom field belongs to
+	ProjectID int64 `xorm:"bigint not null INDEX" json:"project_id" param:"project"`
+	// The title of this custom field
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The type of this custom field. Can be `text`, `number`, `date`, `select`, `multiselect` or `user`. You cannot change the type once the field was created.
+	Type ProjectCustomFieldType `xorm:"not null default 0" json:"type" swaggertype:"string" enums:"text,number,date,select,multiselect,user"`
+	// The values a user can choose from for `select` and `multiselect` fields.
+	Options []string `xorm:"json null" json:"options"`
+	// The position of this custom field in the list. The list of all custom fields will be sorted by this parameter.
+	Position float64 `xorm:"double null" json:"position"`
+
+	// A timestamp when this custom field was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this custom field was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for project custom fields
+func (*ProjectCustomField) TableName() string {
+	return "project_custom_fields"
+}
+
+func (cf *ProjectCustomField) validateOptions() error {
+	if !cf.Type.hasOptions() {
+		cf.Options = nil
+		return nil
+	}
+
+	if len(cf.Options) == 0 {
+		return &ErrInvalidCustomFieldOptions{Title: cf.Title}
+	}
+
+	seen := make(map[string]bool, len(cf.Options))
+	for i, option := range cf.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			return &ErrInvalidCustomFieldOptions{Title: cf.Title}
+		}
+		seen[option] = true
+		cf.Options[i] = option
+	}
+
+	return nil
+}
+
+func (cf *ProjectCustomField) hasOption(option string) bool {
+	for _, o := range cf.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func getCustomFieldsForProject(s *xorm.Session, projectID int64) (fields []*ProjectCustomField, err error) {
+	fields = []*ProjectCustomField{}
+	err = s.
+		Where("project_id = ?", projectID).
+		OrderBy("position asc, id asc").
+		Find(&fields)
+	return
+}
+
+func getCustomFieldsByIDs(s *xorm.Session, ids []int64) (fields map[int64]*ProjectCustomField, err error) {
+	fields = make(map[int64]*ProjectCustomField, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+
+	err = s.In("id", ids).Find(&fields)
+	return
+}
+
+func getCustomFieldByIDAndProject(s *xorm.Session, id, projectID int64) (field *ProjectCustomField, err error) {
+	field = &ProjectCustomField{}
+	exists, err := s.
+		Where(builder.Eq{"id": id, "project_id": projectID}).
+		NoAutoCondition().
+		Get(field)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrCustomFieldDoesNotExist{
+			CustomFieldID: id,
+			ProjectID:     projectID,
+		}
+	}
+
+	return
+}
+
+// ReadAll gets all custom fields of a project
+// @Summary Get all custom fields of a project
+// @Description Returns all custom fields defined for a project, sorted by their position.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {array} models.ProjectCustomField "The custom fields"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/custom_fields [get]
+func (cf *ProjectCustomField) ReadAll(s *xorm.Session, a web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	p := &Project{ID: cf.ProjectID}
+	can, _, err := p.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	fields, err := getCustomFieldsForProject(s, cf.ProjectID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return fields, len(fields), int64(len(fields)), nil
+}
+
+// ReadOne returns one custom field
+// @Summary Get one custom field
+// @Description Returns a custom field of a project by its ID.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param id path int true "Custom Field ID"
+// @Success 200 {object} models.ProjectCustomField "The custom field"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project"
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/custom_fields/{id} [get]
+func (cf *ProjectCustomField) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	field, err := getCustomFieldByIDAndProject(s, cf.ID, cf.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	*cf = *field
+	return
+}
+
+// Create adds a new custom field to a project
+// @Summary Create a custom field
+// @Description Creates a new custom field in a project. Select and multiselect fields need at least one option.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param field body models.ProjectCustomField true "The custom field you want to create."
+// @Success 201 {object} models.ProjectCustomField "The created custom field"
+// @Failure 400 {object} web.HTTPError "Invalid custom field object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/custom_fields [put]
+func (cf *ProjectCustomField) Create(s *xorm.Session, _ web.Auth) (err error) {
+	cf.ID = 0
+
+	err = cf.validateOptions()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Insert(cf)
+	if err != nil {
+		return err
+	}
+
+	if cf.Position == 0 {
+		cf.Position = calculateDefaultPosition(cf.ID, cf.Position)
+		_, err = s.ID(cf.ID).Cols("position").Update(cf)
+	}
+	return
+}
+
+// Update changes a custom field
+// @Summary Update a custom field
+// @Description Updates the title, options or position of a custom field. Values of tasks which use an option that was removed are removed as well.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param id path int true "Custom Field ID"
+// @Param field body models.ProjectCustomField true "The custom field with updated values you want to change."
+// @Success 200 {object} models.ProjectCustomField "The updated custom field."
+// @Failure 400 {object} web.HTTPError "Invalid custom field object provided."
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/custom_fields/{id} [post]
+func (cf *ProjectCustomField) Update(s *xorm.Session, a web.Auth) (err error) {
+	old, err := getCustomFieldByIDAndProject(s, cf.ID, cf.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	cf.Type = old.Type
+	err = cf.validateOptions()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(cf.ID).
+		Cols(
+			"title",
+			"options",
+			"position",
+		).
+		Update(cf)
+	if err != nil {
+		return err
+	}
+
+	if cf.Type.hasOptions() {
+		removedValues := builder.And(
+			builder.Eq{"field_id": cf.ID},
+			builder.NotIn("value_text", cf.Options),
+		)
+
+		taskIDs := []int64{}
+		err = s.
+			Table("task_custom_field_values").
+			Where(removedValues).
+			Distinct("task_id").
+			Find(&taskIDs)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.
+			Where(removedValues).
+			Delete(&TaskCustomFieldValue{})
+		if err != nil {
+			return err
+		}
+
+		// The tasks which lost their value need to be reindexed and re-evaluated against filters
+		tasks := []*Task{}
+		if len(taskIDs) > 0 {
+			tasks, err = GetTasksSimpleByIDs(s, taskIDs)
+		}
+		if err != nil {
+			return err
+		}
+		doer, _ := user.GetFromAuth(a)
+		for _, task := range tasks {
+			err = events.DispatchOnCommit(s, &TaskUpdatedEvent{
+				Task: task,
+				Doer: doer,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return cf.ReadOne(s, nil)
+}
+
+// Delete removes a custom field and all of its values
+// @Summary Delete a custom field
+// @Description Deletes a custom field and the values all tasks in the project have for it.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param id path int true "Custom Field ID"
+// @Success 200 {object} models.Message "The custom field was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project"
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/custom_fields/{id} [delete]
+func (cf *ProjectCustomField) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = getCustomFieldByIDAndProject(s, cf.ID, cf.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("field_id = ?", cf.ID).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ID(cf.ID).Delete(&ProjectCustomField{})
+	return
+}
+
+func deleteCustomFieldsForProject(s *xorm.Session, projectID int64) (err error) {
+	_, err = s.
+		Where(builder.In("field_id", builder.Select("id").From("project_custom_fields").Where(builder.Eq{"project_id": projectID}))).
+		Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("project_id = ?", projectID).Delete(&ProjectCustomField{})
+	return
+}
@@ -0,0 +1,361 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+func TestProjectCustomField_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ProjectID: 1,
+			Title:     "Severity",
+			Type:      ProjectCustomFieldTypeSelect,
+			Options:   []string{" low ", "high"},
+		}
+		can, err := cf.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = cf.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"low", "high"}, cf.Options)
+		assert.NotZero(t, cf.Position)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "project_custom_fields", map[string]interface{}{
+			"id":         cf.ID,
+			"project_id": 1,
+			"title":      "Severity",
+			"type":       ProjectCustomFieldTypeSelect,
+		}, false)
+	})
+	t.Run("select without options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ProjectID: 1,
+			Title:     "Severity",
+			Type:      ProjectCustomFieldTypeMultiSelect,
+		}
+		err := cf.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldOptions(err))
+	})
+	t.Run("duplicate options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ProjectID: 1,
+			Title:     "Severity",
+			Type:      ProjectCustomFieldTypeSelect,
+			Options:   []string{"low", "low"},
+		}
+		err := cf.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldOptions(err))
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ProjectID: 1,
+			Title:     "Severity",
+		}
+		can, err := cf.CanCreate(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestProjectCustomField_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("removes values of removed options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		events.Fake()
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ID:        4,
+			ProjectID: 1,
+			Title:     "Stage",
+			Type:      ProjectCustomFieldTypeText,
+			Options:   []string{"production"},
+		}
+		err := cf.Update(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, ProjectCustomFieldTypeSelect, cf.Type)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "project_custom_fields", map[string]interface{}{
+			"id":    4,
+			"title": "Stage",
+			"type":  ProjectCustomFieldTypeSelect,
+		}, false)
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":  1,
+			"field_id": 4,
+		})
+		events.AssertDispatched(t, &TaskUpdatedEvent{})
+	})
+	t.Run("keeps tasks with remaining options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		events.Fake()
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ID:        4,
+			ProjectID: 1,
+			Title:     "Environment",
+			Options:   []string{"staging", "production", "testing"},
+		}
+		err := cf.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":    1,
+			"field_id":   4,
+			"value_text": "staging",
+		}, false)
+		events.AssertNotDispatched(t, &TaskUpdatedEvent{})
+	})
+	t.Run("field of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{
+			ID:        7,
+			ProjectID: 1,
+			Title:     "Customer",
+		}
+		err := cf.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrCustomFieldDoesNotExist(err))
+	})
+}
+
+func TestProjectCustomField_Delete(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{ID: 1, ProjectID: 1}
+		can, err := cf.CanDelete(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = cf.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "project_custom_fields", map[string]interface{}{
+			"id": 1,
+		})
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"field_id": 1,
+		})
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{ID: 9999, ProjectID: 1}
+		err := cf.Delete(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrCustomFieldDoesNotExist(err))
+	})
+}
+
+func TestProjectCustomField_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{ProjectID: 1}
+		result, resultCount, _, err := cf.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		require.NoError(t, err)
+		assert.Equal(t, 6, resultCount)
+
+		fields := result.([]*ProjectCustomField)
+		assert.Equal(t, "Customer", fields[0].Title)
+		assert.Equal(t, []string{"staging", "production"}, fields[3].Options)
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		cf := &ProjectCustomField{ProjectID: 1}
+		_, _, _, err := cf.ReadAll(s, &user.User{ID: 2}, "", 0, 50)
+		require.Error(t, err)
+	})
+}
+
+func TestTask_updateCustomFieldValues(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("set values of all types", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:        3,
+			ProjectID: 1,
+			Title:     "test",
+			CustomFields: map[int64]interface{}{
+				1: "Initech",
+				2: 5.5,
+				3: "2024-05-01T10:00:00Z",
+				4: "production",
+				5: []interface{}{"docs", "api"},
+				6: float64(1),
+			},
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, "Initech", task.CustomFields[1])
+		assert.InDelta(t, 5.5, task.CustomFields[2], 0)
+		assert.True(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Equal(task.CustomFields[3].(time.Time)))
+		assert.Equal(t, []string{"docs", "api"}, task.CustomFields[5])
+		assert.Equal(t, int64(1), task.CustomFields[6])
+
+		db.AssertExists(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":    3,
+			"field_id":   4,
+			"value_text": "production",
+		}, false)
+		db.AssertCount(t, "task_custom_field_values", builder.Eq{"task_id": 3, "field_id": 5}, 2)
+	})
+	t.Run("remove value", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:        1,
+			ProjectID: 1,
+			Title:     "test",
+			CustomFields: map[int64]interface{}{
+				1: nil,
+			},
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.NotContains(t, task.CustomFields, int64(1))
+		assert.Equal(t, "staging", task.CustomFields[4])
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":  1,
+			"field_id": 1,
+		})
+	})
+	t.Run("invalid option", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			ProjectID:    1,
+			Title:        "test",
+			CustomFields: map[int64]interface{}{4: "development"},
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldValue(err))
+	})
+	t.Run("invalid number", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			ProjectID:    1,
+			Title:        "test",
+			CustomFields: map[int64]interface{}{2: "many"},
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldValue(err))
+	})
+	t.Run("user without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			ProjectID:    1,
+			Title:        "test",
+			CustomFields: map[int64]interface{}{6: float64(2)},
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
+	t.Run("field of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			ProjectID:    1,
+			Title:        "test",
+			CustomFields: map[int64]interface{}{7: "ACME"},
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrCustomFieldDoesNotExist(err))
+	})
+}
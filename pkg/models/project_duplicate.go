@@ -59,7 +59,7 @@ func (pd *ProjectDuplicate) CanCreate(s *xorm.Session, a web.Auth) (canCreate bo
 
 // Create duplicates a project
 // @Summary Duplicate an existing project
-// @Description Copies the project, tasks, files, kanban data, assignees, comments, attachments, labels, relations, backgrounds, user/team permissions and link shares from one project to a new one. The user needs read access in the project and write access in the parent of the new project.
+// @Description Copies the project, tasks, files, kanban data, custom fields, assignees, comments, attachments, labels, relations, backgrounds, user/team permissions and link shares from one project to a new one. The user needs read access in the project and write access in the parent of the new project.
 // @tags project
 // @Accept json
 // @Produce json
@@ -79,6 +79,8 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	pd.Project.ID = 0
 	pd.Project.Identifier = "" // Reset the identifier to trigger regenerating a new one
+	// The copy gets its own inbound address once it is requested
+	pd.Project.InboundEmailToken = ""
 	pd.Project.ParentProjectID = pd.ParentProjectID
 	// Set the owner to the current user
 	pd.Project.OwnerID = doer.GetID()
@@ -95,6 +97,13 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	log.Debugf("Duplicated project %d into new project %d", pd.ProjectID, pd.Project.ID)
 
+	customFieldIDs, err := duplicateCustomFields(s, pd.ProjectID, pd.Project.ID, doer)
+	if err != nil {
+		return
+	}
+
+	log.Debugf("Duplicated all custom fields from project %d into %d", pd.ProjectID, pd.Project.ID)
+
 	newTaskIDs, err := duplicateTasks(s, doer, pd)
 	if err != nil {
 		return
@@ -102,6 +111,13 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	log.Debugf("Duplicated all tasks from project %d into %d", pd.ProjectID, pd.Project.ID)
 
+	err = duplicateCustomFieldValues(s, newTaskIDs, customFieldIDs)
+	if err != nil {
+		return
+	}
+
+	log.Debugf("Duplicated all custom field values from project %d into %d", pd.ProjectID, pd.Project.ID)
+
 	err = duplicateViews(s, pd, doer, newTaskIDs)
 	if err != nil {
 		return
@@ -170,21 +186,67 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 }
 
 func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMap map[int64]int64) (err error) {
-	// Duplicate Views
-	views := make(map[int64]*ProjectView)
-	err = s.Where("project_id = ?", pd.ProjectID).Find(&views)
+	views, buckets, taskBuckets, taskPositions, err := getViewsWithBucketsForProject(s, pd.ProjectID)
+	if err != nil {
+		return
+	}
+
+	return createViewsWithBuckets(s, pd.Project.ID, doer, taskMap, views, buckets, taskBuckets, taskPositions)
+}
+
+// getViewsWithBucketsForProject returns all views of a project together with their buckets, the buckets of all tasks and
+// all task positions.
+func getViewsWithBucketsForProject(s *xorm.Session, projectID int64) (views []*ProjectView, buckets []*Bucket, taskBuckets []*TaskBucket, taskPositions []*TaskPosition, err error) {
+	views, err = getViewsForProject(s, projectID)
+	if err != nil {
+		return
+	}
+
+	viewIDs := []int64{}
+	for _, view := range views {
+		viewIDs = append(viewIDs, view.ID)
+	}
+
+	buckets = []*Bucket{}
+	taskBuckets = []*TaskBucket{}
+	taskPositions = []*TaskPosition{}
+	if len(viewIDs) == 0 {
+		return
+	}
+
+	err = s.In("project_view_id", viewIDs).Find(&buckets)
 	if err != nil {
 		return
 	}
 
-	oldViewIDs := []int64{}
+	err = s.In("project_view_id", viewIDs).Find(&taskBuckets)
+	if err != nil {
+		return
+	}
+
+	err = s.In("project_view_id", viewIDs).Find(&taskPositions)
+	return
+}
+
+// createViewsWithBuckets creates the views and buckets in the project and puts the tasks in their buckets and
+// positions. The ids of the views and buckets are the ones they had in the project they are copied from, taskMap
+// maps the ids of the old tasks to the new ones.
+func createViewsWithBuckets(
+	s *xorm.Session,
+	projectID int64,
+	doer web.Auth,
+	taskMap map[int64]int64,
+	views []*ProjectView,
+	buckets []*Bucket,
+	oldTaskBuckets []*TaskBucket,
+	oldTaskPositions []*TaskPosition,
+) (err error) {
 	viewMap := make(map[int64]int64)
 	for _, view := range views {
 		oldID := view.ID
-		oldViewIDs = append(oldViewIDs, oldID)
 
 		view.ID = 0
-		view.ProjectID = pd.Project.ID
+		view.ProjectID = projectID
 		err = createProjectView(s, view, doer, false, false)
 		if err != nil {
 			return
@@ -193,24 +255,16 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 		viewMap[oldID] = view.ID
 	}
 
-	buckets := []*Bucket{}
-	err = s.In("project_view_id", oldViewIDs).Find(&buckets)
-	if err != nil {
-		return
-	}
-
 	// Old bucket ID as key, new id as value
 	// Used to map the newly created tasks to their new buckets
 	bucketMap := make(map[int64]int64)
 
-	oldBucketIDs := []int64{}
 	for _, b := range buckets {
 		oldBucketID := b.ID
 		oldViewID := b.ProjectViewID
-		oldBucketIDs = append(oldBucketIDs, oldBucketID)
 
 		b.ID = 0
-		b.ProjectID = pd.Project.ID
+		b.ProjectID = projectID
 		b.ProjectViewID = viewMap[oldViewID]
 
 		err = b.Create(s, doer)
@@ -237,17 +291,15 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 		}
 	}
 
-	oldTaskBuckets := []*TaskBucket{}
-	err = s.In("bucket_id", oldBucketIDs).Find(&oldTaskBuckets)
-	if err != nil {
-		return err
-	}
-
 	taskBuckets := []*TaskBucket{}
 	for _, tb := range oldTaskBuckets {
+		taskID, exists := taskMap[tb.TaskID]
+		if !exists {
+			continue
+		}
 		taskBuckets = append(taskBuckets, &TaskBucket{
 			BucketID:      bucketMap[tb.BucketID],
-			TaskID:        taskMap[tb.TaskID],
+			TaskID:        taskID,
 			ProjectViewID: viewMap[tb.ProjectViewID],
 		})
 	}
@@ -259,17 +311,15 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 		}
 	}
 
-	oldTaskPositions := []*TaskPosition{}
-	err = s.In("project_view_id", oldViewIDs).Find(&oldTaskPositions)
-	if err != nil {
-		return
-	}
-
 	taskPositions := []*TaskPosition{}
 	for _, tp := range oldTaskPositions {
+		taskID, exists := taskMap[tp.TaskID]
+		if !exists {
+			continue
+		}
 		taskPositions = append(taskPositions, &TaskPosition{
 			ProjectViewID: viewMap[tp.ProjectViewID],
-			TaskID:        taskMap[tp.TaskID],
+			TaskID:        taskID,
 			Position:      tp.Position,
 		})
 	}
@@ -349,6 +399,8 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate) (newTa
 		t.ID = 0
 		t.ProjectID = ld.Project.ID
 		t.UID = ""
+		// Custom field values are copied separately since they need to reference the new custom fields
+		t.CustomFields = nil
 		err = createTask(s, t, doer, false, false)
 		if err != nil {
 			return nil, err
@@ -431,7 +483,7 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate) (newTa
 			ID:        newTaskIDs[a.TaskID],
 			ProjectID: ld.Project.ID,
 		}
-		if err := t.addNewAssigneeByID(s, a.UserID, ld.Project, doer); err != nil {
+		if _, err := t.addNewAssigneeByID(s, a.UserID, ld.Project, doer); err != nil {
 			if IsErrUserDoesNotHaveAccessToProject(err) {
 				continue
 			}
@@ -443,16 +495,21 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate) (newTa
 
 	// Comments
 	comments := []*TaskComment{}
-	err = s.In("task_id", oldTaskIDs).Find(&comments)
+	err = s.In("task_id", oldTaskIDs).OrderBy("id asc").Find(&comments)
 	if err != nil {
 		return
 	}
+	// Replies are always newer than their parent, so the parent has been copied already.
+	newCommentIDs := make(map[int64]int64, len(comments))
 	for _, c := range comments {
+		oldID := c.ID
 		c.ID = 0
 		c.TaskID = newTaskIDs[c.TaskID]
+		c.ParentID = newCommentIDs[c.ParentID]
 		if _, err := s.Insert(c); err != nil {
 			return nil, err
 		}
+		newCommentIDs[oldID] = c.ID
 	}
 
 	log.Debugf("Duplicated all comments from project %d into %d", ld.ProjectID, ld.Project.ID)
@@ -0,0 +1,351 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/inbound"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/web"
+
+	"github.com/microcosm-cc/bluemonday"
+	"xorm.io/xorm"
+)
+
+const inboundEmailTokenLength = 32
+
+// ProjectInboundEmail holds the email address which can be used to create tasks in a project
+type ProjectInboundEmail struct {
+	ProjectID int64 `json:"-" param:"project"`
+	// Every mail sent to this address creates a new task in the project. The sender
+	// needs to be a user with write access to the project. Because sender addresses can be
+	// forged, anyone who knows the address can create tasks, it should be kept secret.
+	Address string `json:"address"`
+
+	web.Permissions `json:"-"`
+	web.CRUDable    `json:"-"`
+}
+
+func (pi *ProjectInboundEmail) canManage(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares can't send mails
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+	return (&Project{ID: pi.ProjectID}).CanWrite(s, a)
+}
+
+// CanRead checks if a user can see the inbound address of a project
+func (pi *ProjectInboundEmail) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := pi.canManage(s, a)
+	return can, int(PermissionWrite), err
+}
+
+// CanCreate checks if a user can reset the inbound address of a project
+func (pi *ProjectInboundEmail) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return pi.canManage(s, a)
+}
+
+func generateInboundEmailToken() (string, error) {
+	token, err := utils.CryptoRandomString(inboundEmailTokenLength)
+	// Mail servers don't always preserve the case of an address
+	return strings.ToLower(token), err
+}
+
+func setProjectInboundEmailToken(s *xorm.Session, project *Project) (err error) {
+	project.InboundEmailToken, err = generateInboundEmailToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.
+		Where("id = ?", project.ID).
+		Cols("inbound_email_token").
+		NoAutoTime().
+		Update(project)
+	return err
+}
+
+// ReadOne returns the inbound email address of a project
+// @Summary Get the inbound email address of a project
+// @Description Returns the email address which creates a new task in the project for every mail sent to it. The subject becomes the task title, the body its description and all attachments are added to the task. Only mails with the address of a user with write access to the project as sender are accepted. Sender addresses can be forged, so the address itself is the only protection against mails from strangers and should be kept secret. Reset it if it leaked.
+// @tags project
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {object} models.ProjectInboundEmail "The inbound email address."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the project."
+// @Failure 412 {object} web.HTTPError "Inbound mails are not enabled."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/inbound-email [get]
+func (pi *ProjectInboundEmail) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	if !inbound.IsEnabled() {
+		return &ErrInboundEmailDisabled{}
+	}
+
+	project, err := GetProjectSimpleByID(s, pi.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if project.InboundEmailToken == "" {
+		err = setProjectInboundEmailToken(s, project)
+		if err != nil {
+			return err
+		}
+	}
+
+	pi.Address = inbound.GetAddress(project.InboundEmailToken)
+	return nil
+}
+
+// Create resets the inbound email address of a project
+// @Summary Reset the inbound email address of a project
+// @Description Generates a new inbound email address for the project. The old address stops working immediately.
+// @tags project
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 201 {object} models.ProjectInboundEmail "The new inbound email address."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the project."
+// @Failure 412 {object} web.HTTPError "Inbound mails are not enabled."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/inbound-email [put]
+func (pi *ProjectInboundEmail) Create(s *xorm.Session, _ web.Auth) (err error) {
+	if !inbound.IsEnabled() {
+		return &ErrInboundEmailDisabled{}
+	}
+
+	project, err := GetProjectSimpleByID(s, pi.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	err = setProjectInboundEmailToken(s, project)
+	if err != nil {
+		return err
+	}
+
+	pi.Address = inbound.GetAddress(project.InboundEmailToken)
+	return nil
+}
+
+// HandleInboundEmail creates a task for every project address a mail was sent to
+// and a comment for every reply address of a task.
+// All of them are created in one transaction: if one fails, the mail is rejected or deferred as a whole
+// and nothing is created, so a retry by the sending server does not create duplicates.
+func HandleInboundEmail(envelope *inbound.Envelope, msg *inbound.Message) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return err
+	}
+	events.HoldUntilCommit(s)
+
+	// The content of attachment files is not part of the transaction and needs to be removed manually.
+	// This happens before the rollback so no other file can get the id of a removed file in the meantime.
+	attachments := []*TaskAttachment{}
+	rollback := func() {
+		removeInboundEmailAttachmentFiles(attachments)
+		_ = s.Rollback()
+		events.DiscardPending(s)
+	}
+
+	for _, recipient := range envelope.Recipients {
+		token, ok := inbound.GetTokenFromAddress(recipient)
+		if !ok {
+			continue
+		}
+
+		if isReplyToken(token) {
+			err = createCommentFromInboundEmail(s, token, envelope, msg)
+		} else {
+			var created []*TaskAttachment
+			created, err = createTaskFromInboundEmail(s, token, envelope, msg)
+			attachments = append(attachments, created...)
+		}
+		if err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	err = s.Commit()
+	if err != nil {
+		rollback()
+		return err
+	}
+
+	return events.DispatchPending(s)
+}
+
+func removeInboundEmailAttachmentFiles(attachments []*TaskAttachment) {
+	for _, attachment := range attachments {
+		if attachment.File == nil {
+			continue
+		}
+
+		err := attachment.File.RemoveStoredContent()
+		if err != nil {
+			log.Errorf("[Inbound Mail] Could not remove file %d of a rolled back attachment: %s", attachment.File.ID, err)
+		}
+	}
+}
+
+// getInboundEmailSender returns the first user with the sender address of the mail
+// who is allowed to create tasks in the project.
+// The sender address is taken from the mail as it is and can be forged by anyone. It only
+// decides to whom the task is attributed, the secret token in the project address is the
+// only thing protecting a project from mails by strangers.
+func getInboundEmailSender(s *xorm.Session, project *Project, envelope *inbound.Envelope, msg *inbound.Message) (*user.User, error) {
+	from := envelope.From
+	if msg.From != nil {
+		from = msg.From.Address
+	}
+	if from == "" {
+		return nil, &inbound.ErrRejected{Reason: "The mail has no sender"}
+	}
+
+	users := []*user.User{}
+	err := s.
+		Where("lower(email) = ? AND status = ?", strings.ToLower(from), user.StatusActive).
+		OrderBy("id asc").
+		Find(&users)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		can, err := (&Task{ProjectID: project.ID}).CanCreate(s, u)
+		if err != nil {
+			return nil, err
+		}
+		if can {
+			return u, nil
+		}
+	}
+
+	return nil, &inbound.ErrRejected{Reason: "The sender is not allowed to create tasks in this project"}
+}
+
+// createTaskFromInboundEmail returns the attachments it created, even if it failed afterwards.
+func createTaskFromInboundEmail(s *xorm.Session, token string, envelope *inbound.Envelope, msg *inbound.Message) (created []*TaskAttachment, err error) {
+	project := &Project{}
+	exists, err := s.Where("inbound_email_token = ?", strings.ToLower(token)).Get(project)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &inbound.ErrRejected{Reason: "No such project"}
+	}
+	if project.IsArchived {
+		return nil, &inbound.ErrRejected{Reason: "The project is archived"}
+	}
+
+	u, err := getInboundEmailSender(s, project, envelope, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &Task{
+		Title:       getInboundEmailTaskTitle(msg, u),
+		Description: getInboundEmailDescription(msg),
+		ProjectID:   project.ID,
+	}
+	err = task.Create(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := msg.Attachments
+	if !config.ServiceEnableTaskAttachments.GetBool() {
+		attachments = nil
+	}
+
+	for _, attachment := range attachments {
+		ta := &TaskAttachment{TaskID: task.ID}
+		err = ta.NewAttachment(s, io.NopCloser(bytes.NewReader(attachment.Content)), attachment.Filename, uint64(len(attachment.Content)), u)
+		if IsErrTaskAttachmentIsTooLarge(err) {
+			log.Infof("[Inbound Mail] Skipped attachment %s of task %d because it is too large", attachment.Filename, task.ID)
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		created = append(created, ta)
+	}
+
+	log.Debugf("[Inbound Mail] Created task %d in project %d from mail by user %d", task.ID, project.ID, u.ID)
+
+	return created, nil
+}
+
+var forwardPrefixRegex = regexp.MustCompile(`(?i)^\s*(fwd?|wg|tr)\s*:\s*`)
+
+func getInboundEmailTaskTitle(msg *inbound.Message, sender *user.User) string {
+	title := strings.TrimSpace(msg.Subject)
+	for forwardPrefixRegex.MatchString(title) {
+		title = forwardPrefixRegex.ReplaceAllString(title, "")
+	}
+	title = strings.Join(strings.Fields(title), " ")
+
+	if title == "" {
+		title = "Email from " + sender.GetName()
+	}
+
+	runes := []rune(title)
+	if len(runes) > 250 {
+		title = string(runes[:250])
+	}
+	return title
+}
+
+func getInboundEmailDescription(msg *inbound.Message) string {
+	if msg.HTML != "" {
+		return strings.TrimSpace(bluemonday.UGCPolicy().Sanitize(msg.HTML))
+	}
+
+	return convertTextToHTML(msg.Text)
+}
+
+// convertTextToHTML turns plain text into html paragraphs.
+func convertTextToHTML(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, "<p>"+strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>")+"</p>")
+	}
+	return strings.Join(paragraphs, "")
+}
@@ -0,0 +1,217 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/modules/inbound"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enableInboundEmail(t *testing.T) {
+	config.InboundEnabled.Set(true)
+	config.InboundAddress.Set("vikunja@example.com")
+	t.Cleanup(func() {
+		config.InboundEnabled.Set(false)
+		config.InboundAddress.Set("")
+	})
+}
+
+func TestProjectInboundEmail(t *testing.T) {
+	addressRegex := regexp.MustCompile(`^vikunja\+[a-z0-9]{32}@example\.com$`)
+
+	t.Run("read and reset", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pi := &ProjectInboundEmail{ProjectID: 1}
+		err := pi.ReadOne(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Regexp(t, addressRegex, pi.Address)
+		address := pi.Address
+
+		pi = &ProjectInboundEmail{ProjectID: 1}
+		err = pi.ReadOne(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, address, pi.Address)
+
+		pi = &ProjectInboundEmail{ProjectID: 1}
+		err = pi.Create(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Regexp(t, addressRegex, pi.Address)
+		assert.NotEqual(t, address, pi.Address)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pi := &ProjectInboundEmail{ProjectID: 1}
+		err := pi.ReadOne(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrInboundEmailDisabled(err))
+	})
+	t.Run("permissions", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pi := &ProjectInboundEmail{ProjectID: 1}
+		can, _, err := pi.CanRead(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.True(t, can)
+
+		can, _, err = pi.CanRead(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+
+		can, _, err = pi.CanRead(s, &LinkSharing{ID: 1, ProjectID: 1, Permission: PermissionAdmin})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func setInboundEmailToken(t *testing.T, projectID int64, token string) {
+	s := db.NewSession()
+	defer s.Close()
+	_, err := s.Where("id = ?", projectID).Cols("inbound_email_token").Update(&Project{InboundEmailToken: token})
+	require.NoError(t, err)
+}
+
+func parseInboundTestMessage(t *testing.T, from string) *inbound.Message {
+	msg, err := inbound.ParseMessage(strings.NewReader("From: " + from + "\r\n" +
+		"Subject: Fwd: Buy milk\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"b\"\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"Two bottles <please>\r\n" +
+		"\r\n" +
+		"Thanks\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"Content-Disposition: attachment; filename=\"list.txt\"\r\n" +
+		"\r\n" +
+		"milk\r\n" +
+		"--b--\r\n"))
+	require.NoError(t, err)
+	return msg
+}
+
+func TestHandleInboundEmail(t *testing.T) {
+	t.Run("creates a task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+		setInboundEmailToken(t, 1, "testtoken")
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "bounce@example.org",
+			Recipients: []string{"vikunja+TestToken@example.com"},
+		}, parseInboundTestMessage(t, "User 1 <USER1@example.com>"))
+		require.NoError(t, err)
+
+		s := db.NewSession()
+		defer s.Close()
+		task := &Task{}
+		exists, err := s.Where("project_id = ? AND title = ?", 1, "Buy milk").Get(task)
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.Equal(t, int64(1), task.CreatedByID)
+		assert.Equal(t, "<p>Two bottles &lt;please&gt;</p><p>Thanks</p>", task.Description)
+
+		db.AssertExists(t, "task_attachments", map[string]interface{}{
+			"task_id":       task.ID,
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("sender without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+		setInboundEmailToken(t, 1, "testtoken")
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user2@example.com",
+			Recipients: []string{"vikunja+testtoken@example.com"},
+		}, parseInboundTestMessage(t, "user2@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"title": "Buy milk",
+		})
+	})
+	t.Run("rolls back all recipients", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+		setInboundEmailToken(t, 1, "testtoken")
+		events.Fake()
+
+		s := db.NewSession()
+		defer s.Close()
+		filesBefore, err := s.Count(&files.File{})
+		require.NoError(t, err)
+
+		err = HandleInboundEmail(&inbound.Envelope{
+			From: "user1@example.com",
+			Recipients: []string{
+				"vikunja+testtoken@example.com",
+				"vikunja+doesnotexist@example.com",
+			},
+		}, parseInboundTestMessage(t, "user1@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"title": "Buy milk",
+		})
+		filesAfter, err := s.Count(&files.File{})
+		require.NoError(t, err)
+		assert.Equal(t, filesBefore, filesAfter)
+		events.AssertNotDispatched(t, &TaskCreatedEvent{})
+	})
+	t.Run("unknown project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user1@example.com",
+			Recipients: []string{"vikunja+doesnotexist@example.com"},
+		}, parseInboundTestMessage(t, "user1@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+	})
+}
+
+func TestGetInboundEmailTaskTitle(t *testing.T) {
+	u := &user.User{Username: "user1"}
+	assert.Equal(t, "Buy milk", getInboundEmailTaskTitle(&inbound.Message{Subject: "  Fwd: FW: Buy   milk "}, u))
+	assert.Equal(t, "Email from user1", getInboundEmailTaskTitle(&inbound.Message{Subject: "Fwd:"}, u))
+	assert.Len(t, []rune(getInboundEmailTaskTitle(&inbound.Message{Subject: strings.Repeat("ü", 300)}, u)), 250)
+}
@@ -372,6 +372,20 @@ func TestProject_Delete(t *testing.T) {
 		db.AssertMissing(t, "projects", map[string]interface{}{
 			"id": 35,
 		})
+		// The background is only deleted once the project is purged from the trash
+		db.AssertExists(t, "files", map[string]interface{}{
+			"id": 1,
+		}, false)
+
+		s = db.NewSession()
+		defer s.Close()
+		item := &TrashItem{}
+		_, err = s.Where("kind = ? AND entity_id = ?", TrashItemKindProject, 35).Get(item)
+		require.NoError(t, err)
+		err = item.Delete(s, &user.User{ID: 6})
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
 		db.AssertMissing(t, "files", map[string]interface{}{
 			"id": 1,
 		})
@@ -0,0 +1,440 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// ProjectViewSchedule is the schedule of all tasks in a gantt view.
+// Only tasks with a start and end date are scheduled. A task which precedes or blocks another task has to be finished
+// before the other task can start.
+type ProjectViewSchedule struct {
+	// The project the view belongs to.
+	ProjectID int64 `json:"-" param:"project"`
+	// The gantt view to schedule.
+	ProjectViewID int64 `json:"-" param:"view"`
+
+	// The task which is moved. If provided, its start and end date are set to the provided ones before all other
+	// tasks are scheduled.
+	TaskID int64 `json:"task_id,omitempty"`
+	// The new start date of the moved task.
+	StartDate time.Time `json:"start_date"`
+	// The new end date of the moved task. If not provided, the task keeps its duration.
+	EndDate time.Time `json:"end_date"`
+	// If true, all changes are saved. Otherwise they are only returned as a preview.
+	Apply bool `json:"apply"`
+
+	// The schedule of every task in the view. You can only read this property.
+	Tasks []*ScheduledTask `json:"tasks"`
+	// The ids of all tasks on the critical path, ordered by their earliest start. Moving any of them moves the end of the project.
+	CriticalPath []int64 `json:"critical_path"`
+	// All tasks which would need to be moved to start after all their predecessors are finished. Tasks are only ever moved
+	// to a later date, never to an earlier one. If apply is true, these changes were saved.
+	Changes []*ScheduleChange `json:"changes"`
+	// The start of the earliest task in the view.
+	ProjectStartDate time.Time `json:"project_start_date"`
+	// The end of the last task in the view after all tasks were scheduled.
+	ProjectEndDate time.Time `json:"project_end_date"`
+
+	web.Permissions `json:"-"`
+	web.CRUDable    `json:"-"`
+}
+
+// ScheduledTask holds the computed schedule of a single task.
+type ScheduledTask struct {
+	TaskID int64 `json:"task_id"`
+	// The earliest date the task can start, after all its predecessors are finished.
+	EarliestStart time.Time `json:"earliest_start"`
+	// The earliest date the task can be finished.
+	EarliestFinish time.Time `json:"earliest_finish"`
+	// The latest date the task can start without moving the end of the project.
+	LatestStart time.Time `json:"latest_start"`
+	// The latest date the task can be finished without moving the end of the project.
+	LatestFinish time.Time `json:"latest_finish"`
+	// How many seconds the task can be delayed without moving the end of the project.
+	Slack int64 `json:"slack"`
+	// Whether the task is on the critical path.
+	IsCritical bool `json:"is_critical"`
+}
+
+// ScheduleChange is a task which is moved by the schedule.
+type ScheduleChange struct {
+	TaskID       int64     `json:"task_id"`
+	OldStartDate time.Time `json:"old_start_date"`
+	OldEndDate   time.Time `json:"old_end_date"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+}
+
+type scheduleNode struct {
+	task         *Task
+	start        time.Time
+	duration     time.Duration
+	predecessors []int64
+	successors   []int64
+	scheduled    *ScheduledTask
+}
+
+// getTaskScheduleOrder returns the ids of all tasks so that every task comes after all of its predecessors.
+// Tasks which could be scheduled at the same time are ordered by their start date and id.
+func getTaskScheduleOrder(nodes map[int64]*scheduleNode) (order []int64, err error) {
+	ids := make([]int64, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if nodes[ids[i]].start.Equal(nodes[ids[j]].start) {
+			return ids[i] < ids[j]
+		}
+		return nodes[ids[i]].start.Before(nodes[ids[j]].start)
+	})
+
+	inDegree := make(map[int64]int, len(nodes))
+	queue := []int64{}
+	for _, id := range ids {
+		inDegree[id] = len(nodes[id].predecessors)
+		if inDegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	order = make([]int64, 0, len(nodes))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+
+		for _, successor := range nodes[id].successors {
+			inDegree[successor]--
+			if inDegree[successor] == 0 {
+				queue = append(queue, successor)
+			}
+		}
+	}
+
+	if len(order) < len(nodes) {
+		// All tasks which are left over are part of a cycle
+		for _, id := range ids {
+			if inDegree[id] > 0 {
+				return nil, ErrTaskRelationCycle{
+					TaskID:      id,
+					OtherTaskID: nodes[id].predecessors[0],
+					Kind:        RelationKindFollows,
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// computeSchedule calculates the earliest and latest dates of all tasks using the critical path method and
+// returns all tasks which need to be moved to start after their predecessors.
+func (pvs *ProjectViewSchedule) computeSchedule(tasks []*Task, relations []*TaskRelation) (changes []*ScheduleChange, err error) {
+	nodes := make(map[int64]*scheduleNode, len(tasks))
+	for _, t := range tasks {
+		nodes[t.ID] = &scheduleNode{
+			task:     t,
+			start:    t.StartDate,
+			duration: t.EndDate.Sub(t.StartDate),
+		}
+	}
+
+	for _, rel := range relations {
+		predecessor, has := nodes[rel.TaskID]
+		if !has {
+			continue
+		}
+		successor, has := nodes[rel.OtherTaskID]
+		if !has {
+			continue
+		}
+		predecessor.successors = append(predecessor.successors, rel.OtherTaskID)
+		successor.predecessors = append(successor.predecessors, rel.TaskID)
+	}
+
+	order, err := getTaskScheduleOrder(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	pvs.Tasks = make([]*ScheduledTask, 0, len(order))
+	pvs.CriticalPath = []int64{}
+	changes = []*ScheduleChange{}
+
+	// Forward pass: A task can start at its own start date, but not before all its predecessors are finished.
+	for _, id := range order {
+		node := nodes[id]
+		earliestStart := node.start
+		for _, predecessor := range node.predecessors {
+			finish := nodes[predecessor].scheduled.EarliestFinish
+			if finish.After(earliestStart) {
+				earliestStart = finish
+			}
+		}
+
+		node.scheduled = &ScheduledTask{
+			TaskID:         id,
+			EarliestStart:  earliestStart,
+			EarliestFinish: earliestStart.Add(node.duration),
+		}
+		pvs.Tasks = append(pvs.Tasks, node.scheduled)
+
+		if pvs.ProjectStartDate.IsZero() || node.start.Before(pvs.ProjectStartDate) {
+			pvs.ProjectStartDate = node.start
+		}
+		if node.scheduled.EarliestFinish.After(pvs.ProjectEndDate) {
+			pvs.ProjectEndDate = node.scheduled.EarliestFinish
+		}
+
+		if earliestStart.After(node.start) {
+			changes = append(changes, &ScheduleChange{
+				TaskID:       id,
+				OldStartDate: node.task.StartDate,
+				OldEndDate:   node.task.EndDate,
+				StartDate:    earliestStart,
+				EndDate:      earliestStart.Add(node.duration),
+			})
+		}
+	}
+
+	// Backward pass: A task has to be finished when the first of its successors has to start at the latest.
+	for i := len(order) - 1; i >= 0; i-- {
+		node := nodes[order[i]]
+		latestFinish := pvs.ProjectEndDate
+		for _, successor := range node.successors {
+			start := nodes[successor].scheduled.LatestStart
+			if start.Before(latestFinish) {
+				latestFinish = start
+			}
+		}
+
+		node.scheduled.LatestFinish = latestFinish
+		node.scheduled.LatestStart = latestFinish.Add(-node.duration)
+		node.scheduled.Slack = int64(node.scheduled.LatestStart.Sub(node.scheduled.EarliestStart).Seconds())
+		node.scheduled.IsCritical = node.scheduled.Slack <= 0
+	}
+
+	for _, scheduled := range pvs.Tasks {
+		if scheduled.IsCritical {
+			pvs.CriticalPath = append(pvs.CriticalPath, scheduled.TaskID)
+		}
+	}
+	sort.SliceStable(pvs.CriticalPath, func(i, j int) bool {
+		return nodes[pvs.CriticalPath[i]].scheduled.EarliestStart.Before(nodes[pvs.CriticalPath[j]].scheduled.EarliestStart)
+	})
+
+	return changes, nil
+}
+
+func (pvs *ProjectViewSchedule) getTasksAndRelations(s *xorm.Session, a web.Auth) (tasks []*Task, relations []*TaskRelation, err error) {
+	view, err := GetProjectViewByIDAndProject(s, pvs.ProjectViewID, pvs.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if view.ViewKind != ProjectViewKindGantt {
+		return nil, nil, &ErrProjectViewIsNotGantt{ProjectViewID: view.ID}
+	}
+
+	tc := &TaskCollection{
+		ProjectID:     pvs.ProjectID,
+		ProjectViewID: pvs.ProjectViewID,
+	}
+	result, _, _, err := tc.ReadAll(s, a, "", 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allTasks, _ := result.([]*Task)
+	tasks = make([]*Task, 0, len(allTasks))
+	taskIDs := make([]int64, 0, len(allTasks))
+	for _, t := range allTasks {
+		if t.StartDate.IsZero() || t.EndDate.IsZero() {
+			continue
+		}
+		tasks = append(tasks, t)
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	relations = []*TaskRelation{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	// The inverse relations follows and blocked are not needed since every relation exists in both directions.
+	err = s.
+		Where(builder.And(
+			builder.In("task_id", taskIDs),
+			builder.In("other_task_id", taskIDs),
+			builder.In("relation_kind", RelationKindPreceeds, RelationKindBlocking),
+		)).
+		OrderBy("id asc").
+		Find(&relations)
+	return
+}
+
+// ReadOne returns the schedule of a gantt view
+// @Summary Get the schedule of a gantt view
+// @Description Returns the earliest and latest start and end dates of all tasks with a start and end date in a gantt view, the critical path and all tasks which would need to be moved to start after the tasks preceding or blocking them are done.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param view path int true "Project View ID"
+// @Success 200 {object} models.ProjectViewSchedule "The schedule of the view"
+// @Failure 400 {object} web.HTTPError "The view is not a gantt view."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The view does not exist."
+// @Failure 409 {object} web.HTTPError "The tasks in the view depend on each other in a cycle."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/views/{view}/schedule [get]
+func (pvs *ProjectViewSchedule) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	tasks, relations, err := pvs.getTasksAndRelations(s, a)
+	if err != nil {
+		return err
+	}
+
+	pvs.Changes, err = pvs.computeSchedule(tasks, relations)
+	return
+}
+
+// Update moves a task in a gantt view and all tasks depending on it
+// @Summary Move a task and all tasks depending on it
+// @Description Moves a task to the provided dates and shifts all tasks which it precedes or blocks so they start after it is done. Returns a preview of all changes together with the new schedule, set apply to true to save them. Without a task, only tasks which currently start before their predecessors are done are moved.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param view path int true "Project View ID"
+// @Param schedule body models.ProjectViewSchedule true "The task to move and its new dates."
+// @Success 200 {object} models.ProjectViewSchedule "The new schedule of the view"
+// @Failure 400 {object} web.HTTPError "Invalid schedule object provided or the view is not a gantt view."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project or cannot edit one of the moved tasks."
+// @Failure 404 {object} web.HTTPError "The view or task does not exist."
+// @Failure 409 {object} web.HTTPError "The tasks in the view depend on each other in a cycle."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/views/{view}/schedule [post]
+func (pvs *ProjectViewSchedule) Update(s *xorm.Session, a web.Auth) (err error) {
+	tasks, relations, err := pvs.getTasksAndRelations(s, a)
+	if err != nil {
+		return err
+	}
+
+	var moved *ScheduleChange
+	if pvs.TaskID != 0 {
+		moved, err = pvs.moveTask(tasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	pvs.Changes, err = pvs.computeSchedule(tasks, relations)
+	if err != nil {
+		return err
+	}
+
+	if moved != nil {
+		changes := []*ScheduleChange{moved}
+		for _, change := range pvs.Changes {
+			// The moved task itself is moved further if it now starts before one of its predecessors is done
+			if change.TaskID == moved.TaskID {
+				moved.StartDate = change.StartDate
+				moved.EndDate = change.EndDate
+				continue
+			}
+			changes = append(changes, change)
+		}
+		pvs.Changes = changes
+	}
+
+	if !pvs.Apply {
+		return nil
+	}
+
+	return applyScheduleChanges(s, a, pvs.Changes)
+}
+
+// moveTask sets the new dates of the moved task. The task is changed in place so that the schedule is computed with
+// the new dates.
+func (pvs *ProjectViewSchedule) moveTask(tasks []*Task) (change *ScheduleChange, err error) {
+	if pvs.StartDate.IsZero() {
+		return nil, ErrInvalidData{Message: "The moved task needs a start date."}
+	}
+
+	for _, t := range tasks {
+		if t.ID != pvs.TaskID {
+			continue
+		}
+
+		change = &ScheduleChange{
+			TaskID:       t.ID,
+			OldStartDate: t.StartDate,
+			OldEndDate:   t.EndDate,
+			StartDate:    pvs.StartDate,
+			EndDate:      pvs.EndDate,
+		}
+		if change.EndDate.IsZero() {
+			change.EndDate = pvs.StartDate.Add(t.EndDate.Sub(t.StartDate))
+		}
+		if change.EndDate.Before(change.StartDate) {
+			return nil, ErrInvalidData{Message: "The end date of the moved task must be after its start date."}
+		}
+
+		t.StartDate = change.StartDate
+		t.EndDate = change.EndDate
+		return change, nil
+	}
+
+	return nil, ErrTaskDoesNotExist{ID: pvs.TaskID}
+}
+
+func applyScheduleChanges(s *xorm.Session, a web.Auth, changes []*ScheduleChange) (err error) {
+	for _, change := range changes {
+		t := &Task{ID: change.TaskID}
+		can, err := t.CanUpdate(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return ErrGenericForbidden{}
+		}
+
+		err = t.ReadOne(s, a)
+		if err != nil {
+			return err
+		}
+
+		t.StartDate = change.StartDate
+		t.EndDate = change.EndDate
+		err = t.Update(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
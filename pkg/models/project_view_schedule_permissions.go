@@ -0,0 +1,35 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if a user can read the schedule of a view
+func (pvs *ProjectViewSchedule) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	pv := &ProjectView{ID: pvs.ProjectViewID, ProjectID: pvs.ProjectID}
+	return pv.CanRead(s, a)
+}
+
+// CanUpdate checks if a user can move tasks in the schedule of a view.
+// Previewing changes only needs read access, the permission to change each moved task is checked when the changes are saved.
+func (pvs *ProjectViewSchedule) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	can, _, err := pvs.CanRead(s, a)
+	return can, err
+}
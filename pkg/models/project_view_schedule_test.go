@@ -0,0 +1,283 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func scheduleDate(day int) time.Time {
+	return time.Date(2018, 12, day, 0, 0, 0, 0, config.GetTimeZone())
+}
+
+// setupScheduleTasks creates the chain 1 -> 3 -> 4 in project 1 with task 5 preceding task 4 as well.
+func setupScheduleTasks(t *testing.T, s *xorm.Session) {
+	dates := map[int64][2]int{
+		1: {1, 3},
+		3: {3, 5},
+		4: {5, 14},
+		5: {2, 4},
+	}
+	for id, d := range dates {
+		_, err := s.ID(id).
+			Cols("start_date", "end_date").
+			Update(&Task{StartDate: scheduleDate(d[0]), EndDate: scheduleDate(d[1])})
+		require.NoError(t, err)
+	}
+
+	relations := []*TaskRelation{
+		{TaskID: 1, OtherTaskID: 3, RelationKind: RelationKindPreceeds},
+		{TaskID: 3, OtherTaskID: 4, RelationKind: RelationKindBlocking},
+		{TaskID: 4, OtherTaskID: 5, RelationKind: RelationKindFollows},
+	}
+	for _, rel := range relations {
+		err := rel.Create(s, &user.User{ID: 1})
+		require.NoError(t, err)
+	}
+}
+
+func getScheduledTask(pvs *ProjectViewSchedule, taskID int64) *ScheduledTask {
+	for _, st := range pvs.Tasks {
+		if st.TaskID == taskID {
+			return st
+		}
+	}
+	return nil
+}
+
+func TestProjectViewSchedule_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+
+		pvs := &ProjectViewSchedule{ProjectID: 1, ProjectViewID: 2}
+		can, _, err := pvs.CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = pvs.ReadOne(s, u)
+		require.NoError(t, err)
+
+		assert.Empty(t, pvs.Changes)
+		assert.Equal(t, []int64{1, 3, 4}, pvs.CriticalPath)
+		assert.Equal(t, scheduleDate(1).Unix(), pvs.ProjectStartDate.Unix())
+		assert.Equal(t, scheduleDate(14).Unix(), pvs.ProjectEndDate.Unix())
+
+		// Task 9 is the only other task with a start and end date
+		assert.Len(t, pvs.Tasks, 5)
+
+		task5 := getScheduledTask(pvs, 5)
+		require.NotNil(t, task5)
+		assert.Equal(t, int64(24*60*60), task5.Slack)
+		assert.False(t, task5.IsCritical)
+		assert.Equal(t, scheduleDate(4).Unix(), task5.EarliestFinish.Unix())
+		assert.Equal(t, scheduleDate(5).Unix(), task5.LatestFinish.Unix())
+
+		task3 := getScheduledTask(pvs, 3)
+		require.NotNil(t, task3)
+		assert.Equal(t, int64(0), task3.Slack)
+		assert.True(t, task3.IsCritical)
+	})
+	t.Run("task starts before its predecessor is done", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+		_, err := s.ID(3).
+			Cols("start_date", "end_date").
+			Update(&Task{StartDate: scheduleDate(2), EndDate: scheduleDate(4)})
+		require.NoError(t, err)
+
+		pvs := &ProjectViewSchedule{ProjectID: 1, ProjectViewID: 2}
+		err = pvs.ReadOne(s, u)
+		require.NoError(t, err)
+
+		require.Len(t, pvs.Changes, 1)
+		assert.Equal(t, int64(3), pvs.Changes[0].TaskID)
+		assert.Equal(t, scheduleDate(2).Unix(), pvs.Changes[0].OldStartDate.Unix())
+		assert.Equal(t, scheduleDate(3).Unix(), pvs.Changes[0].StartDate.Unix())
+		assert.Equal(t, scheduleDate(5).Unix(), pvs.Changes[0].EndDate.Unix())
+	})
+	t.Run("not a gantt view", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pvs := &ProjectViewSchedule{ProjectID: 1, ProjectViewID: 1}
+		err := pvs.ReadOne(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrProjectViewIsNotGantt(err))
+	})
+	t.Run("cycle", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+		// Relations which were created before cycles were checked
+		_, err := s.Insert(&[]*TaskRelation{
+			{TaskID: 4, OtherTaskID: 1, RelationKind: RelationKindPreceeds, CreatedByID: 1},
+			{TaskID: 1, OtherTaskID: 4, RelationKind: RelationKindFollows, CreatedByID: 1},
+		})
+		require.NoError(t, err)
+
+		pvs := &ProjectViewSchedule{ProjectID: 1, ProjectViewID: 2}
+		err = pvs.ReadOne(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskRelationCycle(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pvs := &ProjectViewSchedule{ProjectID: 1, ProjectViewID: 2}
+		can, _, err := pvs.CanRead(s, &user.User{ID: 13})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestProjectViewSchedule_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("preview", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+
+		pvs := &ProjectViewSchedule{
+			ProjectID:     1,
+			ProjectViewID: 2,
+			TaskID:        1,
+			StartDate:     scheduleDate(2),
+		}
+		err := pvs.Update(s, u)
+		require.NoError(t, err)
+
+		require.Len(t, pvs.Changes, 3)
+		assert.Equal(t, int64(1), pvs.Changes[0].TaskID)
+		assert.Equal(t, scheduleDate(1).Unix(), pvs.Changes[0].OldStartDate.Unix())
+		assert.Equal(t, scheduleDate(4).Unix(), pvs.Changes[0].EndDate.Unix())
+		assert.Equal(t, int64(3), pvs.Changes[1].TaskID)
+		assert.Equal(t, scheduleDate(4).Unix(), pvs.Changes[1].StartDate.Unix())
+		assert.Equal(t, int64(4), pvs.Changes[2].TaskID)
+		assert.Equal(t, scheduleDate(6).Unix(), pvs.Changes[2].StartDate.Unix())
+		assert.Equal(t, scheduleDate(15).Unix(), pvs.Changes[2].EndDate.Unix())
+		assert.Equal(t, scheduleDate(15).Unix(), pvs.ProjectEndDate.Unix())
+
+		task, err := GetTaskByIDSimple(s, 3)
+		require.NoError(t, err)
+		assert.Equal(t, scheduleDate(3).Unix(), task.StartDate.Unix())
+	})
+	t.Run("apply", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+
+		pvs := &ProjectViewSchedule{
+			ProjectID:     1,
+			ProjectViewID: 2,
+			TaskID:        1,
+			StartDate:     scheduleDate(2),
+			EndDate:       scheduleDate(5),
+			Apply:         true,
+		}
+		can, err := pvs.CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = pvs.Update(s, u)
+		require.NoError(t, err)
+		require.Len(t, pvs.Changes, 3)
+
+		for id, dates := range map[int64][2]int{1: {2, 5}, 3: {5, 7}, 4: {7, 16}, 5: {2, 4}} {
+			task, err := GetTaskByIDSimple(s, id)
+			require.NoError(t, err)
+			assert.Equal(t, scheduleDate(dates[0]).Unix(), task.StartDate.Unix(), "start date of task %d", id)
+			assert.Equal(t, scheduleDate(dates[1]).Unix(), task.EndDate.Unix(), "end date of task %d", id)
+		}
+	})
+	t.Run("moved task starts before its predecessor is done", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+
+		pvs := &ProjectViewSchedule{
+			ProjectID:     1,
+			ProjectViewID: 2,
+			TaskID:        3,
+			StartDate:     scheduleDate(2),
+		}
+		err := pvs.Update(s, u)
+		require.NoError(t, err)
+
+		require.Len(t, pvs.Changes, 1)
+		assert.Equal(t, int64(3), pvs.Changes[0].TaskID)
+		assert.Equal(t, scheduleDate(3).Unix(), pvs.Changes[0].StartDate.Unix())
+	})
+	t.Run("task without dates", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pvs := &ProjectViewSchedule{
+			ProjectID:     1,
+			ProjectViewID: 2,
+			TaskID:        2,
+			StartDate:     scheduleDate(2),
+		}
+		err := pvs.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+	})
+	t.Run("without start date", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setupScheduleTasks(t, s)
+
+		pvs := &ProjectViewSchedule{
+			ProjectID:     1,
+			ProjectViewID: 2,
+			TaskID:        1,
+		}
+		err := pvs.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidData(err))
+	})
+}
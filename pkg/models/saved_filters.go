@@ -22,6 +22,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/web"
@@ -140,7 +141,14 @@ func (sf *SavedFilter) Create(s *xorm.Session, auth web.Auth) (err error) {
 	}
 
 	err = CreateDefaultViewsForProject(s, &Project{ID: getProjectIDFromSavedFilterID(sf.ID)}, auth, true, false)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return events.DispatchOnCommit(s, &SavedFilterCreatedEvent{
+		SavedFilter: sf,
+		Doer:        auth,
+	})
 }
 
 func GetSavedFilterSimpleByID(s *xorm.Session, id int64) (sf *SavedFilter, err error) {
@@ -189,7 +197,7 @@ func (sf *SavedFilter) ReadOne(s *xorm.Session, _ web.Auth) error {
 // @Failure 404 {object} web.HTTPError "The saved filter does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /filters/{id} [post]
-func (sf *SavedFilter) Update(s *xorm.Session, _ web.Auth) error {
+func (sf *SavedFilter) Update(s *xorm.Session, a web.Auth) error {
 	origFilter, err := GetSavedFilterSimpleByID(s, sf.ID)
 	if err != nil {
 		return err
@@ -217,6 +225,14 @@ func (sf *SavedFilter) Update(s *xorm.Session, _ web.Auth) error {
 		return err
 	}
 
+	err = events.DispatchOnCommit(s, &SavedFilterUpdatedEvent{
+		SavedFilter: sf,
+		Doer:        a,
+	})
+	if err != nil {
+		return err
+	}
+
 	// Add all tasks which are not already in a bucket to the default bucket
 	kanbanFilterViews := []*ProjectView{}
 	err = s.Where(
@@ -307,11 +323,18 @@ func (sf *SavedFilter) Update(s *xorm.Session, _ web.Auth) error {
 // @Failure 404 {object} web.HTTPError "The saved filter does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /filters/{id} [delete]
-func (sf *SavedFilter) Delete(s *xorm.Session, _ web.Auth) error {
+func (sf *SavedFilter) Delete(s *xorm.Session, a web.Auth) error {
 	_, err := s.
 		Where("id = ?", sf.ID).
 		Delete(sf)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return events.DispatchOnCommit(s, &SavedFilterDeletedEvent{
+		SavedFilter: sf,
+		Doer:        a,
+	})
 }
 
 func addTaskToFilter(s *xorm.Session, filter *SavedFilter, view *ProjectView, fallbackTimezone string, task *Task) (taskBucket *TaskBucket, taskPosition *TaskPosition, err error) {
@@ -558,6 +581,19 @@ func upsertRelatedTaskProperties(s *xorm.Session, logPrefix string, newTaskBucke
 		}
 	}
 
+	if bleveEnabled() && (len(newTaskPositions) > 0 || len(taskIDsToRemove) > 0) {
+		taskIDs := []int64{}
+		for _, position := range newTaskPositions {
+			taskIDs = append(taskIDs, position.TaskID)
+		}
+		taskIDs = append(taskIDs, taskIDsToRemove...)
+		err = reindexTasksByIDsInBleve(s, taskIDs)
+		if err != nil {
+			log.Errorf("%sError reindexing tasks into bleve: %s", logPrefix, err)
+			return
+		}
+	}
+
 	if config.TypesenseEnabled.GetBool() && (len(newTaskPositions) > 0 || len(taskIDsToRemove) > 0) {
 		taskIDs := []int64{}
 		for _, position := range newTaskPositions {
@@ -55,9 +55,13 @@ func SetupTests() {
 		"projects",
 		"task_assignees",
 		"task_attachments",
+		"task_attachment_texts",
 		"task_comments",
+		"task_comment_revisions",
+		"task_references",
 		"task_relations",
 		"task_reminders",
+		"task_reminder_snoozes",
 		"tasks",
 		"team_projects",
 		"team_members",
@@ -74,6 +78,13 @@ func SetupTests() {
 		"project_views",
 		"task_positions",
 		"task_buckets",
+		"task_occurrences",
+		"task_activities",
+		"trash_items",
+		"task_time_entries",
+		"project_custom_fields",
+		"task_custom_field_values",
+		"templates",
 	)
 	if err != nil {
 		log.Fatal(err)
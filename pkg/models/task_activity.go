@@ -0,0 +1,275 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskActivityKind is the kind of a task activity
+type TaskActivityKind string
+
+// All kinds of task activities
+const (
+	TaskActivityKindCreated        TaskActivityKind = `created`
+	TaskActivityKindUpdated        TaskActivityKind = `updated`
+	TaskActivityKindCommented      TaskActivityKind = `commented`
+	TaskActivityKindCommentUpdated TaskActivityKind = `comment_updated`
+	TaskActivityKindCommentDeleted TaskActivityKind = `comment_deleted`
+	TaskActivityKindRestored       TaskActivityKind = `restored`
+)
+
+// TaskActivity is a single entry in the history of a task.
+// Activities are recorded whenever a task is created, changed or commented on and cannot be changed afterwards.
+type TaskActivity struct {
+	// The unique, numeric id of this activity.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+
+	// What happened to the task.
+	Kind TaskActivityKind `xorm:"varchar(50) not null" json:"kind"`
+	// All fields which were changed with their old and new values. Only set for updates.
+	Changes []*TaskActivityChange `xorm:"json null" json:"changes"`
+	// The comment which was created, updated or deleted. Only set for comment activities.
+	CommentID int64 `xorm:"bigint null" json:"comment_id,omitempty"`
+
+	// The user who did this.
+	Doer   *user.User `xorm:"-" json:"doer"`
+	DoerID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this activity happened.
+	Created time.Time `xorm:"created not null INDEX" json:"created"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task activities
+func (*TaskActivity) TableName() string {
+	return "task_activities"
+}
+
+// TaskActivityChange is a single field which was changed.
+// Fields holding a list like assignees, labels, relations and attachments have one change per added or removed item,
+// the old value is empty when an item was added and the new value is empty when it was removed.
+type TaskActivityChange struct {
+	// The name of the changed field, like the property of the task.
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+func getActivityDateValue(date time.Time) interface{} {
+	if date.IsZero() {
+		return nil
+	}
+	return date
+}
+
+func getAssigneeActivityValue(u *user.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       u.ID,
+		"username": u.Username,
+	}
+}
+
+func getLabelActivityValue(l *Label) map[string]interface{} {
+	return map[string]interface{}{
+		"id":    l.ID,
+		"title": l.Title,
+	}
+}
+
+func getRelationActivityValue(otherTaskID int64, kind RelationKind) map[string]interface{} {
+	return map[string]interface{}{
+		"task_id":       otherTaskID,
+		"relation_kind": kind,
+	}
+}
+
+func getAttachmentActivityValue(ta *TaskAttachment) map[string]interface{} {
+	value := map[string]interface{}{
+		"id": ta.ID,
+	}
+	if ta.File != nil {
+		value["file_name"] = ta.File.Name
+	}
+	return value
+}
+
+// getTaskFieldChanges returns all fields which differ between the old and the new version of a task.
+func getTaskFieldChanges(oldTask, newTask *Task) (changes []*TaskActivityChange) {
+	changes = []*TaskActivityChange{}
+	addChange := func(field string, oldValue, newValue interface{}) {
+		changes = append(changes, &TaskActivityChange{
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	// Dates are only compared to the second since that's what the database stores
+	dateChanged := func(oldDate, newDate time.Time) bool {
+		return oldDate.IsZero() != newDate.IsZero() || oldDate.Unix() != newDate.Unix()
+	}
+
+	if oldTask.Title != newTask.Title {
+		addChange("title", oldTask.Title, newTask.Title)
+	}
+	if oldTask.Description != newTask.Description {
+		addChange("description", oldTask.Description, newTask.Description)
+	}
+	if oldTask.Done != newTask.Done {
+		addChange("done", oldTask.Done, newTask.Done)
+	}
+	if dateChanged(oldTask.DueDate, newTask.DueDate) {
+		addChange("due_date", getActivityDateValue(oldTask.DueDate), getActivityDateValue(newTask.DueDate))
+	}
+	if dateChanged(oldTask.StartDate, newTask.StartDate) {
+		addChange("start_date", getActivityDateValue(oldTask.StartDate), getActivityDateValue(newTask.StartDate))
+	}
+	if dateChanged(oldTask.EndDate, newTask.EndDate) {
+		addChange("end_date", getActivityDateValue(oldTask.EndDate), getActivityDateValue(newTask.EndDate))
+	}
+	if oldTask.Priority != newTask.Priority {
+		addChange("priority", oldTask.Priority, newTask.Priority)
+	}
+	if oldTask.PercentDone != newTask.PercentDone {
+		addChange("percent_done", oldTask.PercentDone, newTask.PercentDone)
+	}
+	if oldTask.HexColor != newTask.HexColor {
+		addChange("hex_color", oldTask.HexColor, newTask.HexColor)
+	}
+	if oldTask.RepeatAfter != newTask.RepeatAfter {
+		addChange("repeat_after", oldTask.RepeatAfter, newTask.RepeatAfter)
+	}
+	if oldTask.RepeatMode != newTask.RepeatMode {
+		addChange("repeat_mode", oldTask.RepeatMode, newTask.RepeatMode)
+	}
+	if oldTask.RepeatRule != newTask.RepeatRule {
+		addChange("repeat_rule", oldTask.RepeatRule, newTask.RepeatRule)
+	}
+	if oldTask.EstimatedTime != newTask.EstimatedTime {
+		addChange("estimated_time", oldTask.EstimatedTime, newTask.EstimatedTime)
+	}
+	if oldTask.ProjectID != newTask.ProjectID {
+		addChange("project_id", oldTask.ProjectID, newTask.ProjectID)
+	}
+
+	return
+}
+
+// recordTaskActivity saves a new activity for a task. Updates without any changes are not saved.
+func recordTaskActivity(s *xorm.Session, a web.Auth, activity *TaskActivity) (err error) {
+	if activity.Kind == TaskActivityKindUpdated && len(activity.Changes) == 0 {
+		return nil
+	}
+
+	activity.ID = 0
+	if share, is := a.(*LinkSharing); is {
+		activity.DoerID = share.getUserID()
+	} else if a != nil {
+		activity.DoerID = a.GetID()
+	}
+
+	_, err = s.Insert(activity)
+	return
+}
+
+// recordTaskChanges saves all changes of a task as a new update activity.
+func recordTaskChanges(s *xorm.Session, a web.Auth, taskID int64, changes ...*TaskActivityChange) error {
+	return recordTaskActivity(s, a, &TaskActivity{
+		TaskID:  taskID,
+		Kind:    TaskActivityKindUpdated,
+		Changes: changes,
+	})
+}
+
+// ReadAll returns the activity history of a task
+// @Summary Get the activity history of a task
+// @Description Returns everything which happened to a task, the most recent first. This includes its creation, all changes with the old and new values of the changed fields and comments. The user doing this need to have at least read access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.TaskActivity "The activities of the task"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/activity [get]
+func (ta *TaskActivity) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+
+	canRead, _, err := ta.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	activities, err := getActivitiesForTasks(s, []int64{ta.TaskID}, page, perPage)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.
+		Where("task_id = ?", ta.TaskID).
+		Count(&TaskActivity{})
+	return activities, len(activities), numberOfTotalItems, err
+}
+
+func getActivitiesForTasks(s *xorm.Session, taskIDs []int64, page int, perPage int) (activities []*TaskActivity, err error) {
+	activities = []*TaskActivity{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	query := s.
+		Where(builder.In("task_id", taskIDs)).
+		OrderBy("created desc, id desc")
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&activities)
+	if err != nil {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(activities))
+	for _, activity := range activities {
+		userIDs = append(userIDs, activity.DoerID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, activity := range activities {
+		activity.Doer = users[activity.DoerID]
+	}
+
+	return
+}
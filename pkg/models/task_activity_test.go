@@ -0,0 +1,223 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getActivitiesForTest(t *testing.T, taskID int64) []*TaskActivity {
+	s := db.NewSession()
+	defer s.Close()
+
+	activities, err := getActivitiesForTasks(s, []int64{taskID}, -1, 0)
+	require.NoError(t, err)
+	return activities
+}
+
+func TestTaskActivity_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		ta := &TaskActivity{TaskID: 1}
+		result, resultCount, total, err := ta.ReadAll(s, u, "", 0, 50)
+		require.NoError(t, err)
+		assert.Equal(t, 2, resultCount)
+		assert.Equal(t, int64(2), total)
+
+		activities := result.([]*TaskActivity)
+		assert.Equal(t, int64(2), activities[0].ID)
+		assert.Equal(t, TaskActivityKindUpdated, activities[0].Kind)
+		require.Len(t, activities[0].Changes, 1)
+		assert.Equal(t, "title", activities[0].Changes[0].Field)
+		assert.Equal(t, "task #1", activities[0].Changes[0].NewValue)
+		assert.Equal(t, "user1", activities[0].Doer.Username)
+		assert.Equal(t, int64(1), activities[1].ID)
+		assert.Equal(t, TaskActivityKindCreated, activities[1].Kind)
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 2}
+		ta := &TaskActivity{TaskID: 1}
+		_, _, _, err := ta.ReadAll(s, u, "", 0, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskActivity_Recording(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("task creation", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			Title:     "Lorem",
+			ProjectID: 1,
+		}
+		err := task.Create(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		activities := getActivitiesForTest(t, task.ID)
+		require.Len(t, activities, 1)
+		assert.Equal(t, TaskActivityKindCreated, activities[0].Kind)
+		assert.Equal(t, int64(1), activities[0].Doer.ID)
+	})
+	t.Run("field changes", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:          1,
+			Title:       "task #1 renamed",
+			Description: "Lorem Ipsum",
+			Priority:    3,
+			ProjectID:   1,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		activities := getActivitiesForTest(t, 1)
+		require.Len(t, activities, 3)
+		assert.Equal(t, TaskActivityKindUpdated, activities[0].Kind)
+		require.Len(t, activities[0].Changes, 2)
+		assert.Equal(t, "title", activities[0].Changes[0].Field)
+		assert.Equal(t, "task #1", activities[0].Changes[0].OldValue)
+		assert.Equal(t, "task #1 renamed", activities[0].Changes[0].NewValue)
+		assert.Equal(t, "priority", activities[0].Changes[1].Field)
+		assert.InDelta(t, 0, activities[0].Changes[1].OldValue, 0)
+		assert.InDelta(t, 3, activities[0].Changes[1].NewValue, 0)
+	})
+	t.Run("no changes", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:          1,
+			Title:       "task #1",
+			Description: "Lorem Ipsum",
+			ProjectID:   1,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		activities := getActivitiesForTest(t, 1)
+		assert.Len(t, activities, 2)
+	})
+	t.Run("assignee", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ta := &TaskAssginee{TaskID: 1, UserID: 1}
+		err := ta.Create(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		activities := getActivitiesForTest(t, 1)
+		require.Len(t, activities[0].Changes, 1)
+		assert.Equal(t, "assignees", activities[0].Changes[0].Field)
+		assert.Nil(t, activities[0].Changes[0].OldValue)
+		assert.Equal(t, "user1", activities[0].Changes[0].NewValue.(map[string]interface{})["username"])
+	})
+	t.Run("relation", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := &TaskRelation{
+			TaskID:       1,
+			OtherTaskID:  2,
+			RelationKind: RelationKindSubtask,
+		}
+		err := rel.Create(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		activities := getActivitiesForTest(t, 1)
+		require.Len(t, activities[0].Changes, 1)
+		assert.Equal(t, "relations", activities[0].Changes[0].Field)
+		assert.Equal(t, string(RelationKindSubtask), activities[0].Changes[0].NewValue.(map[string]interface{})["relation_kind"])
+
+		activities = getActivitiesForTest(t, 2)
+		require.Len(t, activities, 1)
+		require.Len(t, activities[0].Changes, 1)
+		assert.Equal(t, string(RelationKindParenttask), activities[0].Changes[0].NewValue.(map[string]interface{})["relation_kind"])
+	})
+	t.Run("comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			Comment: "Lorem Ipsum",
+			TaskID:  1,
+		}
+		err := tc.Create(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		db.AssertExists(t, "task_activities", map[string]interface{}{
+			"task_id":    1,
+			"kind":       TaskActivityKindCommented,
+			"comment_id": tc.ID,
+			"doer_id":    1,
+		}, false)
+	})
+	t.Run("comment edit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			ID:      1,
+			Comment: "Edited",
+			TaskID:  1,
+		}
+		err := tc.Update(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		var edited *TaskActivity
+		for _, activity := range getActivitiesForTest(t, 1) {
+			if activity.Kind == TaskActivityKindCommentUpdated {
+				edited = activity
+			}
+		}
+		require.NotNil(t, edited)
+		assert.Equal(t, int64(1), edited.CommentID)
+		assert.Empty(t, edited.Changes)
+	})
+}
@@ -60,13 +60,14 @@ func getRawTaskAssigneesForTasks(s *xorm.Session, taskIDs []int64) (taskAssignee
 	return
 }
 
-// Create or update a bunch of task assignees
-func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer web.Auth) (err error) {
+// Create or update a bunch of task assignees.
+// Returns the added and removed assignees as activity changes.
+func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer web.Auth) (changes []*TaskActivityChange, err error) {
 
 	// Load the current assignees
 	currentAssignees, err := getRawTaskAssigneesForTasks(s, []int64{t.ID})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	t.Assignees = make([]*user.User, 0, len(currentAssignees))
@@ -78,13 +79,19 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 	if len(assignees) == 0 && len(t.Assignees) > 0 {
 		_, err = s.Where("task_id = ?", t.ID).
 			Delete(&TaskAssginee{})
+		for _, oldAssignee := range t.Assignees {
+			changes = append(changes, &TaskActivityChange{
+				Field:    "assignees",
+				OldValue: getAssigneeActivityValue(oldAssignee),
+			})
+		}
 		t.setTaskAssignees(assignees)
-		return err
+		return changes, err
 	}
 
 	// If we didn't change anything (from 0 to zero) don't do anything.
 	if len(assignees) == 0 && len(t.Assignees) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Make a hashmap of the new assignees for easier comparison
@@ -106,6 +113,10 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 		// Put all assignees which are only on the old project to the trash
 		if !found {
 			assigneesToDelete = append(assigneesToDelete, oldAssignee.ID)
+			changes = append(changes, &TaskActivityChange{
+				Field:    "assignees",
+				OldValue: getAssigneeActivityValue(oldAssignee),
+			})
 		}
 
 		oldAssignees[oldAssignee.ID] = oldAssignee
@@ -117,7 +128,7 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 			And("task_id = ?", t.ID).
 			Delete(&TaskAssginee{})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -136,10 +147,14 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 		}
 
 		// Add the new assignee
-		err = t.addNewAssigneeByID(s, u.ID, project, doer)
+		newAssignee, err := t.addNewAssigneeByID(s, u.ID, project, doer)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		changes = append(changes, &TaskActivityChange{
+			Field:    "assignees",
+			NewValue: getAssigneeActivityValue(newAssignee),
+		})
 	}
 
 	t.setTaskAssignees(assignees)
@@ -176,6 +191,18 @@ func (la *TaskAssginee) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	assignee, err := user.GetUserByID(s, la.UserID)
+	if err != nil {
+		return err
+	}
+	err = recordTaskChanges(s, a, la.TaskID, &TaskActivityChange{
+		Field:    "assignees",
+		OldValue: getAssigneeActivityValue(assignee),
+	})
+	if err != nil {
+		return err
+	}
+
 	err = updateProjectByTaskID(s, la.TaskID)
 	if err != nil {
 		return err
@@ -187,7 +214,7 @@ func (la *TaskAssginee) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
-	err = events.Dispatch(&TaskAssigneeDeletedEvent{
+	err = events.DispatchOnCommit(s, &TaskAssigneeDeletedEvent{
 		Task:     &task,
 		Assignee: &user.User{ID: la.UserID},
 		Doer:     doer,
@@ -195,7 +222,7 @@ func (la *TaskAssginee) Delete(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
-	return events.Dispatch(&TaskUpdatedEvent{
+	return events.DispatchOnCommit(s, &TaskUpdatedEvent{
 		Task: &task,
 		Doer: doer,
 	})
@@ -223,31 +250,39 @@ func (la *TaskAssginee) Create(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	task := &Task{ID: la.TaskID}
-	return task.addNewAssigneeByID(s, la.UserID, project, a)
+	newAssignee, err := task.addNewAssigneeByID(s, la.UserID, project, a)
+	if err != nil {
+		return err
+	}
+
+	return recordTaskChanges(s, a, la.TaskID, &TaskActivityChange{
+		Field:    "assignees",
+		NewValue: getAssigneeActivityValue(newAssignee),
+	})
 }
 
-func (t *Task) addNewAssigneeByID(s *xorm.Session, newAssigneeID int64, project *Project, auth web.Auth) (err error) {
+func (t *Task) addNewAssigneeByID(s *xorm.Session, newAssigneeID int64, project *Project, auth web.Auth) (newAssignee *user.User, err error) {
 	// Check if the user exists and has access to the project
-	newAssignee, err := user.GetUserByID(s, newAssigneeID)
+	newAssignee, err = user.GetUserByID(s, newAssigneeID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	canRead, _, err := project.CanRead(s, newAssignee)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !canRead {
-		return ErrUserDoesNotHaveAccessToProject{project.ID, newAssigneeID}
+		return nil, ErrUserDoesNotHaveAccessToProject{project.ID, newAssigneeID}
 	}
 
 	exist, err := s.
 		Where("task_id = ? AND user_id = ?", t.ID, newAssigneeID).
 		Exist(&TaskAssginee{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if exist {
-		return &ErrUserAlreadyAssigned{
+		return nil, &ErrUserAlreadyAssigned{
 			UserID: newAssigneeID,
 			TaskID: t.ID,
 		}
@@ -258,7 +293,7 @@ func (t *Task) addNewAssigneeByID(s *xorm.Session, newAssigneeID int64, project
 		UserID: newAssigneeID,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	sub := &Subscription{
@@ -269,28 +304,28 @@ func (t *Task) addNewAssigneeByID(s *xorm.Session, newAssigneeID int64, project
 
 	err = sub.Create(s, newAssignee)
 	if err != nil && !IsErrSubscriptionAlreadyExists(err) {
-		return err
+		return nil, err
 	}
 
 	doer, _ := user.GetFromAuth(auth)
 	task, err := GetTaskSimple(s, &Task{ID: t.ID})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = events.Dispatch(&TaskAssigneeCreatedEvent{
+	err = events.DispatchOnCommit(s, &TaskAssigneeCreatedEvent{
 		Task:     &task,
 		Assignee: newAssignee,
 		Doer:     doer,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = events.Dispatch(&TaskUpdatedEvent{
+	err = events.DispatchOnCommit(s, &TaskUpdatedEvent{
 		Task: &task,
 		Doer: doer,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = updateProjectLastUpdated(s, &Project{ID: t.ProjectID})
@@ -385,6 +420,10 @@ func (ba *BulkAssignees) Create(s *xorm.Session, a web.Auth) (err error) {
 		task.Assignees = append(task.Assignees, &assignees[i].User)
 	}
 
-	err = task.updateTaskAssignees(s, ba.Assignees, a)
-	return
+	changes, err := task.updateTaskAssignees(s, ba.Assignees, a)
+	if err != nil {
+		return err
+	}
+
+	return recordTaskChanges(s, a, task.ID, changes...)
 }
@@ -32,6 +32,7 @@ import (
 	"code.vikunja.io/api/pkg/web"
 
 	"github.com/disintegration/imaging"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -61,8 +62,8 @@ func (*TaskAttachment) TableName() string {
 // Note: I'm not sure if only accepting an io.ReadCloser and not an afero.File or os.File instead is a good way of doing things.
 func (ta *TaskAttachment) NewAttachment(s *xorm.Session, f io.ReadCloser, realname string, realsize uint64, a web.Auth) error {
 
-	// Store the file
-	file, err := files.Create(f, realname, realsize, a)
+	// Store the file, its db entry is part of the transaction of the attachment
+	file, err := files.CreateWithMimeAndSession(s, f, realname, realsize, a, "", true)
 	if err != nil {
 		if files.IsErrFileIsTooLarge(err) {
 			return ErrTaskAttachmentIsTooLarge{Size: realsize}
@@ -93,12 +94,20 @@ func (ta *TaskAttachment) NewAttachment(s *xorm.Session, f io.ReadCloser, realna
 		return err
 	}
 
+	err = recordTaskChanges(s, a, ta.TaskID, &TaskActivityChange{
+		Field:    "attachments",
+		NewValue: getAttachmentActivityValue(ta),
+	})
+	if err != nil {
+		return err
+	}
+
 	task, err := GetTaskByIDSimple(s, ta.TaskID)
 	if err != nil {
 		return err
 	}
 
-	return events.Dispatch(&TaskAttachmentCreatedEvent{
+	return events.DispatchOnCommit(s, &TaskAttachmentCreatedEvent{
 		Task:       &task,
 		Attachment: ta,
 		Doer:       ta.CreatedBy,
@@ -323,6 +332,19 @@ func (ta *TaskAttachment) Delete(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	err = deleteAttachmentText(s, ta.ID)
+	if err != nil {
+		return err
+	}
+
+	err = recordTaskChanges(s, a, ta.TaskID, &TaskActivityChange{
+		Field:    "attachments",
+		OldValue: getAttachmentActivityValue(ta),
+	})
+	if err != nil {
+		return err
+	}
+
 	// Delete the underlying file
 	err = ta.File.Delete(s)
 	// If the file does not exist, we don't want to error out
@@ -339,7 +361,7 @@ func (ta *TaskAttachment) Delete(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
-	return events.Dispatch(&TaskAttachmentDeletedEvent{
+	return events.DispatchOnCommit(s, &TaskAttachmentDeletedEvent{
 		Task:       &task,
 		Attachment: ta,
 		Doer:       doer,
@@ -392,3 +414,10 @@ func getTaskAttachmentsByTaskIDs(s *xorm.Session, taskIDs []int64) (attachments
 
 	return
 }
+
+// getTasksWithAttachmentsSubQuery returns a query for the ids of all tasks which have at least one attachment.
+func getTasksWithAttachmentsSubQuery() *builder.Builder {
+	return builder.
+		Select("task_attachments.task_id").
+		From("task_attachments")
+}
@@ -0,0 +1,249 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/microcosm-cc/bluemonday"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskAttachmentText holds the text extracted from a text based task attachment so that it can be searched.
+type TaskAttachmentText struct {
+	ID           int64  `xorm:"bigint autoincr not null unique pk" json:"id"`
+	TaskID       int64  `xorm:"bigint not null INDEX" json:"task_id"`
+	AttachmentID int64  `xorm:"bigint not null unique" json:"attachment_id"`
+	Text         string `xorm:"longtext null" json:"-"`
+}
+
+// TableName returns the table name for task attachment texts
+func (*TaskAttachmentText) TableName() string {
+	return "task_attachment_texts"
+}
+
+// Only the first MiB of text of an attachment is stored, everything after that is not searchable.
+const maxAttachmentTextLength = 1 << 20
+
+type attachmentTextKind int
+
+const (
+	attachmentTextKindNone attachmentTextKind = iota
+	attachmentTextKindPlain
+	attachmentTextKindHTML
+	attachmentTextKindPDF
+)
+
+func getAttachmentTextKind(file *files.File) attachmentTextKind {
+	switch strings.ToLower(filepath.Ext(file.Name)) {
+	case ".txt", ".text", ".md", ".markdown", ".csv":
+		return attachmentTextKindPlain
+	case ".html", ".htm":
+		return attachmentTextKindHTML
+	case ".pdf":
+		return attachmentTextKindPDF
+	}
+
+	mediaType, _, err := mime.ParseMediaType(file.Mime)
+	if err != nil {
+		return attachmentTextKindNone
+	}
+	switch {
+	case mediaType == "text/html":
+		return attachmentTextKindHTML
+	case mediaType == "application/pdf":
+		return attachmentTextKindPDF
+	case strings.HasPrefix(mediaType, "text/"):
+		return attachmentTextKindPlain
+	}
+
+	return attachmentTextKindNone
+}
+
+func extractPDFText(r io.ReaderAt, size int64) (text string, err error) {
+	// The pdf parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not parse pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(io.LimitReader(plain, maxAttachmentTextLength))
+	return string(content), err
+}
+
+// extractTextFromFile returns the text content of a file. Files which are not text based return an empty string.
+func extractTextFromFile(file *files.File) (text string, err error) {
+	kind := getAttachmentTextKind(file)
+	if kind == attachmentTextKindNone {
+		return "", nil
+	}
+
+	if file.File == nil {
+		err = file.LoadFileByID()
+		if err != nil {
+			return "", err
+		}
+		defer file.File.Close()
+	}
+
+	switch kind {
+	case attachmentTextKindPDF:
+		stat, err := file.File.Stat()
+		if err != nil {
+			return "", err
+		}
+		text, err = extractPDFText(file.File, stat.Size())
+		if err != nil {
+			return "", err
+		}
+	case attachmentTextKindPlain, attachmentTextKindHTML:
+		content, err := io.ReadAll(io.LimitReader(file.File, maxAttachmentTextLength))
+		if err != nil {
+			return "", err
+		}
+		text = string(content)
+		if kind == attachmentTextKindHTML {
+			text = html.UnescapeString(bluemonday.StrictPolicy().Sanitize(text))
+		}
+	}
+
+	// Postgres does not allow null bytes in text columns
+	text = strings.ReplaceAll(strings.ToValidUTF8(text, ""), "\x00", "")
+	return strings.TrimSpace(text), nil
+}
+
+// extractAttachmentText extracts the text of a task attachment and stores it. It returns false if the attachment
+// is not text based.
+func extractAttachmentText(s *xorm.Session, attachment *TaskAttachment) (extracted bool, err error) {
+	file := attachment.File
+	if file == nil || file.Name == "" {
+		file = &files.File{ID: attachment.FileID}
+		err = file.LoadFileMetaByID()
+		if err != nil {
+			if files.IsErrFileDoesNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	if getAttachmentTextKind(file) == attachmentTextKindNone {
+		return false, nil
+	}
+
+	// Open the file separately so that reading it does not move the offset of an already loaded file
+	text, err := extractTextFromFile(&files.File{ID: file.ID, Name: file.Name, Mime: file.Mime})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		log.Errorf("Could not extract the text of attachment %d: %s", attachment.ID, err)
+		// Store an empty text for files which cannot be parsed so that they are not tried again
+		text = ""
+	}
+
+	_, err = s.Where("attachment_id = ?", attachment.ID).Delete(&TaskAttachmentText{})
+	if err != nil {
+		return false, err
+	}
+	_, err = s.Insert(&TaskAttachmentText{
+		TaskID:       attachment.TaskID,
+		AttachmentID: attachment.ID,
+		Text:         text,
+	})
+	return true, err
+}
+
+func deleteAttachmentText(s *xorm.Session, attachmentID int64) error {
+	_, err := s.Where("attachment_id = ?", attachmentID).Delete(&TaskAttachmentText{})
+	return err
+}
+
+// getAttachmentTextsByTask returns the non-empty texts of all attachments of the given tasks, mapped by task id.
+func getAttachmentTextsByTask(s *xorm.Session, taskIDs []int64) (textsByTask map[int64][]string, err error) {
+	textsByTask = make(map[int64][]string)
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	texts := []*TaskAttachmentText{}
+	err = s.
+		In("task_id", taskIDs).
+		And("text != ''").
+		OrderBy("attachment_id asc").
+		Find(&texts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range texts {
+		textsByTask[t.TaskID] = append(textsByTask[t.TaskID], t.Text)
+	}
+	return
+}
+
+// ExtractMissingAttachmentTexts extracts the text of all text based attachments which were uploaded before
+// attachment texts were searchable.
+func ExtractMissingAttachmentTexts() (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	attachments := []*TaskAttachment{}
+	err = s.
+		Where(builder.NotIn("id", builder.Select("attachment_id").From("task_attachment_texts"))).
+		Find(&attachments)
+	if err != nil {
+		return err
+	}
+
+	var extracted int
+	for _, attachment := range attachments {
+		has, err := extractAttachmentText(s, attachment)
+		if err != nil {
+			return err
+		}
+		if has {
+			extracted++
+		}
+	}
+
+	log.Infof("Extracted the text of %d attachments", extracted)
+
+	return nil
+}
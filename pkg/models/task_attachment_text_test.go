@@ -0,0 +1,173 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestPDF returns a minimal pdf document with one page containing the text.
+func createTestPDF(text string) []byte {
+	content := "BT /F1 24 Tf 72 700 Td (" + text + ") Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, 0, len(objects))
+	for i, object := range objects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestExtractAttachmentText(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	createAttachment := func(t *testing.T, name string, content []byte) *TaskAttachment {
+		s := db.NewSession()
+		defer s.Close()
+
+		ta := &TaskAttachment{TaskID: 1}
+		err := ta.NewAttachment(s, io.NopCloser(bytes.NewReader(content)), name, uint64(len(content)), u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+		return ta
+	}
+
+	extract := func(t *testing.T, ta *TaskAttachment) (bool, string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		extracted, err := extractAttachmentText(s, ta)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		text := &TaskAttachmentText{}
+		_, err = s.Where("attachment_id = ?", ta.ID).Get(text)
+		require.NoError(t, err)
+		return extracted, text.Text
+	}
+
+	t.Run("plain text", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "notes.md", []byte("# Notes\n\nThe budget was approved."))
+		extracted, text := extract(t, ta)
+		assert.True(t, extracted)
+		assert.Equal(t, "# Notes\n\nThe budget was approved.", text)
+	})
+	t.Run("html", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "page.html", []byte("<html><script>alert(1)</script><p>Tom &amp; Jerry</p></html>"))
+		extracted, text := extract(t, ta)
+		assert.True(t, extracted)
+		assert.Equal(t, "Tom & Jerry", text)
+	})
+	t.Run("pdf", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "report.pdf", createTestPDF("Quarterly budget"))
+		extracted, text := extract(t, ta)
+		assert.True(t, extracted)
+		assert.Contains(t, text, "Quarterly budget")
+	})
+	t.Run("invalid pdf", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "broken.pdf", []byte("not a pdf"))
+		extracted, text := extract(t, ta)
+		assert.True(t, extracted)
+		assert.Empty(t, text)
+	})
+	t.Run("not text based", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "image.png", []byte("not really an image"))
+		extracted, _ := extract(t, ta)
+		assert.False(t, extracted)
+		db.AssertMissing(t, "task_attachment_texts", map[string]interface{}{
+			"attachment_id": ta.ID,
+		})
+	})
+	t.Run("missing texts are extracted", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+
+		ta := createAttachment(t, "todo.txt", []byte("Call the plumber"))
+		err := ExtractMissingAttachmentTexts()
+		require.NoError(t, err)
+		db.AssertExists(t, "task_attachment_texts", map[string]interface{}{
+			"task_id":       1,
+			"attachment_id": ta.ID,
+			"text":          "Call the plumber",
+		}, false)
+	})
+	t.Run("text is deleted with the attachment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ta := &TaskAttachment{ID: 1, TaskID: 1}
+		err := ta.Delete(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+		db.AssertMissing(t, "task_attachment_texts", map[string]interface{}{
+			"attachment_id": 1,
+		})
+	})
+}
+
+func TestGetAttachmentTextKind(t *testing.T) {
+	assert.Equal(t, attachmentTextKindPlain, getAttachmentTextKind(&files.File{Name: "data.CSV"}))
+	assert.Equal(t, attachmentTextKindPlain, getAttachmentTextKind(&files.File{Name: "log", Mime: "text/plain; charset=utf-8"}))
+	assert.Equal(t, attachmentTextKindHTML, getAttachmentTextKind(&files.File{Name: "page", Mime: "text/html"}))
+	assert.Equal(t, attachmentTextKindPDF, getAttachmentTextKind(&files.File{Name: "scan", Mime: "application/pdf"}))
+	assert.Equal(t, attachmentTextKindNone, getAttachmentTextKind(&files.File{Name: "photo.jpg", Mime: "image/jpeg"}))
+	assert.Equal(t, attachmentTextKindNone, getAttachmentTextKind(&files.File{Name: "README"}))
+}
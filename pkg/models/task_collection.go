@@ -58,6 +58,12 @@ type TaskCollection struct {
 	// You can set this multiple times with different values.
 	Expand []TaskCollectionExpandable `query:"expand" json:"-"`
 
+	// The title, description and identifier of tasks are always searched.
+	// If set to `comments`, the search will also match the text of task comments.
+	// If set to `attachments`, the search will also match the text of txt, md, csv, html and pdf attachments.
+	// You can set this multiple times with different values.
+	SearchIn []TaskSearchField `query:"search_in" json:"search_in,omitempty"`
+
 	isSavedFilter bool
 
 	web.CRUDable    `xorm:"-" json:"-"`
@@ -87,12 +93,41 @@ func (t TaskCollectionExpandable) Validate() error {
 	return InvalidFieldErrorWithMessage([]string{"expand"}, "Expand must be one of the following values: subtasks, buckets, reactions")
 }
 
+// TaskSearchField is a part of a task a search can match.
+type TaskSearchField string
+
+const TaskSearchFieldTitle TaskSearchField = `title`
+const TaskSearchFieldDescription TaskSearchField = `description`
+const TaskSearchFieldIdentifier TaskSearchField = `identifier`
+const TaskSearchFieldComments TaskSearchField = `comments`
+const TaskSearchFieldAttachments TaskSearchField = `attachments`
+
+// Validate validates if the TaskSearchField value can be searched in additionally.
+func (t TaskSearchField) Validate() error {
+	switch t {
+	case TaskSearchFieldComments:
+		return nil
+	case TaskSearchFieldAttachments:
+		return nil
+	}
+
+	return InvalidFieldErrorWithMessage([]string{"search_in"}, "Search in must be one of the following values: comments, attachments")
+}
+
 func validateTaskField(fieldName string) error {
 	switch fieldName {
 	case
 		taskPropertyAssignees,
 		taskPropertyLabels,
-		taskPropertyReminders:
+		taskPropertyReminders,
+		taskPropertyOccurrences,
+		taskPropertyIsBlocked,
+		taskPropertyParentTask,
+		taskPropertyHasSubtasks,
+		taskPropertySubtasksDonePercent,
+		taskPropertyHasAttachments,
+		taskPropertyCommentCount,
+		taskPropertyCreatedBy:
 		return nil
 	}
 
@@ -236,6 +271,7 @@ func getFilterValueForBucketFilter(filter string, view *ProjectView) (newFilter
 // @Param filter_timezone query string false "The time zone which should be used for date match (statements like "now" resolve to different actual times)"
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
 // @Param expand query array false "If set to `subtasks`, Vikunja will fetch only tasks which do not have subtasks and then in a second step, will fetch all of these subtasks. This may result in more tasks than the pagination limit being returned, but all subtasks will be present in the response. If set to `buckets`, the buckets of each task will be present in the response. If set to `reactions`, the reactions of each task will be present in the response. If set to `comments`, the first 50 comments of each task will be present in the response. You can set this multiple times with different values."
+// @Param search_in query array false "Search the text of comments or attachments too. If set to `comments`, the search will also match the text of task comments. If set to `attachments`, the search will also match the text of txt, md, csv, html and pdf attachments. You can set this multiple times with different values. Where a search matched is returned as `matched_in` of each task."
 // @Security JWTKeyAuth
 // @Success 200 {array} models.Task "The tasks"
 // @Failure 500 {object} models.Message "Internal error"
@@ -286,6 +322,9 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 		tc.ProjectViewID = tf.ProjectViewID
 		tc.ProjectID = tf.ProjectID
 		tc.isSavedFilter = true
+		if len(tf.SearchIn) > 0 {
+			tc.SearchIn = tf.SearchIn
+		}
 
 		if tf.Filter != "" {
 			if tc.Filter != "" {
@@ -326,6 +365,10 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 			if view.Filter.Search != "" {
 				search = view.Filter.Search
 			}
+
+			if len(tf.SearchIn) == 0 {
+				tf.SearchIn = view.Filter.SearchIn
+			}
 		}
 
 		if strings.Contains(tf.Filter, taskPropertyBucketID) {
@@ -349,7 +392,15 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 		}
 	}
 
+	for _, searchIn := range tf.SearchIn {
+		err = searchIn.Validate()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
 	opts.search = search
+	opts.searchIn = tf.SearchIn
 	opts.page = page
 	opts.perPage = perPage
 	opts.expand = tf.Expand
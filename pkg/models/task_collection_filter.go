@@ -140,6 +140,11 @@ func parseFilterFromExpression(f fexpr.ExprGroup, loc *time.Location) (filter *t
 		return nil, err
 	}
 
+	if _, is := getCustomFieldIDFromTaskProperty(filter.field); is {
+		filter.value, err = getCustomFieldFilterValue(filter.comparator, value, loc)
+		return filter, err
+	}
+
 	reflectValue, filter.value, err = getNativeValueForTaskField(filter.field, filter.comparator, value, loc)
 	if err != nil {
 		return nil, ErrInvalidTaskFilterValue{
@@ -268,6 +273,25 @@ func getFilterComparatorFromOp(op fexpr.SignOp) (taskFilterComparator, error) {
 	}
 }
 
+// Task fields which hold a duration in seconds. Filter values for these can also be given as a duration like 2h30m.
+var durationTaskFields = map[string]bool{
+	"EstimatedTime": true,
+	"TimeSpent":     true,
+}
+
+func parseDurationFromUserInput(rawValue string) (seconds int64, err error) {
+	seconds, err = strconv.ParseInt(rawValue, 10, 64)
+	if err == nil {
+		return
+	}
+
+	duration, err := time.ParseDuration(rawValue)
+	if err != nil {
+		return 0, err
+	}
+	return int64(duration.Seconds()), nil
+}
+
 func getValueForField(field reflect.StructField, rawValue string, loc *time.Location) (value interface{}, err error) {
 
 	if loc == nil {
@@ -278,6 +302,10 @@ func getValueForField(field reflect.StructField, rawValue string, loc *time.Loca
 
 	switch field.Type.Kind() {
 	case reflect.Int64:
+		if durationTaskFields[field.Name] {
+			value, err = parseDurationFromUserInput(rawValue)
+			return
+		}
 		value, err = strconv.ParseInt(rawValue, 10, 64)
 	case reflect.Float64:
 		value, err = strconv.ParseFloat(rawValue, 64)
@@ -328,6 +356,17 @@ func getValueForField(field reflect.StructField, rawValue string, loc *time.Loca
 	return
 }
 
+// derivedTaskFilterFields holds the types of all filter fields which are not a column of the task itself but
+// derived from its relations. The field names need to match the filter field in camel case.
+type derivedTaskFilterFields struct {
+	ParentTask          int64
+	HasSubtasks         bool
+	SubtasksDonePercent float64
+	HasAttachments      bool
+	CommentCount        int64
+	CreatedBy           string
+}
+
 func getNativeValueForTaskField(fieldName string, comparator taskFilterComparator, value string, loc *time.Location) (reflectField *reflect.StructField, nativeValue interface{}, err error) {
 
 	realFieldName := strings.ReplaceAll(strcase.ToCamel(fieldName), "Id", "ID")
@@ -338,7 +377,10 @@ func getNativeValueForTaskField(fieldName string, comparator taskFilterComparato
 		return nil, valueSlice, nil
 	}
 
-	field, ok := reflect.TypeOf(&Task{}).Elem().FieldByName(realFieldName)
+	field, ok := reflect.TypeOf(&derivedTaskFilterFields{}).Elem().FieldByName(realFieldName)
+	if !ok {
+		field, ok = reflect.TypeOf(&Task{}).Elem().FieldByName(realFieldName)
+	}
 	if !ok {
 		return nil, nil, ErrInvalidTaskField{TaskField: fieldName}
 	}
@@ -350,6 +392,13 @@ func getNativeValueForTaskField(fieldName string, comparator taskFilterComparato
 		}
 	}
 
+	if realFieldName == "Occurrences" {
+		field, ok = reflect.TypeOf(&TaskOccurrence{}).Elem().FieldByName("DoneAt")
+		if !ok {
+			return nil, nil, ErrInvalidTaskField{TaskField: fieldName}
+		}
+	}
+
 	if comparator == taskFilterComparatorIn || comparator == taskFilterComparatorNotIn {
 		vals := strings.Split(value, ",")
 		valueSlice := []interface{}{}
@@ -284,4 +284,15 @@ func TestParseFilter(t *testing.T) {
 			assert.Equal(t, 0, date.Year())
 		}
 	})
+	t.Run("relation fields", func(t *testing.T) {
+		result, err := getTaskFiltersFromFilterString("parent_task = 12 && subtasks_done_percent < 50 && has_attachments = true && comment_count > 3 && created_by = alice", "UTC")
+
+		require.NoError(t, err)
+		require.Len(t, result, 5)
+		assert.Equal(t, int64(12), result[0].value)
+		assert.InDelta(t, 50.0, result[1].value, 0)
+		assert.Equal(t, true, result[2].value)
+		assert.Equal(t, int64(3), result[3].value)
+		assert.Equal(t, "alice", result[4].value)
+	})
 }
@@ -51,6 +51,17 @@ const (
 	taskPropertyAssignees     string = "assignees"
 	taskPropertyLabels        string = "labels"
 	taskPropertyReminders     string = "reminders"
+	taskPropertyOccurrences   string = "occurrences"
+	taskPropertyEstimatedTime string = "estimated_time"
+	taskPropertyTimeSpent     string = "time_spent"
+	taskPropertyIsBlocked     string = "is_blocked"
+
+	taskPropertyParentTask          string = "parent_task"
+	taskPropertyHasSubtasks         string = "has_subtasks"
+	taskPropertySubtasksDonePercent string = "subtasks_done_percent"
+	taskPropertyHasAttachments      string = "has_attachments"
+	taskPropertyCommentCount        string = "comment_count"
+	taskPropertyCreatedBy           string = "created_by"
 )
 
 const (
@@ -107,7 +118,12 @@ func validateTaskFieldForSorting(fieldName string) error {
 		taskPropertyUpdated,
 		taskPropertyPosition,
 		taskPropertyBucketID,
-		taskPropertyIndex:
+		taskPropertyIndex,
+		taskPropertyEstimatedTime,
+		taskPropertyTimeSpent:
+		return nil
+	}
+	if _, is := getCustomFieldIDFromTaskProperty(fieldName); is {
 		return nil
 	}
 	return ErrInvalidTaskField{TaskField: fieldName}
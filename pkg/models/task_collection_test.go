@@ -112,6 +112,11 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		Labels: []*Label{
 			label4,
 		},
+		CustomFields: map[int64]interface{}{
+			1: "ACME",
+			2: float64(3),
+			4: "staging",
+		},
 		RelatedTasks: map[RelationKind][]*Task{
 			RelationKindSubtask: {
 				{
@@ -182,9 +187,16 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		CreatedByID: 1,
 		CreatedBy:   user1,
 		ProjectID:   1,
+		TimeSpent:   7200,
 		Labels: []*Label{
 			label4,
 		},
+		CustomFields: map[int64]interface{}{
+			1: "Globex",
+			2: float64(8),
+			5: []string{"api", "frontend"},
+			6: int64(1),
+		},
 		RelatedTasks: map[RelationKind][]*Task{},
 		Reminders: []*TaskReminder{
 			{
@@ -206,9 +218,12 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		CreatedBy:    user1,
 		ProjectID:    1,
 		RelatedTasks: map[RelationKind][]*Task{},
-		Created:      time.Unix(1543626724, 0).In(loc),
-		Updated:      time.Unix(1543626724, 0).In(loc),
-		Priority:     100,
+		CustomFields: map[int64]interface{}{
+			3: time.Unix(1543626724, 0).In(loc),
+		},
+		Created:  time.Unix(1543626724, 0).In(loc),
+		Updated:  time.Unix(1543626724, 0).In(loc),
+		Priority: 100,
 	}
 	task4 := &Task{
 		ID:           4,
@@ -547,6 +562,7 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		CreatedByID: 1,
 		CreatedBy:   user1,
 		ProjectID:   1,
+		TimeSpent:   3600,
 		Assignees: []*user.User{
 			user1,
 			user2,
@@ -1065,6 +1081,40 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "filtered occurrence dates",
+			fields: fields{
+				Filter: "occurrences > '2018-11-30T23:00:00+00:00'",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task28,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filtered time spent as duration",
+			fields: fields{
+				Filter: "time_spent > 1h30m",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filtered time spent in seconds",
+			fields: fields{
+				Filter: "time_spent >= 3600",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+				task30,
+			},
+			wantErr: false,
+		},
 		{
 			name: "filter in keyword",
 			fields: fields{
@@ -1441,6 +1491,144 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "filter by text custom field",
+			fields: fields{
+				Filter: "custom_field_1 = ACME",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by number custom field",
+			fields: fields{
+				Filter: "custom_field_2 > 5",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by multiselect custom field",
+			fields: fields{
+				Filter: "custom_field_5 in api, docs",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by user custom field with username",
+			fields: fields{
+				Filter: "custom_field_6 = user1",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by user custom field with usernames",
+			fields: fields{
+				Filter: "custom_field_6 in user2, user1",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by user custom field with user id",
+			fields: fields{
+				Filter: "custom_field_6 = 1",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by user custom field with nonexisting username",
+			fields: fields{
+				Filter: "custom_field_6 = doesnotexist",
+			},
+			args:    defaultArgs,
+			want:    []*Task{},
+			wantErr: false,
+		},
+		{
+			name: "filter by date custom field",
+			fields: fields{
+				Filter: "custom_field_3 < 2018-12-02",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task3,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by custom field with nulls",
+			fields: fields{
+				Filter:             "custom_field_4 = production",
+				FilterIncludeNulls: true,
+				ProjectID:          1,
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+				task3,
+				task4,
+				task5,
+				task6,
+				task7,
+				task8,
+				task9,
+				task10,
+				task11,
+				task12,
+				task27,
+				task28,
+				task29,
+				task30,
+				task31,
+				task33,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter by custom field of another project",
+			fields: fields{
+				Filter: "custom_field_7 = ACME",
+			},
+			args:    defaultArgs,
+			want:    []*Task{},
+			wantErr: false,
+		},
+		{
+			name: "order by number custom field",
+			fields: fields{
+				Filter:  "custom_field_2 > 0",
+				SortBy:  []string{"custom_field_2", "id"},
+				OrderBy: []string{"desc", "asc"},
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task2,
+				task1,
+			},
+			wantErr: false,
+		},
 		{
 			name: "order by position",
 			fields: fields{
@@ -1595,6 +1783,12 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 	// Here we're explicitly testing search with and without paradeDB. Both return different results but that's
 	// expected - paradeDB returns more results than other databases with a naive like-search.
 
+	taskWithSearchMatches := func(task *Task, matchedIn ...TaskSearchField) *Task {
+		matched := *task
+		matched.MatchedIn = matchedIn
+		return &matched
+	}
+
 	if db.ParadeDBAvailable() {
 		tests = append(tests, testcase{
 			name:   "search for task index",
@@ -1605,8 +1799,8 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 				page:   0,
 			},
 			want: []*Task{
-				task17, // has the text #17 in the title
-				task33, // has the index 17
+				taskWithSearchMatches(task17, TaskSearchFieldTitle),      // has the text #17 in the title
+				taskWithSearchMatches(task33, TaskSearchFieldIdentifier), // has the index 17
 			},
 			wantErr: false,
 		})
@@ -1620,7 +1814,7 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 				page:   0,
 			},
 			want: []*Task{
-				task33, // has the index 17
+				taskWithSearchMatches(task33, TaskSearchFieldIdentifier), // has the index 17
 			},
 			wantErr: false,
 		})
@@ -1827,3 +2021,116 @@ func TestTaskCollection_SubtaskWithMultipleParentsNoDuplicates(t *testing.T) {
 	assert.True(t, foundParent1, "Parent task 41 should be present")
 	assert.True(t, foundParent2, "Parent task 42 should be present")
 }
+
+func TestTaskCollection_RelationFilters(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	getTaskIDs := func(t *testing.T, filter string) []int64 {
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID: 1,
+			Filter:    filter,
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.NoError(t, err)
+
+		ids := []int64{}
+		for _, task := range result.([]*Task) {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	t.Run("parent task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{29}, getTaskIDs(t, "parent_task = 1"))
+		assert.Equal(t, []int64{29}, getTaskIDs(t, "parent_task in 1, 2"))
+
+		ids := getTaskIDs(t, "parent_task != 1")
+		assert.NotEmpty(t, ids)
+		assert.NotContains(t, ids, int64(29))
+	})
+	t.Run("has subtasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "has_subtasks = true"))
+
+		ids := getTaskIDs(t, "has_subtasks = false")
+		assert.NotEmpty(t, ids)
+		assert.NotContains(t, ids, int64(1))
+	})
+	t.Run("subtasks done percent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "subtasks_done_percent < 50"))
+		assert.Empty(t, getTaskIDs(t, "subtasks_done_percent >= 50"))
+
+		s := db.NewSession()
+		_, err := s.Where("id = ?", 29).Cols("done").Update(&Task{Done: true})
+		require.NoError(t, err)
+		s.Close()
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "subtasks_done_percent = 100"))
+		assert.Empty(t, getTaskIDs(t, "subtasks_done_percent < 50"))
+	})
+	t.Run("has attachments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "has_attachments = true"))
+		assert.NotContains(t, getTaskIDs(t, "has_attachments != true"), int64(1))
+	})
+	t.Run("comment count", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "comment_count > 0"))
+		assert.Empty(t, getTaskIDs(t, "comment_count > 3"))
+		assert.NotContains(t, getTaskIDs(t, "comment_count = 0"), int64(1))
+	})
+	t.Run("comment count with comment search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// The comments of a task are only searched when asked for
+		tc := &TaskCollection{ProjectID: 1, Filter: "comment_count > 0"}
+		result, _, _, err := tc.ReadAll(s, u, "dolor sit", 0, 50)
+		require.NoError(t, err)
+		assert.Empty(t, result.([]*Task))
+
+		tc.SearchIn = []TaskSearchField{TaskSearchFieldComments}
+		result, _, _, err = tc.ReadAll(s, u, "dolor sit", 0, 50)
+		require.NoError(t, err)
+		require.Len(t, result.([]*Task), 1)
+		assert.Equal(t, int64(1), result.([]*Task)[0].ID)
+	})
+	t.Run("created by", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		all := getTaskIDs(t, "")
+		assert.Equal(t, all, getTaskIDs(t, "created_by = user1"))
+		assert.Equal(t, all, getTaskIDs(t, "created_by in user1, user2"))
+		assert.Empty(t, getTaskIDs(t, "created_by = user2"))
+		assert.Empty(t, getTaskIDs(t, "created_by != user1"))
+	})
+	t.Run("combined", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		assert.Equal(t, []int64{1}, getTaskIDs(t, "has_subtasks = true && comment_count > 0 && created_by = user1"))
+	})
+	t.Run("invalid comparator", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID: 1,
+			Filter:    "has_subtasks > true",
+		}
+		_, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterComparator(err))
+	})
+}
@@ -0,0 +1,140 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/inbound"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+const (
+	replyTokenPrefix          = "reply-"
+	replyTokenSignatureLength = 24
+)
+
+func getReplyTokenSignature(taskID, userID int64) string {
+	mac := hmac.New(sha256.New, []byte(config.ServiceJWTSecret.GetString()))
+	_, _ = fmt.Fprintf(mac, "task-reply:%d:%d", taskID, userID)
+	return hex.EncodeToString(mac.Sum(nil))[:replyTokenSignatureLength]
+}
+
+// getTaskReplyAddress returns a signed address a user can reply to in order to
+// comment on a task. It returns an empty string if replying via email is not possible.
+func getTaskReplyAddress(taskID, userID int64) string {
+	if !inbound.IsEnabled() || !config.ServiceEnableTaskComments.GetBool() {
+		return ""
+	}
+
+	return inbound.GetAddress(fmt.Sprintf("%s%d-%d-%s", replyTokenPrefix, taskID, userID, getReplyTokenSignature(taskID, userID)))
+}
+
+func isReplyToken(token string) bool {
+	return strings.HasPrefix(strings.ToLower(token), replyTokenPrefix)
+}
+
+// parseReplyToken returns the task and user of a reply token if its signature is valid.
+func parseReplyToken(token string) (taskID, userID int64, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.ToLower(token), replyTokenPrefix), "-")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+
+	taskID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	userID, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(getReplyTokenSignature(taskID, userID))) {
+		return 0, 0, false
+	}
+
+	return taskID, userID, true
+}
+
+func createCommentFromInboundEmail(s *xorm.Session, token string, envelope *inbound.Envelope, msg *inbound.Message) error {
+	if !config.ServiceEnableTaskComments.GetBool() {
+		return &inbound.ErrRejected{Reason: "Comments are disabled"}
+	}
+
+	taskID, userID, ok := parseReplyToken(token)
+	if !ok {
+		return &inbound.ErrRejected{Reason: "Invalid reply address"}
+	}
+
+	u, err := user.GetUserWithEmail(s, &user.User{ID: userID})
+	if err != nil {
+		if user.IsErrUserDoesNotExist(err) {
+			return &inbound.ErrRejected{Reason: "Invalid reply address"}
+		}
+		return err
+	}
+	if u.Status == user.StatusDisabled {
+		return &inbound.ErrRejected{Reason: "The user is disabled"}
+	}
+
+	// Only the user the notification was sent to can reply to it
+	from := envelope.From
+	if msg.From != nil {
+		from = msg.From.Address
+	}
+	if !strings.EqualFold(from, u.Email) {
+		return &inbound.ErrRejected{Reason: "The sender does not match the recipient of the notification"}
+	}
+
+	comment := &TaskComment{
+		TaskID:  taskID,
+		Comment: convertTextToHTML(msg.ReplyText()),
+	}
+	if comment.Comment == "" {
+		return &inbound.ErrRejected{Reason: "The reply is empty"}
+	}
+
+	can, err := comment.CanCreate(s, u)
+	if err != nil {
+		if IsErrTaskDoesNotExist(err) {
+			return &inbound.ErrRejected{Reason: "The task does not exist"}
+		}
+		return err
+	}
+	if !can {
+		return &inbound.ErrRejected{Reason: "The sender is not allowed to comment on this task"}
+	}
+
+	err = comment.Create(s, u)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Inbound Mail] Created comment %d on task %d from mail by user %d", comment.ID, taskID, u.ID)
+
+	return nil
+}
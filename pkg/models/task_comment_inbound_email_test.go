@@ -0,0 +1,139 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/inbound"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseInboundTestReply(t *testing.T, from string) *inbound.Message {
+	msg, err := inbound.ParseMessage(strings.NewReader("From: " + from + "\r\n" +
+		"Subject: Re: Task #1\r\n" +
+		"\r\n" +
+		"Sounds good!\r\n" +
+		"\r\n" +
+		"On Mon, 1 Jan 2024 at 10:00, Vikunja <vikunja@example.com> wrote:\r\n" +
+		"> Are we done?\r\n"))
+	require.NoError(t, err)
+	return msg
+}
+
+func getTokenOfReplyAddress(t *testing.T, address string) string {
+	token, ok := inbound.GetTokenFromAddress(address)
+	require.True(t, ok)
+	return token
+}
+
+func TestTaskReplyAddress(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		assert.Empty(t, getTaskReplyAddress(1, 1))
+	})
+	t.Run("signed", func(t *testing.T) {
+		enableInboundEmail(t)
+
+		n := &TaskCommentNotification{Task: &Task{ID: 1}}
+		address := n.ReplyTo(&user.User{ID: 1})
+		assert.Regexp(t, `^vikunja\+reply-1-1-[0-9a-f]{24}@example\.com$`, address)
+
+		token := getTokenOfReplyAddress(t, address)
+		taskID, userID, ok := parseReplyToken(token)
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), taskID)
+		assert.Equal(t, int64(1), userID)
+
+		_, _, ok = parseReplyToken(strings.Replace(token, "reply-1-1-", "reply-2-1-", 1))
+		assert.False(t, ok)
+		_, _, ok = parseReplyToken("reply-1-1")
+		assert.False(t, ok)
+	})
+}
+
+func TestHandleInboundEmail_Reply(t *testing.T) {
+	t.Run("creates a comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user1@example.com",
+			Recipients: []string{getTaskReplyAddress(1, 1)},
+		}, parseInboundTestReply(t, "User 1 <user1@example.com>"))
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"task_id":   1,
+			"author_id": 1,
+			"comment":   "<p>Sounds good!</p>",
+		}, false)
+	})
+	t.Run("sender does not match", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user2@example.com",
+			Recipients: []string{getTaskReplyAddress(1, 1)},
+		}, parseInboundTestReply(t, "user2@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+		db.AssertMissing(t, "task_comments", map[string]interface{}{
+			"comment": "<p>Sounds good!</p>",
+		})
+	})
+	t.Run("no access to the task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user2@example.com",
+			Recipients: []string{getTaskReplyAddress(1, 2)},
+		}, parseInboundTestReply(t, "user2@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		err := HandleInboundEmail(&inbound.Envelope{
+			From:       "user1@example.com",
+			Recipients: []string{"vikunja+reply-1-1-000000000000000000000000@example.com"},
+		}, parseInboundTestReply(t, "user1@example.com"))
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+	})
+	t.Run("empty reply", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		enableInboundEmail(t)
+
+		msg, err := inbound.ParseMessage(strings.NewReader("From: user1@example.com\r\n\r\n> only a quote\r\n"))
+		require.NoError(t, err)
+		err = HandleInboundEmail(&inbound.Envelope{
+			From:       "user1@example.com",
+			Recipients: []string{getTaskReplyAddress(1, 1)},
+		}, msg)
+		require.Error(t, err)
+		assert.True(t, inbound.IsErrRejected(err))
+	})
+}
@@ -0,0 +1,93 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/xorm"
+)
+
+// TaskCommentRevision is a previous version of an edited task comment
+type TaskCommentRevision struct {
+	ID        int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	CommentID int64 `xorm:"bigint not null INDEX" json:"comment_id" param:"commentid"`
+	TaskID    int64 `xorm:"bigint not null INDEX" json:"-" param:"task"`
+	// The text of the comment before it was edited.
+	Comment string `xorm:"text not null" json:"comment"`
+
+	// A timestamp when the comment was edited and this version was replaced.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task comment revisions
+func (*TaskCommentRevision) TableName() string {
+	return "task_comment_revisions"
+}
+
+// ReadAll returns all previous versions of a comment
+// @Summary Get the edit history of a task comment
+// @Description Returns all previous versions of a comment, newest first. Only project admins can see the edit history of comments.
+// @tags task
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param commentID path int true "Comment ID"
+// @Success 200 {array} models.TaskCommentRevision "The previous versions of the comment."
+// @Failure 403 {object} web.HTTPError "The user is not an admin of the project."
+// @Failure 404 {object} web.HTTPError "The task comment was not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/comments/{commentID}/revisions [get]
+func (r *TaskCommentRevision) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	comment := &TaskComment{ID: r.CommentID, TaskID: r.TaskID}
+	err = getTaskCommentSimple(s, comment)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if comment.TaskID != r.TaskID {
+		return nil, 0, 0, ErrTaskCommentDoesNotExist{ID: r.CommentID, TaskID: r.TaskID}
+	}
+
+	task, err := GetTaskSimple(s, &Task{ID: r.TaskID})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	isAdmin, err := (&Project{ID: task.ProjectID}).IsAdmin(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !isAdmin {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+	query := s.
+		Where("comment_id = ?", r.CommentID).
+		OrderBy("created desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+
+	revisions := []*TaskCommentRevision{}
+	numberOfTotalItems, err = query.FindAndCount(&revisions)
+	return revisions, len(revisions), numberOfTotalItems, err
+}
@@ -0,0 +1,69 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskCommentRevision_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		tc := &TaskComment{ID: 1, TaskID: 1, Comment: "Edited"}
+		err := tc.Update(s, u)
+		require.NoError(t, err)
+
+		r := &TaskCommentRevision{CommentID: 1, TaskID: 1}
+		result, resultCount, total, err := r.ReadAll(s, u, "", 0, -1)
+		require.NoError(t, err)
+		revisions := result.([]*TaskCommentRevision)
+		assert.Equal(t, 2, resultCount)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, "Lorem Ipsum Dolor Sit Amet", revisions[0].Comment)
+		assert.Equal(t, "Lorem Ipsum", revisions[1].Comment)
+	})
+	t.Run("not a project admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &TaskCommentRevision{CommentID: 1, TaskID: 1}
+		_, _, _, err := r.ReadAll(s, &LinkSharing{ID: 1, ProjectID: 1, Permission: PermissionRead}, "", 0, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("comment of another task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &TaskCommentRevision{CommentID: 2, TaskID: 1}
+		_, _, _, err := r.ReadAll(s, &user.User{ID: 1}, "", 0, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskCommentDoesNotExist(err))
+	})
+}
@@ -36,6 +36,14 @@ type TaskComment struct {
 	Author   *user.User `xorm:"-" json:"author"`
 	TaskID   int64      `xorm:"not null" json:"-" param:"task"`
 
+	// The id of the comment this comment is a reply to. 0 if it is not a reply.
+	ParentID int64 `xorm:"bigint null INDEX" json:"parent_id"`
+	// The replies to this comment. Only returned when requesting the comments threaded.
+	Replies []*TaskComment `xorm:"-" json:"replies,omitempty"`
+
+	// If true, comments are returned as threads with their replies nested instead of a flat list.
+	Threaded bool `xorm:"-" json:"-" query:"threaded"`
+
 	Reactions ReactionMap `xorm:"-" json:"reactions"`
 
 	Created time.Time `xorm:"created" json:"created"`
@@ -79,6 +87,17 @@ func (tc *TaskComment) CreateWithTimestamps(s *xorm.Session, a web.Auth) (err er
 		return err
 	}
 
+	if tc.ParentID != 0 {
+		parent := &TaskComment{ID: tc.ParentID}
+		err = getTaskCommentSimple(s, parent)
+		if err != nil && !IsErrTaskCommentDoesNotExist(err) {
+			return err
+		}
+		if err != nil || parent.TaskID != tc.TaskID {
+			return &ErrInvalidTaskCommentParent{ParentID: tc.ParentID, TaskID: tc.TaskID}
+		}
+	}
+
 	tc.Author, err = GetUserOrLinkShareUser(s, a)
 	if err != nil {
 		return err
@@ -97,7 +116,16 @@ func (tc *TaskComment) CreateWithTimestamps(s *xorm.Session, a web.Auth) (err er
 		}
 	}
 
-	return events.Dispatch(&TaskCommentCreatedEvent{
+	err = recordTaskActivity(s, a, &TaskActivity{
+		TaskID:    tc.TaskID,
+		Kind:      TaskActivityKindCommented,
+		CommentID: tc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return events.DispatchOnCommit(s, &TaskCommentCreatedEvent{
 		Task:    &task,
 		Comment: tc,
 		Doer:    tc.Author,
@@ -118,7 +146,33 @@ func (tc *TaskComment) CreateWithTimestamps(s *xorm.Session, a web.Auth) (err er
 // @Failure 404 {object} web.HTTPError "The task comment was not found."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/{taskID}/comments/{commentID} [delete]
-func (tc *TaskComment) Delete(s *xorm.Session, _ web.Auth) error {
+func (tc *TaskComment) Delete(s *xorm.Session, a web.Auth) error {
+	existing := &TaskComment{ID: tc.ID, TaskID: tc.TaskID}
+	err := getTaskCommentSimple(s, existing)
+	if err != nil {
+		return err
+	}
+
+	// Replies to the deleted comment become replies to its parent
+	_, err = s.
+		Where("parent_id = ?", tc.ID).
+		Cols("parent_id").
+		NoAutoTime().
+		Update(&TaskComment{ParentID: existing.ParentID})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("comment_id = ?", tc.ID).Delete(&TaskCommentRevision{})
+	if err != nil {
+		return err
+	}
+
+	err = deleteTaskReferencesForComment(s, tc.ID)
+	if err != nil {
+		return err
+	}
+
 	deleted, err := s.
 		ID(tc.ID).
 		NoAutoCondition().
@@ -131,12 +185,21 @@ func (tc *TaskComment) Delete(s *xorm.Session, _ web.Auth) error {
 		return err
 	}
 
+	err = recordTaskActivity(s, a, &TaskActivity{
+		TaskID:    tc.TaskID,
+		Kind:      TaskActivityKindCommentDeleted,
+		CommentID: tc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
 	task, err := GetTaskByIDSimple(s, tc.TaskID)
 	if err != nil {
 		return err
 	}
 
-	return events.Dispatch(&TaskCommentDeletedEvent{
+	return events.DispatchOnCommit(s, &TaskCommentDeletedEvent{
 		Task:    &task,
 		Comment: tc,
 		Doer:    tc.Author,
@@ -157,7 +220,13 @@ func (tc *TaskComment) Delete(s *xorm.Session, _ web.Auth) error {
 // @Failure 404 {object} web.HTTPError "The task comment was not found."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/{taskID}/comments/{commentID} [post]
-func (tc *TaskComment) Update(s *xorm.Session, _ web.Auth) error {
+func (tc *TaskComment) Update(s *xorm.Session, a web.Auth) error {
+	oldComment := &TaskComment{ID: tc.ID, TaskID: tc.TaskID}
+	err := getTaskCommentSimple(s, oldComment)
+	if err != nil {
+		return err
+	}
+
 	updated, err := s.
 		ID(tc.ID).
 		Cols("comment").
@@ -170,12 +239,34 @@ func (tc *TaskComment) Update(s *xorm.Session, _ web.Auth) error {
 		return err
 	}
 
+	if oldComment.Comment != tc.Comment {
+		_, err = s.Insert(&TaskCommentRevision{
+			CommentID: oldComment.ID,
+			TaskID:    oldComment.TaskID,
+			Comment:   oldComment.Comment,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The activity only records that the comment was edited. Previous versions
+	// of the text are kept in the revisions which only project admins can see.
+	err = recordTaskActivity(s, a, &TaskActivity{
+		TaskID:    tc.TaskID,
+		Kind:      TaskActivityKindCommentUpdated,
+		CommentID: tc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
 	task, err := GetTaskSimple(s, &Task{ID: tc.TaskID})
 	if err != nil {
 		return err
 	}
 
-	return events.Dispatch(&TaskCommentUpdatedEvent{
+	return events.DispatchOnCommit(s, &TaskCommentUpdatedEvent{
 		Task:    &task,
 		Comment: tc,
 		Doer:    tc.Author,
@@ -237,6 +328,7 @@ func (tc *TaskComment) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
 // @Produce json
 // @Security JWTKeyAuth
 // @Param taskID path int true "Task ID"
+// @Param threaded query bool false "If true, only comments which are not a reply are returned at the top level with all their replies nested in `replies`. Pagination then applies to the top level comments."
 // @Success 200 {array} models.TaskComment "The array with all task comments"
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/{taskID}/comments [get]
@@ -251,7 +343,53 @@ func (tc *TaskComment) ReadAll(s *xorm.Session, auth web.Auth, search string, pa
 		return nil, 0, 0, ErrGenericForbidden{}
 	}
 
-	return getAllCommentsForTasksWithoutPermissionCheck(s, []int64{tc.TaskID}, search, page, perPage)
+	if !tc.Threaded {
+		return getAllCommentsForTasksWithoutPermissionCheck(s, []int64{tc.TaskID}, search, page, perPage)
+	}
+
+	comments, _, _, err := getAllCommentsForTasksWithoutPermissionCheck(s, []int64{tc.TaskID}, search, 0, 0)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	threads := nestTaskComments(comments)
+	numberOfTotalItems = int64(len(threads))
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		if start > len(threads) {
+			start = len(threads)
+		}
+		end := start + limit
+		if end > len(threads) {
+			end = len(threads)
+		}
+		threads = threads[start:end]
+	}
+
+	return threads, len(threads), numberOfTotalItems, nil
+}
+
+// nestTaskComments puts all replies into the replies of their parent comment and
+// returns the comments which are not a reply. Replies whose parent is not part of
+// the comments are returned at the top level as well.
+func nestTaskComments(comments []*TaskComment) (threads []*TaskComment) {
+	commentsByID := make(map[int64]*TaskComment, len(comments))
+	for _, comment := range comments {
+		commentsByID[comment.ID] = comment
+	}
+
+	threads = []*TaskComment{}
+	for _, comment := range comments {
+		parent, has := commentsByID[comment.ParentID]
+		if comment.ParentID == 0 || !has {
+			threads = append(threads, comment)
+			continue
+		}
+		parent.Replies = append(parent.Replies, comment)
+	}
+
+	return threads
 }
 
 func addCommentsToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) (err error) {
@@ -333,3 +471,6 @@ func getAllCommentsForTasksWithoutPermissionCheck(s *xorm.Session, taskIDs []int
 	numberOfTotalItems, err = totalItemsQuery.Count(&TaskCommentWithAuthor{})
 	return comments, len(comments), numberOfTotalItems, err
 }
+
+// commentCountSQL counts the comments of a task.
+const commentCountSQL = "(SELECT COUNT(*) FROM task_comments WHERE task_comments.task_id = tasks.id)"
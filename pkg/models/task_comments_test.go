@@ -92,6 +92,96 @@ func TestTaskComment_Create(t *testing.T) {
 			"name":          (&TaskCommentNotification{}).Name(),
 		}, false)
 	})
+	t.Run("reply", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			Comment:  "reply",
+			TaskID:   1,
+			ParentID: 1,
+		}
+		err := tc.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"id":        tc.ID,
+			"task_id":   1,
+			"parent_id": 1,
+		}, false)
+	})
+	t.Run("reply to a comment of another task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			Comment:  "reply",
+			TaskID:   1,
+			ParentID: 2,
+		}
+		err := tc.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTaskCommentParent(err))
+	})
+	t.Run("reply to a nonexisting comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			Comment:  "reply",
+			TaskID:   1,
+			ParentID: 9999,
+		}
+		err := tc.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTaskCommentParent(err))
+	})
+	t.Run("should notify the author of the parent comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task, err := GetTaskByIDSimple(s, 32)
+		require.NoError(t, err)
+		parent := &TaskComment{
+			Comment: "Parent",
+			TaskID:  32,
+		}
+		err = parent.Create(s, &user.User{ID: 2})
+		require.NoError(t, err)
+
+		tc := &TaskComment{
+			Comment:  "Reply",
+			TaskID:   32,
+			ParentID: parent.ID,
+		}
+		err = tc.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		ev := &TaskCommentCreatedEvent{
+			Task:    &task,
+			Doer:    u,
+			Comment: tc,
+		}
+		events.TestListener(t, ev, &SendTaskCommentNotification{})
+		db.AssertExists(t, "notifications", map[string]interface{}{
+			"subject_id":    tc.ID,
+			"notifiable_id": 2,
+			"name":          (&TaskCommentReplyNotification{}).Name(),
+		}, false)
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"subject_id":    tc.ID,
+			"notifiable_id": 2,
+			"name":          (&TaskCommentNotification{}).Name(),
+		})
+	})
 }
 
 func TestTaskComment_Delete(t *testing.T) {
@@ -114,6 +204,32 @@ func TestTaskComment_Delete(t *testing.T) {
 		db.AssertMissing(t, "task_comments", map[string]interface{}{
 			"id": 1,
 		})
+		db.AssertMissing(t, "task_comment_revisions", map[string]interface{}{
+			"comment_id": 1,
+		})
+	})
+	t.Run("keeps replies", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		reply := &TaskComment{Comment: "reply", TaskID: 1, ParentID: 1}
+		err := reply.Create(s, u)
+		require.NoError(t, err)
+		replyToReply := &TaskComment{Comment: "reply to reply", TaskID: 1, ParentID: reply.ID}
+		err = replyToReply.Create(s, u)
+		require.NoError(t, err)
+
+		tc := &TaskComment{ID: reply.ID, TaskID: 1}
+		err = tc.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"id":        replyToReply.ID,
+			"parent_id": 1,
+		}, false)
 	})
 	t.Run("nonexisting comment", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
@@ -158,6 +274,29 @@ func TestTaskComment_Update(t *testing.T) {
 			"id":      1,
 			"comment": "testing",
 		}, false)
+		db.AssertExists(t, "task_comment_revisions", map[string]interface{}{
+			"comment_id": 1,
+			"task_id":    1,
+			"comment":    "Lorem Ipsum Dolor Sit Amet",
+		}, false)
+	})
+	t.Run("unchanged text", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskComment{
+			ID:      1,
+			Comment: "Lorem Ipsum Dolor Sit Amet",
+		}
+		err := tc.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "task_comment_revisions", map[string]interface{}{
+			"comment": "Lorem Ipsum Dolor Sit Amet",
+		})
 	})
 	t.Run("nonexisting comment", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
@@ -226,6 +365,48 @@ func TestTaskComment_ReadAll(t *testing.T) {
 		assert.Equal(t, "Lorem Ipsum Dolor Sit Amet", resultComment[0].Comment)
 		assert.NotEmpty(t, resultComment[0].Author.ID)
 	})
+	t.Run("threaded", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 1}
+
+		reply := &TaskComment{Comment: "reply", TaskID: 1, ParentID: 1}
+		err := reply.Create(s, u)
+		require.NoError(t, err)
+		replyToReply := &TaskComment{Comment: "reply to reply", TaskID: 1, ParentID: reply.ID}
+		err = replyToReply.Create(s, u)
+		require.NoError(t, err)
+		other := &TaskComment{Comment: "other", TaskID: 1}
+		err = other.Create(s, u)
+		require.NoError(t, err)
+
+		tc := &TaskComment{TaskID: 1, Threaded: true}
+		result, resultCount, total, err := tc.ReadAll(s, u, "", 0, -1)
+		require.NoError(t, err)
+		threads := result.([]*TaskComment)
+		assert.Equal(t, 2, resultCount)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, int64(1), threads[0].ID)
+		require.Len(t, threads[0].Replies, 1)
+		assert.Equal(t, reply.ID, threads[0].Replies[0].ID)
+		require.Len(t, threads[0].Replies[0].Replies, 1)
+		assert.Equal(t, replyToReply.ID, threads[0].Replies[0].Replies[0].ID)
+		assert.Equal(t, other.ID, threads[1].ID)
+
+		// Pagination applies to the threads
+		result, resultCount, total, err = tc.ReadAll(s, u, "", 2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resultCount)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, other.ID, result.([]*TaskComment)[0].ID)
+
+		// Flat by default
+		tc = &TaskComment{TaskID: 1}
+		result, _, _, err = tc.ReadAll(s, u, "", 0, -1)
+		require.NoError(t, err)
+		assert.Len(t, result.([]*TaskComment), 4)
+	})
 	t.Run("no access to task", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
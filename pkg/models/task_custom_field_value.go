@@ -0,0 +1,686 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskCustomFieldValue holds the value one task has for a custom field of its project.
+// Multiselect fields have one row per selected option.
+type TaskCustomFieldValue struct {
+	ID      int64 `xorm:"bigint autoincr not null unique pk"`
+	TaskID  int64 `xorm:"bigint not null INDEX"`
+	FieldID int64 `xorm:"bigint not null INDEX"`
+
+	// Holds the value of text, select and multiselect fields
+	ValueText string `xorm:"text null"`
+	// Holds the value of number fields and the user id of user fields
+	ValueNumber float64 `xorm:"double null"`
+	// Holds the value of date fields
+	ValueDate time.Time `xorm:"DATETIME null 'value_date'"`
+}
+
+// TableName returns the table name for task custom field values
+func (*TaskCustomFieldValue) TableName() string {
+	return "task_custom_field_values"
+}
+
+const customFieldTaskPropertyPrefix = "custom_field_"
+
+var customFieldTaskPropertyRegex = regexp.MustCompile(`^` + customFieldTaskPropertyPrefix + `(\d+)$`)
+
+// getCustomFieldIDFromTaskProperty returns the id of the custom field if the property is
+// something like custom_field_12, as used in filters and when sorting.
+func getCustomFieldIDFromTaskProperty(property string) (fieldID int64, is bool) {
+	match := customFieldTaskPropertyRegex.FindStringSubmatch(property)
+	if len(match) != 2 {
+		return 0, false
+	}
+
+	fieldID, err := strconv.ParseInt(match[1], 10, 64)
+	return fieldID, err == nil
+}
+
+// Returns the value as it is shown in the custom_fields property of a task
+func (cf *ProjectCustomField) getTaskValue(values []*TaskCustomFieldValue) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	switch cf.Type {
+	case ProjectCustomFieldTypeNumber:
+		return values[0].ValueNumber
+	case ProjectCustomFieldTypeDate:
+		return values[0].ValueDate
+	case ProjectCustomFieldTypeUser:
+		return int64(values[0].ValueNumber)
+	case ProjectCustomFieldTypeMultiSelect:
+		options := make([]string, 0, len(values))
+		for _, v := range values {
+			options = append(options, v.ValueText)
+		}
+		return options
+	case ProjectCustomFieldTypeText, ProjectCustomFieldTypeSelect:
+		fallthrough
+	default:
+		return values[0].ValueText
+	}
+}
+
+func getNumberFromCustomFieldValue(value interface{}) (number float64, ok bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+
+	return 0, false
+}
+
+// Converts a value as it was sent for a task into rows to store. No rows mean the value should be removed.
+//
+//nolint:gocyclo
+func (cf *ProjectCustomField) getRowsFromTaskValue(s *xorm.Session, project *Project, value interface{}) (rows []*TaskCustomFieldValue, err error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	invalid := &ErrInvalidCustomFieldValue{
+		CustomFieldID: cf.ID,
+		Value:         value,
+	}
+
+	switch cf.Type {
+	case ProjectCustomFieldTypeText:
+		text, is := value.(string)
+		if !is {
+			return nil, invalid
+		}
+		if text == "" {
+			return nil, nil
+		}
+		rows = append(rows, &TaskCustomFieldValue{ValueText: text})
+	case ProjectCustomFieldTypeNumber:
+		number, is := getNumberFromCustomFieldValue(value)
+		if !is {
+			return nil, invalid
+		}
+		rows = append(rows, &TaskCustomFieldValue{ValueNumber: number})
+	case ProjectCustomFieldTypeDate:
+		var date time.Time
+		switch v := value.(type) {
+		case time.Time:
+			date = v
+		case string:
+			if v == "" {
+				return nil, nil
+			}
+			date, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, invalid
+			}
+		default:
+			return nil, invalid
+		}
+		if date.IsZero() {
+			return nil, nil
+		}
+		rows = append(rows, &TaskCustomFieldValue{ValueDate: date})
+	case ProjectCustomFieldTypeSelect:
+		option, is := value.(string)
+		if !is {
+			return nil, invalid
+		}
+		if option == "" {
+			return nil, nil
+		}
+		if !cf.hasOption(option) {
+			return nil, invalid
+		}
+		rows = append(rows, &TaskCustomFieldValue{ValueText: option})
+	case ProjectCustomFieldTypeMultiSelect:
+		var options []string
+		switch v := value.(type) {
+		case []string:
+			options = v
+		case []interface{}:
+			for _, o := range v {
+				option, is := o.(string)
+				if !is {
+					return nil, invalid
+				}
+				options = append(options, option)
+			}
+		default:
+			return nil, invalid
+		}
+		seen := make(map[string]bool, len(options))
+		for _, option := range options {
+			if seen[option] {
+				continue
+			}
+			if !cf.hasOption(option) {
+				return nil, invalid
+			}
+			seen[option] = true
+			rows = append(rows, &TaskCustomFieldValue{ValueText: option})
+		}
+	case ProjectCustomFieldTypeUser:
+		number, is := getNumberFromCustomFieldValue(value)
+		if !is || number != float64(int64(number)) {
+			return nil, invalid
+		}
+		if number == 0 {
+			return nil, nil
+		}
+		u, err := user.GetUserByID(s, int64(number))
+		if err != nil {
+			return nil, err
+		}
+		canRead, _, err := project.CanRead(s, u)
+		if err != nil {
+			return nil, err
+		}
+		if !canRead {
+			return nil, ErrUserDoesNotHaveAccessToProject{ProjectID: project.ID, UserID: u.ID}
+		}
+		rows = append(rows, &TaskCustomFieldValue{ValueNumber: number})
+	default:
+		return nil, invalid
+	}
+
+	return rows, nil
+}
+
+// updateCustomFieldValues saves the values of all custom fields in the map for the task.
+// Fields which are not part of the map keep their value, fields with a nil value are removed from the task.
+// Afterwards, the custom fields of the task are set to all values it now has.
+func (t *Task) updateCustomFieldValues(s *xorm.Session, values map[int64]interface{}) (err error) {
+	var project *Project
+	if len(values) > 0 {
+		project, err = GetProjectSimpleByID(s, t.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for fieldID, value := range values {
+		field, err := getCustomFieldByIDAndProject(s, fieldID, t.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := field.getRowsFromTaskValue(s, project, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.
+			Where("task_id = ? AND field_id = ?", t.ID, fieldID).
+			Delete(&TaskCustomFieldValue{})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			row.TaskID = t.ID
+			row.FieldID = fieldID
+		}
+
+		if len(rows) > 0 {
+			_, err = s.Insert(&rows)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	taskValues, err := getCustomFieldValueMap(s, []int64{t.ID})
+	if err != nil {
+		return err
+	}
+	t.CustomFields = taskValues[t.ID]
+	return nil
+}
+
+// removeCustomFieldValuesFromOtherProjects removes all values of a task for custom fields which do not belong to
+// the task's project. This is used when a task is moved to another project.
+func removeCustomFieldValuesFromOtherProjects(s *xorm.Session, task *Task) (err error) {
+	_, err = s.
+		Where("task_id = ?", task.ID).
+		And(builder.NotIn("field_id", builder.Select("id").From("project_custom_fields").Where(builder.Eq{"project_id": task.ProjectID}))).
+		Delete(&TaskCustomFieldValue{})
+	return
+}
+
+func getCustomFieldValueMap(s *xorm.Session, taskIDs []int64) (taskValues map[int64]map[int64]interface{}, err error) {
+	taskValues = make(map[int64]map[int64]interface{})
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	rows := []*TaskCustomFieldValue{}
+	err = s.
+		In("task_id", taskIDs).
+		OrderBy("id asc").
+		Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	fieldIDs := []int64{}
+	rowsByTaskAndField := make(map[int64]map[int64][]*TaskCustomFieldValue)
+	for _, row := range rows {
+		if _, has := rowsByTaskAndField[row.TaskID]; !has {
+			rowsByTaskAndField[row.TaskID] = make(map[int64][]*TaskCustomFieldValue)
+		}
+		if _, has := rowsByTaskAndField[row.TaskID][row.FieldID]; !has {
+			fieldIDs = append(fieldIDs, row.FieldID)
+		}
+		rowsByTaskAndField[row.TaskID][row.FieldID] = append(rowsByTaskAndField[row.TaskID][row.FieldID], row)
+	}
+
+	fields, err := getCustomFieldsByIDs(s, fieldIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for taskID, valuesByField := range rowsByTaskAndField {
+		for fieldID, values := range valuesByField {
+			field, has := fields[fieldID]
+			if !has {
+				continue
+			}
+			if _, has := taskValues[taskID]; !has {
+				taskValues[taskID] = make(map[int64]interface{})
+			}
+			taskValues[taskID][fieldID] = field.getTaskValue(values)
+		}
+	}
+
+	return
+}
+
+func addCustomFieldValuesToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) (err error) {
+	taskValues, err := getCustomFieldValueMap(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	for taskID, values := range taskValues {
+		if task, has := taskMap[taskID]; has {
+			task.CustomFields = values
+		}
+	}
+
+	return
+}
+
+// customFieldFilterValue holds a filter value for a custom field in every representation the field could be
+// stored in. The type of the field is only checked in the database, a representation is nil if the value
+// could not be parsed into it.
+type customFieldFilterValue struct {
+	text   interface{}
+	number interface{}
+	date   interface{}
+}
+
+func getCustomFieldFilterValue(comparator taskFilterComparator, rawValue string, loc *time.Location) (value *customFieldFilterValue, err error) {
+	rawValues := []string{rawValue}
+	isList := comparator == taskFilterComparatorIn || comparator == taskFilterComparatorNotIn
+	if isList {
+		rawValues = strings.Split(rawValue, ",")
+	}
+
+	valueType := reflect.TypeOf(TaskCustomFieldValue{})
+	numberField, _ := valueType.FieldByName("ValueNumber")
+	dateField, _ := valueType.FieldByName("ValueDate")
+
+	texts := make([]interface{}, 0, len(rawValues))
+	numbers := make([]interface{}, 0, len(rawValues))
+	dates := make([]interface{}, 0, len(rawValues))
+	for _, raw := range rawValues {
+		raw = strings.TrimSpace(raw)
+		texts = append(texts, raw)
+
+		number, err := getValueForField(numberField, raw, loc)
+		if err == nil {
+			numbers = append(numbers, number)
+		}
+
+		date, err := getValueForField(dateField, raw, loc)
+		if err == nil {
+			dates = append(dates, date)
+		}
+	}
+
+	value = &customFieldFilterValue{}
+	if isList {
+		value.text = texts
+		if len(numbers) == len(rawValues) {
+			value.number = numbers
+		}
+		if len(dates) == len(rawValues) {
+			value.date = dates
+		}
+		return
+	}
+
+	value.text = texts[0]
+	if len(numbers) == 1 {
+		value.number = numbers[0]
+	}
+	if len(dates) == 1 {
+		value.date = dates[0]
+	}
+	return
+}
+
+func collectCustomFieldFilters(filters []*taskFilter, fieldFilters map[int64][]*taskFilter) {
+	for _, f := range filters {
+		if nested, is := f.value.([]*taskFilter); is {
+			collectCustomFieldFilters(nested, fieldFilters)
+			continue
+		}
+		if fieldID, is := getCustomFieldIDFromTaskProperty(f.field); is {
+			fieldFilters[fieldID] = append(fieldFilters[fieldID], f)
+		}
+	}
+}
+
+// resolveCustomFieldUsernames makes it possible to filter user fields by username, like the assignees. Because the
+// fields store the id of the user, the usernames are replaced with the ids of their users. Unknown usernames are
+// replaced with 0 so that they match no task.
+func resolveCustomFieldUsernames(s *xorm.Session, filters []*taskFilter) (err error) {
+	fieldFilters := make(map[int64][]*taskFilter)
+	collectCustomFieldFilters(filters, fieldFilters)
+	if len(fieldFilters) == 0 {
+		return nil
+	}
+
+	fieldIDs := make([]int64, 0, len(fieldFilters))
+	for fieldID := range fieldFilters {
+		fieldIDs = append(fieldIDs, fieldID)
+	}
+	fields, err := getCustomFieldsByIDs(s, fieldIDs)
+	if err != nil {
+		return err
+	}
+
+	for fieldID, filters := range fieldFilters {
+		field, has := fields[fieldID]
+		if !has || field.Type != ProjectCustomFieldTypeUser {
+			continue
+		}
+
+		for _, f := range filters {
+			value, is := f.value.(*customFieldFilterValue)
+			// Values which are numbers already are user ids
+			if !is || value.number != nil || !strictComparators[f.comparator] {
+				continue
+			}
+
+			var usernames []string
+			switch v := value.text.(type) {
+			case string:
+				usernames = []string{v}
+			case []interface{}:
+				for _, username := range v {
+					usernames = append(usernames, username.(string))
+				}
+			}
+
+			users, err := user.GetUsersByUsername(s, usernames, false)
+			if err != nil {
+				return err
+			}
+			ids := make(map[string]float64, len(usernames))
+			for _, username := range usernames {
+				// Values of a list which are not all usernames can still contain user ids
+				ids[username], _ = strconv.ParseFloat(username, 64)
+			}
+			for _, u := range users {
+				ids[u.Username] = float64(u.ID)
+			}
+
+			if _, isList := value.text.([]interface{}); isList {
+				numbers := make([]interface{}, 0, len(usernames))
+				for _, username := range usernames {
+					numbers = append(numbers, ids[username])
+				}
+				value.number = numbers
+				continue
+			}
+			value.number = ids[usernames[0]]
+		}
+	}
+
+	return nil
+}
+
+func getCustomFieldBaseSubQuery(fieldID int64) *builder.Builder {
+	return builder.
+		Select("1").
+		From("task_custom_field_values").
+		Join("INNER", "project_custom_fields", "project_custom_fields.id = task_custom_field_values.field_id").
+		Where(builder.And(
+			builder.Expr("task_custom_field_values.task_id = tasks.id"),
+			builder.Eq{"task_custom_field_values.field_id": fieldID},
+		))
+}
+
+func getCustomFieldFilterCond(fieldID int64, f *taskFilter, includeNulls bool) (cond builder.Cond, err error) {
+	value, is := f.value.(*customFieldFilterValue)
+	if !is {
+		return nil, ErrInvalidTaskFilterValue{Field: f.field, Value: f.value}
+	}
+
+	comparator := f.comparator
+	if strictComparators[comparator] {
+		comparator = taskFilterComparatorIn
+	}
+
+	// Since the field type is only known in the database, every representation of the value is
+	// compared with the column of the field types it could apply to.
+	getTypedCond := func(column string, v interface{}, types ...ProjectCustomFieldType) (builder.Cond, error) {
+		if comparator == taskFilterComparatorIn {
+			if _, is := v.([]interface{}); !is {
+				v = []interface{}{v}
+			}
+		}
+		valueCond, err := getFilterCond(&taskFilter{
+			field:      "task_custom_field_values." + column,
+			value:      v,
+			comparator: comparator,
+		}, false)
+		if err != nil {
+			return nil, err
+		}
+		typeValues := make([]interface{}, 0, len(types))
+		for _, t := range types {
+			typeValues = append(typeValues, int(t))
+		}
+		return builder.And(builder.In("project_custom_fields.type", typeValues), valueCond), nil
+	}
+
+	conds := []builder.Cond{}
+	textCond, err := getTypedCond("value_text", value.text, ProjectCustomFieldTypeText, ProjectCustomFieldTypeSelect, ProjectCustomFieldTypeMultiSelect)
+	if err != nil {
+		return nil, err
+	}
+	conds = append(conds, textCond)
+
+	if value.number != nil && comparator != taskFilterComparatorLike {
+		numberCond, err := getTypedCond("value_number", value.number, ProjectCustomFieldTypeNumber, ProjectCustomFieldTypeUser)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, numberCond)
+	}
+
+	if value.date != nil && comparator != taskFilterComparatorLike {
+		dateCond, err := getTypedCond("value_date", value.date, ProjectCustomFieldTypeDate)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, dateCond)
+	}
+
+	subQuery := getCustomFieldBaseSubQuery(fieldID).And(builder.Or(conds...))
+
+	if f.comparator == taskFilterComparatorNotEquals || f.comparator == taskFilterComparatorNotIn {
+		cond = builder.NotExists(subQuery)
+	} else {
+		cond = builder.Exists(subQuery)
+	}
+
+	if includeNulls {
+		cond = builder.Or(cond, builder.NotExists(getCustomFieldBaseSubQuery(fieldID)))
+	}
+
+	return cond, nil
+}
+
+var customFieldSortColumns = []string{"value_number", "value_date", "value_text"}
+
+// getCustomFieldSortColumns returns the aliases of the columns added by getCustomFieldSortSelects.
+// Only one of them holds a value for a given field type, so sorting by all of them in turn sorts by the field.
+func getCustomFieldSortColumns(fieldID int64) (columns []string) {
+	for _, column := range customFieldSortColumns {
+		columns = append(columns, fmt.Sprintf("%s%d_%s", customFieldTaskPropertyPrefix, fieldID, column))
+	}
+	return
+}
+
+func getCustomFieldSortParams(opts *taskSearchOptions) (fieldIDs []int64) {
+	for _, param := range opts.sortby {
+		fieldID, is := getCustomFieldIDFromTaskProperty(param.sortBy)
+		if is {
+			fieldIDs = append(fieldIDs, fieldID)
+		}
+	}
+	return
+}
+
+// getCustomFieldSortSelects returns the columns which need to be selected to sort tasks by custom fields.
+// They come from the tables joined with joinCustomFieldSortTables.
+func getCustomFieldSortSelects(opts *taskSearchOptions) (selects string) {
+	for _, fieldID := range getCustomFieldSortParams(opts) {
+		aliases := getCustomFieldSortColumns(fieldID)
+		for i, column := range customFieldSortColumns {
+			selects += fmt.Sprintf(", %s%d.%s AS %s", customFieldTaskPropertyPrefix, fieldID, column, aliases[i])
+		}
+	}
+
+	return
+}
+
+// joinCustomFieldSortTables joins one row per task with the values of each custom field the tasks are sorted by.
+// Multiselect fields can have more than one value per task, only the first one is used for sorting.
+// The field ids are parsed from the sort parameter and therefore safe to put into the query.
+func joinCustomFieldSortTables(query *xorm.Session, opts *taskSearchOptions) *xorm.Session {
+	for _, fieldID := range getCustomFieldSortParams(opts) {
+		alias := fmt.Sprintf("%s%d", customFieldTaskPropertyPrefix, fieldID)
+		query = query.Join(
+			"LEFT",
+			fmt.Sprintf(
+				"(SELECT task_id, MIN(value_number) AS value_number, MIN(value_date) AS value_date, MIN(value_text) AS value_text "+
+					"FROM task_custom_field_values WHERE field_id = %d GROUP BY task_id) %s",
+				fieldID,
+				alias,
+			),
+			alias+".task_id = tasks.id",
+		)
+	}
+
+	return query
+}
+
+func duplicateCustomFields(s *xorm.Session, fromProjectID, toProjectID int64, doer web.Auth) (fieldMap map[int64]int64, err error) {
+	fields, err := getCustomFieldsForProject(s, fromProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldMap = make(map[int64]int64, len(fields))
+	for _, field := range fields {
+		oldID := field.ID
+		field.ProjectID = toProjectID
+		err = field.Create(s, doer)
+		if err != nil {
+			return nil, err
+		}
+		fieldMap[oldID] = field.ID
+	}
+
+	return
+}
+
+func duplicateCustomFieldValues(s *xorm.Session, taskMap map[int64]int64, fieldMap map[int64]int64) (err error) {
+	oldTaskIDs := make([]int64, 0, len(taskMap))
+	for oldID := range taskMap {
+		oldTaskIDs = append(oldTaskIDs, oldID)
+	}
+
+	if len(oldTaskIDs) == 0 {
+		return nil
+	}
+
+	values := []*TaskCustomFieldValue{}
+	err = s.In("task_id", oldTaskIDs).Find(&values)
+	if err != nil {
+		return err
+	}
+
+	newValues := make([]*TaskCustomFieldValue, 0, len(values))
+	for _, v := range values {
+		fieldID, has := fieldMap[v.FieldID]
+		if !has {
+			continue
+		}
+		v.ID = 0
+		v.TaskID = taskMap[v.TaskID]
+		v.FieldID = fieldID
+		newValues = append(newValues, v)
+	}
+
+	if len(newValues) == 0 {
+		return nil
+	}
+
+	_, err = s.Insert(&newValues)
+	return
+}
@@ -0,0 +1,101 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// getBlockedTasksSubQuery returns a query for the ids of all tasks which are blocked by at least one task which is not done.
+func getBlockedTasksSubQuery() *builder.Builder {
+	return builder.
+		Select("task_relations.task_id").
+		From("task_relations").
+		Join("INNER", "tasks blocking_tasks", "blocking_tasks.id = task_relations.other_task_id").
+		Where(builder.And(
+			builder.Eq{"task_relations.relation_kind": RelationKindBlocked},
+			builder.Eq{"blocking_tasks.done": false},
+		))
+}
+
+// getOpenBlockingTaskIDs returns the ids of all tasks which block the given task and are not done.
+func getOpenBlockingTaskIDs(s *xorm.Session, taskID int64) (taskIDs []int64, err error) {
+	taskIDs = []int64{}
+	err = s.
+		Table("task_relations").
+		Join("INNER", "tasks", "tasks.id = task_relations.other_task_id").
+		Where(builder.And(
+			builder.Eq{"task_relations.task_id": taskID},
+			builder.Eq{"task_relations.relation_kind": RelationKindBlocked},
+			builder.Eq{"tasks.done": false},
+		)).
+		OrderBy("task_relations.other_task_id asc").
+		Cols("task_relations.other_task_id").
+		Find(&taskIDs)
+	return
+}
+
+// checkTaskCanBeDone returns an error if the task's project enforces dependencies and the task is still blocked
+// by other tasks which are not done.
+func checkTaskCanBeDone(s *xorm.Session, t *Task) (err error) {
+	project, err := GetProjectSimpleByID(s, t.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if !project.EnforceDependencies {
+		return nil
+	}
+
+	blockingTaskIDs, err := getOpenBlockingTaskIDs(s, t.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(blockingTaskIDs) > 0 {
+		return &ErrTaskIsBlocked{
+			TaskID:         t.ID,
+			BlockedByTasks: blockingTaskIDs,
+		}
+	}
+
+	return nil
+}
+
+func addIsBlockedToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) (err error) {
+	blockedTaskIDs := []int64{}
+	err = s.
+		Table("tasks").
+		Where(builder.And(
+			builder.In("id", taskIDs),
+			builder.In("id", getBlockedTasksSubQuery()),
+		)).
+		Cols("id").
+		Find(&blockedTaskIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, taskID := range blockedTaskIDs {
+		if t, has := taskMap[taskID]; has {
+			t.IsBlocked = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+// blockTask makes task 1 blocked by the given task and enables the enforcement of dependencies in project 1.
+func blockTask(t *testing.T, s *xorm.Session, blockingTaskID int64, enforce bool) {
+	rel := &TaskRelation{
+		TaskID:       1,
+		OtherTaskID:  blockingTaskID,
+		RelationKind: RelationKindBlocked,
+	}
+	err := rel.Create(s, &user.User{ID: 1})
+	require.NoError(t, err)
+
+	_, err = s.ID(1).Cols("enforce_dependencies").Update(&Project{EnforceDependencies: enforce})
+	require.NoError(t, err)
+}
+
+func TestTask_Update_Dependencies(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("blocked by open task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, true)
+
+		task := &Task{
+			ID:        1,
+			Title:     "task #1",
+			Done:      true,
+			ProjectID: 1,
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+		assert.Equal(t, []int64{3}, err.(*ErrTaskIsBlocked).BlockedByTasks)
+	})
+	t.Run("blocked by done task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 2, true)
+
+		task := &Task{
+			ID:        1,
+			Title:     "task #1",
+			Done:      true,
+			ProjectID: 1,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		assert.True(t, task.Done)
+	})
+	t.Run("not enforced", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, false)
+
+		task := &Task{
+			ID:        1,
+			Title:     "task #1",
+			Done:      true,
+			ProjectID: 1,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		assert.True(t, task.Done)
+	})
+	t.Run("bulk", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, true)
+
+		bt := &BulkTask{
+			IDs:  []int64{1, 4},
+			Task: Task{Done: true},
+		}
+		err := bt.GetTasksByIDs(s)
+		require.NoError(t, err)
+		err = bt.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+	})
+	t.Run("move to done bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, true)
+
+		tb := &TaskBucket{
+			TaskID:        1,
+			BucketID:      3,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+	})
+}
+
+func TestTask_IsBlocked(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("read one", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, false)
+
+		task := &Task{ID: 1}
+		err := task.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.True(t, task.IsBlocked)
+
+		task = &Task{ID: 3}
+		err = task.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.False(t, task.IsBlocked)
+	})
+	t.Run("filter blocked", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, false)
+
+		tc := &TaskCollection{
+			ProjectID: 1,
+			Filter:    "is_blocked = true",
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.NoError(t, err)
+		tasks := result.([]*Task)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, int64(1), tasks[0].ID)
+		assert.True(t, tasks[0].IsBlocked)
+	})
+	t.Run("filter not blocked", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		blockTask(t, s, 3, false)
+
+		tc := &TaskCollection{
+			ProjectID: 1,
+			Filter:    "is_blocked != true",
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.NoError(t, err)
+		tasks := result.([]*Task)
+		assert.NotEmpty(t, tasks)
+		for _, task := range tasks {
+			assert.NotEqual(t, int64(1), task.ID)
+		}
+	})
+	t.Run("filter with invalid comparator", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID: 1,
+			Filter:    "is_blocked > true",
+		}
+		_, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterComparator(err))
+	})
+}
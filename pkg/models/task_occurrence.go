@@ -0,0 +1,168 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// The number of the most recent occurrences returned with a single task.
+const taskReadOneOccurrencesLimit = 10
+
+// TaskOccurrence is a completed occurrence of a repeating task.
+// Every time a repeating task is marked as done, the dates it had at that point are saved as an occurrence
+// before they are moved to the next one. Occurrences cannot be changed once they are created.
+type TaskOccurrence struct {
+	// The unique, numeric id of this occurrence.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+
+	// The due date of the task when this occurrence was completed.
+	DueDate time.Time `xorm:"DATETIME null 'due_date'" json:"due_date"`
+	// The start date of the task when this occurrence was completed.
+	StartDate time.Time `xorm:"DATETIME null 'start_date'" json:"start_date"`
+	// The end date of the task when this occurrence was completed.
+	EndDate time.Time `xorm:"DATETIME null 'end_date'" json:"end_date"`
+
+	// The time when this occurrence was marked as done.
+	DoneAt time.Time `xorm:"DATETIME not null INDEX 'done_at'" json:"done_at"`
+	// The user who marked this occurrence as done.
+	DoneBy   *user.User `xorm:"-" json:"done_by"`
+	DoneByID int64      `xorm:"bigint not null" json:"-"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task occurrences
+func (*TaskOccurrence) TableName() string {
+	return "task_occurrences"
+}
+
+// saveTaskOccurrence records the current dates of a repeating task as a completed occurrence.
+// It must be called with the task as it was before its dates were moved to the next occurrence.
+func saveTaskOccurrence(s *xorm.Session, a web.Auth, task *Task, doneAt time.Time) (err error) {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	occurrence := &TaskOccurrence{
+		TaskID:    task.ID,
+		DueDate:   task.DueDate,
+		StartDate: task.StartDate,
+		EndDate:   task.EndDate,
+		DoneAt:    doneAt,
+	}
+	if doer != nil {
+		occurrence.DoneByID = doer.ID
+	}
+
+	_, err = s.Insert(occurrence)
+	return
+}
+
+// ReadAll returns all completed occurrences of a task
+// @Summary Get all completed occurrences of a task
+// @Description Returns all completed occurrences of a repeating task, the most recent first. The user doing this need to have at least read access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.TaskOccurrence "The completed occurrences of the task"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/occurrences [get]
+func (to *TaskOccurrence) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+
+	canRead, _, err := to.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	occurrences, err := getOccurrencesForTasks(s, []int64{to.TaskID}, page, perPage)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.
+		Where("task_id = ?", to.TaskID).
+		Count(&TaskOccurrence{})
+	return occurrences, len(occurrences), numberOfTotalItems, err
+}
+
+func getOccurrencesForTasks(s *xorm.Session, taskIDs []int64, page int, perPage int) (occurrences []*TaskOccurrence, err error) {
+	occurrences = []*TaskOccurrence{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	query := s.
+		Where(builder.In("task_id", taskIDs)).
+		OrderBy("done_at desc, id desc")
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&occurrences)
+	if err != nil {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(occurrences))
+	for _, o := range occurrences {
+		userIDs = append(userIDs, o.DoneByID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, o := range occurrences {
+		o.DoneBy = users[o.DoneByID]
+	}
+
+	return
+}
+
+func addOccurrencesToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) (err error) {
+	occurrences, err := getOccurrencesForTasks(s, taskIDs, -1, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range occurrences {
+		if task, exists := taskMap[o.TaskID]; exists {
+			task.Occurrences = append(task.Occurrences, o)
+		}
+	}
+
+	return nil
+}
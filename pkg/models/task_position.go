@@ -162,6 +162,11 @@ func RecalculateTaskPositions(s *xorm.Session, view *ProjectView, a web.Auth) (e
 		opts.projectIDs = append(opts.projectIDs, p.ID)
 	}
 
+	err = resolveCustomFieldUsernames(s, opts.parsedFilters)
+	if err != nil {
+		return err
+	}
+
 	dbSearcher := &dbTaskSearcher{
 		s: s,
 		a: a,
@@ -208,7 +213,7 @@ func RecalculateTaskPositions(s *xorm.Session, view *ProjectView, a web.Auth) (e
 
 	log.Debugf("Inserted %d new positions for %d total tasks in view %d", count, len(allTasks), view.ID)
 
-	return events.Dispatch(&TaskPositionsRecalculatedEvent{
+	return events.DispatchOnCommit(s, &TaskPositionsRecalculatedEvent{
 		NewTaskPositions: newPositions,
 	})
 }
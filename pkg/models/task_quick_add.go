@@ -0,0 +1,309 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/modules/quickadd"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/xorm"
+)
+
+// QuickAddTask creates a task from a single line of text like "Buy milk tomorrow *groceries @alice +Home !3".
+type QuickAddTask struct {
+	// The project the task is created in, unless the text contains another one.
+	ProjectID int64 `json:"-" param:"project"`
+	// The text to parse.
+	Text string `json:"text" valid:"required"`
+	// Which prefixes to use: `vikunja` (*label, +project, @assignee, !priority), `todoist` (@label, #project, +assignee, !priority) or `disabled` to not parse anything. Defaults to the quick add magic mode of the user's frontend settings or `vikunja`.
+	Mode quickadd.Mode `json:"mode"`
+	// If true, the text is only parsed and the task is returned without saving it. Labels which don't exist yet will have an id of 0.
+	ParseOnly bool `json:"parse_only"`
+
+	// The created or parsed task.
+	Task *Task `json:"task"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// CanCreate checks if a user can create a task from a text
+func (q *QuickAddTask) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return (&Project{ID: q.ProjectID}).CanWrite(s, a)
+}
+
+// Create parses a text into a task and creates it
+// @Summary Create a task from text
+// @Description Parses a text like "Buy milk tomorrow *groceries @alice +Home !3" into a task and creates it. Dates (relative like "tomorrow", "next week" or "in 3 days" and absolute like "2021-02-17" or "Feb 17", optionally with a time like "at 15:00") are parsed in the user's time zone and respecting their week start. Labels which don't exist yet are created, assignees which don't exist or don't have access to the project stay in the title. Repeat intervals like "every 2 weeks" or "every monday" are parsed as well.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Project ID"
+// @Param task body models.QuickAddTask true "The text to parse"
+// @Success 201 {object} models.QuickAddTask "The parsed text with the created task."
+// @Failure 400 {object} web.HTTPError "Invalid quick add mode or the text was empty."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project"
+// @Failure 404 {object} web.HTTPError "The project in the text does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{id}/tasks/quick [put]
+func (q *QuickAddTask) Create(s *xorm.Session, a web.Auth) (err error) {
+	opts, err := getQuickAddOptions(s, a, q.Mode)
+	if err != nil {
+		return err
+	}
+	q.Mode = opts.Mode
+
+	result := quickadd.Parse(q.Text, opts)
+
+	// Keep the whole text if nothing but magic was in it
+	if result.Title == "" {
+		result = quickadd.Parse(q.Text, &quickadd.Options{Mode: quickadd.ModeDisabled})
+	}
+
+	task := &Task{
+		Title:     result.Title,
+		ProjectID: q.ProjectID,
+		Priority:  result.Priority,
+		DueDate:   result.Date,
+	}
+	setQuickAddRepeat(task, result.Repeat)
+
+	if result.Project != "" {
+		task.ProjectID, err = findQuickAddProject(s, a, result.Project)
+		if err != nil {
+			return err
+		}
+	}
+
+	task.Assignees, err = findQuickAddAssignees(s, task.ProjectID, result.Assignees)
+	if err != nil {
+		return err
+	}
+	found := make([]string, 0, len(task.Assignees))
+	for _, u := range task.Assignees {
+		found = append(found, u.Username)
+	}
+	task.Title = quickadd.RemoveAssignees(task.Title, q.Mode, found)
+
+	labels, err := findQuickAddLabels(s, a, result.Labels, !q.ParseOnly)
+	if err != nil {
+		return err
+	}
+
+	q.Task = task
+	if q.ParseOnly {
+		task.Labels = labels
+		return nil
+	}
+
+	err = task.Create(s, a)
+	if err != nil {
+		return err
+	}
+
+	if len(labels) > 0 {
+		err = task.UpdateTaskLabels(s, a, labels)
+	}
+	return err
+}
+
+func getQuickAddOptions(s *xorm.Session, a web.Auth, mode quickadd.Mode) (opts *quickadd.Options, err error) {
+	opts = &quickadd.Options{
+		Mode: mode,
+		Now:  time.Now().In(config.GetTimeZone()),
+	}
+
+	if mode != "" && !mode.IsValid() {
+		return nil, &ErrInvalidQuickAddMode{Mode: string(mode)}
+	}
+
+	// Link shares have no settings
+	if _, is := a.(*LinkSharing); is {
+		if opts.Mode == "" {
+			opts.Mode = quickadd.ModeVikunja
+		}
+		return opts, nil
+	}
+
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Mode == "" {
+		opts.Mode = quickadd.ModeVikunja
+		if settings, is := u.FrontendSettings.(map[string]interface{}); is {
+			if m, is := settings["quickAddMagicMode"].(string); is && quickadd.Mode(m).IsValid() {
+				opts.Mode = quickadd.Mode(m)
+			}
+		}
+	}
+
+	if u.Timezone != "" {
+		tz, err := time.LoadLocation(u.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		opts.Now = opts.Now.In(tz)
+	}
+	opts.WeekStart = time.Weekday(u.WeekStart)
+
+	return opts, nil
+}
+
+// setQuickAddRepeat sets the repeat interval like the frontend does. Months and years don't have a fixed length,
+// they use the monthly repeat mode or a repeat rule instead.
+func setQuickAddRepeat(task *Task, repeat *quickadd.Repeat) {
+	if repeat == nil {
+		return
+	}
+
+	switch {
+	case repeat.Weekday != nil:
+		task.RepeatRule = "FREQ=WEEKLY;INTERVAL=" + strconv.FormatInt(repeat.Amount, 10) + ";BYDAY=" + strings.ToUpper(repeat.Weekday.String()[:2])
+	case repeat.Unit == quickadd.RepeatUnitMonth && repeat.Amount == 1:
+		task.RepeatMode = TaskRepeatModeMonth
+	case repeat.Unit == quickadd.RepeatUnitMonth:
+		task.RepeatRule = "FREQ=MONTHLY;INTERVAL=" + strconv.FormatInt(repeat.Amount, 10)
+	case repeat.Unit == quickadd.RepeatUnitYear:
+		task.RepeatRule = "FREQ=YEARLY;INTERVAL=" + strconv.FormatInt(repeat.Amount, 10)
+	default:
+		task.RepeatAfter = int64(repeat.Duration().Seconds())
+	}
+}
+
+// findQuickAddProject returns the id of the project with the title, the user needs write access to it.
+func findQuickAddProject(s *xorm.Session, a web.Auth, title string) (projectID int64, err error) {
+	if ls, is := a.(*LinkSharing); is {
+		project, err := GetProjectSimpleByID(s, ls.ProjectID)
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(project.Title, title) {
+			return project.ID, nil
+		}
+		return 0, &ErrQuickAddProjectDoesNotExist{Title: title}
+	}
+
+	projects, _, _, err := getRawProjectsForUser(s, &projectOptions{
+		user: &user.User{ID: a.GetID()},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, project := range projects {
+		if project.ID < 0 || !strings.EqualFold(project.Title, title) {
+			continue
+		}
+
+		canWrite, err := project.CanWrite(s, a)
+		if err != nil {
+			return 0, err
+		}
+		if canWrite {
+			return project.ID, nil
+		}
+	}
+
+	return 0, &ErrQuickAddProjectDoesNotExist{Title: title}
+}
+
+// findQuickAddAssignees returns all users with one of the usernames which have access to the project.
+func findQuickAddAssignees(s *xorm.Session, projectID int64, usernames []string) (assignees []*user.User, err error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	users, err := user.GetUsersByUsername(s, usernames, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		canRead, _, err := (&Project{ID: projectID}).CanRead(s, u)
+		if err != nil {
+			return nil, err
+		}
+		if canRead {
+			assignees = append(assignees, u)
+		}
+	}
+
+	return assignees, nil
+}
+
+// findQuickAddLabels returns the labels with the titles the user has access to. Labels which don't exist yet are
+// created if create is true, link shares can't create labels.
+func findQuickAddLabels(s *xorm.Session, a web.Auth, titles []string, create bool) (labels []*Label, err error) {
+	if len(titles) == 0 {
+		return nil, nil
+	}
+
+	existing, _, _, err := GetLabelsByTaskIDs(s, &LabelByTaskIDsOptions{
+		User:                a,
+		Search:              titles,
+		GetUnusedLabels:     true,
+		GroupByLabelIDsOnly: true,
+		GetForUser:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, isLinkShare := a.(*LinkSharing)
+
+	seen := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		if seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+
+		var label *Label
+		for _, l := range existing {
+			if strings.EqualFold(l.Title, title) {
+				label = &l.Label
+				break
+			}
+		}
+
+		if label == nil {
+			if isLinkShare {
+				continue
+			}
+			label = &Label{Title: title}
+			if create {
+				err = label.Create(s, a)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
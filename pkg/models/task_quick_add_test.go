@@ -0,0 +1,229 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/quickadd"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickAddTask_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{
+			ProjectID: 1,
+			Text:      `Lorem ipsum tomorrow *"Label #1" *newlabel @user1 @user2 !3`,
+		}
+		err := q.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, quickadd.ModeVikunja, q.Mode)
+		require.NotNil(t, q.Task)
+		assert.NotZero(t, q.Task.ID)
+		// user2 has no access to the project
+		assert.Equal(t, "Lorem ipsum @user2", q.Task.Title)
+		assert.Equal(t, int64(1), q.Task.ProjectID)
+		assert.Equal(t, int64(3), q.Task.Priority)
+
+		tomorrow := time.Now().In(config.GetTimeZone()).AddDate(0, 0, 1)
+		assert.Equal(t, time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, config.GetTimeZone()).Unix(), q.Task.DueDate.Unix())
+
+		require.Len(t, q.Task.Assignees, 1)
+		assert.Equal(t, int64(1), q.Task.Assignees[0].ID)
+		require.Len(t, q.Task.Labels, 2)
+		assert.Equal(t, int64(1), q.Task.Labels[0].ID)
+		assert.Equal(t, "newlabel", q.Task.Labels[1].Title)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         q.Task.ID,
+			"title":      "Lorem ipsum @user2",
+			"project_id": 1,
+			"priority":   3,
+		}, false)
+		db.AssertExists(t, "labels", map[string]interface{}{
+			"title":         "newlabel",
+			"created_by_id": 1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  q.Task.ID,
+			"label_id": 1,
+		}, false)
+		db.AssertExists(t, "task_assignees", map[string]interface{}{
+			"task_id": q.Task.ID,
+			"user_id": 1,
+		}, false)
+	})
+	t.Run("parse only", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{
+			ProjectID: 1,
+			Text:      "Lorem ipsum *newlabel",
+			ParseOnly: true,
+		}
+		err := q.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Zero(t, q.Task.ID)
+		assert.Equal(t, "Lorem ipsum", q.Task.Title)
+		require.Len(t, q.Task.Labels, 1)
+		assert.Zero(t, q.Task.Labels[0].ID)
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"title": "Lorem ipsum",
+		})
+		db.AssertMissing(t, "labels", map[string]interface{}{
+			"title": "newlabel",
+		})
+	})
+	t.Run("other project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{
+			ProjectID: 1,
+			Text:      "Lorem ipsum +test10",
+		}
+		err := q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), q.Task.ProjectID)
+		assert.Equal(t, "Lorem ipsum", q.Task.Title)
+	})
+	t.Run("project without write access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{
+			ProjectID: 1,
+			Text:      "Lorem ipsum +Test3",
+		}
+		err := q.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrQuickAddProjectDoesNotExist(err))
+	})
+	t.Run("repeating", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{ProjectID: 1, Text: "Lorem every 2 weeks", ParseOnly: true}
+		err := q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(14*24*60*60), q.Task.RepeatAfter)
+		assert.False(t, q.Task.DueDate.IsZero())
+
+		q = &QuickAddTask{ProjectID: 1, Text: "Lorem every month", ParseOnly: true}
+		err = q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, TaskRepeatModeMonth, q.Task.RepeatMode)
+
+		q = &QuickAddTask{ProjectID: 1, Text: "Lorem every monday"}
+		err = q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", q.Task.RepeatRule)
+		assert.Equal(t, time.Monday, q.Task.DueDate.In(config.GetTimeZone()).Weekday())
+	})
+	t.Run("only magic", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{ProjectID: 1, Text: "tomorrow", ParseOnly: true}
+		err := q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, "tomorrow", q.Task.Title)
+		assert.True(t, q.Task.DueDate.IsZero())
+	})
+	t.Run("invalid mode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		q := &QuickAddTask{ProjectID: 1, Text: "Lorem", Mode: "invalid"}
+		err := q.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidQuickAddMode(err))
+	})
+	t.Run("mode from the frontend settings", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Exec("UPDATE users SET frontend_settings = ? WHERE id = ?", `{"quickAddMagicMode":"todoist"}`, 1)
+		require.NoError(t, err)
+
+		q := &QuickAddTask{ProjectID: 1, Text: "Lorem *ipsum @dolor", ParseOnly: true}
+		err = q.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, quickadd.ModeTodoist, q.Mode)
+		assert.Equal(t, "Lorem *ipsum", q.Task.Title)
+		require.Len(t, q.Task.Labels, 1)
+		assert.Equal(t, "dolor", q.Task.Labels[0].Title)
+	})
+	t.Run("user time zone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("timezone").
+			Update(&user.User{Timezone: "Pacific/Kiritimati"})
+		require.NoError(t, err)
+
+		q := &QuickAddTask{ProjectID: 1, Text: "Lorem today at 9:00", ParseOnly: true}
+		err = q.Create(s, u)
+		require.NoError(t, err)
+
+		tz, err := time.LoadLocation("Pacific/Kiritimati")
+		require.NoError(t, err)
+		now := time.Now().In(tz)
+		assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, tz).Unix(), q.Task.DueDate.Unix())
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{ID: 2, ProjectID: 1, Permission: PermissionWrite}
+		q := &QuickAddTask{ProjectID: 1, Text: "Lorem *newlabel +test1", ParseOnly: true}
+		err := q.Create(s, share)
+		require.NoError(t, err)
+		assert.Equal(t, "Lorem", q.Task.Title)
+		assert.Empty(t, q.Task.Labels)
+	})
+}
@@ -0,0 +1,307 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskReference is a backlink from a task description or comment to a task it mentions with its identifier,
+// like #42 or PROJ-17.
+type TaskReference struct {
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The task which is referenced.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id"`
+	// The task whose description or comment contains the reference.
+	SourceTaskID int64 `xorm:"bigint not null INDEX" json:"source_task_id"`
+	// The comment which contains the reference. 0 if the reference is part of the description of the source task.
+	CommentID int64 `xorm:"bigint not null default 0 INDEX" json:"comment_id"`
+	// The task whose description or comment contains the reference.
+	SourceTask *Task `xorm:"-" json:"source_task"`
+
+	CreatedByID int64 `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when the reference was first found.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for task references
+func (*TaskReference) TableName() string {
+	return "task_references"
+}
+
+var (
+	// Matches html tags so that things like colors in style attributes are not considered a reference
+	taskReferenceHTMLTagRegex = regexp.MustCompile(`<[^>]*>`)
+	// #42 references the task with index 42 in the same project. Html entities like &#39; and url fragments are ignored.
+	taskReferenceIndexRegex = regexp.MustCompile(`(?:^|[^\w&/#])#(\d+)\b`)
+	// PROJ-17 references the task with index 17 in the project with the identifier PROJ.
+	taskReferenceIdentifierRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_-])([\p{L}\p{N}_]{1,10})-(\d+)\b`)
+)
+
+type taskReferenceMatch struct {
+	identifier string
+	index      int64
+}
+
+func findTaskReferenceMatches(text string) (matches []*taskReferenceMatch) {
+	text = taskReferenceHTMLTagRegex.ReplaceAllString(text, " ")
+
+	for _, match := range taskReferenceIndexRegex.FindAllStringSubmatch(text, -1) {
+		index, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, &taskReferenceMatch{index: index})
+	}
+
+	for _, match := range taskReferenceIdentifierRegex.FindAllStringSubmatch(text, -1) {
+		index, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, &taskReferenceMatch{identifier: match[1], index: index})
+	}
+
+	return
+}
+
+// FindReferencedTasksInText returns all tasks referenced in a text, mapped by their id. The index of references
+// without a project identifier is resolved in the project of the source task. Only tasks the doer can read are
+// returned and the source task never references itself.
+// Identifiers are only resolved to projects the doer can read. If more than one of them shares an identifier,
+// the project of the source task wins, otherwise the one with the lowest id.
+func FindReferencedTasksInText(s *xorm.Session, text string, sourceTask *Task, doer *user.User) (tasks map[int64]*Task, err error) {
+	tasks = make(map[int64]*Task)
+
+	matches := findTaskReferenceMatches(text)
+	if len(matches) == 0 {
+		return
+	}
+
+	identifiers := []string{}
+	for _, match := range matches {
+		if match.identifier != "" {
+			identifiers = append(identifiers, match.identifier)
+		}
+	}
+
+	projectIDsByIdentifier := make(map[string]int64)
+	if len(identifiers) > 0 {
+		projects := []*Project{}
+		err = s.
+			In("identifier", identifiers).
+			OrderBy("id ASC").
+			Find(&projects)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			_, resolved := projectIDsByIdentifier[p.Identifier]
+			if resolved && p.ID != sourceTask.ProjectID {
+				continue
+			}
+
+			canRead, err := canDoerReadReferencedProject(s, doer, sourceTask, p.ID)
+			if err != nil {
+				return nil, err
+			}
+			if canRead {
+				projectIDsByIdentifier[p.Identifier] = p.ID
+			}
+		}
+	}
+
+	conds := []builder.Cond{}
+	for _, match := range matches {
+		projectID := sourceTask.ProjectID
+		if match.identifier != "" {
+			var has bool
+			projectID, has = projectIDsByIdentifier[match.identifier]
+			if !has {
+				continue
+			}
+		}
+		conds = append(conds, builder.Eq{"project_id": projectID, "`index`": match.index})
+	}
+	if len(conds) == 0 {
+		return
+	}
+
+	found := []*Task{}
+	err = s.Where(builder.Or(conds...)).Find(&found)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range found {
+		if t.ID != sourceTask.ID {
+			tasks[t.ID] = t
+		}
+	}
+
+	return
+}
+
+func canDoerReadReferencedProject(s *xorm.Session, doer *user.User, sourceTask *Task, projectID int64) (bool, error) {
+	if projectID == sourceTask.ProjectID {
+		return true, nil
+	}
+
+	// Link shares only have access to the project of the source task
+	if doer == nil || doer.ID <= 0 {
+		return false, nil
+	}
+
+	canRead, _, err := (&Project{ID: projectID}).CanRead(s, doer)
+	if IsErrProjectDoesNotExist(err) {
+		return false, nil
+	}
+	return canRead, err
+}
+
+// updateTaskReferences replaces all references stored for the description (commentID 0) or a comment of the source
+// task with the ones found in text. It returns the tasks which were not referenced before.
+func updateTaskReferences(s *xorm.Session, sourceTask *Task, commentID int64, text string, doer *user.User) (added []*Task, err error) {
+	referenced, err := FindReferencedTasksInText(s, text, sourceTask, doer)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := []*TaskReference{}
+	err = s.
+		Where("source_task_id = ? AND comment_id = ?", sourceTask.ID, commentID).
+		Find(&existing)
+	if err != nil {
+		return nil, err
+	}
+
+	existingTaskIDs := make(map[int64]bool, len(existing))
+	removedIDs := []int64{}
+	for _, ref := range existing {
+		existingTaskIDs[ref.TaskID] = true
+		if _, has := referenced[ref.TaskID]; !has {
+			removedIDs = append(removedIDs, ref.ID)
+		}
+	}
+
+	if len(removedIDs) > 0 {
+		_, err = s.In("id", removedIDs).Delete(&TaskReference{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var createdByID int64
+	if doer != nil {
+		createdByID = doer.ID
+	}
+
+	for _, t := range referenced {
+		if existingTaskIDs[t.ID] {
+			continue
+		}
+
+		_, err = s.Insert(&TaskReference{
+			TaskID:       t.ID,
+			SourceTaskID: sourceTask.ID,
+			CommentID:    commentID,
+			CreatedByID:  createdByID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, t)
+	}
+
+	return
+}
+
+func deleteTaskReferencesForComment(s *xorm.Session, commentID int64) (err error) {
+	_, err = s.Where("comment_id = ?", commentID).Delete(&TaskReference{})
+	return
+}
+
+// getTaskMentionedIn returns all references to a task from tasks the user can read, oldest first.
+func getTaskMentionedIn(s *xorm.Session, a web.Auth, taskID int64) (references []*TaskReference, err error) {
+	all := []*TaskReference{}
+	err = s.
+		Where("task_id = ?", taskID).
+		OrderBy("created asc, id asc").
+		Find(&all)
+	if err != nil || len(all) == 0 {
+		return nil, err
+	}
+
+	sourceTaskIDs := make([]int64, 0, len(all))
+	for _, ref := range all {
+		sourceTaskIDs = append(sourceTaskIDs, ref.SourceTaskID)
+	}
+
+	// Trashed tasks are not found here, references from them are hidden until they are restored.
+	sourceTasks, err := GetTasksSimpleByIDs(s, sourceTaskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceTaskMap := make(map[int64]*Task, len(sourceTasks))
+	projectIDs := []int64{}
+	for _, t := range sourceTasks {
+		sourceTaskMap[t.ID] = t
+		projectIDs = append(projectIDs, t.ProjectID)
+	}
+
+	projects, err := GetProjectsMapByIDs(s, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	canReadProject := make(map[int64]bool)
+	references = []*TaskReference{}
+	for _, ref := range all {
+		sourceTask, has := sourceTaskMap[ref.SourceTaskID]
+		if !has {
+			continue
+		}
+
+		canRead, checked := canReadProject[sourceTask.ProjectID]
+		if !checked {
+			canRead, _, err = (&Project{ID: sourceTask.ProjectID}).CanRead(s, a)
+			if err != nil && !IsErrProjectDoesNotExist(err) {
+				return nil, err
+			}
+			canReadProject[sourceTask.ProjectID] = canRead
+		}
+		if !canRead {
+			continue
+		}
+
+		sourceTask.setIdentifier(projects[sourceTask.ProjectID])
+		ref.SourceTask = sourceTask
+		references = append(references, ref)
+	}
+
+	return references, nil
+}
@@ -0,0 +1,226 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindReferencedTasksInText(t *testing.T) {
+	const text = `<p>See #2 and test3-1, not test2-1 or #999.</p>
+<p>Isn&#39;t <span style="color:#3">colored</span>, https://example.com/#4 and #1 are ignored.</p>`
+
+	t.Run("user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tasks, err := FindReferencedTasksInText(s, text, &Task{ID: 1, ProjectID: 1}, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 2)
+		assert.Contains(t, tasks, int64(2))
+		assert.Contains(t, tasks, int64(32))
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tasks, err := FindReferencedTasksInText(s, text, &Task{ID: 1, ProjectID: 1}, &user.User{ID: -2})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Contains(t, tasks, int64(2))
+	})
+	t.Run("shared identifier", func(t *testing.T) {
+		t.Run("ignores projects the doer cannot read", func(t *testing.T) {
+			db.LoadAndAssertFixtures(t)
+			s := db.NewSession()
+			defer s.Close()
+
+			// Project 2 uses test2 as well but user 1 has no access to it
+			_, err := s.Where("id = ?", 3).Cols("identifier").Update(&Project{Identifier: "test2"})
+			require.NoError(t, err)
+
+			tasks, err := FindReferencedTasksInText(s, "test2-1", &Task{ID: 1, ProjectID: 1}, &user.User{ID: 1})
+			require.NoError(t, err)
+			assert.Len(t, tasks, 1)
+			assert.Contains(t, tasks, int64(32))
+		})
+		t.Run("lowest project id wins", func(t *testing.T) {
+			db.LoadAndAssertFixtures(t)
+			s := db.NewSession()
+			defer s.Close()
+
+			_, err := s.Where("id = ?", 3).Cols("identifier").Update(&Project{Identifier: "test1"})
+			require.NoError(t, err)
+
+			tasks, err := FindReferencedTasksInText(s, "test1-1", &Task{ID: 2, ProjectID: 2}, &user.User{ID: 1})
+			require.NoError(t, err)
+			assert.Len(t, tasks, 1)
+			assert.Contains(t, tasks, int64(1))
+		})
+		t.Run("project of the source task wins", func(t *testing.T) {
+			db.LoadAndAssertFixtures(t)
+			s := db.NewSession()
+			defer s.Close()
+
+			_, err := s.Where("id = ?", 3).Cols("identifier").Update(&Project{Identifier: "test1"})
+			require.NoError(t, err)
+
+			tasks, err := FindReferencedTasksInText(s, "test1-1", &Task{ID: 33, ProjectID: 3}, &user.User{ID: 1})
+			require.NoError(t, err)
+			assert.Len(t, tasks, 1)
+			assert.Contains(t, tasks, int64(32))
+		})
+	})
+	t.Run("no references", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tasks, err := FindReferencedTasksInText(s, "Lorem ipsum", &Task{ID: 1, ProjectID: 1}, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+}
+
+func TestUpdateTaskReferences(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	u := &user.User{ID: 1}
+	source := &Task{ID: 1, ProjectID: 1}
+
+	// Task 32 is already referenced by task 1
+	added, err := updateTaskReferences(s, source, 0, "#2 and test3-1", u)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	assert.Equal(t, int64(2), added[0].ID)
+
+	added, err = updateTaskReferences(s, source, 0, "#2 and #5", u)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	assert.Equal(t, int64(5), added[0].ID)
+	err = s.Commit()
+	require.NoError(t, err)
+
+	db.AssertExists(t, "task_references", map[string]interface{}{
+		"task_id":        2,
+		"source_task_id": 1,
+		"comment_id":     0,
+		"created_by_id":  1,
+	}, false)
+	db.AssertExists(t, "task_references", map[string]interface{}{
+		"task_id":        5,
+		"source_task_id": 1,
+	}, false)
+	db.AssertMissing(t, "task_references", map[string]interface{}{
+		"task_id": 32,
+	})
+}
+
+func TestUpdateTaskReferences_Listeners(t *testing.T) {
+	t.Run("description", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		referenced := &Task{Title: "Referenced", ProjectID: 3}
+		err := referenced.Create(s, u)
+		require.NoError(t, err)
+		_, err = s.Insert(&Subscription{EntityType: SubscriptionEntityTask, EntityID: referenced.ID, UserID: 2})
+		require.NoError(t, err)
+		_, err = s.
+			Where("id = ?", 32).
+			Cols("description").
+			Update(&Task{Description: "<p>Relates to #" + strconv.FormatInt(referenced.Index, 10) + "</p>"})
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		events.TestListener(t, &TaskUpdatedEvent{Task: &Task{ID: 32}, Doer: u}, &UpdateTaskReferences{})
+
+		db.AssertExists(t, "task_references", map[string]interface{}{
+			"task_id":        referenced.ID,
+			"source_task_id": 32,
+			"comment_id":     0,
+		}, false)
+		db.AssertExists(t, "notifications", map[string]interface{}{
+			"notifiable_id": 2,
+			"subject_id":    32,
+			"name":          (&TaskReferencedNotification{}).Name(),
+		}, false)
+	})
+	t.Run("comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		tc := &TaskComment{Comment: "Same as #2", TaskID: 1}
+		err := tc.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		events.TestListener(t, &TaskCommentCreatedEvent{Task: &Task{ID: 1}, Comment: tc, Doer: u}, &UpdateTaskCommentReferences{})
+
+		db.AssertExists(t, "task_references", map[string]interface{}{
+			"task_id":        2,
+			"source_task_id": 1,
+			"comment_id":     tc.ID,
+		}, false)
+
+		err = tc.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "task_references", map[string]interface{}{
+			"comment_id": tc.ID,
+		})
+	})
+}
+
+func TestTask_ReadOne_MentionedIn(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	task := &Task{ID: 32}
+	err := task.ReadOne(s, &user.User{ID: 1})
+	require.NoError(t, err)
+	require.Len(t, task.MentionedIn, 1)
+	assert.Equal(t, int64(1), task.MentionedIn[0].SourceTask.ID)
+	assert.Equal(t, "test1-1", task.MentionedIn[0].SourceTask.Identifier)
+
+	// User 2 can read task 32 but not the project of task 1
+	task = &Task{ID: 32}
+	err = task.ReadOne(s, &user.User{ID: 2})
+	require.NoError(t, err)
+	assert.Empty(t, task.MentionedIn)
+}
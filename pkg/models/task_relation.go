@@ -240,8 +240,10 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		RelationKind: getInverseRelation(rel.RelationKind),
 	}
 
-	// If we're creating a subtask relation, check if we're about to create a cycle
-	if rel.RelationKind == RelationKindSubtask || rel.RelationKind == RelationKindParenttask {
+	// If we're creating a subtask, blocking or precedes relation, check if we're about to create a cycle
+	if rel.RelationKind == RelationKindSubtask || rel.RelationKind == RelationKindParenttask ||
+		rel.RelationKind == RelationKindBlocking || rel.RelationKind == RelationKindBlocked ||
+		rel.RelationKind == RelationKindPreceeds || rel.RelationKind == RelationKindFollows {
 		err = checkTaskRelationCycle(s, rel, rel.OtherTaskID, nil, nil)
 		if err != nil {
 			return err
@@ -257,13 +259,18 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	err = recordRelationActivity(s, a, rel, true)
+	if err != nil {
+		return err
+	}
+
 	doer, _ := user.GetFromAuth(a)
 	task, err := GetTaskByIDSimple(s, rel.TaskID)
 	if err != nil {
 		return err
 	}
 
-	return events.Dispatch(&TaskRelationCreatedEvent{
+	return events.DispatchOnCommit(s, &TaskRelationCreatedEvent{
 		Task:     &task,
 		Relation: rel,
 		Doer:     doer,
@@ -322,15 +329,78 @@ func (rel *TaskRelation) Delete(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	err = recordRelationActivity(s, a, rel, false)
+	if err != nil {
+		return err
+	}
+
 	doer, _ := user.GetFromAuth(a)
 	task, err := GetTaskByIDSimple(s, rel.TaskID)
 	if err != nil {
 		return err
 	}
 
-	return events.Dispatch(&TaskRelationDeletedEvent{
+	return events.DispatchOnCommit(s, &TaskRelationDeletedEvent{
 		Task:     &task,
 		Relation: rel,
 		Doer:     doer,
 	})
 }
+
+// recordRelationActivity records a created or removed relation in the activity history of both tasks.
+func recordRelationActivity(s *xorm.Session, a web.Auth, rel *TaskRelation, created bool) error {
+	sides := []*TaskRelation{
+		rel,
+		{
+			TaskID:       rel.OtherTaskID,
+			OtherTaskID:  rel.TaskID,
+			RelationKind: getInverseRelation(rel.RelationKind),
+		},
+	}
+
+	for _, side := range sides {
+		change := &TaskActivityChange{Field: "relations"}
+		value := getRelationActivityValue(side.OtherTaskID, side.RelationKind)
+		if created {
+			change.NewValue = value
+		} else {
+			change.OldValue = value
+		}
+
+		err := recordTaskChanges(s, a, side.TaskID, change)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTasksWithSubtasksSubQuery returns a query for the ids of all tasks which have at least one subtask.
+func getTasksWithSubtasksSubQuery() *builder.Builder {
+	return builder.
+		Select("task_relations.task_id").
+		From("task_relations").
+		Where(builder.Eq{"task_relations.relation_kind": RelationKindSubtask})
+}
+
+// subtasksDonePercentSQL calculates the percentage of done subtasks of a task. It is NULL for tasks without subtasks.
+const subtasksDonePercentSQL = "(SELECT 100.0 * SUM(CASE WHEN subtasks.done THEN 1 ELSE 0 END) / NULLIF(COUNT(*), 0) " +
+	"FROM task_relations subtask_relations " +
+	"INNER JOIN tasks subtasks ON subtasks.id = subtask_relations.other_task_id " +
+	"WHERE subtask_relations.task_id = tasks.id AND subtask_relations.relation_kind = '" + string(RelationKindSubtask) + "')"
+
+// getTaskIDsDependingOnTask returns the ids of all tasks whose derived state, like being blocked or the percentage
+// of done subtasks, depends on the given task.
+func getTaskIDsDependingOnTask(s *xorm.Session, taskID int64) (taskIDs []int64, err error) {
+	taskIDs = []int64{}
+	err = s.
+		Table("task_relations").
+		Where(builder.And(
+			builder.Eq{"other_task_id": taskID},
+			builder.In("relation_kind", RelationKindBlocked, RelationKindSubtask),
+		)).
+		Cols("task_id").
+		Find(&taskIDs)
+	return
+}
@@ -56,6 +56,8 @@ type TaskReminder struct {
 	RelativePeriod int64 `xorm:"bigint null" json:"relative_period"`
 	// The name of the date field to which the relative period refers to.
 	RelativeTo ReminderRelation `xorm:"varchar(50) null" json:"relative_to"`
+	// If set, the reminder is sent again every this many seconds until the task is done. Default: 0, the reminder is only sent once.
+	RepeatInterval int64 `xorm:"bigint null" json:"repeat_interval"`
 }
 
 // TableName returns a pretty table name
@@ -171,8 +173,15 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 	reminders := []*TaskReminder{}
 	err = s.
 		Join("INNER", "tasks", "tasks.id = task_reminders.task_id").
-		// All reminders from -12h to +14h to include all time zones
-		Where("reminder >= ? and reminder < ?", now.Add(time.Hour*-12).Format(dbTimeFormat), nextMinute.Add(time.Hour*14).Format(dbTimeFormat)).
+		// All reminders from -12h to +14h to include all time zones.
+		// Repeating reminders are sent until the task is done, no matter when they started.
+		Where(builder.And(
+			builder.Lt{"reminder": nextMinute.Add(time.Hour * 14).Format(dbTimeFormat)},
+			builder.Or(
+				builder.Gte{"reminder": now.Add(time.Hour * -12).Format(dbTimeFormat)},
+				builder.Gt{"repeat_interval": 0},
+			),
+		)).
 		And("tasks.done = false").
 		Find(&reminders)
 	if err != nil {
@@ -206,6 +215,11 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 
 	seen := make(map[int64]map[int64]bool)
 
+	snoozed, err := getSnoozedUsersForTasks(s, taskIDs, now)
+	if err != nil {
+		return
+	}
+
 	projects, err := GetProjectsMapSimpleByTaskIDs(s, taskIDs)
 	if err != nil {
 		return
@@ -227,7 +241,10 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 				continue
 			}
 
-			seen[r.TaskID][u.User.ID] = true
+			// Users who snoozed the reminders of the task get them when the snooze ends
+			if snoozed[r.TaskID][u.User.ID] {
+				continue
+			}
 
 			if u.User.Timezone == "" {
 				u.User.Timezone = config.GetTimeZone().String()
@@ -244,7 +261,8 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 			}
 
 			actualReminder := r.Reminder.In(tz)
-			if (actualReminder.After(now) && actualReminder.Before(now.Add(time.Minute))) || actualReminder.Equal(now) {
+			if isReminderDue(actualReminder, r.RepeatInterval, now) {
+				seen[r.TaskID][u.User.ID] = true
 				reminderNotifications = append(reminderNotifications, &ReminderDueNotification{
 					User:    u.User,
 					Task:    u.Task,
@@ -257,6 +275,130 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 	return
 }
 
+// isReminderDue checks if a reminder or one of its repetitions is due in the minute starting at now.
+func isReminderDue(reminder time.Time, repeatInterval int64, now time.Time) bool {
+	if (reminder.After(now) && reminder.Before(now.Add(time.Minute))) || reminder.Equal(now) {
+		return true
+	}
+
+	if repeatInterval <= 0 || reminder.After(now) {
+		return false
+	}
+
+	interval := time.Duration(repeatInterval) * time.Second
+	lastRepetition := reminder.Add(now.Sub(reminder) / interval * interval)
+	if lastRepetition.Equal(now) {
+		return true
+	}
+
+	return lastRepetition.Add(interval).Before(now.Add(time.Minute))
+}
+
+// getReminderRecipientsForTasks returns all users per task who get the reminders of that task. These are the creator,
+// assignees and subscribers of the task who have email reminders enabled.
+func getReminderRecipientsForTasks(s *xorm.Session, taskIDs []int64) (recipients map[int64]map[int64]*taskUser, err error) {
+	taskUsers, err := getTaskUsersForTasks(s, taskIDs, builder.Eq{"users.email_reminders_enabled": true})
+	if err != nil {
+		return nil, err
+	}
+
+	recipients = make(map[int64]map[int64]*taskUser)
+	for _, tu := range taskUsers {
+		if recipients[tu.Task.ID] == nil {
+			recipients[tu.Task.ID] = make(map[int64]*taskUser)
+		}
+		recipients[tu.Task.ID][tu.User.ID] = tu
+	}
+
+	return
+}
+
+// getSnoozedUsersForTasks returns all users per task who snoozed the reminders of that task.
+func getSnoozedUsersForTasks(s *xorm.Session, taskIDs []int64, now time.Time) (snoozed map[int64]map[int64]bool, err error) {
+	snoozes := []*TaskReminderSnooze{}
+	err = s.In("task_id", taskIDs).Find(&snoozes)
+	if err != nil {
+		return nil, err
+	}
+
+	snoozed = make(map[int64]map[int64]bool)
+	for _, snooze := range snoozes {
+		if snooze.SnoozedUntil.Before(now) {
+			continue
+		}
+		if snoozed[snooze.TaskID] == nil {
+			snoozed[snooze.TaskID] = make(map[int64]bool)
+		}
+		snoozed[snooze.TaskID][snooze.UserID] = true
+	}
+
+	return
+}
+
+// getSnoozedRemindersDue returns all snoozes which end in the minute starting at now or before and the notifications
+// which should be sent for them.
+func getSnoozedRemindersDue(s *xorm.Session, now time.Time) (snoozes []*TaskReminderSnooze, reminderNotifications []*ReminderDueNotification, err error) {
+	now = utils.GetTimeWithoutNanoSeconds(now)
+	nextMinute := now.Add(1 * time.Minute)
+
+	candidates := []*TaskReminderSnooze{}
+	err = s.
+		Where("snoozed_until < ?", nextMinute.Add(time.Hour*14).Format(dbTimeFormat)).
+		Find(&candidates)
+	if err != nil {
+		return
+	}
+
+	snoozes = []*TaskReminderSnooze{}
+	taskIDs := []int64{}
+	for _, snooze := range candidates {
+		if !snooze.SnoozedUntil.Before(nextMinute) {
+			continue
+		}
+		snoozes = append(snoozes, snooze)
+		taskIDs = append(taskIDs, snooze.TaskID)
+	}
+
+	if len(snoozes) == 0 {
+		return
+	}
+
+	// The users might have lost access to the task or disabled reminders since they snoozed them.
+	recipients, err := getReminderRecipientsForTasks(s, taskIDs)
+	if err != nil {
+		return
+	}
+
+	projects, err := GetProjectsMapSimpleByTaskIDs(s, taskIDs)
+	if err != nil {
+		return
+	}
+
+	reminderNotifications = []*ReminderDueNotification{}
+	for _, snooze := range snoozes {
+		recipient, has := recipients[snooze.TaskID][snooze.UserID]
+		if !has || recipient.Task.Done {
+			continue
+		}
+
+		can, _, err := (&Task{ID: snooze.TaskID}).CanRead(s, recipient.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !can {
+			continue
+		}
+
+		reminderNotifications = append(reminderNotifications, &ReminderDueNotification{
+			User:    recipient.User,
+			Task:    recipient.Task,
+			Project: projects[recipient.Task.ProjectID],
+		})
+	}
+
+	return
+}
+
 // RegisterReminderCron registers a cron function which runs every minute to check if any reminders are due the
 // next minute to send emails.
 func RegisterReminderCron() {
@@ -284,6 +426,25 @@ func RegisterReminderCron() {
 			return
 		}
 
+		snoozes, snoozedReminders, err := getSnoozedRemindersDue(s, now)
+		if err != nil {
+			log.Errorf("[Task Reminder Cron] Could not get snoozed reminders in the next minute: %s", err)
+			return
+		}
+		reminders = append(reminders, snoozedReminders...)
+
+		if len(snoozes) > 0 {
+			snoozeIDs := make([]int64, 0, len(snoozes))
+			for _, snooze := range snoozes {
+				snoozeIDs = append(snoozeIDs, snooze.ID)
+			}
+			_, err = s.In("id", snoozeIDs).Delete(&TaskReminderSnooze{})
+			if err != nil {
+				log.Errorf("[Task Reminder Cron] Could not delete snoozes: %s", err)
+				return
+			}
+		}
+
 		if len(reminders) == 0 {
 			return
 		}
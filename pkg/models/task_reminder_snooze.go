@@ -0,0 +1,149 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/xorm"
+)
+
+// TaskReminderSnoozePreset is a predefined duration to snooze a reminder for
+type TaskReminderSnoozePreset string
+
+// All available snooze presets
+const (
+	TaskReminderSnoozePreset10Minutes TaskReminderSnoozePreset = `10m`
+	TaskReminderSnoozePreset1Hour     TaskReminderSnoozePreset = `1h`
+	TaskReminderSnoozePresetTomorrow  TaskReminderSnoozePreset = `tomorrow`
+)
+
+// The hour in the user's time zone when a reminder snoozed until tomorrow fires again.
+const reminderSnoozeTomorrowHour = 9
+
+// TaskReminderSnooze postpones the reminders of a task for a single user.
+// The reminder is sent to the user again when the snooze ends. Until then, all other reminders of the task are
+// not sent to that user.
+type TaskReminderSnooze struct {
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+
+	// One of `10m`, `1h` or `tomorrow` (9am in the user's time zone). Only used when snoozed_until is not provided.
+	Preset TaskReminderSnoozePreset `xorm:"-" json:"preset"`
+	// The time when the reminder should be sent again.
+	SnoozedUntil time.Time `xorm:"DATETIME not null INDEX 'snoozed_until'" json:"snoozed_until"`
+
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for reminder snoozes
+func (*TaskReminderSnooze) TableName() string {
+	return "task_reminder_snoozes"
+}
+
+// ReminderSnoozeAction is a way to snooze a reminder right from its notification
+type ReminderSnoozeAction struct {
+	Preset TaskReminderSnoozePreset `json:"preset"`
+	// The api route to send a PUT request with the preset to.
+	URL string `json:"url"`
+}
+
+func getReminderSnoozeActions(taskID int64) []*ReminderSnoozeAction {
+	url := config.ServicePublicURL.GetString() + "api/v1/tasks/" + strconv.FormatInt(taskID, 10) + "/reminders/snooze"
+	return []*ReminderSnoozeAction{
+		{Preset: TaskReminderSnoozePreset10Minutes, URL: url},
+		{Preset: TaskReminderSnoozePreset1Hour, URL: url},
+		{Preset: TaskReminderSnoozePresetTomorrow, URL: url},
+	}
+}
+
+func getSnoozeTimeFromPreset(preset TaskReminderSnoozePreset, now time.Time, tz *time.Location) (until time.Time, err error) {
+	switch preset {
+	case TaskReminderSnoozePreset10Minutes:
+		return now.Add(10 * time.Minute), nil
+	case TaskReminderSnoozePreset1Hour:
+		return now.Add(time.Hour), nil
+	case TaskReminderSnoozePresetTomorrow:
+		local := now.In(tz)
+		return time.Date(local.Year(), local.Month(), local.Day()+1, reminderSnoozeTomorrowHour, 0, 0, 0, tz), nil
+	}
+
+	return time.Time{}, &ErrInvalidReminderSnoozePreset{Preset: preset}
+}
+
+// Create snoozes the reminders of a task for the current user
+// @Summary Snooze the reminders of a task
+// @Description Snoozes the reminders of a task for the current user. The reminder will be sent again at the provided time or after the duration of the preset. Until then, no other reminders of this task are sent to the user. Snoozing again replaces the previous snooze.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Task ID"
+// @Param snooze body models.TaskReminderSnooze true "Either a preset or the time until the reminder should be snoozed."
+// @Success 201 {object} models.TaskReminderSnooze "The snooze."
+// @Failure 400 {object} web.HTTPError "Invalid preset or time provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task or does not get its reminders."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/reminders/snooze [put]
+func (trs *TaskReminderSnooze) Create(s *xorm.Session, a web.Auth) (err error) {
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return err
+	}
+
+	now := utils.GetTimeWithoutNanoSeconds(time.Now())
+	if trs.SnoozedUntil.IsZero() {
+		tz := config.GetTimeZone()
+		if u.Timezone != "" {
+			tz, err = time.LoadLocation(u.Timezone)
+			if err != nil {
+				return err
+			}
+		}
+
+		trs.SnoozedUntil, err = getSnoozeTimeFromPreset(trs.Preset, now, tz)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !trs.SnoozedUntil.After(now) {
+		return &ErrReminderSnoozeInThePast{TaskID: trs.TaskID}
+	}
+
+	_, err = s.
+		Where("task_id = ? AND user_id = ?", trs.TaskID, u.ID).
+		Delete(&TaskReminderSnooze{})
+	if err != nil {
+		return err
+	}
+
+	trs.ID = 0
+	trs.UserID = u.ID
+	_, err = s.Insert(trs)
+	return
+}
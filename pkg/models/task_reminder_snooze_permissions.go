@@ -0,0 +1,44 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can snooze the reminders of a task
+func (trs *TaskReminderSnooze) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	// Only users get reminders
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	t := &Task{ID: trs.TaskID}
+	can, _, err := t.CanRead(s, a)
+	if err != nil || !can {
+		return false, err
+	}
+
+	// Only users who get the reminders of the task can snooze them
+	recipients, err := getReminderRecipientsForTasks(s, []int64{trs.TaskID})
+	if err != nil {
+		return false, err
+	}
+	_, is := recipients[trs.TaskID][a.GetID()]
+	return is, nil
+}
@@ -0,0 +1,116 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskReminderSnooze_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("preset", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		before := time.Now()
+		snooze := &TaskReminderSnooze{TaskID: 1, Preset: TaskReminderSnoozePreset10Minutes}
+		err := snooze.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.WithinDuration(t, before.Add(10*time.Minute), snooze.SnoozedUntil, time.Minute)
+		// Replaces the previous snooze
+		db.AssertMissing(t, "task_reminder_snoozes", map[string]interface{}{"id": 1})
+		db.AssertExists(t, "task_reminder_snoozes", map[string]interface{}{
+			"id":      snooze.ID,
+			"task_id": 1,
+			"user_id": 1,
+		}, false)
+	})
+	t.Run("invalid preset", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		snooze := &TaskReminderSnooze{TaskID: 1, Preset: "forever"}
+		err := snooze.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidReminderSnoozePreset(err))
+	})
+	t.Run("in the past", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		snooze := &TaskReminderSnooze{TaskID: 1, SnoozedUntil: time.Now().Add(-time.Hour)}
+		err := snooze.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrReminderSnoozeInThePast(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		snooze := &TaskReminderSnooze{TaskID: 14}
+		can, err := snooze.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("not a reminder recipient", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		snooze := &TaskReminderSnooze{TaskID: 1}
+		can, err := snooze.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+
+		_, err = s.Where("id = ?", 1).Cols("email_reminders_enabled").Update(&user.User{EmailRemindersEnabled: false})
+		require.NoError(t, err)
+
+		can, err = snooze.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestGetSnoozeTimeFromPreset(t *testing.T) {
+	tz, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2018, 12, 1, 23, 30, 0, 0, time.UTC)
+
+	until, err := getSnoozeTimeFromPreset(TaskReminderSnoozePresetTomorrow, now, tz)
+	require.NoError(t, err)
+	// 22:30 UTC is already the next day in Berlin
+	assert.Equal(t, time.Date(2018, 12, 3, 9, 0, 0, 0, tz), until)
+
+	until, err = getSnoozeTimeFromPreset(TaskReminderSnoozePreset1Hour, now, tz)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), until)
+}
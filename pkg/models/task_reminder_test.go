@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -51,3 +52,137 @@ func TestReminderGetTasksInTheNextMinute(t *testing.T) {
 		assert.Empty(t, taskIDs)
 	})
 }
+
+func TestReminderRepeatUntilDone(t *testing.T) {
+	t.Run("Found repetition", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("repeat_interval").Update(&TaskReminder{RepeatInterval: 3600})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T03:12:00Z")
+		require.NoError(t, err)
+		notifications, err := getTasksWithRemindersDueAndTheirUsers(s, now)
+		require.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.Equal(t, int64(27), notifications[0].Task.ID)
+	})
+	t.Run("Not between repetitions", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("repeat_interval").Update(&TaskReminder{RepeatInterval: 3600})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T03:42:00Z")
+		require.NoError(t, err)
+		notifications, err := getTasksWithRemindersDueAndTheirUsers(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, notifications)
+	})
+	t.Run("isReminderDue", func(t *testing.T) {
+		reminder := time.Date(2018, 12, 1, 1, 12, 4, 0, time.UTC)
+		at := func(hour, minute int) time.Time {
+			return time.Date(2018, 12, 1, hour, minute, 0, 0, time.UTC)
+		}
+
+		assert.True(t, isReminderDue(reminder, 0, at(1, 12)))
+		assert.False(t, isReminderDue(reminder, 0, at(2, 12)))
+		assert.True(t, isReminderDue(reminder, 1800, at(2, 12)))
+		assert.True(t, isReminderDue(reminder, 1800, at(2, 42)))
+		assert.False(t, isReminderDue(reminder, 1800, at(2, 30)))
+		assert.False(t, isReminderDue(reminder, 1800, at(1, 0)))
+	})
+}
+
+func TestReminderSnooze(t *testing.T) {
+	t.Run("Skips snoozed users", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T01:12:00Z")
+		require.NoError(t, err)
+		_, err = s.Insert(&TaskReminderSnooze{
+			TaskID:       27,
+			UserID:       1,
+			SnoozedUntil: now.Add(10 * time.Minute),
+		})
+		require.NoError(t, err)
+
+		notifications, err := getTasksWithRemindersDueAndTheirUsers(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, notifications)
+	})
+	t.Run("Sends when the snooze ends", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T01:19:30Z")
+		require.NoError(t, err)
+		snoozes, notifications, err := getSnoozedRemindersDue(s, now)
+		require.NoError(t, err)
+		require.Len(t, snoozes, 1)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, int64(1), notifications[0].Task.ID)
+		assert.Equal(t, int64(1), notifications[0].User.ID)
+
+		now, err = time.Parse(time.RFC3339Nano, "2018-12-01T01:15:00Z")
+		require.NoError(t, err)
+		snoozes, notifications, err = getSnoozedRemindersDue(s, now)
+		require.NoError(t, err)
+		assert.Empty(t, snoozes)
+		assert.Empty(t, notifications)
+	})
+	t.Run("Does not send when reminders are disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("email_reminders_enabled").Update(&user.User{EmailRemindersEnabled: false})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T01:19:30Z")
+		require.NoError(t, err)
+		snoozes, notifications, err := getSnoozedRemindersDue(s, now)
+		require.NoError(t, err)
+		// The snooze is still returned so that it gets removed
+		assert.Len(t, snoozes, 1)
+		assert.Empty(t, notifications)
+	})
+	t.Run("Does not send to users who no longer get the reminders", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("created_by_id").Update(&Task{CreatedByID: 2})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T01:19:30Z")
+		require.NoError(t, err)
+		snoozes, notifications, err := getSnoozedRemindersDue(s, now)
+		require.NoError(t, err)
+		assert.Len(t, snoozes, 1)
+		assert.Empty(t, notifications)
+	})
+	t.Run("Does not send to users who lost access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 1 is created by user 1, moving it to project 2 leaves them without access
+		_, err := s.Where("id = ?", 1).Cols("project_id").Update(&Task{ProjectID: 2})
+		require.NoError(t, err)
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-01T01:19:30Z")
+		require.NoError(t, err)
+		snoozes, notifications, err := getSnoozedRemindersDue(s, now)
+		require.NoError(t, err)
+		assert.Len(t, snoozes, 1)
+		assert.Empty(t, notifications)
+	})
+}
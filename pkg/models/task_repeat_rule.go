@@ -0,0 +1,557 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepeatRuleFrequency is the FREQ part of a recurrence rule
+type RepeatRuleFrequency string
+
+const (
+	RepeatRuleFrequencySecondly RepeatRuleFrequency = "SECONDLY"
+	RepeatRuleFrequencyMinutely RepeatRuleFrequency = "MINUTELY"
+	RepeatRuleFrequencyHourly   RepeatRuleFrequency = "HOURLY"
+	RepeatRuleFrequencyDaily    RepeatRuleFrequency = "DAILY"
+	RepeatRuleFrequencyWeekly   RepeatRuleFrequency = "WEEKLY"
+	RepeatRuleFrequencyMonthly  RepeatRuleFrequency = "MONTHLY"
+	RepeatRuleFrequencyYearly   RepeatRuleFrequency = "YEARLY"
+)
+
+// The maximum number of consecutive periods without an occurrence we look at when searching for the next occurrence
+// of a rule. This prevents endless loops for rules which never match (like BYMONTHDAY=31;BYMONTH=2).
+const maxRepeatRulePeriods = 1000
+
+var repeatRuleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var repeatRuleWeekdayNames = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// RepeatRuleWeekday is one entry of the BYDAY part of a recurrence rule, for example "-1FR" (the last friday).
+type RepeatRuleWeekday struct {
+	// 0 means every matching weekday in the period
+	Ordinal int
+	Weekday time.Weekday
+}
+
+func (w RepeatRuleWeekday) String() string {
+	if w.Ordinal == 0 {
+		return repeatRuleWeekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.Ordinal) + repeatRuleWeekdayNames[w.Weekday]
+}
+
+// RepeatRule is a parsed RFC 5545 recurrence rule.
+// Like in RFC 5545, COUNT is the number of occurrences of the whole series, including the first one.
+type RepeatRule struct {
+	Frequency RepeatRuleFrequency
+	Interval  int
+	Count     int
+	Until     time.Time
+	// Set when UNTIL only holds a date. The series then includes the whole day in the time zone of its start.
+	UntilIsDate bool
+	ByDay       []RepeatRuleWeekday
+	ByMonthDay  []int
+	ByMonth     []int
+	BySetPos    []int
+	WeekStart   time.Weekday
+}
+
+func parseRepeatRuleIntList(rule, part, value string, minValue, maxValue int) (list []int, err error) {
+	for _, v := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimPrefix(v, "+"))
+		if err != nil || i < minValue || i > maxValue || i == 0 {
+			return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid " + part + " value " + v}
+		}
+		list = append(list, i)
+	}
+	return
+}
+
+func parseRepeatRuleUntil(value string) (until time.Time, isDate bool, err error) {
+	formats := []string{"20060102T150405Z", "20060102T150405"}
+	for _, format := range formats {
+		until, err = time.Parse(format, value)
+		if err == nil {
+			return until, false, nil
+		}
+	}
+
+	until, err = time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return until, true, nil
+}
+
+// ParseRepeatRule parses an RFC 5545 RRULE value like "FREQ=MONTHLY;BYDAY=-1FR;COUNT=10".
+// The value may be prefixed with "RRULE:".
+func ParseRepeatRule(rule string) (r *RepeatRule, err error) {
+	value := strings.TrimSpace(rule)
+	value = strings.TrimPrefix(strings.ToUpper(value), "RRULE:")
+
+	r = &RepeatRule{
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, val, found := strings.Cut(part, "=")
+		if !found || val == "" {
+			return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid part " + part}
+		}
+
+		switch key {
+		case "FREQ":
+			switch RepeatRuleFrequency(val) {
+			case RepeatRuleFrequencySecondly,
+				RepeatRuleFrequencyMinutely,
+				RepeatRuleFrequencyHourly,
+				RepeatRuleFrequencyDaily,
+				RepeatRuleFrequencyWeekly,
+				RepeatRuleFrequencyMonthly,
+				RepeatRuleFrequencyYearly:
+				r.Frequency = RepeatRuleFrequency(val)
+			default:
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "unknown frequency " + val}
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err != nil || r.Interval < 1 {
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "interval must be a positive number"}
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err != nil || r.Count < 1 {
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "count must be a positive number"}
+			}
+		case "UNTIL":
+			r.Until, r.UntilIsDate, err = parseRepeatRuleUntil(val)
+			if err != nil {
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid until date " + val}
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				if len(day) < 2 {
+					return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid weekday " + day}
+				}
+				weekday, has := repeatRuleWeekdays[day[len(day)-2:]]
+				if !has {
+					return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid weekday " + day}
+				}
+				var ordinal int
+				if len(day) > 2 {
+					ordinal, err = strconv.Atoi(strings.TrimPrefix(day[:len(day)-2], "+"))
+					if err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+						return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid weekday " + day}
+					}
+				}
+				r.ByDay = append(r.ByDay, RepeatRuleWeekday{Ordinal: ordinal, Weekday: weekday})
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseRepeatRuleIntList(rule, key, val, -31, 31)
+			if err != nil {
+				return nil, err
+			}
+		case "BYMONTH":
+			r.ByMonth, err = parseRepeatRuleIntList(rule, key, val, 1, 12)
+			if err != nil {
+				return nil, err
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseRepeatRuleIntList(rule, key, val, -366, 366)
+			if err != nil {
+				return nil, err
+			}
+		case "WKST":
+			weekday, has := repeatRuleWeekdays[val]
+			if !has {
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "invalid week start " + val}
+			}
+			r.WeekStart = weekday
+		default:
+			return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "unsupported part " + key}
+		}
+	}
+
+	if r.Frequency == "" {
+		return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "the frequency is required"}
+	}
+
+	if r.Count != 0 && !r.Until.IsZero() {
+		return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "count and until cannot be used together"}
+	}
+
+	if r.Frequency != RepeatRuleFrequencyMonthly && r.Frequency != RepeatRuleFrequencyYearly {
+		for _, day := range r.ByDay {
+			if day.Ordinal != 0 {
+				return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "weekdays with a position are only allowed for monthly or yearly rules"}
+			}
+		}
+	}
+
+	if r.Frequency == RepeatRuleFrequencyWeekly && len(r.ByMonthDay) > 0 {
+		return nil, ErrInvalidRepeatRule{Rule: rule, Reason: "days of the month are not allowed for weekly rules"}
+	}
+
+	return r, nil
+}
+
+func joinRepeatRuleInts(list []int) string {
+	parts := make([]string, 0, len(list))
+	for _, i := range list {
+		parts = append(parts, strconv.Itoa(i))
+	}
+	return strings.Join(parts, ",")
+}
+
+// String returns the rule in its canonical RRULE value form, without the "RRULE:" prefix.
+func (r *RepeatRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinRepeatRuleInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinRepeatRuleInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinRepeatRuleInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+repeatRuleWeekdayNames[r.WeekStart])
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func containsInt(list []int, i int) bool {
+	for _, v := range list {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks the BY* parts which limit (rather than expand) the occurrences of a period.
+func (r *RepeatRule) matches(t time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(t.Month())) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := daysInMonth(t.Year(), t.Month())
+		var found bool
+		for _, md := range r.ByMonthDay {
+			if md == t.Day() || (md < 0 && days+md+1 == t.Day()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 {
+		var found bool
+		for _, day := range r.ByDay {
+			if day.Weekday == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns all days of a set of days which match the BYDAY part, respecting the ordinals.
+func (r *RepeatRule) filterDaysByWeekday(days []time.Time) (result []time.Time) {
+	for _, day := range r.ByDay {
+		var matching []time.Time
+		for _, d := range days {
+			if d.Weekday() == day.Weekday {
+				matching = append(matching, d)
+			}
+		}
+
+		switch {
+		case day.Ordinal == 0:
+			result = append(result, matching...)
+		case day.Ordinal > 0 && day.Ordinal <= len(matching):
+			result = append(result, matching[day.Ordinal-1])
+		case day.Ordinal < 0 && -day.Ordinal <= len(matching):
+			result = append(result, matching[len(matching)+day.Ordinal])
+		}
+	}
+
+	return
+}
+
+// Returns all occurrences of the rule in the given month.
+func (r *RepeatRule) expandMonth(start time.Time, year int, month time.Month) (result []time.Time) {
+	days := daysInMonth(year, month)
+	at := func(day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if start.Day() <= days {
+			result = append(result, at(start.Day()))
+		}
+		return
+	}
+
+	allDays := make([]time.Time, 0, days)
+	for d := 1; d <= days; d++ {
+		allDays = append(allDays, at(d))
+	}
+
+	if len(r.ByDay) == 0 {
+		for _, d := range allDays {
+			if r.matches(d) {
+				result = append(result, d)
+			}
+		}
+		return
+	}
+
+	for _, d := range r.filterDaysByWeekday(allDays) {
+		if r.matches(d) {
+			result = append(result, d)
+		}
+	}
+	return
+}
+
+// Returns all occurrences of the rule in the nth period after the period which contains start.
+func (r *RepeatRule) expandPeriod(start time.Time, n int) (result []time.Time) {
+	step := n * r.Interval
+
+	switch r.Frequency {
+	case RepeatRuleFrequencySecondly, RepeatRuleFrequencyMinutely, RepeatRuleFrequencyHourly:
+		candidate := start.Add(time.Duration(step) * r.unitDuration())
+		if r.matches(candidate) {
+			result = append(result, candidate)
+		}
+	case RepeatRuleFrequencyDaily:
+		candidate := start.AddDate(0, 0, step)
+		if r.matches(candidate) {
+			result = append(result, candidate)
+		}
+	case RepeatRuleFrequencyWeekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := start.AddDate(0, 0, step*7-offset)
+		for i := 0; i < 7; i++ {
+			candidate := weekStart.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && candidate.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matches(candidate) {
+				result = append(result, candidate)
+			}
+		}
+	case RepeatRuleFrequencyMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, start.Location())
+		result = r.expandMonth(start, first.Year(), first.Month())
+	case RepeatRuleFrequencyYearly:
+		year := start.Year() + step
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+			// Weekday positions are relative to the whole year in this case
+			var allDays []time.Time
+			for d := time.Date(year, time.January, 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location()); d.Year() == year; d = d.AddDate(0, 0, 1) {
+				allDays = append(allDays, d)
+			}
+			result = r.filterDaysByWeekday(allDays)
+			break
+		}
+
+		months := []int{int(start.Month())}
+		switch {
+		case len(r.ByMonth) > 0:
+			months = r.ByMonth
+		case len(r.ByMonthDay) > 0:
+			// Month days without months expand to every month of the year
+			months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		}
+		for _, m := range months {
+			result = append(result, r.expandMonth(start, year, time.Month(m))...)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Before(result[j])
+	})
+
+	return r.applySetPos(result)
+}
+
+func (r *RepeatRule) applySetPos(occurrences []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(occurrences) == 0 {
+		return occurrences
+	}
+
+	result := []time.Time{}
+	for _, pos := range r.BySetPos {
+		switch {
+		case pos > 0 && pos <= len(occurrences):
+			result = append(result, occurrences[pos-1])
+		case pos < 0 && -pos <= len(occurrences):
+			result = append(result, occurrences[len(occurrences)+pos])
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Before(result[j])
+	})
+	return result
+}
+
+func (r *RepeatRule) unitDuration() time.Duration {
+	switch r.Frequency {
+	case RepeatRuleFrequencySecondly:
+		return time.Second
+	case RepeatRuleFrequencyMinutely:
+		return time.Minute
+	case RepeatRuleFrequencyHourly:
+		return time.Hour
+	default:
+		return 0
+	}
+}
+
+// Returns the number of periods between start and t which can be skipped without missing an occurrence.
+func (r *RepeatRule) periodsToSkip(start, t time.Time) int {
+	if !t.After(start) {
+		return 0
+	}
+
+	var periods int
+	switch r.Frequency {
+	case RepeatRuleFrequencySecondly, RepeatRuleFrequencyMinutely, RepeatRuleFrequencyHourly:
+		periods = int(t.Sub(start) / r.unitDuration())
+	case RepeatRuleFrequencyDaily:
+		periods = int(t.Sub(start).Hours() / 24)
+	case RepeatRuleFrequencyWeekly:
+		periods = int(t.Sub(start).Hours() / 24 / 7)
+	case RepeatRuleFrequencyMonthly:
+		periods = (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	case RepeatRuleFrequencyYearly:
+		periods = t.Year() - start.Year()
+	}
+
+	// Step back one period to account for daylight saving time and the like
+	skip := periods/r.Interval - 1
+	if skip < 0 {
+		return 0
+	}
+	return skip
+}
+
+// Next returns the first occurrence of a series starting at start which is after the provided time.
+// Returns false if the series does not have any more occurrences after that time, either because they are after
+// UNTIL or because all COUNT occurrences of the series are before it.
+func (r *RepeatRule) Next(start, after time.Time) (next time.Time, exists bool) {
+	// All occurrences need to be counted if the series has a COUNT, otherwise the periods up to after can be skipped.
+	var skip int
+	if r.Count == 0 {
+		skip = r.periodsToSkip(start, after)
+	}
+
+	until := r.Until
+	if r.UntilIsDate {
+		// A date without a time includes the whole day
+		until = time.Date(until.Year(), until.Month(), until.Day(), 23, 59, 59, 0, start.Location())
+	}
+
+	var occurrences, emptyPeriods int
+	for n := skip; emptyPeriods < maxRepeatRulePeriods; n++ {
+		emptyPeriods++
+		for _, occurrence := range r.expandPeriod(start, n) {
+			if occurrence.Before(start) {
+				continue
+			}
+			if !until.IsZero() && occurrence.After(until) {
+				return time.Time{}, false
+			}
+
+			occurrences++
+			emptyPeriods = 0
+			if r.Count > 0 && occurrences > r.Count {
+				return time.Time{}, false
+			}
+
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
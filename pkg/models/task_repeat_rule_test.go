@@ -0,0 +1,235 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepeatRule(t *testing.T) {
+	t.Run("canonical form", func(t *testing.T) {
+		tests := map[string]string{
+			"FREQ=DAILY":                                    "FREQ=DAILY",
+			"RRULE:FREQ=WEEKLY;INTERVAL=1":                  "FREQ=WEEKLY",
+			"freq=weekly;interval=2;byday=tu":               "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			"FREQ=MONTHLY;BYDAY=-1FR;COUNT=10":              "FREQ=MONTHLY;COUNT=10;BYDAY=-1FR",
+			"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1": "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=+15":          "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=15",
+			"FREQ=DAILY;UNTIL=20240131T100000Z":             "FREQ=DAILY;UNTIL=20240131T100000Z",
+			"FREQ=DAILY;UNTIL=20240131":                     "FREQ=DAILY;UNTIL=20240131",
+			"FREQ=WEEKLY;WKST=SU;BYDAY=SA,SU":               "FREQ=WEEKLY;BYDAY=SA,SU;WKST=SU",
+		}
+		for rule, expected := range tests {
+			parsed, err := ParseRepeatRule(rule)
+			require.NoError(t, err, rule)
+			assert.Equal(t, expected, parsed.String(), rule)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		rules := []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=FORTNIGHTLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;COUNT=2;UNTIL=20240101",
+			"FREQ=WEEKLY;BYDAY=2TU",
+			"FREQ=MONTHLY;BYDAY=XX",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=YEARLY;BYMONTH=13",
+			"FREQ=DAILY;BYHOUR=10",
+			"FREQ",
+		}
+		for _, rule := range rules {
+			_, err := ParseRepeatRule(rule)
+			require.Error(t, err, rule)
+			assert.True(t, IsErrInvalidRepeatRule(err), rule)
+		}
+	})
+}
+
+func TestRepeatRule_Next(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		after  time.Time
+		want   time.Time
+		exists bool
+	}{
+		{
+			name:   "daily",
+			rule:   "FREQ=DAILY",
+			start:  date(2024, time.January, 31),
+			after:  date(2024, time.January, 31),
+			want:   date(2024, time.February, 1),
+			exists: true,
+		},
+		{
+			name:   "every second tuesday",
+			rule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			start:  date(2024, time.January, 2),
+			after:  date(2024, time.January, 2),
+			want:   date(2024, time.January, 16),
+			exists: true,
+		},
+		{
+			name:   "weekdays only",
+			rule:   "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			start:  date(2024, time.January, 5), // Friday
+			after:  date(2024, time.January, 5),
+			want:   date(2024, time.January, 8),
+			exists: true,
+		},
+		{
+			name:   "last friday of the month",
+			rule:   "FREQ=MONTHLY;BYDAY=-1FR",
+			start:  date(2024, time.January, 26),
+			after:  date(2024, time.January, 26),
+			want:   date(2024, time.February, 23),
+			exists: true,
+		},
+		{
+			name:   "last workday of the month",
+			rule:   "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start:  date(2024, time.February, 29),
+			after:  date(2024, time.February, 29),
+			want:   date(2024, time.March, 29),
+			exists: true,
+		},
+		{
+			name:   "month day skips short months",
+			rule:   "FREQ=MONTHLY;BYMONTHDAY=31",
+			start:  date(2024, time.January, 31),
+			after:  date(2024, time.January, 31),
+			want:   date(2024, time.March, 31),
+			exists: true,
+		},
+		{
+			name:   "negative month day",
+			rule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start:  date(2024, time.January, 31),
+			after:  date(2024, time.January, 31),
+			want:   date(2024, time.February, 29),
+			exists: true,
+		},
+		{
+			name:   "yearly by month",
+			rule:   "FREQ=YEARLY;BYMONTH=3,9;BYMONTHDAY=1",
+			start:  date(2024, time.March, 1),
+			after:  date(2024, time.March, 1),
+			want:   date(2024, time.September, 1),
+			exists: true,
+		},
+		{
+			name:   "yearly by month day in every month",
+			rule:   "FREQ=YEARLY;BYMONTHDAY=1",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 1),
+			want:   date(2024, time.February, 1),
+			exists: true,
+		},
+		{
+			name:   "yearly by month day with count",
+			rule:   "FREQ=YEARLY;BYMONTHDAY=1;COUNT=12",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.November, 15),
+			want:   date(2024, time.December, 1),
+			exists: true,
+		},
+		{
+			name:   "yearly friday the 13th",
+			rule:   "FREQ=YEARLY;BYDAY=FR;BYMONTHDAY=13",
+			start:  date(2024, time.September, 13),
+			after:  date(2024, time.September, 13),
+			want:   date(2024, time.December, 13),
+			exists: true,
+		},
+		{
+			name:   "skips periods until after",
+			rule:   "FREQ=DAILY;INTERVAL=3",
+			start:  date(2020, time.January, 1),
+			after:  date(2024, time.January, 1),
+			want:   date(2024, time.January, 4),
+			exists: true,
+		},
+		{
+			name:   "last occurrence of count",
+			rule:   "FREQ=DAILY;COUNT=1",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 1),
+			exists: false,
+		},
+		{
+			name:   "count from the start of the series",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 2),
+			want:   date(2024, time.January, 3),
+			exists: true,
+		},
+		{
+			name:   "count with skipped occurrences",
+			rule:   "FREQ=DAILY;COUNT=3",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 3),
+			exists: false,
+		},
+		{
+			name:   "after until",
+			rule:   "FREQ=WEEKLY;UNTIL=20240105T000000Z",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 1),
+			exists: false,
+		},
+		{
+			name:   "on until date",
+			rule:   "FREQ=WEEKLY;UNTIL=20240108",
+			start:  date(2024, time.January, 1),
+			after:  date(2024, time.January, 1),
+			want:   date(2024, time.January, 8),
+			exists: true,
+		},
+		{
+			name:   "until date in the time zone of the start",
+			rule:   "FREQ=DAILY;UNTIL=20240108",
+			start:  time.Date(2024, time.January, 7, 22, 0, 0, 0, newYork),
+			after:  time.Date(2024, time.January, 7, 22, 0, 0, 0, newYork),
+			want:   time.Date(2024, time.January, 8, 22, 0, 0, 0, newYork),
+			exists: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRepeatRule(tt.rule)
+			require.NoError(t, err)
+
+			next, exists := rule.Next(tt.start, tt.after)
+			assert.Equal(t, tt.exists, exists)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
@@ -19,6 +19,7 @@ package models
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -27,6 +28,9 @@ import (
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/web"
 
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/typesense/typesense-go/v2/typesense/api"
 	"github.com/typesense/typesense-go/v2/typesense/api/pointer"
 	"xorm.io/builder"
@@ -62,6 +66,12 @@ var subTableFilters = SubTableFilters{
 		FilterableField: "reminder",
 		AllowNullCheck:  true,
 	},
+	"occurrences": {
+		Table:           "task_occurrences",
+		BaseFilter:      "tasks.id = task_id",
+		FilterableField: "done_at",
+		AllowNullCheck:  true,
+	},
 	"assignees": {
 		Table:           "task_assignees",
 		BaseFilter:      "tasks.id = task_id",
@@ -80,6 +90,24 @@ var subTableFilters = SubTableFilters{
 		FilterableField: "parent_project_id",
 		AllowNullCheck:  false,
 	},
+	taskPropertyParentTask: {
+		Table:           "task_relations",
+		BaseFilter:      "tasks.id = task_id AND relation_kind = '" + string(RelationKindParenttask) + "'",
+		FilterableField: "other_task_id",
+		AllowNullCheck:  true,
+	},
+	taskPropertyCreatedBy: {
+		Table:           "users",
+		BaseFilter:      "tasks.created_by_id = id",
+		FilterableField: "username",
+		AllowNullCheck:  false,
+	},
+}
+
+// Filter fields which are calculated from other tables for every task, mapped to the sql calculating them.
+var derivedFilterColumns = map[string]string{
+	taskPropertySubtasksDonePercent: subtasksDonePercentSQL,
+	taskPropertyCommentCount:        commentCountSQL,
 }
 
 var strictComparators = map[taskFilterComparator]bool{
@@ -133,19 +161,30 @@ func getOrderByDBStatement(opts *taskSearchOptions) (orderby string, err error)
 			prefix = "tasks."
 		}
 
-		// Mysql sorts columns with null values before ones without null value.
-		// Because it does not have support for NULLS FIRST or NULLS LAST we work around this by
-		// first sorting for null (or not null) values and then the order we actually want to.
-		if db.Type() == schemas.MYSQL {
-			orderby += prefix + "`" + param.sortBy + "` IS NULL, "
+		columns := []string{prefix + "`" + param.sortBy + "`"}
+		if fieldID, is := getCustomFieldIDFromTaskProperty(param.sortBy); is {
+			columns = getCustomFieldSortColumns(fieldID)
 		}
 
-		orderby += prefix + "`" + param.sortBy + "` " + param.orderBy.String()
+		for j, column := range columns {
+			// Mysql sorts columns with null values before ones without null value.
+			// Because it does not have support for NULLS FIRST or NULLS LAST we work around this by
+			// first sorting for null (or not null) values and then the order we actually want to.
+			if db.Type() == schemas.MYSQL {
+				orderby += column + " IS NULL, "
+			}
 
-		// Postgres and sqlite allow us to control how columns with null values are sorted.
-		// To make that consistent with the sort order we have and other dbms, we're adding a separate clause here.
-		if db.Type() == schemas.POSTGRES || db.Type() == schemas.SQLITE {
-			orderby += " NULLS LAST"
+			orderby += column + " " + param.orderBy.String()
+
+			// Postgres and sqlite allow us to control how columns with null values are sorted.
+			// To make that consistent with the sort order we have and other dbms, we're adding a separate clause here.
+			if db.Type() == schemas.POSTGRES || db.Type() == schemas.SQLITE {
+				orderby += " NULLS LAST"
+			}
+
+			if (j + 1) < len(columns) {
+				orderby += ", "
+			}
 		}
 
 		if (i + 1) < len(opts.sortby) {
@@ -171,6 +210,47 @@ func convertFiltersToDBFilterCond(rawFilters []*taskFilter, includeNulls bool) (
 			continue
 		}
 
+		if fieldID, is := getCustomFieldIDFromTaskProperty(f.field); is {
+			filter, err := getCustomFieldFilterCond(fieldID, f, includeNulls)
+			if err != nil {
+				return nil, err
+			}
+			dbFilters = append(dbFilters, filter)
+			continue
+		}
+
+		var taskIDSubQuery *builder.Builder
+		switch f.field {
+		case taskPropertyIsBlocked:
+			taskIDSubQuery = getBlockedTasksSubQuery()
+		case taskPropertyHasSubtasks:
+			taskIDSubQuery = getTasksWithSubtasksSubQuery()
+		case taskPropertyHasAttachments:
+			taskIDSubQuery = getTasksWithAttachmentsSubQuery()
+		}
+		if taskIDSubQuery != nil {
+			filter, err := getTaskIDSubQueryFilterCond(f, taskIDSubQuery)
+			if err != nil {
+				return nil, err
+			}
+			dbFilters = append(dbFilters, filter)
+			continue
+		}
+
+		if column, is := derivedFilterColumns[f.field]; is {
+			filter, err := getFilterCond(&taskFilter{
+				field:      column,
+				value:      f.value,
+				comparator: f.comparator,
+				isNumeric:  f.isNumeric,
+			}, includeNulls)
+			if err != nil {
+				return nil, err
+			}
+			dbFilters = append(dbFilters, filter)
+			continue
+		}
+
 		subTableFilterParams, ok := subTableFilters[f.field]
 		if ok {
 			if f.field == "assignees" && (f.comparator == taskFilterComparatorLike) {
@@ -245,15 +325,41 @@ func convertFiltersToDBFilterCond(rawFilters []*taskFilter, includeNulls bool) (
 	return filterCond, nil
 }
 
-func hasBucketIDInParsedFilter(filters []*taskFilter) bool {
+// getTaskIDSubQueryFilterCond returns the db condition for a boolean filter which is true for all tasks whose id
+// is returned by the given sub query.
+func getTaskIDSubQueryFilterCond(f *taskFilter, subQuery *builder.Builder) (cond builder.Cond, err error) {
+	if f.comparator != taskFilterComparatorEquals && f.comparator != taskFilterComparatorNotEquals {
+		return nil, ErrInvalidTaskFilterComparator{Comparator: f.comparator}
+	}
+
+	value, is := f.value.(bool)
+	if !is {
+		return nil, ErrInvalidTaskFilterValue{
+			Field: f.field,
+			Value: f.value,
+		}
+	}
+
+	if f.comparator == taskFilterComparatorNotEquals {
+		value = !value
+	}
+
+	if value {
+		return builder.In("tasks.id", subQuery), nil
+	}
+
+	return builder.NotIn("tasks.id", subQuery), nil
+}
+
+func hasFieldInParsedFilter(filters []*taskFilter, field string) bool {
 	for _, filter := range filters {
 		if subfilters, is := filter.value.([]*taskFilter); is {
-			has := hasBucketIDInParsedFilter(subfilters)
+			has := hasFieldInParsedFilter(subfilters, field)
 			if has {
 				return true
 			}
 		}
-		if filter.field == taskPropertyBucketID {
+		if filter.field == field {
 			return true
 		}
 	}
@@ -269,7 +375,7 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 		return nil, 0, err
 	}
 
-	joinTaskBuckets := hasBucketIDInParsedFilter(opts.parsedFilters)
+	joinTaskBuckets := hasFieldInParsedFilter(opts.parsedFilters, taskPropertyBucketID)
 
 	filterCond, err := convertFiltersToDBFilterCond(opts.parsedFilters, opts.filterIncludeNulls)
 	if err != nil {
@@ -279,13 +385,28 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 	// Then return all tasks for that projects
 	var where builder.Cond
 
+	var fullTextSearch bool
 	if opts.search != "" {
-		where = db.MultiFieldSearchWithTableAlias([]string{"title", "description"}, opts.search, "tasks")
+		if fullTextSearchEnabled() {
+			where, fullTextSearch = getFullTextSearchCond(opts.search)
+		}
+		if !fullTextSearch {
+			where = db.MultiFieldSearchWithTableAlias([]string{"title", "description"}, opts.search, "tasks")
+		}
 
 		searchIndex := getTaskIndexFromSearchString(opts.search)
 		if searchIndex > 0 {
 			where = builder.Or(where, builder.Eq{"`index`": searchIndex})
 		}
+
+		if opts.searchesIn(TaskSearchFieldComments) {
+			where = builder.Or(where, builder.In("tasks.id",
+				builder.Select("task_id").From("task_comments").Where(db.ILIKE("comment", opts.search))))
+		}
+		if opts.searchesIn(TaskSearchFieldAttachments) {
+			where = builder.Or(where, builder.In("tasks.id",
+				builder.Select("task_id").From("task_attachment_texts").Where(db.ILIKE("text", opts.search))))
+		}
 	}
 
 	var projectIDCond builder.Cond
@@ -315,6 +436,20 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 	if strings.Contains(orderby, "task_positions.") {
 		distinct += ", task_positions.position"
 	}
+	distinct += getCustomFieldSortSelects(opts)
+
+	// Without an explicit sort order, the best matches of a full text search come first.
+	var rankJoin string
+	var rankJoinArgs []interface{}
+	if fullTextSearch && len(opts.sortby) == 1 && opts.sortby[0].sortBy == taskPropertyID {
+		rankJoin, rankJoinArgs, _ = getFullTextSearchRankJoin(opts.search)
+		distinct += ", search_results.search_rank"
+		rankOrder := "search_results.search_rank DESC"
+		if db.Type() == schemas.POSTGRES || db.Type() == schemas.SQLITE {
+			rankOrder += " NULLS LAST"
+		}
+		orderby = rankOrder + ", " + orderby
+	}
 
 	var expandSubtasks = false
 	for _, expandable := range opts.expand {
@@ -337,6 +472,7 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 	if limit > 0 {
 		query = query.Limit(limit, start)
 	}
+	query = joinCustomFieldSortTables(query, opts)
 
 	for _, param := range opts.sortby {
 		if param.sortBy == taskPropertyPosition {
@@ -359,6 +495,9 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 			Join("LEFT", "task_relations", "tasks.id = task_relations.task_id and task_relations.relation_kind = 'parenttask'").
 			Join("LEFT", "tasks parent_tasks", "task_relations.other_task_id = parent_tasks.id")
 	}
+	if rankJoin != "" {
+		query = query.Join("LEFT", rankJoin, "search_results.task_id = tasks.id", rankJoinArgs...)
+	}
 
 	tasks = []*Task{}
 	err = query.
@@ -466,6 +605,18 @@ func convertFilterValues(value interface{}) string {
 		return strings.Join(filter, ",")
 	}
 
+	// Typesense stores the value of a custom field with its native type, which is not known here. We use the
+	// most specific representation the filter value could be parsed into.
+	if customFieldValue, is := value.(*customFieldFilterValue); is {
+		if customFieldValue.number != nil {
+			return convertFilterValues(customFieldValue.number)
+		}
+		if customFieldValue.date != nil {
+			return convertFilterValues(customFieldValue.date)
+		}
+		return convertFilterValues(customFieldValue.text)
+	}
+
 	switch v := value.(type) {
 	case string:
 		return v
@@ -473,6 +624,8 @@ func convertFilterValues(value interface{}) string {
 		return strconv.Itoa(v)
 	case int64:
 		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
 	case bool:
 		if v {
 			return "true"
@@ -525,6 +678,14 @@ func convertParsedFilterToTypesense(rawFilters []*taskFilter) (filterBy string,
 			f.field = "buckets"
 		}
 
+		if f.field == taskPropertyParentTask {
+			f.field = "parent_task_ids"
+		}
+
+		if fieldID, is := getCustomFieldIDFromTaskProperty(f.field); is {
+			f.field = "custom_fields.field_" + strconv.FormatInt(fieldID, 10)
+		}
+
 		filter := f.field
 
 		switch f.comparator {
@@ -561,20 +722,16 @@ func convertParsedFilterToTypesense(rawFilters []*taskFilter) (filterBy string,
 		filters = append(filters, filter)
 	}
 
-	if len(filters) > 0 {
-		if len(filters) == 1 {
-			filterBy = filters[0]
-		} else {
-			for i, f := range filters {
-				if len(filters) > i+1 {
-					switch rawFilters[i+1].join {
-					case filterConcatOr:
-						filterBy = f + " || " + filters[i+1]
-					case filterConcatAnd:
-						filterBy = f + " && " + filters[i+1]
-					}
-				}
-			}
+	for i, f := range filters {
+		if i == 0 {
+			filterBy = f
+			continue
+		}
+		switch rawFilters[i].join {
+		case filterConcatOr:
+			filterBy += " || " + f
+		case filterConcatAnd:
+			filterBy += " && " + f
 		}
 	}
 
@@ -623,6 +780,10 @@ func (t *typesenseTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task,
 			sortBy = "positions.view_" + strconv.FormatInt(param.projectViewID, 10)
 		}
 
+		if fieldID, is := getCustomFieldIDFromTaskProperty(param.sortBy); is {
+			sortBy = "custom_fields.field_" + strconv.FormatInt(fieldID, 10)
+		}
+
 		sortbyFields = append(sortbyFields, sortBy+"(missing_values:last):"+param.orderBy.String())
 
 		if usedParams == 2 {
@@ -643,9 +804,17 @@ func (t *typesenseTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task,
 		opts.search = "*"
 	}
 
+	queryBy := "title, identifier, description"
+	if opts.searchesIn(TaskSearchFieldComments) {
+		queryBy += ", comments.comment"
+	}
+	if opts.searchesIn(TaskSearchFieldAttachments) {
+		queryBy += ", attachment_texts"
+	}
+
 	params := &api.SearchCollectionParams{
 		Q:                pointer.String(opts.search),
-		QueryBy:          pointer.String("title, identifier, description, comments.comment"),
+		QueryBy:          pointer.String(queryBy),
 		Page:             pointer.Int(opts.page),
 		ExhaustiveSearch: pointer.True(),
 		FilterBy:         pointer.String(strings.Join(filterBy, " && ")),
@@ -691,11 +860,13 @@ func (t *typesenseTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task,
 	if strings.Contains(orderby, "task_positions.") {
 		distinct += ", task_positions.position"
 	}
+	distinct += getCustomFieldSortSelects(opts)
 
 	query := t.s.
 		Distinct(distinct).
-		In("id", taskIDs).
+		In("tasks.id", taskIDs).
 		OrderBy(orderby)
+	query = joinCustomFieldSortTables(query, opts)
 
 	for _, param := range opts.sortby {
 		if param.sortBy == taskPropertyPosition {
@@ -707,3 +878,490 @@ func (t *typesenseTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task,
 	err = query.Find(&tasks)
 	return tasks, int64(*result.Found), err
 }
+
+type bleveTaskSearcher struct {
+	s                   *xorm.Session
+	a                   web.Auth
+	hasFavoritesProject bool
+}
+
+// Filter fields which are stored under a different name in the bleve index.
+var bleveFilterFields = map[string]string{
+	"label_id":             "labels",
+	"project":              "project_id",
+	taskPropertyBucketID:   "buckets",
+	taskPropertyParentTask: "parent_task_ids",
+}
+
+// Text fields are analyzed for the search. Filtering and sorting uses a copy of them indexed as one term.
+var bleveTextFields = map[string]string{
+	"title":       "title_keyword",
+	"description": "description_keyword",
+}
+
+func getBleveFieldName(field string) string {
+	if fieldID, is := getCustomFieldIDFromTaskProperty(field); is {
+		return "custom_fields.field_" + strconv.FormatInt(fieldID, 10)
+	}
+	if name, has := bleveFilterFields[field]; has {
+		return name
+	}
+	if name, has := bleveTextFields[field]; has {
+		return name
+	}
+	return field
+}
+
+func convertParsedFilterToBleve(rawFilters []*taskFilter) (filterQuery query.Query, err error) {
+	for _, f := range rawFilters {
+		var q query.Query
+		if nested, is := f.value.([]*taskFilter); is {
+			q, err = convertParsedFilterToBleve(nested)
+		} else {
+			q, err = getBleveFilterQuery(f)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if q == nil {
+			continue
+		}
+
+		if filterQuery == nil {
+			filterQuery = q
+			continue
+		}
+
+		switch f.join {
+		case filterConcatOr:
+			filterQuery = bleve.NewDisjunctionQuery(filterQuery, q)
+		case filterConcatAnd:
+			filterQuery = bleve.NewConjunctionQuery(filterQuery, q)
+		}
+	}
+
+	return
+}
+
+func getBleveFilterQuery(f *taskFilter) (query.Query, error) {
+	comparator := f.comparator
+	negate := false
+	switch comparator {
+	case taskFilterComparatorNotEquals:
+		comparator = taskFilterComparatorEquals
+		negate = true
+	case taskFilterComparatorNotIn:
+		comparator = taskFilterComparatorIn
+		negate = true
+	}
+
+	q, err := getBleveValueQuery(getBleveFieldName(f.field), comparator, f.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !negate {
+		return q, nil
+	}
+
+	negated := bleve.NewBooleanQuery()
+	negated.AddMustNot(q)
+	return negated, nil
+}
+
+func getBleveValueQuery(field string, comparator taskFilterComparator, value interface{}) (query.Query, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]query.Query, 0, len(v))
+		for _, val := range v {
+			q, err := getBleveValueQuery(field, comparator, val)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, q)
+		}
+		return bleve.NewDisjunctionQuery(values...), nil
+	case []string:
+		values := make([]interface{}, 0, len(v))
+		for _, val := range v {
+			values = append(values, val)
+		}
+		return getBleveValueQuery(field, comparator, values)
+	case *customFieldFilterValue:
+		// The value of a custom field is stored with its native type, which is not known here.
+		values := []interface{}{}
+		for _, val := range []interface{}{v.number, v.date, v.text} {
+			if val != nil {
+				values = append(values, val)
+			}
+		}
+		return getBleveValueQuery(field, comparator, values)
+	case bool:
+		if comparator != taskFilterComparatorEquals {
+			return nil, ErrInvalidTaskFilterComparator{Comparator: comparator}
+		}
+		q := bleve.NewBoolFieldQuery(v)
+		q.SetField(field)
+		return q, nil
+	case string:
+		return getBleveStringQuery(field, comparator, v)
+	case time.Time:
+		return getBleveNumericQuery(field, comparator, getBleveTimestamp(v))
+	case int:
+		return getBleveNumericQuery(field, comparator, float64(v))
+	case int64:
+		return getBleveNumericQuery(field, comparator, float64(v))
+	case float64:
+		return getBleveNumericQuery(field, comparator, v)
+	}
+
+	return nil, ErrInvalidTaskFilterValue{
+		Field: field,
+		Value: value,
+	}
+}
+
+func getBleveStringQuery(field string, comparator taskFilterComparator, value string) (query.Query, error) {
+	// All string fields are indexed lowercase
+	value = strings.ToLower(value)
+
+	var q query.FieldableQuery
+	switch comparator {
+	case taskFilterComparatorEquals, taskFilterComparatorIn:
+		q = bleve.NewTermQuery(value)
+	case taskFilterComparatorLike:
+		q = bleve.NewWildcardQuery("*" + value + "*")
+	case taskFilterComparatorGreater:
+		q = bleve.NewTermRangeInclusiveQuery(value, "", pointer.False(), nil)
+	case taskFilterComparatorGreateEquals:
+		q = bleve.NewTermRangeInclusiveQuery(value, "", pointer.True(), nil)
+	case taskFilterComparatorLess:
+		q = bleve.NewTermRangeInclusiveQuery("", value, nil, pointer.False())
+	case taskFilterComparatorLessEquals:
+		q = bleve.NewTermRangeInclusiveQuery("", value, nil, pointer.True())
+	default:
+		return nil, ErrInvalidTaskFilterComparator{Comparator: comparator}
+	}
+
+	q.SetField(field)
+	return q, nil
+}
+
+func getBleveNumericQuery(field string, comparator taskFilterComparator, value float64) (query.Query, error) {
+	var q *query.NumericRangeQuery
+	switch comparator {
+	case taskFilterComparatorEquals, taskFilterComparatorIn:
+		q = bleve.NewNumericRangeInclusiveQuery(&value, &value, pointer.True(), pointer.True())
+	case taskFilterComparatorGreater:
+		q = bleve.NewNumericRangeInclusiveQuery(&value, nil, pointer.False(), nil)
+	case taskFilterComparatorGreateEquals:
+		q = bleve.NewNumericRangeInclusiveQuery(&value, nil, pointer.True(), nil)
+	case taskFilterComparatorLess:
+		q = bleve.NewNumericRangeInclusiveQuery(nil, &value, nil, pointer.False())
+	case taskFilterComparatorLessEquals:
+		q = bleve.NewNumericRangeInclusiveQuery(nil, &value, nil, pointer.True())
+	default:
+		return nil, ErrInvalidTaskFilterComparator{Comparator: comparator}
+	}
+
+	q.SetField(field)
+	return q, nil
+}
+
+func getBleveSearchQuery(search string, searchIn []TaskSearchField) query.Query {
+	fields := []string{"title", "description"}
+	for _, field := range searchIn {
+		switch field {
+		case TaskSearchFieldComments:
+			fields = append(fields, "comments")
+		case TaskSearchFieldAttachments:
+			fields = append(fields, "attachment_texts")
+		}
+	}
+
+	searchQueries := []query.Query{}
+	for _, field := range fields {
+		q := bleve.NewMatchQuery(search)
+		q.SetField(field)
+		q.SetOperator(query.MatchQueryOperatorAnd)
+		if field == "title" {
+			q.SetBoost(2)
+		}
+		searchQueries = append(searchQueries, q)
+	}
+
+	// Allow finding tasks while the last word is still being typed
+	if !strings.Contains(search, " ") {
+		q := bleve.NewPrefixQuery(strings.ToLower(search))
+		q.SetField("title")
+		searchQueries = append(searchQueries, q)
+	}
+
+	identifier := bleve.NewTermQuery(strings.ToLower(search))
+	identifier.SetField("identifier")
+	searchQueries = append(searchQueries, identifier)
+
+	searchIndex := getTaskIndexFromSearchString(search)
+	if searchIndex > 0 {
+		index := float64(searchIndex)
+		q := bleve.NewNumericRangeInclusiveQuery(&index, &index, pointer.True(), pointer.True())
+		q.SetField("index")
+		searchQueries = append(searchQueries, q)
+	}
+
+	return bleve.NewDisjunctionQuery(searchQueries...)
+}
+
+func getBleveSortOrder(opts *taskSearchOptions) (sortOrder search.SortOrder, err error) {
+	// Without an explicit sort order, the best matches of a search come first.
+	if opts.search != "" && len(opts.sortby) == 1 && opts.sortby[0].sortBy == taskPropertyID {
+		sortOrder = append(sortOrder, &search.SortScore{Desc: true})
+	}
+
+	for _, param := range opts.sortby {
+
+		if opts.isSavedFilter && param.sortBy == taskPropertyPosition {
+			continue
+		}
+
+		// Validate the params
+		if err := param.validate(); err != nil {
+			return nil, err
+		}
+
+		field := getBleveFieldName(param.sortBy)
+		if param.sortBy == taskPropertyPosition {
+			field = "positions.view_" + strconv.FormatInt(param.projectViewID, 10)
+		}
+
+		sortOrder = append(sortOrder, &search.SortField{
+			Field:   field,
+			Desc:    param.orderBy == orderDescending,
+			Missing: search.SortFieldMissingLast,
+		})
+	}
+
+	return
+}
+
+func (b *bleveTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCount int64, err error) {
+
+	projectQueries := make([]query.Query, 0, len(opts.projectIDs))
+	for _, id := range opts.projectIDs {
+		q, err := getBleveNumericQuery("project_id", taskFilterComparatorEquals, float64(id))
+		if err != nil {
+			return nil, 0, err
+		}
+		projectQueries = append(projectQueries, q)
+	}
+
+	if b.hasFavoritesProject {
+		// All favorite tasks for that user
+		favoriteTaskIDs := []string{}
+		err = b.s.
+			Table("favorites").
+			Where(builder.And(
+				builder.Eq{"user_id": b.a.GetID()},
+				builder.Eq{"kind": FavoriteKindTask},
+			)).
+			Cols("entity_id").
+			Find(&favoriteTaskIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		projectQueries = append(projectQueries, bleve.NewDocIDQuery(favoriteTaskIDs))
+	}
+
+	queries := []query.Query{bleve.NewDisjunctionQuery(projectQueries...)}
+
+	filter, err := convertParsedFilterToBleve(opts.parsedFilters)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter != nil {
+		queries = append(queries, filter)
+	}
+
+	if opts.search != "" {
+		queries = append(queries, getBleveSearchQuery(opts.search, opts.searchIn))
+	}
+
+	sortOrder, err := getBleveSortOrder(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit, start := getLimitFromPageIndex(opts.page, opts.perPage)
+	if limit == 0 {
+		docCount, err := bleveIndex.DocCount()
+		if err != nil {
+			return nil, 0, err
+		}
+		limit = int(docCount)
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(queries...), limit, start, false)
+	request.SortByCustom(sortOrder)
+
+	result, err := bleveIndex.Search(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	taskIDs := make([]int64, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		taskID, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	tasks = []*Task{}
+	if len(taskIDs) == 0 {
+		return tasks, int64(result.Total), nil
+	}
+
+	taskMap := make(map[int64]*Task, len(taskIDs))
+	err = b.s.In("id", taskIDs).Find(&taskMap)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Keep the order of the search results. Tasks which were deleted but are still in the index are skipped.
+	for _, id := range taskIDs {
+		if task, has := taskMap[id]; has {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, int64(result.Total), nil
+}
+
+func (opts *taskSearchOptions) searchesIn(field TaskSearchField) bool {
+	return slices.Contains(opts.searchIn, field)
+}
+
+// isTextMatchingSearch approximates the matching of the search backends: A text matches if it contains the search
+// or all of its words.
+func isTextMatchingSearch(text, search string) bool {
+	text = strings.ToLower(text)
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return false
+	}
+	if strings.Contains(text, search) {
+		return true
+	}
+
+	words := strings.Fields(search)
+	if len(words) < 2 {
+		return false
+	}
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTextMatchingAnySearchWord(text, search string) bool {
+	text = strings.ToLower(text)
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTaskIDsWithTextMatchingSearch returns the ids of all tasks which have a row in table whose column matches
+// the search.
+func getTaskIDsWithTextMatchingSearch(s *xorm.Session, table, column string, taskIDs []int64, search string) (matched map[int64]bool, err error) {
+	// Only rows containing at least one of the search words are candidates
+	wordConds := []builder.Cond{}
+	for _, word := range strings.Fields(search) {
+		wordConds = append(wordConds, db.ILIKE(column, word))
+	}
+
+	rows := []*struct {
+		TaskID int64
+		Text   string
+	}{}
+	err = s.
+		Table(table).
+		Select("task_id, " + column + " AS text").
+		Where(builder.And(builder.In("task_id", taskIDs), builder.Or(wordConds...))).
+		Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	matched = make(map[int64]bool)
+	for _, row := range rows {
+		if isTextMatchingSearch(row.Text, search) {
+			matched[row.TaskID] = true
+		}
+	}
+	return
+}
+
+// addSearchMatchesToTasks sets where the search matched for all tasks of a search result.
+func addSearchMatchesToTasks(s *xorm.Session, tasks []*Task, opts *taskSearchOptions) (err error) {
+	if opts.search == "" || len(tasks) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	matchedComments := make(map[int64]bool)
+	if opts.searchesIn(TaskSearchFieldComments) {
+		matchedComments, err = getTaskIDsWithTextMatchingSearch(s, "task_comments", "comment", taskIDs, opts.search)
+		if err != nil {
+			return err
+		}
+	}
+
+	matchedAttachments := make(map[int64]bool)
+	if opts.searchesIn(TaskSearchFieldAttachments) {
+		matchedAttachments, err = getTaskIDsWithTextMatchingSearch(s, "task_attachment_texts", "text", taskIDs, opts.search)
+		if err != nil {
+			return err
+		}
+	}
+
+	searchIndex := getTaskIndexFromSearchString(opts.search)
+	for _, t := range tasks {
+		if isTextMatchingSearch(t.Title, opts.search) {
+			t.MatchedIn = append(t.MatchedIn, TaskSearchFieldTitle)
+		}
+		if isTextMatchingSearch(t.Description, opts.search) {
+			t.MatchedIn = append(t.MatchedIn, TaskSearchFieldDescription)
+		}
+		if searchIndex > 0 && t.Index == searchIndex {
+			t.MatchedIn = append(t.MatchedIn, TaskSearchFieldIdentifier)
+		}
+		if matchedComments[t.ID] {
+			t.MatchedIn = append(t.MatchedIn, TaskSearchFieldComments)
+		}
+		if matchedAttachments[t.ID] {
+			t.MatchedIn = append(t.MatchedIn, TaskSearchFieldAttachments)
+		}
+
+		// Some backends match tasks which contain only some of the words of the search
+		if len(t.MatchedIn) == 0 {
+			if isTextMatchingAnySearchWord(t.Title, opts.search) {
+				t.MatchedIn = append(t.MatchedIn, TaskSearchFieldTitle)
+			}
+			if isTextMatchingAnySearchWord(t.Description, opts.search) {
+				t.MatchedIn = append(t.MatchedIn, TaskSearchFieldDescription)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+// The native full text search keeps one document per task in the task_search_index table. How that table looks
+// and how it is queried depends on the database:
+//   - postgres stores a weighted tsvector with a GIN index
+//   - sqlite uses an FTS5 virtual table with the task id as rowid
+//   - mysql stores title and description with a FULLTEXT index
+const taskSearchIndexTable = "task_search_index"
+
+var (
+	// fullTextSearchAvailable is true once the search index was created successfully.
+	fullTextSearchAvailable bool
+	// fullTextSearchLanguage holds the postgres text search configuration used to stem the documents.
+	fullTextSearchLanguage = "simple"
+
+	fullTextSearchTermRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+func fullTextSearchEnabled() bool {
+	return config.DatabaseFullTextSearch.GetBool() && fullTextSearchAvailable
+}
+
+// InitFullTextSearch checks if the full text search index, which is created by a migration, can be used. If the
+// index is empty, all tasks are indexed.
+func InitFullTextSearch() (err error) {
+	fullTextSearchAvailable = false
+	if !config.DatabaseFullTextSearch.GetBool() {
+		return nil
+	}
+
+	fullTextSearchAvailable, err = checkFullTextSearchIndex()
+	if err != nil || !fullTextSearchAvailable {
+		return err
+	}
+
+	indexed, err := x.Table(taskSearchIndexTable).Exist()
+	if err != nil || indexed {
+		return err
+	}
+
+	log.Infof("The full text search index is empty, indexing all tasks…")
+	return ReindexAllTasksIntoFullTextSearch()
+}
+
+// RebuildFullTextSearchIndex replaces the full text search index with all tasks. The table of the index needs to be
+// recreated before when the language of the full text search was changed.
+func RebuildFullTextSearchIndex() error {
+	err := InitFullTextSearch()
+	if err != nil {
+		return err
+	}
+
+	return ReindexAllTasksIntoFullTextSearch()
+}
+
+func checkFullTextSearchIndex() (available bool, err error) {
+	exists, err := x.IsTableExist(taskSearchIndexTable)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		log.Warningf("Full text search is enabled but the full text search index does not exist, falling back to a simple search. Run the index command to create it.")
+		return false, nil
+	}
+
+	switch db.Type() {
+	case schemas.POSTGRES:
+		language := strings.ToLower(config.DatabaseFullTextLanguage.GetString())
+		exists, err := x.SQL("SELECT 1 FROM pg_ts_config WHERE cfgname = ?", language).Exist()
+		if err != nil {
+			return false, err
+		}
+		fullTextSearchLanguage = language
+		if !exists {
+			log.Warningf("Full text search language %s is not available in postgres, words will not be stemmed", language)
+			fullTextSearchLanguage = "simple"
+		}
+	case schemas.SQLITE:
+		_, err = x.Exec("SELECT rowid FROM " + taskSearchIndexTable + " LIMIT 1")
+		if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+			log.Warningf("Full text search is enabled but sqlite was built without FTS5 support, falling back to a simple search. Build Vikunja with the sqlite_fts5 tag to use it.")
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	case schemas.MYSQL:
+		// The mysql index does not depend on the language
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func getFullTextSearchIndexKeyColumn() string {
+	if db.Type() == schemas.SQLITE {
+		return "rowid"
+	}
+	return "task_id"
+}
+
+// insertTasksIntoFullTextSearch indexes all tasks matching the condition. A nil condition indexes all tasks.
+func insertTasksIntoFullTextSearch(s *xorm.Session, cond builder.Cond) (err error) {
+	var query string
+	args := []interface{}{}
+
+	switch db.Type() {
+	case schemas.POSTGRES:
+		query = "INSERT INTO " + taskSearchIndexTable + " (task_id, document) SELECT id, " +
+			"setweight(to_tsvector(?::regconfig, COALESCE(title, '')), 'A') || " +
+			"setweight(to_tsvector(?::regconfig, COALESCE(description, '')), 'B') FROM tasks"
+		args = append(args, fullTextSearchLanguage, fullTextSearchLanguage)
+	case schemas.MYSQL:
+		query = "INSERT INTO " + taskSearchIndexTable + " (task_id, title, description) SELECT id, title, description FROM tasks"
+	case schemas.SQLITE:
+		query = "INSERT INTO " + taskSearchIndexTable + " (rowid, title, description) SELECT id, COALESCE(title, ''), COALESCE(description, '') FROM tasks"
+	}
+
+	if cond != nil {
+		condSQL, condArgs, err := builder.ToSQL(cond)
+		if err != nil {
+			return err
+		}
+		query += " WHERE " + condSQL
+		args = append(args, condArgs...)
+	}
+
+	_, err = s.Exec(append([]interface{}{query}, args...)...)
+	return
+}
+
+// ReindexAllTasksIntoFullTextSearch replaces all documents of the full text search index with the current tasks.
+func ReindexAllTasksIntoFullTextSearch() (err error) {
+	if !fullTextSearchEnabled() {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	if err = s.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = s.Exec("DELETE FROM " + taskSearchIndexTable); err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	if err = insertTasksIntoFullTextSearch(s, nil); err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+func removeTasksFromFullTextSearch(s *xorm.Session, taskIDs []int64) (err error) {
+	if !fullTextSearchEnabled() || len(taskIDs) == 0 {
+		return nil
+	}
+
+	condSQL, condArgs, err := builder.ToSQL(builder.In(getFullTextSearchIndexKeyColumn(), taskIDs))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Exec(append([]interface{}{"DELETE FROM " + taskSearchIndexTable + " WHERE " + condSQL}, condArgs...)...)
+	return
+}
+
+// updateTasksInFullTextSearch replaces the documents of the given tasks in the full text search index.
+func updateTasksInFullTextSearch(s *xorm.Session, taskIDs []int64) (err error) {
+	if !fullTextSearchEnabled() || len(taskIDs) == 0 {
+		return nil
+	}
+
+	err = removeTasksFromFullTextSearch(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	return insertTasksIntoFullTextSearch(s, builder.In("id", taskIDs))
+}
+
+// getFullTextSearchQuery converts the search string into a query of the current database. Every term of the search
+// needs to match, the last letters of a term may be missing.
+func getFullTextSearchQuery(search string) (query string, ok bool) {
+	terms := fullTextSearchTermRegex.FindAllString(strings.ToLower(search), -1)
+	if len(terms) == 0 {
+		return "", false
+	}
+
+	for i, term := range terms {
+		switch db.Type() {
+		case schemas.POSTGRES:
+			terms[i] = term + ":*"
+		case schemas.MYSQL:
+			terms[i] = "+" + term + "*"
+		case schemas.SQLITE:
+			terms[i] = `"` + term + `"*`
+		}
+	}
+
+	if db.Type() == schemas.POSTGRES {
+		return strings.Join(terms, " & "), true
+	}
+
+	return strings.Join(terms, " "), true
+}
+
+// getFullTextSearchMatch returns the sql matching documents of the search index and the score of each match,
+// higher scores are better matches.
+func getFullTextSearchMatch(query string) (match string, matchArgs []interface{}, score string, scoreArgs []interface{}) {
+	switch db.Type() {
+	case schemas.POSTGRES:
+		return "document @@ to_tsquery(?::regconfig, ?)", []interface{}{fullTextSearchLanguage, query},
+			"ts_rank(document, to_tsquery(?::regconfig, ?))", []interface{}{fullTextSearchLanguage, query}
+	case schemas.MYSQL:
+		return "MATCH(title, description) AGAINST (? IN BOOLEAN MODE)", []interface{}{query},
+			"MATCH(title, description) AGAINST (? IN BOOLEAN MODE)", []interface{}{query}
+	case schemas.SQLITE:
+		// bm25 returns lower values for better matches. Title matches are weighted ten times higher.
+		return taskSearchIndexTable + " MATCH ?", []interface{}{query},
+			"-bm25(" + taskSearchIndexTable + ", 10.0, 1.0)", nil
+	}
+	return "", nil, "", nil
+}
+
+// getFullTextSearchCond returns the condition for all tasks matching the search.
+func getFullTextSearchCond(search string) (cond builder.Cond, ok bool) {
+	query, ok := getFullTextSearchQuery(search)
+	if !ok {
+		return nil, false
+	}
+
+	match, args, _, _ := getFullTextSearchMatch(query)
+	return builder.In("tasks.id", builder.
+		Select(getFullTextSearchIndexKeyColumn()).
+		From(taskSearchIndexTable).
+		Where(builder.Expr(match, args...)),
+	), true
+}
+
+// getFullTextSearchRankJoin returns a subquery with the relevance of all tasks matching the search as search_rank.
+// It is meant to be left joined as search_results to sort tasks by relevance.
+func getFullTextSearchRankJoin(search string) (table string, args []interface{}, ok bool) {
+	query, ok := getFullTextSearchQuery(search)
+	if !ok {
+		return "", nil, false
+	}
+
+	match, matchArgs, score, scoreArgs := getFullTextSearchMatch(query)
+	args = append(args, scoreArgs...)
+	args = append(args, matchArgs...)
+
+	return "(SELECT " + getFullTextSearchIndexKeyColumn() + " AS task_id, " + score + " AS search_rank " +
+		"FROM " + taskSearchIndexTable + " WHERE " + match + ") search_results", args, true
+}
@@ -0,0 +1,182 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm/schemas"
+)
+
+// createFullTextSearchIndexTable creates the table of the search index like its migration does, because the tests
+// do not run migrations.
+func createFullTextSearchIndexTable(t *testing.T) {
+	var err error
+	switch db.Type() {
+	case schemas.POSTGRES:
+		_, err = x.Exec("CREATE TABLE IF NOT EXISTS " + taskSearchIndexTable + " (task_id BIGINT NOT NULL PRIMARY KEY, document TSVECTOR NOT NULL)")
+		if err == nil {
+			_, err = x.Exec("CREATE INDEX IF NOT EXISTS IDX_" + taskSearchIndexTable + "_document ON " + taskSearchIndexTable + " USING GIN (document)")
+		}
+	case schemas.MYSQL:
+		_, err = x.Exec("CREATE TABLE IF NOT EXISTS " + taskSearchIndexTable + " (task_id BIGINT NOT NULL PRIMARY KEY, title LONGTEXT, description LONGTEXT, " +
+			"FULLTEXT INDEX IDX_" + taskSearchIndexTable + "_text (title, description)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	case schemas.SQLITE:
+		_, err = x.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + taskSearchIndexTable + " USING fts5(title, description, tokenize = 'porter unicode61 remove_diacritics 2')")
+		if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("sqlite was built without FTS5 support")
+		}
+	}
+	require.NoError(t, err)
+}
+
+func setupFullTextSearch(t *testing.T) {
+	config.DatabaseFullTextSearch.Set(true)
+	t.Cleanup(func() {
+		config.DatabaseFullTextSearch.Set(false)
+		fullTextSearchAvailable = false
+	})
+
+	createFullTextSearchIndexTable(t)
+	require.NoError(t, InitFullTextSearch())
+	if !fullTextSearchAvailable {
+		t.Skip("Full text search is not supported by this database")
+	}
+
+	db.LoadAndAssertFixtures(t)
+	require.NoError(t, ReindexAllTasksIntoFullTextSearch())
+}
+
+func TestGetFullTextSearchQuery(t *testing.T) {
+	query, ok := getFullTextSearchQuery("Lorem, ipsum!")
+	require.True(t, ok)
+
+	switch db.Type() {
+	case schemas.POSTGRES:
+		assert.Equal(t, "lorem:* & ipsum:*", query)
+	case schemas.MYSQL:
+		assert.Equal(t, "+lorem* +ipsum*", query)
+	case schemas.SQLITE:
+		assert.Equal(t, `"lorem"* "ipsum"*`, query)
+	}
+
+	_, ok = getFullTextSearchQuery(" #&! ")
+	assert.False(t, ok)
+}
+
+func TestInitFullTextSearch(t *testing.T) {
+	t.Run("indexes all tasks into an empty index", func(t *testing.T) {
+		setupFullTextSearch(t)
+		_, err := x.Exec("DELETE FROM " + taskSearchIndexTable)
+		require.NoError(t, err)
+
+		require.NoError(t, InitFullTextSearch())
+		assert.True(t, fullTextSearchAvailable)
+		indexed, err := x.Table(taskSearchIndexTable).Count()
+		require.NoError(t, err)
+		tasks, err := x.Count(&Task{})
+		require.NoError(t, err)
+		assert.Equal(t, tasks, indexed)
+	})
+	t.Run("falls back without the index", func(t *testing.T) {
+		setupFullTextSearch(t)
+		_, err := x.Exec("DROP TABLE " + taskSearchIndexTable)
+		require.NoError(t, err)
+
+		require.NoError(t, InitFullTextSearch())
+		assert.False(t, fullTextSearchAvailable)
+	})
+}
+
+func TestTaskCollection_FullTextSearch(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	search := func(t *testing.T, search string) []int64 {
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{ProjectID: 1}
+		result, _, _, err := tc.ReadAll(s, u, search, 0, 50)
+		require.NoError(t, err)
+
+		ids := []int64{}
+		for _, task := range result.([]*Task) {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	t.Run("description", func(t *testing.T) {
+		setupFullTextSearch(t)
+
+		assert.Equal(t, []int64{6}, search(t, "unique"))
+	})
+	t.Run("prefix", func(t *testing.T) {
+		setupFullTextSearch(t)
+
+		assert.Equal(t, []int64{6}, search(t, "uniq"))
+		assert.Equal(t, []int64{1}, search(t, "lor ips"))
+	})
+	t.Run("all terms must match", func(t *testing.T) {
+		setupFullTextSearch(t)
+
+		assert.Equal(t, []int64{3}, search(t, "high prio"))
+	})
+	t.Run("stemming", func(t *testing.T) {
+		setupFullTextSearch(t)
+		if db.Type() == schemas.MYSQL {
+			t.Skip("mysql does not stem words")
+		}
+
+		assert.Contains(t, search(t, "dates"), int64(7))
+	})
+	t.Run("ranked by relevance", func(t *testing.T) {
+		setupFullTextSearch(t)
+
+		s := db.NewSession()
+		_, err := s.Where("id = ?", 12).Cols("title").Update(&Task{Title: "unique basic task"})
+		require.NoError(t, err)
+		require.NoError(t, updateTasksInFullTextSearch(s, []int64{12}))
+		s.Close()
+
+		// Task 12 has the term in its title, task 6 only in the description
+		assert.Equal(t, []int64{12, 6}, search(t, "unique"))
+	})
+	t.Run("listener", func(t *testing.T) {
+		setupFullTextSearch(t)
+
+		s := db.NewSession()
+		_, err := s.Where("id = ?", 2).Cols("title").Update(&Task{Title: "Quarterly report"})
+		require.NoError(t, err)
+		s.Close()
+		assert.Empty(t, search(t, "quarterly"))
+
+		events.TestListener(t, &TaskUpdatedEvent{Task: &Task{ID: 2}}, &UpdateTaskInFullTextSearch{})
+		assert.Equal(t, []int64{2}, search(t, "quarterly"))
+
+		events.TestListener(t, &TaskDeletedEvent{Task: &Task{ID: 2}}, &RemoveTaskFromFullTextSearch{})
+		assert.Empty(t, search(t, "quarterly"))
+	})
+}
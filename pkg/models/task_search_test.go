@@ -54,3 +54,107 @@ func TestKanbanViewBucketFiltering(t *testing.T) {
 		assert.NotContains(t, taskBuckets, id)
 	}
 }
+
+func TestConvertTimeTrackingFilterToTypesense(t *testing.T) {
+	filters, err := getTaskFiltersFromFilterString("estimated_time >= 2h && time_spent < 30m", "UTC")
+	require.NoError(t, err)
+
+	filterBy, err := convertParsedFilterToTypesense(filters)
+	require.NoError(t, err)
+	assert.Equal(t, "estimated_time:>=7200 && time_spent:<1800", filterBy)
+
+	tt := convertTaskToTypesenseTask(&Task{ID: 1, EstimatedTime: 7200, TimeSpent: 1800}, nil, nil, nil)
+	assert.Equal(t, int64(7200), tt.EstimatedTime)
+	assert.Equal(t, int64(1800), tt.TimeSpent)
+}
+
+func TestConvertRelationFiltersToTypesense(t *testing.T) {
+	filters, err := getTaskFiltersFromFilterString("parent_task = 12 && has_subtasks = true && is_blocked = false && subtasks_done_percent < 50 && has_attachments = true && comment_count > 3 && created_by = alice", "UTC")
+	require.NoError(t, err)
+
+	filterBy, err := convertParsedFilterToTypesense(filters)
+	require.NoError(t, err)
+	assert.Equal(t, "parent_task_ids:=12 && has_subtasks:=true && is_blocked:=false && subtasks_done_percent:<50 && has_attachments:=true && comment_count:>3 && created_by:=alice", filterBy)
+
+	tt := convertTaskToTypesenseTask(&Task{
+		ID:          1,
+		IsBlocked:   true,
+		CreatedBy:   &user.User{Username: "alice"},
+		Attachments: []*TaskAttachment{{ID: 1}},
+		Comments:    []*TaskComment{{ID: 1}, {ID: 2}},
+		RelatedTasks: RelatedTaskMap{
+			RelationKindParenttask: {{ID: 12}},
+			RelationKindSubtask:    {{ID: 2, Done: true}, {ID: 3}, {ID: 4}, {ID: 5}},
+		},
+	}, nil, nil, nil)
+	assert.Equal(t, []int64{12}, tt.ParentTaskIDs)
+	assert.True(t, tt.HasSubtasks)
+	require.NotNil(t, tt.SubtasksDonePercent)
+	assert.InDelta(t, 25.0, *tt.SubtasksDonePercent, 0.001)
+	assert.True(t, tt.IsBlocked)
+	assert.True(t, tt.HasAttachments)
+	assert.Equal(t, int64(2), tt.CommentCount)
+	assert.Equal(t, "alice", tt.CreatedBy)
+
+	tt = convertTaskToTypesenseTask(&Task{ID: 2}, nil, nil, nil)
+	assert.False(t, tt.HasSubtasks)
+	assert.Nil(t, tt.SubtasksDonePercent)
+}
+
+func TestTaskSearchInCommentsAndAttachments(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	search := func(t *testing.T, searchText string, searchIn ...TaskSearchField) map[int64][]TaskSearchField {
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{ProjectID: 1, SearchIn: searchIn}
+		res, _, _, err := tc.ReadAll(s, u, searchText, 0, 50)
+		require.NoError(t, err)
+
+		matches := make(map[int64][]TaskSearchField)
+		for _, task := range res.([]*Task) {
+			matches[task.ID] = task.MatchedIn
+		}
+		return matches
+	}
+
+	t.Run("comments are not searched by default", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		assert.Empty(t, search(t, "dolor sit"))
+	})
+	t.Run("comments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		matches := search(t, "dolor sit", TaskSearchFieldComments)
+		require.Len(t, matches, 1)
+		assert.Equal(t, []TaskSearchField{TaskSearchFieldComments}, matches[1])
+	})
+	t.Run("attachments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		assert.Empty(t, search(t, "revenue forecast", TaskSearchFieldComments))
+
+		matches := search(t, "revenue forecast", TaskSearchFieldAttachments)
+		require.Len(t, matches, 1)
+		assert.Equal(t, []TaskSearchField{TaskSearchFieldAttachments}, matches[1])
+	})
+	t.Run("multiple places", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		matches := search(t, "lorem", TaskSearchFieldComments, TaskSearchFieldAttachments)
+		assert.Equal(t, []TaskSearchField{TaskSearchFieldDescription, TaskSearchFieldComments}, matches[1])
+	})
+	t.Run("title and identifier", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		matches := search(t, "#2")
+		assert.Equal(t, []TaskSearchField{TaskSearchFieldTitle, TaskSearchFieldIdentifier}, matches[2])
+	})
+	t.Run("invalid search in", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{ProjectID: 1, SearchIn: []TaskSearchField{TaskSearchFieldTitle}}
+		_, _, _, err := tc.ReadAll(s, u, "lorem", 0, 50)
+		require.Error(t, err)
+		assert.IsType(t, ValidationHTTPError{}, err)
+	})
+}
@@ -0,0 +1,309 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskTimeEntry is a span of time a user spent working on a task.
+// An entry without an end time is a running timer.
+type TaskTimeEntry struct {
+	// The unique, numeric id of this time entry.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"entry"`
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+
+	// The user who tracked this time entry.
+	User   *user.User `xorm:"-" json:"user"`
+	UserID int64      `xorm:"bigint not null INDEX" json:"-"`
+
+	// When this time entry started.
+	StartTime time.Time `xorm:"DATETIME not null INDEX 'start_time'" json:"start_time"`
+	// When this time entry ended. If this is not set, the time entry is a running timer.
+	EndTime time.Time `xorm:"DATETIME null INDEX 'end_time'" json:"end_time"`
+	// The duration of this time entry in seconds. You cannot change this value, it is calculated from the start and end time.
+	Duration int64 `xorm:"bigint not null default 0" json:"duration"`
+	// An optional note about what was done in this time.
+	Note string `xorm:"text null" json:"note" valid:"dbtext"`
+
+	// A timestamp when this time entry was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this time entry was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task time entries
+func (*TaskTimeEntry) TableName() string {
+	return "task_time_entries"
+}
+
+func (te *TaskTimeEntry) isRunning() bool {
+	return te.EndTime.IsZero()
+}
+
+func (te *TaskTimeEntry) calculateDuration() error {
+	if te.StartTime.IsZero() || (!te.EndTime.IsZero() && te.EndTime.Before(te.StartTime)) {
+		return ErrInvalidTimeEntryRange{Start: te.StartTime, End: te.EndTime}
+	}
+
+	te.Duration = 0
+	if !te.isRunning() {
+		te.Duration = int64(te.EndTime.Sub(te.StartTime).Seconds())
+	}
+	return nil
+}
+
+// Create adds a new time entry to a task
+// @Summary Add a time entry to a task
+// @Description Adds a finished time entry to a task for the current user. The user doing this need to have at least write access to the task. Use the timer endpoints to track time as it happens.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param entry body models.TaskTimeEntry true "The time entry object"
+// @Success 201 {object} models.TaskTimeEntry "The created time entry."
+// @Failure 400 {object} web.HTTPError "Invalid time entry object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/time_entries [put]
+func (te *TaskTimeEntry) Create(s *xorm.Session, a web.Auth) (err error) {
+	// Running timers can only be started through the timer endpoint
+	if te.EndTime.IsZero() {
+		return ErrInvalidTimeEntryRange{Start: te.StartTime, End: te.EndTime}
+	}
+
+	te.ID = 0
+	te.Created = time.Time{}
+	te.Updated = time.Time{}
+
+	err = te.calculateDuration()
+	if err != nil {
+		return err
+	}
+
+	te.User, err = GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+	te.UserID = te.User.ID
+
+	_, err = s.Insert(te)
+	if err != nil {
+		return err
+	}
+
+	return updateTaskTimeSpent(s, a, te.TaskID)
+}
+
+// Update changes a time entry
+// @Summary Update a time entry
+// @Description Updates the start time, end time or note of a time entry. Only the user who tracked the time entry can change it.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param entryID path int true "Time Entry ID"
+// @Param entry body models.TaskTimeEntry true "The time entry object"
+// @Success 200 {object} models.TaskTimeEntry "The updated time entry."
+// @Failure 400 {object} web.HTTPError "Invalid time entry object provided."
+// @Failure 404 {object} web.HTTPError "The time entry does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/time_entries/{entryID} [post]
+func (te *TaskTimeEntry) Update(s *xorm.Session, a web.Auth) (err error) {
+	old := &TaskTimeEntry{ID: te.ID, TaskID: te.TaskID}
+	err = getTaskTimeEntrySimple(s, old)
+	if err != nil {
+		return err
+	}
+
+	// A running timer keeps running until it is stopped
+	if old.isRunning() {
+		te.EndTime = time.Time{}
+	}
+	if !old.isRunning() && te.EndTime.IsZero() {
+		return ErrInvalidTimeEntryRange{Start: te.StartTime, End: te.EndTime}
+	}
+
+	err = te.calculateDuration()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(te.ID).
+		Cols("start_time", "end_time", "duration", "note").
+		Update(te)
+	if err != nil {
+		return err
+	}
+
+	te.UserID = old.UserID
+	te.Created = old.Created
+	users, err := getUsersOrLinkSharesFromIDs(s, []int64{te.UserID})
+	if err != nil {
+		return err
+	}
+	te.User = users[te.UserID]
+
+	return updateTaskTimeSpent(s, a, te.TaskID)
+}
+
+// Delete removes a time entry
+// @Summary Delete a time entry
+// @Description Deletes a time entry. Only the user who tracked the time entry can delete it.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param entryID path int true "Time Entry ID"
+// @Success 200 {object} models.Message "The time entry was successfully deleted."
+// @Failure 404 {object} web.HTTPError "The time entry does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/time_entries/{entryID} [delete]
+func (te *TaskTimeEntry) Delete(s *xorm.Session, a web.Auth) (err error) {
+	deleted, err := s.
+		Where("id = ? AND task_id = ?", te.ID, te.TaskID).
+		NoAutoCondition().
+		Delete(&TaskTimeEntry{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrTimeEntryDoesNotExist{ID: te.ID, TaskID: te.TaskID}
+	}
+
+	return updateTaskTimeSpent(s, a, te.TaskID)
+}
+
+// ReadAll returns all time entries of a task
+// @Summary Get all time entries of a task
+// @Description Returns all time entries of a task from all users, the most recent first. The user doing this need to have at least read access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.TaskTimeEntry "The time entries of the task"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/time_entries [get]
+func (te *TaskTimeEntry) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+
+	canRead, _, err := te.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	entries, err := getTimeEntriesForTasks(s, []int64{te.TaskID}, page, perPage)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.
+		Where("task_id = ?", te.TaskID).
+		Count(&TaskTimeEntry{})
+	return entries, len(entries), numberOfTotalItems, err
+}
+
+func getTaskTimeEntrySimple(s *xorm.Session, te *TaskTimeEntry) error {
+	exists, err := s.
+		Where("id = ? AND task_id = ?", te.ID, te.TaskID).
+		NoAutoCondition().
+		Get(te)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTimeEntryDoesNotExist{ID: te.ID, TaskID: te.TaskID}
+	}
+	return nil
+}
+
+func getTimeEntriesForTasks(s *xorm.Session, taskIDs []int64, page int, perPage int) (entries []*TaskTimeEntry, err error) {
+	entries = []*TaskTimeEntry{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	query := s.
+		Where(builder.In("task_id", taskIDs)).
+		OrderBy("start_time desc, id desc")
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries)
+	if err != nil {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		userIDs = append(userIDs, e.UserID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		e.User = users[e.UserID]
+	}
+
+	return
+}
+
+// updateTaskTimeSpent sums up all finished time entries of a task and saves the total with the task.
+// It dispatches a task updated event so search indexes pick up the new total.
+func updateTaskTimeSpent(s *xorm.Session, a web.Auth, taskID int64) error {
+	total, err := s.
+		Where("task_id = ?", taskID).
+		And(builder.NotNull{"end_time"}).
+		SumInt(&TaskTimeEntry{}, "duration")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(taskID).
+		Cols("time_spent").
+		NoAutoTime().
+		Update(&Task{TimeSpent: total})
+	if err != nil {
+		return err
+	}
+
+	return triggerTaskUpdatedEventForTaskID(s, a, taskID)
+}
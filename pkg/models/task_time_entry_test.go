@@ -0,0 +1,296 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskTimeEntry_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			TaskID:    1,
+			StartTime: time.Date(2018, 12, 4, 10, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2018, 12, 4, 10, 45, 0, 0, time.UTC),
+			Note:      "test",
+		}
+		err := te.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2700), te.Duration)
+		assert.Equal(t, int64(1), te.User.ID)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":       te.ID,
+			"task_id":  1,
+			"user_id":  1,
+			"duration": 2700,
+			"note":     "test",
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         1,
+			"time_spent": 2700,
+		}, false)
+	})
+	t.Run("ends before it starts", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			TaskID:    1,
+			StartTime: time.Date(2018, 12, 4, 10, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2018, 12, 4, 9, 0, 0, 0, time.UTC),
+		}
+		err := te.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTimeEntryRange(err))
+	})
+	t.Run("without end", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			TaskID:    1,
+			StartTime: time.Date(2018, 12, 4, 10, 0, 0, 0, time.UTC),
+		}
+		err := te.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTimeEntryRange(err))
+	})
+}
+
+func TestTaskTimeEntry_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:        2,
+			TaskID:    2,
+			StartTime: time.Date(2018, 12, 2, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2018, 12, 2, 10, 0, 0, 0, time.UTC),
+			Note:      "updated",
+		}
+		can, err := te.CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = te.Update(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3600), te.Duration)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":       2,
+			"duration": 3600,
+			"note":     "updated",
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         2,
+			"time_spent": 9000,
+		}, false)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:     9999,
+			TaskID: 2,
+		}
+		_, err := te.CanUpdate(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTimeEntryDoesNotExist(err))
+	})
+	t.Run("entry of another task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:     3,
+			TaskID: 2,
+		}
+		_, err := te.CanUpdate(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTimeEntryDoesNotExist(err))
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:     2,
+			TaskID: 2,
+		}
+		can, err := te.CanUpdate(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTaskTimeEntry_Delete(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:     1,
+			TaskID: 2,
+		}
+		err := te.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "task_time_entries", map[string]interface{}{
+			"id": 1,
+		})
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         2,
+			"time_spent": 1800,
+		}, false)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{
+			ID:     9999,
+			TaskID: 2,
+		}
+		err := te.Delete(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTimeEntryDoesNotExist(err))
+	})
+}
+
+func TestTaskTimeEntry_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 2}
+		result, resultCount, total, err := te.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		require.NoError(t, err)
+		assert.Equal(t, 2, resultCount)
+		assert.Equal(t, int64(2), total)
+
+		entries := result.([]*TaskTimeEntry)
+		assert.Equal(t, int64(2), entries[0].ID)
+		assert.Equal(t, int64(1), entries[1].ID)
+		assert.Equal(t, "user1", entries[0].User.Username)
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 2}
+		_, _, _, err := te.ReadAll(s, &user.User{ID: 2}, "", 0, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskTimer(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("start stops the running timer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTimer{TaskID: 1, Note: "working"}
+		err := tt.Create(s, u)
+		require.NoError(t, err)
+		assert.True(t, tt.TimeEntry.isRunning())
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":      tt.TimeEntry.ID,
+			"task_id": 1,
+			"user_id": 1,
+			"note":    "working",
+		}, false)
+		db.AssertMissing(t, "task_time_entries", map[string]interface{}{
+			"id":       4,
+			"duration": 0,
+		})
+	})
+	t.Run("stop", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTimer{TaskID: 3}
+		err := tt.Update(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), tt.TimeEntry.ID)
+		assert.False(t, tt.TimeEntry.isRunning())
+		assert.Positive(t, tt.TimeEntry.Duration)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         3,
+			"time_spent": tt.TimeEntry.Duration,
+		}, false)
+	})
+	t.Run("stop without running timer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTimer{TaskID: 1}
+		err := tt.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrNoRunningTimer(err))
+	})
+	t.Run("read running timer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTimer{TaskID: 3}
+		err := tt.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), tt.TimeEntry.ID)
+	})
+}
@@ -0,0 +1,281 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+const timeReportDateFormat = "2006-01-02"
+
+// TimeReport summarizes the time tracked in a project or by a user.
+type TimeReport struct {
+	// The project to summarize the time for. If this is not set, the report contains all time tracked by the current user on tasks they can read.
+	ProjectID int64 `json:"project_id" param:"project"`
+	// Only include time tracked by this user.
+	UserID int64 `json:"user_id" query:"user_id"`
+	// Only include time entries starting on or after this day, formatted as YYYY-MM-DD.
+	From string `json:"from" query:"from"`
+	// Only include time entries starting on or before this day, formatted as YYYY-MM-DD.
+	To string `json:"to" query:"to"`
+
+	// The total time in seconds of all time entries in this report.
+	Total int64 `json:"total"`
+	// The tracked time per day, in the timezone of the current user.
+	ByDay []*TimeReportDay `json:"by_day"`
+	// The tracked time per assignee of the tasks. Time on tasks with multiple assignees counts towards each of them,
+	// time on tasks without assignees is summarized in an entry without an assignee.
+	ByAssignee []*TimeReportAssignee `json:"by_assignee"`
+	// The tracked time per label of the tasks. Time on tasks with multiple labels counts towards each of them.
+	ByLabel []*TimeReportLabel `json:"by_label"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TimeReportDay is the time tracked on one day.
+type TimeReportDay struct {
+	Day      string `json:"day"`
+	Duration int64  `json:"duration"`
+}
+
+// TimeReportAssignee is the time tracked on tasks assigned to one user.
+type TimeReportAssignee struct {
+	Assignee *user.User `json:"assignee"`
+	Duration int64      `json:"duration"`
+}
+
+// TimeReportLabel is the time tracked on tasks with one label.
+type TimeReportLabel struct {
+	Label    *Label `json:"label"`
+	Duration int64  `json:"duration"`
+}
+
+// CanRead checks if a user can see the time report
+func (tr *TimeReport) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if tr.ProjectID == 0 {
+		_, isUser := a.(*user.User)
+		return isUser, int(PermissionRead), nil
+	}
+
+	p := &Project{ID: tr.ProjectID}
+	return p.CanRead(s, a)
+}
+
+func parseTimeReportDate(field string, value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.ParseInLocation(timeReportDateFormat, value, loc)
+	if err != nil {
+		return time.Time{}, InvalidFieldErrorWithMessage([]string{field}, "The date must be formatted as YYYY-MM-DD.")
+	}
+	return date, nil
+}
+
+// ReadOne returns the time report
+// @Summary Get a time report
+// @Description Summarizes all finished time entries of a project by day, assignee and label. When called without a project, it summarizes all time tracked by the current user on tasks they can still read.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param projectID path int true "Project ID"
+// @Param user_id query int false "Only include time tracked by this user."
+// @Param from query string false "Only include time entries starting on or after this day, formatted as YYYY-MM-DD."
+// @Param to query string false "Only include time entries starting on or before this day, formatted as YYYY-MM-DD."
+// @Success 200 {object} models.TimeReport "The time report."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 412 {object} web.HTTPError "Invalid date provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{projectID}/time_report [get]
+// @Router /time_report [get]
+func (tr *TimeReport) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	loc := config.GetTimeZone()
+	if u, is := a.(*user.User); is {
+		fullUser, err := user.GetUserByID(s, u.ID)
+		if err != nil {
+			return err
+		}
+		if fullUser.Timezone != "" {
+			loc, err = time.LoadLocation(fullUser.Timezone)
+			if err != nil {
+				return ErrInvalidTimezone{Name: fullUser.Timezone, LoadError: err}
+			}
+		}
+	}
+
+	from, err := parseTimeReportDate("from", tr.From, loc)
+	if err != nil {
+		return err
+	}
+	to, err := parseTimeReportDate("to", tr.To, loc)
+	if err != nil {
+		return err
+	}
+
+	cond := builder.And(builder.NotNull{"end_time"})
+	if tr.ProjectID != 0 {
+		cond = builder.And(cond, builder.In("task_id", builder.Select("id").From("tasks").Where(builder.Eq{"project_id": tr.ProjectID})))
+	} else {
+		tr.UserID = a.GetID()
+
+		// Like in the report of a project, only time on tasks the user can still read counts. This leaves out
+		// deleted tasks and tasks of projects the user lost access to.
+		var projects []*Project
+		projects, _, err = getAllProjectsForUser(s, a.GetID(), &projectOptions{
+			user:        &user.User{ID: a.GetID()},
+			getArchived: true,
+		})
+		if err != nil {
+			return err
+		}
+		projectIDs := make([]int64, 0, len(projects))
+		for _, p := range projects {
+			if p.ID > 0 {
+				projectIDs = append(projectIDs, p.ID)
+			}
+		}
+		cond = builder.And(cond, builder.In("task_id", builder.Select("id").From("tasks").Where(builder.In("project_id", projectIDs))))
+	}
+	if tr.UserID != 0 {
+		cond = builder.And(cond, builder.Eq{"user_id": tr.UserID})
+	}
+	if !from.IsZero() {
+		cond = builder.And(cond, builder.Gte{"start_time": from})
+	}
+	if !to.IsZero() {
+		cond = builder.And(cond, builder.Lt{"start_time": to.AddDate(0, 0, 1)})
+	}
+
+	entries := []*TaskTimeEntry{}
+	err = s.Where(cond).OrderBy("start_time asc").Find(&entries)
+	if err != nil {
+		return err
+	}
+
+	taskIDs := []int64{}
+	taskDurations := make(map[int64]int64)
+	days := make(map[string]int64)
+	tr.Total = 0
+	for _, e := range entries {
+		if _, has := taskDurations[e.TaskID]; !has {
+			taskIDs = append(taskIDs, e.TaskID)
+		}
+		taskDurations[e.TaskID] += e.Duration
+		days[e.StartTime.In(loc).Format(timeReportDateFormat)] += e.Duration
+		tr.Total += e.Duration
+	}
+
+	tr.ByDay = make([]*TimeReportDay, 0, len(days))
+	for day, duration := range days {
+		tr.ByDay = append(tr.ByDay, &TimeReportDay{Day: day, Duration: duration})
+	}
+	sort.Slice(tr.ByDay, func(i, j int) bool {
+		return tr.ByDay[i].Day < tr.ByDay[j].Day
+	})
+
+	tr.ByAssignee, err = getTimeReportByAssignee(s, taskIDs, taskDurations)
+	if err != nil {
+		return err
+	}
+
+	tr.ByLabel, err = getTimeReportByLabel(s, taskIDs, taskDurations)
+	return err
+}
+
+func getTimeReportByAssignee(s *xorm.Session, taskIDs []int64, taskDurations map[int64]int64) (byAssignee []*TimeReportAssignee, err error) {
+	byAssignee = []*TimeReportAssignee{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	assignees, err := getRawTaskAssigneesForTasks(s, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	assigneeMap := make(map[int64]*TimeReportAssignee)
+	assignedTasks := make(map[int64]bool)
+	for _, a := range assignees {
+		assignedTasks[a.TaskID] = true
+		if _, has := assigneeMap[a.ID]; !has {
+			u := a.User
+			assigneeMap[a.ID] = &TimeReportAssignee{Assignee: &u}
+			byAssignee = append(byAssignee, assigneeMap[a.ID])
+		}
+		assigneeMap[a.ID].Duration += taskDurations[a.TaskID]
+	}
+
+	unassigned := &TimeReportAssignee{}
+	for _, taskID := range taskIDs {
+		if !assignedTasks[taskID] {
+			unassigned.Duration += taskDurations[taskID]
+		}
+	}
+	if unassigned.Duration > 0 {
+		byAssignee = append(byAssignee, unassigned)
+	}
+
+	sort.SliceStable(byAssignee, func(i, j int) bool {
+		return byAssignee[i].Duration > byAssignee[j].Duration
+	})
+
+	return
+}
+
+func getTimeReportByLabel(s *xorm.Session, taskIDs []int64, taskDurations map[int64]int64) (byLabel []*TimeReportLabel, err error) {
+	byLabel = []*TimeReportLabel{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	labels := []*LabelWithTaskID{}
+	err = s.Table("label_tasks").
+		Select("label_tasks.task_id, labels.*").
+		In("label_tasks.task_id", taskIDs).
+		Join("INNER", "labels", "label_tasks.label_id = labels.id").
+		Find(&labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labelMap := make(map[int64]*TimeReportLabel)
+	for _, l := range labels {
+		if _, has := labelMap[l.ID]; !has {
+			label := l.Label
+			labelMap[l.ID] = &TimeReportLabel{Label: &label}
+			byLabel = append(byLabel, labelMap[l.ID])
+		}
+		labelMap[l.ID].Duration += taskDurations[l.TaskID]
+	}
+
+	sort.SliceStable(byLabel, func(i, j int) bool {
+		return byLabel[i].Duration > byLabel[j].Duration
+	})
+
+	return
+}
@@ -0,0 +1,123 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeReport_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TimeReport{ProjectID: 1}
+		err := tr.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10800), tr.Total)
+
+		require.Len(t, tr.ByDay, 2)
+		assert.Equal(t, "2018-12-01", tr.ByDay[0].Day)
+		assert.Equal(t, int64(5400), tr.ByDay[0].Duration)
+		assert.Equal(t, "2018-12-02", tr.ByDay[1].Day)
+		assert.Equal(t, int64(5400), tr.ByDay[1].Duration)
+
+		// Task 2 has no assignees, task 30 is assigned to user 1 and 2
+		require.Len(t, tr.ByAssignee, 3)
+		assert.Nil(t, tr.ByAssignee[0].Assignee)
+		assert.Equal(t, int64(7200), tr.ByAssignee[0].Duration)
+		assert.Equal(t, int64(3600), tr.ByAssignee[1].Duration)
+		assert.Equal(t, int64(3600), tr.ByAssignee[2].Duration)
+
+		require.Len(t, tr.ByLabel, 1)
+		assert.Equal(t, int64(4), tr.ByLabel[0].Label.ID)
+		assert.Equal(t, int64(7200), tr.ByLabel[0].Duration)
+	})
+	t.Run("date range", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TimeReport{ProjectID: 1, From: "2018-12-02", To: "2018-12-02"}
+		err := tr.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5400), tr.Total)
+		require.Len(t, tr.ByDay, 1)
+	})
+	t.Run("invalid date", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TimeReport{ProjectID: 1, From: "yesterday"}
+		err := tr.ReadOne(s, u)
+		require.Error(t, err)
+	})
+	t.Run("own time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TimeReport{}
+		can, _, err := tr.CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = tr.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tr.UserID)
+		assert.Equal(t, int64(10800), tr.Total)
+	})
+	t.Run("own time only on readable tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 1 has no access to project 2
+		_, err := s.Where("id = ?", 30).Cols("project_id").Update(&Task{ProjectID: 2})
+		require.NoError(t, err)
+
+		tr := &TimeReport{}
+		err = tr.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7200), tr.Total)
+
+		_, err = s.ID(2).Delete(&Task{})
+		require.NoError(t, err)
+
+		tr = &TimeReport{}
+		err = tr.ReadOne(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), tr.Total)
+	})
+	t.Run("no permission", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TimeReport{ProjectID: 1}
+		can, _, err := tr.CanRead(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -0,0 +1,178 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskTimer is the running timer of the current user on a task.
+// Each user can only have one running timer at a time, starting a new one stops the one which is currently running.
+type TaskTimer struct {
+	TaskID int64 `xorm:"-" json:"task_id" param:"task"`
+	// An optional note which will be saved with the time entry when starting a timer.
+	Note string `xorm:"-" json:"note" valid:"dbtext"`
+
+	// The time entry tracked by this timer.
+	TimeEntry *TaskTimeEntry `xorm:"-" json:"time_entry"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+func getRunningTimeEntryForUser(s *xorm.Session, userID int64, taskID int64) (entry *TaskTimeEntry, err error) {
+	cond := builder.And(
+		builder.Eq{"user_id": userID},
+		builder.IsNull{"end_time"},
+	)
+	if taskID != 0 {
+		cond = builder.And(cond, builder.Eq{"task_id": taskID})
+	}
+
+	entry = &TaskTimeEntry{}
+	exists, err := s.Where(cond).Get(entry)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNoRunningTimer{TaskID: taskID, UserID: userID}
+	}
+	return entry, nil
+}
+
+func stopTimeEntry(s *xorm.Session, a web.Auth, entry *TaskTimeEntry, endTime time.Time) (err error) {
+	entry.EndTime = endTime
+	err = entry.calculateDuration()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(entry.ID).
+		Cols("end_time", "duration").
+		Update(entry)
+	if err != nil {
+		return err
+	}
+
+	return updateTaskTimeSpent(s, a, entry.TaskID)
+}
+
+// Create starts a new timer
+// @Summary Start a timer on a task
+// @Description Starts tracking time on a task for the current user. If the user already has a running timer on any task, it is stopped first. The user doing this need to have at least write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param timer body models.TaskTimer true "The timer object with an optional note"
+// @Success 201 {object} models.TaskTimer "The started timer."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/timer [put]
+func (tt *TaskTimer) Create(s *xorm.Session, a web.Auth) (err error) {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	running, err := getRunningTimeEntryForUser(s, doer.ID, 0)
+	if err != nil && !IsErrNoRunningTimer(err) {
+		return err
+	}
+	if err == nil {
+		err = stopTimeEntry(s, a, running, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	tt.TimeEntry = &TaskTimeEntry{
+		TaskID:    tt.TaskID,
+		UserID:    doer.ID,
+		User:      doer,
+		StartTime: now,
+		Note:      tt.Note,
+	}
+	_, err = s.Insert(tt.TimeEntry)
+	return err
+}
+
+// Update stops a running timer
+// @Summary Stop the running timer on a task
+// @Description Stops the running timer of the current user on a task and returns the finished time entry.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Success 200 {object} models.TaskTimer "The stopped timer."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 404 {object} web.HTTPError "There is no running timer on this task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/timer/stop [post]
+func (tt *TaskTimer) Update(s *xorm.Session, a web.Auth) (err error) {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	tt.TimeEntry, err = getRunningTimeEntryForUser(s, doer.ID, tt.TaskID)
+	if err != nil {
+		return err
+	}
+	tt.TimeEntry.User = doer
+	tt.Note = tt.TimeEntry.Note
+
+	return stopTimeEntry(s, a, tt.TimeEntry, time.Now())
+}
+
+// ReadOne returns the running timer
+// @Summary Get the running timer on a task
+// @Description Returns the running timer of the current user on a task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Success 200 {object} models.TaskTimer "The running timer."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task"
+// @Failure 404 {object} web.HTTPError "There is no running timer on this task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/timer [get]
+func (tt *TaskTimer) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	tt.TimeEntry, err = getRunningTimeEntryForUser(s, doer.ID, tt.TaskID)
+	if err != nil {
+		return err
+	}
+	tt.TimeEntry.User = doer
+	tt.Note = tt.TimeEntry.Note
+	return nil
+}
@@ -71,6 +71,10 @@ type Task struct {
 	RepeatAfter int64 `xorm:"bigint INDEX null" json:"repeat_after" valid:"range(0|9223372036854775807)"`
 	// Can have three possible values which will trigger when the task is marked as done: 0 = repeats after the amount specified in repeat_after, 1 = repeats all dates each months (ignoring repeat_after), 3 = repeats from the current date rather than the last set date.
 	RepeatMode TaskRepeatMode `xorm:"not null default 0" json:"repeat_mode"`
+	// An RFC 5545 recurrence rule like `FREQ=MONTHLY;BYDAY=-1FR;COUNT=10`. If this is set, it takes precedence over repeat_after and repeat_mode. When marking the task as done, all dates and reminders are moved to the next occurrence of the rule. The rule itself is never changed, when the series ended the task stays done.
+	RepeatRule string `xorm:"text null" json:"repeat_rule"`
+	// The first occurrence of the repeat rule's series, COUNT and UNTIL of the rule are evaluated from it. It is set to the due date (or start or end date if there is none) whenever the repeat rule changes. You can only read this property.
+	RepeatRuleStart time.Time `xorm:"DATETIME null 'repeat_rule_start'" json:"repeat_rule_start"`
 	// The task priority. Can be anything you want, it is possible to sort by this later.
 	Priority int64 `xorm:"bigint null" json:"priority"`
 	// When this task starts.
@@ -85,6 +89,16 @@ type Task struct {
 	HexColor string `xorm:"varchar(6) null" json:"hex_color" valid:"runelength(0|7)" maxLength:"7"`
 	// Determines how far a task is left from being done
 	PercentDone float64 `xorm:"DOUBLE null" json:"percent_done"`
+	// The original estimate of how long this task will take, in seconds.
+	EstimatedTime int64 `xorm:"bigint not null default 0" json:"estimated_time" valid:"range(0|9223372036854775807)"`
+	// The total time tracked on this task in seconds, summed up from all finished time entries. You can only read this property, use the time entry and timer endpoints to track time.
+	TimeSpent int64 `xorm:"bigint not null default 0" json:"time_spent"`
+
+	// The values of the custom fields of the task's project, with the custom field id as key. Text and select fields
+	// hold a string, number fields a number, date fields a date, multiselect fields an array of strings and user
+	// fields the id of the user. When updating a task, only the fields present are changed, set a field to null to
+	// remove its value.
+	CustomFields map[int64]interface{} `xorm:"-" json:"custom_fields"`
 
 	// The task identifier, based on the project identifier and the task's index
 	Identifier string `xorm:"-" json:"identifier"`
@@ -106,10 +120,25 @@ type Task struct {
 	// True if a task is a favorite task. Favorite tasks show up in a separate "Important" project. This value depends on the user making the call to the api.
 	IsFavorite bool `xorm:"-" json:"is_favorite"`
 
+	// True if the task is blocked by at least one task which is not done yet. You can only read this property, use task relations to change it.
+	IsBlocked bool `xorm:"-" json:"is_blocked"`
+
 	// The subscription status for the user reading this task. You can only read this property, use the subscription endpoints to modify it.
 	// Will only returned when retrieving one task.
 	Subscription *Subscription `xorm:"-" json:"subscription,omitempty"`
 
+	// The most recent completed occurrences of this task if it is a repeating task, the most recent first. Use the occurrences endpoint of the task to get all of them. You can only read this property.
+	// Will only returned when retrieving one task.
+	Occurrences []*TaskOccurrence `xorm:"-" json:"occurrences,omitempty"`
+
+	// All tasks referencing this task with its identifier (like #42 or PROJ-17) in their description or one of their comments. You can only read this property.
+	// Will only returned when retrieving one task.
+	MentionedIn []*TaskReference `xorm:"-" json:"mentioned_in,omitempty"`
+
+	// Where the search matched this task, one of `title`, `description`, `identifier`, `comments` or `attachments`.
+	// Will only returned when searching for tasks.
+	MatchedIn []TaskSearchField `xorm:"-" json:"matched_in,omitempty"`
+
 	// A timestamp when this task was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
 	// A timestamp when this task was last updated. You cannot change this value.
@@ -173,7 +202,53 @@ func (t *Task) GetFrontendURL() string {
 
 func (t *Task) isRepeating() bool {
 	return t.RepeatAfter > 0 ||
-		t.RepeatMode == TaskRepeatModeMonth
+		t.RepeatMode == TaskRepeatModeMonth ||
+		t.RepeatRule != ""
+}
+
+// Validates the repeat rule of a task and brings it into its canonical form.
+func (t *Task) normalizeRepeatRule() error {
+	if t.RepeatRule == "" {
+		return nil
+	}
+
+	rule, err := ParseRepeatRule(t.RepeatRule)
+	if err != nil {
+		return err
+	}
+
+	t.RepeatRule = rule.String()
+	return nil
+}
+
+// Returns the date the dates of a repeating task are moved relative to. This is the due date, or the start or end
+// date if the task does not have a due date.
+func (t *Task) getRepeatReferenceDate() time.Time {
+	switch {
+	case !t.DueDate.IsZero():
+		return t.DueDate
+	case !t.StartDate.IsZero():
+		return t.StartDate
+	default:
+		return t.EndDate
+	}
+}
+
+// Sets the start of the series of the task's repeat rule. It is kept as long as the rule does not change, because
+// the occurrences counted by COUNT depend on it.
+func (t *Task) setRepeatRuleStart(ot *Task) {
+	switch {
+	case t.RepeatRule == "":
+		t.RepeatRuleStart = time.Time{}
+	case ot != nil && ot.RepeatRule == t.RepeatRule && !ot.RepeatRuleStart.IsZero():
+		t.RepeatRuleStart = ot.RepeatRuleStart
+	default:
+		t.RepeatRuleStart = t.getRepeatReferenceDate()
+		if t.RepeatRuleStart.IsZero() {
+			// Tasks without dates start the series when the rule is set
+			t.RepeatRuleStart = time.Now()
+		}
+	}
 }
 
 type taskFilterConcatinator string
@@ -185,6 +260,7 @@ const (
 
 type taskSearchOptions struct {
 	search             string
+	searchIn           []TaskSearchField
 	page               int
 	perPage            int
 	sortby             []*sortParam
@@ -213,6 +289,7 @@ type taskSearchOptions struct {
 // @Param filter_timezone query string false "The time zone which should be used for date match (statements like "now" resolve to different actual times)"
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
 // @Param expand query []string false "If set to `subtasks`, Vikunja will fetch only tasks which do not have subtasks and then in a second step, will fetch all of these subtasks. This may result in more tasks than the pagination limit being returned, but all subtasks will be present in the response. If set to `buckets`, the buckets of each task will be present in the response. If set to `reactions`, the reactions of each task will be present in the response. If set to `comments`, the first 50 comments of each task will be present in the response. You can set this multiple times with different values."
+// @Param search_in query []string false "Search the text of comments or attachments too. If set to `comments`, the search will also match the text of task comments. If set to `attachments`, the search will also match the text of txt, md, csv, html and pdf attachments. You can set this multiple times with different values. Where a search matched is returned as `matched_in` of each task."
 // @Security JWTKeyAuth
 // @Success 200 {array} models.Task "The tasks"
 // @Failure 500 {object} models.Message "Internal error"
@@ -299,6 +376,11 @@ func getRawTasksForProjects(s *xorm.Session, projects []*Project, a web.Auth, op
 
 	opts.search = strings.TrimSpace(opts.search)
 
+	err = resolveCustomFieldUsernames(s, opts.parsedFilters)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
 	var dbSearcher taskSearcher = &dbTaskSearcher{
 		s:                   s,
 		a:                   a,
@@ -317,10 +399,21 @@ func getRawTasksForProjects(s *xorm.Session, projects []*Project, a web.Auth, op
 			log.Warningf("Unable to fetch tasks from Typesense, error was '%v'. Falling back to db.", err)
 			tasks, totalItems, err = dbSearcher.Search(origOpts)
 		}
+	} else if bleveEnabled() {
+		var bleveSearcher taskSearcher = &bleveTaskSearcher{
+			s:                   s,
+			a:                   a,
+			hasFavoritesProject: hasFavoritesProject,
+		}
+		tasks, totalItems, err = bleveSearcher.Search(opts)
 	} else {
 		tasks, totalItems, err = dbSearcher.Search(opts)
 	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
 
+	err = addSearchMatchesToTasks(s, tasks, opts)
 	return tasks, len(tasks), totalItems, err
 }
 
@@ -637,6 +730,16 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth, vi
 		return
 	}
 
+	err = addCustomFieldValuesToTasks(s, taskIDs, taskMap)
+	if err != nil {
+		return
+	}
+
+	err = addIsBlockedToTasks(s, taskIDs, taskMap)
+	if err != nil {
+		return
+	}
+
 	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
 	if err != nil {
 		return
@@ -866,6 +969,15 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool, setB
 
 	t.HexColor = utils.NormalizeHex(t.HexColor)
 
+	err = t.normalizeRepeatRule()
+	if err != nil {
+		return err
+	}
+	t.setRepeatRuleStart(nil)
+
+	// The time spent is only ever calculated from the time entries of a task
+	t.TimeSpent = 0
+
 	_, err = s.Insert(t)
 	if err != nil {
 		return err
@@ -907,7 +1019,7 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool, setB
 
 	// Update the assignees
 	if updateAssignees {
-		if err := t.updateTaskAssignees(s, t.Assignees, a); err != nil {
+		if _, err := t.updateTaskAssignees(s, t.Assignees, a); err != nil {
 			return err
 		}
 	}
@@ -917,15 +1029,27 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool, setB
 		return err
 	}
 
+	if err := t.updateCustomFieldValues(s, t.CustomFields); err != nil {
+		return err
+	}
+
 	t.setIdentifier(p)
 
+	err = recordTaskActivity(s, a, &TaskActivity{
+		TaskID: t.ID,
+		Kind:   TaskActivityKindCreated,
+	})
+	if err != nil {
+		return err
+	}
+
 	if t.IsFavorite {
 		if err := addToFavorites(s, t.ID, createdBy, FavoriteKindTask); err != nil {
 			return err
 		}
 	}
 
-	err = events.Dispatch(&TaskCreatedEvent{
+	err = events.DispatchOnCommit(s, &TaskCreatedEvent{
 		Task: t,
 		Doer: createdBy,
 	})
@@ -1029,11 +1153,34 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return
 	}
+	originalTask := ot
 
 	if t.ProjectID == 0 {
 		t.ProjectID = ot.ProjectID
 	}
 
+	err = t.normalizeRepeatRule()
+	if err != nil {
+		return err
+	}
+	t.setRepeatRuleStart(&ot)
+
+	// The time spent can only be changed through time entries
+	t.TimeSpent = ot.TimeSpent
+
+	if !ot.Done && t.Done {
+		err = checkTaskCanBeDone(s, t)
+		if err != nil {
+			return err
+		}
+
+		// Completing the last occurrence of a repeat rule ends the series, the task is then done like any other
+		if t.RepeatRule == ot.RepeatRule && ot.isLastRepeatRuleOccurrence() {
+			t.RepeatRule = ""
+			t.RepeatRuleStart = time.Time{}
+		}
+	}
+
 	// Get the stored reminders
 	reminders, err := getRemindersForTasks(s, []int64{t.ID})
 	if err != nil {
@@ -1044,7 +1191,8 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	ot.Reminders = reminders
 
 	// Update the assignees
-	if err := ot.updateTaskAssignees(s, t.Assignees, a); err != nil {
+	assigneeChanges, err := ot.updateTaskAssignees(s, t.Assignees, a)
+	if err != nil {
 		return err
 	}
 
@@ -1061,9 +1209,12 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		"hex_color",
 		"done_at",
 		"percent_done",
+		"estimated_time",
 		"project_id",
 		"bucket_id",
 		"repeat_mode",
+		"repeat_rule",
+		"repeat_rule_start",
 		"cover_image_attachment_id",
 	}
 
@@ -1113,6 +1264,7 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 				TaskID:        t.ID,
 				ProjectViewID: view.ID,
 				ProjectID:     t.ProjectID,
+				skipActivity:  true,
 			}
 			err = tb.Update(s, a)
 			if err != nil {
@@ -1131,6 +1283,18 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
+	if t.ProjectID != ot.ProjectID {
+		err = removeCustomFieldValuesFromOtherProjects(s, t)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = t.updateCustomFieldValues(s, t.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	// When a task changed its done status, make sure it is in the correct bucket
 	if t.ProjectID == ot.ProjectID && !t.isRepeating() && t.Done != ot.Done {
 		err = t.moveTaskToDoneBuckets(s, a, views)
@@ -1139,6 +1303,14 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
+	// Keep the occurrence which was just completed before its dates are moved to the next one
+	if !ot.Done && t.Done && ot.isRepeating() {
+		err = saveTaskOccurrence(s, a, &ot, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
 	// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 	updateDone(&ot, t)
 
@@ -1246,10 +1418,19 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if t.PercentDone == 0 {
 		ot.PercentDone = 0
 	}
+	// Estimated time
+	if t.EstimatedTime == 0 {
+		ot.EstimatedTime = 0
+	}
 	// Repeat from current date
 	if t.RepeatMode == TaskRepeatModeDefault {
 		ot.RepeatMode = TaskRepeatModeDefault
 	}
+	// Repeat rule
+	if t.RepeatRule == "" {
+		ot.RepeatRule = ""
+		ot.RepeatRuleStart = time.Time{}
+	}
 	// Is Favorite
 	if !t.IsFavorite {
 		ot.IsFavorite = false
@@ -1261,7 +1442,7 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	_, err = s.ID(t.ID).
 		Cols(colsToUpdate...).
-		Update(ot)
+		Update(&ot)
 	*t = ot
 	if err != nil {
 		return err
@@ -1276,8 +1457,14 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 	t.Updated = nt.Updated
 
+	changes := append(getTaskFieldChanges(&originalTask, t), assigneeChanges...)
+	err = recordTaskChanges(s, a, t.ID, changes...)
+	if err != nil {
+		return err
+	}
+
 	doer, _ := user.GetFromAuth(a)
-	err = events.Dispatch(&TaskUpdatedEvent{
+	err = events.DispatchOnCommit(s, &TaskUpdatedEvent{
 		Task: t,
 		Doer: doer,
 	})
@@ -1327,6 +1514,7 @@ func (t *Task) moveTaskToDoneBuckets(s *xorm.Session, a web.Auth, views []*Proje
 			TaskID:        t.ID,
 			ProjectViewID: view.ID,
 			ProjectID:     t.ProjectID,
+			skipActivity:  true,
 		}
 		err = tb.Update(s, a)
 		if err != nil {
@@ -1494,6 +1682,82 @@ func setTaskDatesFromCurrentDateRepeat(oldTask, newTask *Task) {
 	newTask.Done = false
 }
 
+// Returns the occurrence of the task's repeat rule which follows the current one. It returns false if the series of
+// the rule ended.
+func (t *Task) getNextRepeatRuleOccurrence(rule *RepeatRule) (next time.Time, exists bool) {
+	current := t.getRepeatReferenceDate()
+
+	// The next occurrence is searched from the start of the series so that occurrences which passed while the task
+	// was not done count towards the COUNT of the rule as well.
+	seriesStart := t.RepeatRuleStart
+	if seriesStart.IsZero() {
+		seriesStart = current
+	}
+	if seriesStart.IsZero() {
+		seriesStart = time.Now()
+	}
+
+	after := time.Now()
+	if current.After(after) {
+		after = current
+	}
+
+	return rule.Next(seriesStart, after)
+}
+
+// Checks if the current occurrence of the task is the last one of the series of its repeat rule.
+func (t *Task) isLastRepeatRuleOccurrence() bool {
+	if t.RepeatRule == "" {
+		return false
+	}
+
+	rule, err := ParseRepeatRule(t.RepeatRule)
+	if err != nil {
+		return false
+	}
+
+	_, exists := t.getNextRepeatRuleOccurrence(rule)
+	return !exists
+}
+
+func setTaskDatesFromRepeatRule(oldTask, newTask *Task) {
+	rule, err := ParseRepeatRule(oldTask.RepeatRule)
+	if err != nil {
+		log.Errorf("Could not parse repeat rule of task %d: %s", oldTask.ID, err)
+		return
+	}
+
+	// All dates are moved by the same amount to keep the difference between them.
+	current := oldTask.getRepeatReferenceDate()
+
+	next, exists := oldTask.getNextRepeatRuleOccurrence(rule)
+	if !exists {
+		// The series ended, the task stays done
+		return
+	}
+
+	if !current.IsZero() {
+		diff := next.Sub(current)
+
+		if !oldTask.DueDate.IsZero() {
+			newTask.DueDate = oldTask.DueDate.Add(diff)
+		}
+		if !oldTask.StartDate.IsZero() {
+			newTask.StartDate = oldTask.StartDate.Add(diff)
+		}
+		if !oldTask.EndDate.IsZero() {
+			newTask.EndDate = oldTask.EndDate.Add(diff)
+		}
+
+		newTask.Reminders = oldTask.Reminders
+		for in, r := range oldTask.Reminders {
+			newTask.Reminders[in].Reminder = r.Reminder.Add(diff)
+		}
+	}
+
+	newTask.Done = false
+}
+
 // This helper function updates the reminders, doneAt, start and end dates of the *old* task
 // and saves the new values in the newTask object.
 // We make a few assumptions here:
@@ -1501,12 +1765,14 @@ func setTaskDatesFromCurrentDateRepeat(oldTask, newTask *Task) {
 //  2. Because of 1., this functions should not be used to update values other than Done in the same go
 func updateDone(oldTask *Task, newTask *Task) {
 	if !oldTask.Done && newTask.Done {
-		switch oldTask.RepeatMode {
-		case TaskRepeatModeMonth:
+		switch {
+		case oldTask.RepeatRule != "":
+			setTaskDatesFromRepeatRule(oldTask, newTask)
+		case oldTask.RepeatMode == TaskRepeatModeMonth:
 			setTaskDatesMonthRepeat(oldTask, newTask)
-		case TaskRepeatModeFromCurrentDate:
+		case oldTask.RepeatMode == TaskRepeatModeFromCurrentDate:
 			setTaskDatesFromCurrentDateRepeat(oldTask, newTask)
-		case TaskRepeatModeDefault:
+		case oldTask.RepeatMode == TaskRepeatModeDefault:
 			setTaskDatesDefault(oldTask, newTask)
 		}
 
@@ -1583,7 +1849,9 @@ func (t *Task) updateReminders(s *xorm.Session, task *Task) (err error) {
 			TaskID:         t.ID,
 			Reminder:       r.Reminder,
 			RelativePeriod: r.RelativePeriod,
-			RelativeTo:     r.RelativeTo}
+			RelativeTo:     r.RelativeTo,
+			RepeatInterval: max(r.RepeatInterval, 0),
+		}
 		_, err = s.Insert(taskReminder)
 		if err != nil {
 			return err
@@ -1611,7 +1879,7 @@ func updateTaskLastUpdated(s *xorm.Session, task *Task) error {
 
 // Delete implements the delete method for a task
 // @Summary Delete a task
-// @Description Deletes a task from a project. This does not mean "mark it done".
+// @Description Deletes a task from a project. This does not mean "mark it done". The task is moved to the trash and can be restored until the trash item expires.
 // @tags task
 // @Produce json
 // @Security JWTKeyAuth
@@ -1630,74 +1898,37 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
-	// Delete assignees
-	if _, err = s.Where("task_id = ?", t.ID).Delete(&TaskAssginee{}); err != nil {
-		return err
-	}
-
 	// Delete Favorites
 	err = removeFromFavorite(s, t.ID, a, FavoriteKindTask)
 	if err != nil {
 		return
 	}
 
-	// Delete label associations
-	_, err = s.Where("task_id = ?", t.ID).Delete(&LabelTask{})
-	if err != nil {
-		return
-	}
-
-	// Delete task attachments
-	attachments, err := getTaskAttachmentsByTaskIDs(s, []int64{t.ID})
+	project, err := GetProjectSimpleByID(s, fullTask.ProjectID)
 	if err != nil {
 		return err
 	}
-	for _, attachment := range attachments {
-		// Using the attachment delete method here because that takes care of removing all files properly
-		err = attachment.Delete(s, a)
-		if err != nil && !IsErrTaskAttachmentDoesNotExist(err) {
-			return err
-		}
-	}
-
-	// Delete all comments
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskComment{})
-	if err != nil {
-		return
-	}
-
-	// Delete all relations
-	_, err = s.Where("task_id = ? OR other_task_id = ?", t.ID, t.ID).Delete(&TaskRelation{})
-	if err != nil {
-		return
-	}
-
-	// Delete all reminders
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskReminder{})
-	if err != nil {
-		return
-	}
-
-	// Delete all positions
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskPosition{})
-	if err != nil {
-		return
-	}
 
-	// Delete all bucket relations
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskBucket{})
+	// Move the task to the trash, everything else is only deleted once the trash item expires
+	trashed, err := moveTasksToTrash(s, []int64{t.ID})
 	if err != nil {
-		return
+		return err
 	}
 
-	// Actually delete the task
-	_, err = s.ID(t.ID).Delete(Task{})
+	err = addToTrash(s, a, &TrashItem{
+		Kind:      TrashItemKindTask,
+		EntityID:  t.ID,
+		Title:     fullTask.Title,
+		ProjectID: fullTask.ProjectID,
+		OwnerID:   project.OwnerID,
+		Content:   &trashContent{Tasks: trashed},
+	})
 	if err != nil {
 		return err
 	}
 
 	doer, _ := user.GetFromAuth(a)
-	err = events.Dispatch(&TaskDeletedEvent{
+	err = events.DispatchOnCommit(s, &TaskDeletedEvent{
 		Task: fullTask,
 		Doer: doer,
 	})
@@ -1705,7 +1936,7 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
-	err = updateProjectLastUpdated(s, &Project{ID: t.ProjectID})
+	err = updateProjectLastUpdated(s, project)
 	return
 }
 
@@ -1758,7 +1989,13 @@ func (t *Task) ReadOne(s *xorm.Session, a web.Auth) (err error) {
 		t.Subscription = &subs.Subscription
 	}
 
-	return
+	t.Occurrences, err = getOccurrencesForTasks(s, []int64{t.ID}, 1, taskReadOneOccurrencesLimit)
+	if err != nil {
+		return err
+	}
+
+	t.MentionedIn, err = getTaskMentionedIn(s, a, t.ID)
+	return err
 }
 
 func triggerTaskUpdatedEventForTaskID(s *xorm.Session, auth web.Auth, taskID int64) error {
@@ -1768,7 +2005,7 @@ func triggerTaskUpdatedEventForTaskID(s *xorm.Session, auth web.Auth, taskID int
 	}
 
 	doer, _ := user.GetFromAuth(auth)
-	err = events.Dispatch(&TaskUpdatedEvent{
+	err = events.DispatchOnCommit(s, &TaskUpdatedEvent{
 		Task: &t,
 		Doer: doer,
 	})
@@ -217,6 +217,104 @@ func TestTask_Update(t *testing.T) {
 		require.Error(t, err)
 		assert.True(t, IsErrTaskDoesNotExist(err))
 	})
+	t.Run("repeat rule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:         1,
+			Title:      "test10000",
+			ProjectID:  1,
+			RepeatRule: "rrule:freq=weekly;interval=1;byday=mo,we",
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE", task.RepeatRule)
+		assert.False(t, task.RepeatRuleStart.IsZero())
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":          1,
+			"repeat_rule": "FREQ=WEEKLY;BYDAY=MO,WE",
+		}, false)
+
+		// The series keeps its start as long as the rule does not change
+		start := task.RepeatRuleStart
+		task = &Task{
+			ID:         1,
+			Title:      "test10000",
+			ProjectID:  1,
+			RepeatRule: "FREQ=WEEKLY;BYDAY=MO,WE",
+			DueDate:    time.Now().AddDate(0, 0, 3),
+		}
+		err = task.Update(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, start.Unix(), task.RepeatRuleStart.Unix())
+	})
+	t.Run("marking a repeating task done saves the occurrence", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:          1,
+			Title:       "test",
+			ProjectID:   1,
+			DueDate:     time.Date(2018, 12, 1, 10, 0, 0, 0, time.UTC),
+			RepeatAfter: 3600,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+
+		task.Done = true
+		err = task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		assert.False(t, task.Done)
+
+		db.AssertExists(t, "task_occurrences", map[string]interface{}{
+			"task_id":    1,
+			"done_by_id": 1,
+		}, false)
+	})
+	t.Run("marking a task done does not save an occurrence", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:        1,
+			Title:     "test",
+			ProjectID: 1,
+			Done:      true,
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "task_occurrences", map[string]interface{}{
+			"task_id": 1,
+		})
+	})
+	t.Run("invalid repeat rule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:         1,
+			Title:      "test10000",
+			ProjectID:  1,
+			RepeatRule: "FREQ=WEEKLY;BYDAY=1MO",
+		}
+		err := task.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRepeatRule(err))
+	})
 	t.Run("default bucket when moving a task between projects", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -334,6 +432,47 @@ func TestTask_Update(t *testing.T) {
 			"bucket_id": 1,
 		}, false)
 	})
+	t.Run("repeating tasks are moved to the done bucket when their series ended", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		dueDate := time.Date(2018, 12, 1, 10, 0, 0, 0, time.UTC)
+		task := &Task{
+			ID:         1,
+			Title:      "test",
+			ProjectID:  1,
+			DueDate:    dueDate,
+			RepeatRule: "FREQ=DAILY;COUNT=1",
+		}
+		err := task.Update(s, u)
+		require.NoError(t, err)
+
+		task = &Task{
+			ID:         1,
+			Title:      "test",
+			ProjectID:  1,
+			DueDate:    dueDate,
+			RepeatRule: "FREQ=DAILY;COUNT=1",
+			Done:       true,
+		}
+		err = task.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		assert.True(t, task.Done)
+		assert.Empty(t, task.RepeatRule)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":          1,
+			"done":        true,
+			"repeat_rule": "",
+		}, false)
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":   1,
+			"bucket_id": 3,
+		}, false)
+	})
 	t.Run("moving a task between projects should give it a correct index", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -829,6 +968,121 @@ func TestUpdateDone(t *testing.T) {
 				assert.False(t, newTask.Done)
 			})
 		})
+		t.Run("repeat rule", func(t *testing.T) {
+			t.Run("due date", func(t *testing.T) {
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=MONTHLY;BYDAY=-1FR",
+					DueDate:    time.Now().AddDate(0, 0, 1),
+				}
+				newTask := &Task{
+					Done: true,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.True(t, newTask.DueDate.After(oldTask.DueDate))
+				assert.Equal(t, time.Friday, newTask.DueDate.Weekday())
+				assert.NotEqual(t, newTask.DueDate.Month(), newTask.DueDate.AddDate(0, 0, 7).Month())
+				assert.False(t, newTask.Done)
+			})
+			t.Run("keeps the difference between dates and reminders", func(t *testing.T) {
+				dueDate := time.Date(2019, time.February, 12, 10, 0, 0, 0, time.UTC)
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+					DueDate:    dueDate,
+					StartDate:  dueDate.Add(-48 * time.Hour),
+					EndDate:    dueDate.Add(time.Hour),
+					Reminders: []*TaskReminder{
+						{
+							Reminder: dueDate.Add(-time.Hour),
+						},
+					},
+				}
+				newTask := &Task{
+					Done: true,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.True(t, newTask.DueDate.After(time.Now()))
+				assert.Equal(t, time.Tuesday, newTask.DueDate.Weekday())
+				assert.Equal(t, 0, int(newTask.DueDate.Sub(dueDate).Hours()/24)%14)
+				assert.Equal(t, 48*time.Hour, newTask.DueDate.Sub(newTask.StartDate))
+				assert.Equal(t, time.Hour, newTask.EndDate.Sub(newTask.DueDate))
+				assert.Equal(t, time.Hour, newTask.DueDate.Sub(newTask.Reminders[0].Reminder))
+				assert.False(t, newTask.Done)
+			})
+			t.Run("counts occurrences from the start of the series", func(t *testing.T) {
+				dueDate := time.Now().AddDate(0, 0, 1)
+				oldTask := &Task{
+					Done:            false,
+					RepeatRule:      "FREQ=DAILY;COUNT=3",
+					RepeatRuleStart: dueDate.AddDate(0, 0, -1),
+					DueDate:         dueDate,
+				}
+				newTask := &Task{
+					Done:       true,
+					RepeatRule: oldTask.RepeatRule,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, dueDate.AddDate(0, 0, 1), newTask.DueDate)
+				assert.Equal(t, "FREQ=DAILY;COUNT=3", newTask.RepeatRule)
+				assert.False(t, newTask.Done)
+			})
+			t.Run("last occurrence", func(t *testing.T) {
+				dueDate := time.Now().AddDate(0, 0, 1)
+				oldTask := &Task{
+					Done:            false,
+					RepeatRule:      "FREQ=DAILY;COUNT=1",
+					RepeatRuleStart: dueDate,
+					DueDate:         dueDate,
+				}
+				newTask := &Task{
+					Done:       true,
+					DueDate:    dueDate,
+					RepeatRule: oldTask.RepeatRule,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, dueDate, newTask.DueDate)
+				assert.Equal(t, "FREQ=DAILY;COUNT=1", newTask.RepeatRule)
+				assert.True(t, newTask.Done)
+			})
+			t.Run("occurrences which passed while the task was not done count", func(t *testing.T) {
+				// The series started five days ago and was never done, so all three occurrences already passed.
+				start := time.Now().AddDate(0, 0, -5)
+				oldTask := &Task{
+					Done:            false,
+					RepeatRule:      "FREQ=DAILY;COUNT=3",
+					RepeatRuleStart: start,
+					DueDate:         start,
+				}
+				newTask := &Task{
+					Done:    true,
+					DueDate: start,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, start, newTask.DueDate)
+				assert.True(t, newTask.Done)
+			})
+			t.Run("takes precedence over repeat after", func(t *testing.T) {
+				dueDate := time.Now().AddDate(0, 0, 1)
+				oldTask := &Task{
+					Done:        false,
+					RepeatAfter: 3600,
+					RepeatRule:  "FREQ=WEEKLY",
+					DueDate:     dueDate,
+				}
+				newTask := &Task{
+					Done: true,
+				}
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, dueDate.AddDate(0, 0, 7), newTask.DueDate)
+			})
+		})
 	})
 }
 
@@ -855,6 +1109,38 @@ func TestTask_ReadOne(t *testing.T) {
 		require.Error(t, err)
 		assert.True(t, IsErrTaskDoesNotExist(err))
 	})
+	t.Run("with occurrences", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 28}
+		err := task.ReadOne(s, u)
+		require.NoError(t, err)
+		require.Len(t, task.Occurrences, 2)
+		assert.Equal(t, int64(2), task.Occurrences[0].ID)
+		assert.Equal(t, int64(1), task.Occurrences[0].DoneBy.ID)
+	})
+	t.Run("only the most recent occurrences", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for i := 1; i <= taskReadOneOccurrencesLimit; i++ {
+			err := saveTaskOccurrence(s, u, &Task{ID: 28}, time.Now().AddDate(0, 0, i))
+			require.NoError(t, err)
+		}
+
+		task := &Task{ID: 28}
+		err := task.ReadOne(s, u)
+		require.NoError(t, err)
+		require.Len(t, task.Occurrences, taskReadOneOccurrencesLimit)
+		assert.True(t, task.Occurrences[0].DoneAt.After(task.Occurrences[1].DoneAt))
+		for _, o := range task.Occurrences {
+			assert.NotEqual(t, int64(1), o.ID)
+			assert.NotEqual(t, int64(2), o.ID)
+		}
+	})
 	t.Run("with subscription", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -70,7 +70,7 @@ func (tm *TeamMember) Create(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	doer, _ := user2.GetFromAuth(a)
-	return events.Dispatch(&TeamMemberAddedEvent{
+	return events.DispatchOnCommit(s, &TeamMemberAddedEvent{
 		Team:   team,
 		Member: member,
 		Doer:   doer,
@@ -213,7 +213,7 @@ func (t *Team) CreateNewTeam(s *xorm.Session, a web.Auth, firstUserShouldBeAdmin
 		return err
 	}
 
-	return events.Dispatch(&TeamCreatedEvent{
+	return events.DispatchOnCommit(s, &TeamCreatedEvent{
 		Team: t,
 		Doer: a,
 	})
@@ -352,7 +352,7 @@ func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
-	return events.Dispatch(&TeamDeletedEvent{
+	return events.DispatchOnCommit(s, &TeamDeletedEvent{
 		Team: t,
 		Doer: a,
 	})
@@ -371,7 +371,7 @@ func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 400 {object} web.HTTPError "Invalid team object provided."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /teams/{id} [post]
-func (t *Team) Update(s *xorm.Session, _ web.Auth) (err error) {
+func (t *Team) Update(s *xorm.Session, a web.Auth) (err error) {
 	// Check if we have a name
 	if t.Name == "" {
 		return ErrTeamNameCannotBeEmpty{}
@@ -390,9 +390,13 @@ func (t *Team) Update(s *xorm.Session, _ web.Auth) (err error) {
 
 	// Get the newly updated team
 	team, err := GetTeamByID(s, t.ID)
-	if team != nil {
-		*t = *team
+	if err != nil {
+		return
 	}
+	*t = *team
 
-	return
+	return events.DispatchOnCommit(s, &TeamUpdatedEvent{
+		Team: t,
+		Doer: a,
+	})
 }
@@ -0,0 +1,248 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/xorm"
+)
+
+// TemplateInstance holds everything needed to create a new project or task from a template
+type TemplateInstance struct {
+	// The template to use
+	TemplateID int64 `json:"-" param:"template"`
+	// All dates of the template are shifted relative to this date. Defaults to now.
+	AnchorDate time.Time `json:"anchor_date"`
+	// The title of the new project or task. Defaults to the title of the template for projects and the title of the saved task for tasks.
+	Title string `json:"title"`
+	// The parent of the new project when using a project template. If not provided, the project is created as a top-level project.
+	ParentProjectID int64 `json:"parent_project_id,omitempty"`
+	// The project to create the new task in when using a task template. Required for task templates.
+	ProjectID int64 `json:"project_id,omitempty"`
+
+	// The created project when using a project template.
+	Project *Project `json:"project,omitempty"`
+	// The created task when using a task template.
+	Task *Task `json:"task,omitempty"`
+
+	template *Template
+
+	web.Permissions `json:"-"`
+	web.CRUDable    `json:"-"`
+}
+
+// Create creates a new project or task from a template
+// @Summary Use a template
+// @Description Creates a new project or task from a template. All due, start and end dates as well as reminders are shifted so they have the same distance to the provided anchor date as they had to the anchor date of the template. Tasks created from a template are never done. Labels the user does not have access to are not added.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Param instance body models.TemplateInstance true "The anchor date and the parent project of the new project or the project of the new task."
+// @Success 201 {object} models.TemplateInstance "The created project or task."
+// @Failure 400 {object} web.HTTPError "Invalid template instance object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template or the target project."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id}/instantiate [put]
+func (ti *TemplateInstance) Create(s *xorm.Session, doer web.Auth) (err error) {
+	if ti.AnchorDate.IsZero() {
+		ti.AnchorDate = time.Now()
+	}
+
+	if ti.template.Content == nil {
+		ti.template.Content = &TemplateContent{}
+	}
+
+	log.Debugf("Using template %d with anchor date %s", ti.template.ID, ti.AnchorDate)
+
+	if ti.template.Kind == TemplateKindTask {
+		return ti.createTask(s, doer)
+	}
+
+	return ti.createProject(s, doer)
+}
+
+func (ti *TemplateInstance) createTask(s *xorm.Session, doer web.Auth) (err error) {
+	if len(ti.template.Content.Tasks) == 0 {
+		return &ErrTemplateDoesNotExist{TemplateID: ti.template.ID}
+	}
+
+	tt := ti.template.Content.Tasks[0]
+	ti.Task = tt.toTask(ti.AnchorDate)
+	ti.Task.ProjectID = ti.ProjectID
+	if ti.Title != "" {
+		ti.Task.Title = ti.Title
+	}
+
+	err = createTask(s, ti.Task, doer, false, true)
+	if err != nil {
+		return err
+	}
+
+	err = addTemplateLabelsToTask(s, doer, ti.Task.ID, tt.LabelIDs)
+	if err != nil {
+		return err
+	}
+
+	return ti.Task.ReadOne(s, doer)
+}
+
+func (ti *TemplateInstance) createProject(s *xorm.Session, doer web.Auth) (err error) {
+	content := ti.template.Content
+
+	ti.Project = &Project{
+		Title:               ti.template.Title,
+		Description:         content.Description,
+		HexColor:            content.HexColor,
+		EnforceDependencies: content.EnforceDependencies,
+		ParentProjectID:     ti.ParentProjectID,
+	}
+	if ti.Title != "" {
+		ti.Project.Title = ti.Title
+	}
+
+	// Projects created from templates without views get the default ones
+	withDefaultViews := len(content.Views) == 0
+	err = CreateProject(s, ti.Project, doer, withDefaultViews, withDefaultViews)
+	if err != nil {
+		return err
+	}
+
+	// Old custom field id as key, new custom field as value
+	fields := make(map[int64]*ProjectCustomField, len(content.CustomFields))
+	for _, field := range content.CustomFields {
+		oldID := field.ID
+		field.ID = 0
+		field.ProjectID = ti.Project.ID
+		err = field.Create(s, doer)
+		if err != nil {
+			return err
+		}
+		fields[oldID] = field
+	}
+
+	// Old task id as key, new task id as value
+	taskMap := make(map[int64]int64, len(content.Tasks))
+	for _, tt := range content.Tasks {
+		t := tt.toTask(ti.AnchorDate)
+		t.ProjectID = ti.Project.ID
+		t.CustomFields = getTemplateCustomFieldValues(tt, fields, ti.AnchorDate)
+
+		err = createTask(s, t, doer, false, false)
+		if err != nil {
+			return err
+		}
+		taskMap[tt.ID] = t.ID
+
+		err = addTemplateLabelsToTask(s, doer, t.ID, tt.LabelIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, r := range content.Relations {
+		taskID, exists := taskMap[r.TaskID]
+		if !exists {
+			continue
+		}
+		otherTaskID, exists := taskMap[r.OtherTaskID]
+		if !exists {
+			continue
+		}
+
+		_, err = s.Insert(&TaskRelation{
+			TaskID:       taskID,
+			OtherTaskID:  otherTaskID,
+			RelationKind: r.RelationKind,
+			CreatedByID:  doer.GetID(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = createViewsWithBuckets(s, ti.Project.ID, doer, taskMap, content.Views, content.Buckets, content.TaskBuckets, content.Positions)
+	if err != nil {
+		return err
+	}
+
+	return ti.Project.ReadOne(s, doer)
+}
+
+// getTemplateCustomFieldValues returns the custom field values of a template task for the new custom fields.
+// User fields are left out since the users may not have access to the new project.
+func getTemplateCustomFieldValues(tt *TemplateTask, fields map[int64]*ProjectCustomField, anchor time.Time) (values map[int64]interface{}) {
+	for oldID, value := range tt.CustomFields {
+		field, exists := fields[oldID]
+		if !exists || field.Type == ProjectCustomFieldTypeUser {
+			continue
+		}
+
+		if field.Type == ProjectCustomFieldTypeDate {
+			offset, is := getNumberFromCustomFieldValue(value)
+			if !is {
+				continue
+			}
+			seconds := int64(offset)
+			value = getDateFromTemplateOffset(anchor, &seconds)
+		}
+
+		if values == nil {
+			values = make(map[int64]interface{})
+		}
+		values[field.ID] = value
+	}
+
+	return
+}
+
+// addTemplateLabelsToTask adds all labels the user has access to to the task.
+func addTemplateLabelsToTask(s *xorm.Session, doer web.Auth, taskID int64, labelIDs []int64) (err error) {
+	for _, labelID := range labelIDs {
+		label, err := getLabelByIDSimple(s, labelID)
+		if err != nil {
+			if IsErrLabelDoesNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		hasAccess, _, err := label.hasAccessToLabel(s, doer)
+		if err != nil {
+			return err
+		}
+		if !hasAccess {
+			continue
+		}
+
+		_, err = s.Insert(&LabelTask{
+			LabelID: labelID,
+			TaskID:  taskID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,572 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TemplateKind defines what a template creates when it is used
+type TemplateKind int
+
+const (
+	TemplateKindProject TemplateKind = iota
+	TemplateKindTask
+)
+
+func (k *TemplateKind) MarshalJSON() ([]byte, error) {
+	switch *k {
+	case TemplateKindProject:
+		return []byte(`"project"`), nil
+	case TemplateKindTask:
+		return []byte(`"task"`), nil
+	}
+
+	return []byte(`null`), nil
+}
+
+func (k *TemplateKind) UnmarshalJSON(bytes []byte) error {
+	var value string
+	err := json.Unmarshal(bytes, &value)
+	if err != nil {
+		return err
+	}
+
+	switch value {
+	case "project":
+		*k = TemplateKindProject
+	case "task":
+		*k = TemplateKindTask
+	default:
+		return fmt.Errorf("unknown template kind: %s", value)
+	}
+
+	return nil
+}
+
+// Template is a reusable copy of a project or a single task.
+// All dates in a template are saved relative to its anchor date and shifted to a new anchor date when it is used.
+type Template struct {
+	// The unique numeric id of this template.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"template"`
+	// The title of the template. If not provided when creating a template, the title of the project or task is used.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"runelength(0|250)" maxLength:"250"`
+	// The description of the template.
+	Description string `xorm:"longtext null" json:"description"`
+	// Whether this template creates a project or a task. It is set from the source of the template, you cannot change this value.
+	Kind TemplateKind `xorm:"not null default 0" json:"kind" swaggertype:"string" enums:"project,task"`
+
+	// The project to create a project template from. Only used when creating a template.
+	ProjectID int64 `xorm:"-" json:"project_id,omitempty"`
+	// The task to create a task template from. Only used when creating a template.
+	TaskID int64 `xorm:"-" json:"task_id,omitempty"`
+
+	// The date all dates in the template are relative to. If not provided when creating the template, the earliest
+	// date of all tasks is used. You cannot change this value.
+	AnchorDate time.Time `xorm:"DATETIME not null 'anchor_date'" json:"anchor_date"`
+	// Everything this template creates when it is used. You cannot change this value, create a new template instead.
+	Content *TemplateContent `xorm:"json null" json:"content"`
+
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who owns this template.
+	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
+
+	// A timestamp when this template was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this template was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for templates
+func (*Template) TableName() string {
+	return "templates"
+}
+
+// TemplateContent holds everything a template creates when it is used.
+// Tasks, views and buckets keep the ids they had when the template was created, the relations, bucket assignments
+// and positions refer to them.
+type TemplateContent struct {
+	// The description of the project created from a project template.
+	Description string `json:"description,omitempty"`
+	// The color of the project created from a project template.
+	HexColor string `json:"hex_color,omitempty"`
+	// Whether the project created from a project template enforces task dependencies.
+	EnforceDependencies bool `json:"enforce_dependencies,omitempty"`
+
+	Tasks        []*TemplateTask       `json:"tasks"`
+	Relations    []*TaskRelation       `json:"relations,omitempty"`
+	Views        []*ProjectView        `json:"views,omitempty"`
+	Buckets      []*Bucket             `json:"buckets,omitempty"`
+	TaskBuckets  []*TaskBucket         `json:"task_buckets,omitempty"`
+	Positions    []*TaskPosition       `json:"positions,omitempty"`
+	CustomFields []*ProjectCustomField `json:"custom_fields,omitempty"`
+}
+
+// TemplateTask is a task saved in a template.
+// Its dates are saved as offsets in seconds from the anchor date of the template.
+type TemplateTask struct {
+	// The id of the task the template task was created from.
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Priority    int64          `json:"priority"`
+	HexColor    string         `json:"hex_color"`
+	PercentDone float64        `json:"percent_done"`
+	RepeatAfter int64          `json:"repeat_after"`
+	RepeatMode  TaskRepeatMode `json:"repeat_mode"`
+	RepeatRule  string         `json:"repeat_rule"`
+
+	EstimatedTime int64 `json:"estimated_time"`
+
+	DueDate   *int64 `json:"due_date"`
+	StartDate *int64 `json:"start_date"`
+	EndDate   *int64 `json:"end_date"`
+
+	Reminders []*TemplateTaskReminder `json:"reminders,omitempty"`
+	LabelIDs  []int64                 `json:"label_ids,omitempty"`
+	// The values of the custom fields of a project template. Date values are saved as offsets like all other dates.
+	CustomFields map[int64]interface{} `json:"custom_fields,omitempty"`
+}
+
+// TemplateTaskReminder is a reminder of a task saved in a template.
+type TemplateTaskReminder struct {
+	// The offset of an absolute reminder from the anchor date in seconds. Not set for relative reminders.
+	Reminder       *int64           `json:"reminder"`
+	RelativePeriod int64            `json:"relative_period"`
+	RelativeTo     ReminderRelation `json:"relative_to"`
+	RepeatInterval int64            `json:"repeat_interval"`
+}
+
+func getTemplateDateOffset(anchor, date time.Time) *int64 {
+	if date.IsZero() {
+		return nil
+	}
+
+	offset := int64(date.Sub(anchor).Seconds())
+	return &offset
+}
+
+func getDateFromTemplateOffset(anchor time.Time, offset *int64) time.Time {
+	if offset == nil {
+		return time.Time{}
+	}
+
+	return anchor.Add(time.Duration(*offset) * time.Second)
+}
+
+// getEarliestTaskDate returns the earliest due, start, end or absolute reminder date of all tasks.
+func getEarliestTaskDate(tasks []*Task) (earliest time.Time) {
+	check := func(date time.Time) {
+		if !date.IsZero() && (earliest.IsZero() || date.Before(earliest)) {
+			earliest = date
+		}
+	}
+
+	for _, t := range tasks {
+		check(t.DueDate)
+		check(t.StartDate)
+		check(t.EndDate)
+		for _, r := range t.Reminders {
+			if r.RelativeTo == "" {
+				check(r.Reminder)
+			}
+		}
+	}
+
+	return
+}
+
+func newTemplateTask(t *Task, anchor time.Time, fieldTypes map[int64]ProjectCustomFieldType) *TemplateTask {
+	tt := &TemplateTask{
+		ID:            t.ID,
+		Title:         t.Title,
+		Description:   t.Description,
+		Priority:      t.Priority,
+		HexColor:      t.HexColor,
+		PercentDone:   t.PercentDone,
+		RepeatAfter:   t.RepeatAfter,
+		RepeatMode:    t.RepeatMode,
+		RepeatRule:    t.RepeatRule,
+		EstimatedTime: t.EstimatedTime,
+		DueDate:       getTemplateDateOffset(anchor, t.DueDate),
+		StartDate:     getTemplateDateOffset(anchor, t.StartDate),
+		EndDate:       getTemplateDateOffset(anchor, t.EndDate),
+	}
+
+	for _, r := range t.Reminders {
+		reminder := &TemplateTaskReminder{
+			RelativePeriod: r.RelativePeriod,
+			RelativeTo:     r.RelativeTo,
+			RepeatInterval: r.RepeatInterval,
+		}
+		// Relative reminders are calculated from the task's dates when the template is used
+		if r.RelativeTo == "" {
+			reminder.Reminder = getTemplateDateOffset(anchor, r.Reminder)
+		}
+		tt.Reminders = append(tt.Reminders, reminder)
+	}
+
+	for _, l := range t.Labels {
+		tt.LabelIDs = append(tt.LabelIDs, l.ID)
+	}
+
+	for fieldID, value := range t.CustomFields {
+		fieldType, exists := fieldTypes[fieldID]
+		if !exists {
+			continue
+		}
+		if tt.CustomFields == nil {
+			tt.CustomFields = make(map[int64]interface{})
+		}
+		if date, is := value.(time.Time); is && fieldType == ProjectCustomFieldTypeDate {
+			value = int64(date.Sub(anchor).Seconds())
+		}
+		tt.CustomFields[fieldID] = value
+	}
+
+	return tt
+}
+
+// toTask returns a new task with all dates shifted to the anchor date.
+func (tt *TemplateTask) toTask(anchor time.Time) *Task {
+	t := &Task{
+		Title:         tt.Title,
+		Description:   tt.Description,
+		Priority:      tt.Priority,
+		HexColor:      tt.HexColor,
+		PercentDone:   tt.PercentDone,
+		RepeatAfter:   tt.RepeatAfter,
+		RepeatMode:    tt.RepeatMode,
+		RepeatRule:    tt.RepeatRule,
+		EstimatedTime: tt.EstimatedTime,
+		DueDate:       getDateFromTemplateOffset(anchor, tt.DueDate),
+		StartDate:     getDateFromTemplateOffset(anchor, tt.StartDate),
+		EndDate:       getDateFromTemplateOffset(anchor, tt.EndDate),
+	}
+
+	for _, r := range tt.Reminders {
+		t.Reminders = append(t.Reminders, &TaskReminder{
+			Reminder:       getDateFromTemplateOffset(anchor, r.Reminder),
+			RelativePeriod: r.RelativePeriod,
+			RelativeTo:     r.RelativeTo,
+			RepeatInterval: r.RepeatInterval,
+		})
+	}
+
+	return t
+}
+
+func (tmpl *Template) setContentFromProject(s *xorm.Session, a web.Auth) (err error) {
+	project, err := GetProjectSimpleByID(s, tmpl.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if tmpl.Title == "" {
+		tmpl.Title = project.Title
+	}
+
+	tasks, _, _, err := getTasksForProjects(s, []*Project{project}, a, &taskSearchOptions{}, nil)
+	if err != nil {
+		return err
+	}
+
+	if tmpl.AnchorDate.IsZero() {
+		tmpl.AnchorDate = getEarliestTaskDate(tasks)
+	}
+	if tmpl.AnchorDate.IsZero() {
+		tmpl.AnchorDate = time.Now()
+	}
+
+	fields, err := getCustomFieldsForProject(s, project.ID)
+	if err != nil {
+		return err
+	}
+	fieldTypes := make(map[int64]ProjectCustomFieldType, len(fields))
+	for _, field := range fields {
+		fieldTypes[field.ID] = field.Type
+	}
+
+	tmpl.Content = &TemplateContent{
+		Description:         project.Description,
+		HexColor:            project.HexColor,
+		EnforceDependencies: project.EnforceDependencies,
+		Tasks:               make([]*TemplateTask, 0, len(tasks)),
+		CustomFields:        fields,
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		tmpl.Content.Tasks = append(tmpl.Content.Tasks, newTemplateTask(t, tmpl.AnchorDate, fieldTypes))
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	// Only relations between tasks of the project can be recreated
+	tmpl.Content.Relations = []*TaskRelation{}
+	if len(taskIDs) > 0 {
+		err = s.
+			Where(builder.And(
+				builder.In("task_id", taskIDs),
+				builder.In("other_task_id", taskIDs),
+			)).
+			Find(&tmpl.Content.Relations)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpl.Content.Views, tmpl.Content.Buckets, tmpl.Content.TaskBuckets, tmpl.Content.Positions, err = getViewsWithBucketsForProject(s, project.ID)
+	if err != nil {
+		return err
+	}
+
+	// Tasks created from a template are never done, so they can't be in a done bucket either
+	for _, view := range tmpl.Content.Views {
+		if view.DoneBucketID == 0 {
+			continue
+		}
+		defaultBucketID, err := getDefaultBucketID(s, view)
+		if err != nil {
+			return err
+		}
+		for _, tb := range tmpl.Content.TaskBuckets {
+			if tb.BucketID == view.DoneBucketID {
+				tb.BucketID = defaultBucketID
+			}
+		}
+	}
+
+	return nil
+}
+
+func (tmpl *Template) setContentFromTask(s *xorm.Session, a web.Auth) (err error) {
+	t := &Task{ID: tmpl.TaskID}
+	err = t.ReadOne(s, a)
+	if err != nil {
+		return err
+	}
+
+	if tmpl.Title == "" {
+		tmpl.Title = t.Title
+	}
+
+	if tmpl.AnchorDate.IsZero() {
+		tmpl.AnchorDate = getEarliestTaskDate([]*Task{t})
+	}
+	if tmpl.AnchorDate.IsZero() {
+		tmpl.AnchorDate = time.Now()
+	}
+
+	// Custom fields belong to the project of the task, a task template can be used in any project.
+	tmpl.Content = &TemplateContent{
+		Tasks: []*TemplateTask{newTemplateTask(t, tmpl.AnchorDate, nil)},
+	}
+
+	return nil
+}
+
+func getTemplateByID(s *xorm.Session, id int64) (tmpl *Template, err error) {
+	tmpl = &Template{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrTemplateDoesNotExist{TemplateID: id}
+	}
+	return
+}
+
+// Create creates a new template
+// @Summary Create a template
+// @Description Creates a new template from a project or a single task. A project template contains the project's tasks with their labels, reminders and relations as well as its views, buckets and custom fields. All dates are saved relative to the anchor date of the template. The user needs read access to the project or task.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template body models.Template true "The template with the project or task to create it from."
+// @Success 201 {object} models.Template "The created template."
+// @Failure 400 {object} web.HTTPError "Invalid template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project or task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates [put]
+func (tmpl *Template) Create(s *xorm.Session, a web.Auth) (err error) {
+	tmpl.ID = 0
+	tmpl.OwnerID = a.GetID()
+
+	switch {
+	case tmpl.ProjectID != 0 && tmpl.TaskID == 0:
+		tmpl.Kind = TemplateKindProject
+		err = tmpl.setContentFromProject(s, a)
+	case tmpl.TaskID != 0 && tmpl.ProjectID == 0:
+		tmpl.Kind = TemplateKindTask
+		err = tmpl.setContentFromTask(s, a)
+	default:
+		return &ErrInvalidTemplateSource{ProjectID: tmpl.ProjectID, TaskID: tmpl.TaskID}
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Insert(tmpl)
+	if err != nil {
+		return err
+	}
+
+	tmpl.Owner, err = user.GetUserByID(s, tmpl.OwnerID)
+	return
+}
+
+// ReadOne returns one template
+// @Summary Get one template
+// @Description Returns a template by its ID.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.Template "The template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [get]
+func (tmpl *Template) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	// The template was already loaded in the permission check
+	tmpl.Owner, err = user.GetUserByID(s, tmpl.OwnerID)
+	return
+}
+
+// ReadAll returns all templates of the current user
+// @Summary Get all templates
+// @Description Returns all templates the current user created.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search templates by title."
+// @Success 200 {array} models.Template "The templates"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates [get]
+func (tmpl *Template) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	where := builder.And(builder.Eq{"owner_id": a.GetID()})
+	if search != "" {
+		where = builder.And(where, db.ILIKE("title", search))
+	}
+
+	templates := []*Template{}
+	query := s.Where(where).OrderBy("id asc")
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	owner, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, t := range templates {
+		t.Owner = owner
+	}
+
+	numberOfTotalItems, err = s.Where(where).Count(&Template{})
+	return templates, len(templates), numberOfTotalItems, err
+}
+
+// Update updates a template
+// @Summary Update a template
+// @Description Updates the title and description of a template. To change its content, create a new template.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Param template body models.Template true "The template"
+// @Success 200 {object} models.Template "The updated template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [post]
+func (tmpl *Template) Update(s *xorm.Session, a web.Auth) (err error) {
+	old, err := getTemplateByID(s, tmpl.ID)
+	if err != nil {
+		return err
+	}
+
+	if tmpl.Title == "" {
+		tmpl.Title = old.Title
+	}
+
+	_, err = s.
+		ID(tmpl.ID).
+		Cols("title", "description").
+		Update(tmpl)
+	if err != nil {
+		return err
+	}
+
+	description := tmpl.Description
+	title := tmpl.Title
+	*tmpl = *old
+	tmpl.Title = title
+	tmpl.Description = description
+
+	return tmpl.ReadOne(s, a)
+}
+
+// Delete deletes a template
+// @Summary Delete a template
+// @Description Deletes a template. Projects and tasks created from it are not changed.
+// @tags template
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.Message "The template was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [delete]
+func (tmpl *Template) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", tmpl.ID).
+		Delete(&Template{})
+	return
+}
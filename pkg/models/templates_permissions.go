@@ -0,0 +1,104 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if a user has the permission to read a template
+func (tmpl *Template) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := tmpl.canDoTemplate(s, a)
+	return can, int(PermissionAdmin), err
+}
+
+// CanDelete checks if a user has the permission to delete a template
+func (tmpl *Template) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return tmpl.canDoTemplate(s, a)
+}
+
+// CanUpdate checks if a user has the permission to update a template
+func (tmpl *Template) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	// A normal check would replace the passed struct which in our case would override the values we want to update.
+	t := &Template{ID: tmpl.ID}
+	return t.canDoTemplate(s, a)
+}
+
+// CanCreate checks if a user has the permission to create a template from a project or task
+func (tmpl *Template) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	switch {
+	case tmpl.ProjectID != 0 && tmpl.TaskID == 0:
+		p := &Project{ID: tmpl.ProjectID}
+		can, _, err := p.CanRead(s, a)
+		return can, err
+	case tmpl.TaskID != 0 && tmpl.ProjectID == 0:
+		t := &Task{ID: tmpl.TaskID}
+		can, _, err := t.CanRead(s, a)
+		return can, err
+	default:
+		return false, &ErrInvalidTemplateSource{ProjectID: tmpl.ProjectID, TaskID: tmpl.TaskID}
+	}
+}
+
+// Helper function to check template permissions since they all have the same logic
+func (tmpl *Template) canDoTemplate(s *xorm.Session, a web.Auth) (can bool, err error) {
+	// Templates belong to users, link shares can't have any
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	t, err := getTemplateByID(s, tmpl.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// Only owners are allowed to do something with a template
+	if t.OwnerID != a.GetID() {
+		return false, nil
+	}
+
+	*tmpl = *t
+
+	return true, nil
+}
+
+// CanCreate checks if a user has the permission to use a template. They need to own the template and be able
+// to create a project in the parent project or a task in the project.
+func (ti *TemplateInstance) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	ti.template = &Template{ID: ti.TemplateID}
+	can, err := ti.template.canDoTemplate(s, a)
+	if err != nil || !can {
+		return can, err
+	}
+
+	if ti.template.Kind == TemplateKindTask {
+		if ti.ProjectID == 0 {
+			return false, &ErrTemplateNeedsProject{TemplateID: ti.TemplateID}
+		}
+
+		t := &Task{ProjectID: ti.ProjectID}
+		return t.CanCreate(s, a)
+	}
+
+	p := &Project{ParentProjectID: ti.ParentProjectID}
+	return p.CanCreate(s, a)
+}
@@ -0,0 +1,345 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("from project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ProjectID: 1}
+		can, err := tmpl.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = tmpl.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, TemplateKindProject, tmpl.Kind)
+		assert.Equal(t, "Test1", tmpl.Title)
+		assert.Equal(t, int64(1), tmpl.OwnerID)
+		assert.NotEmpty(t, tmpl.Content.Tasks)
+		assert.NotEmpty(t, tmpl.Content.Views)
+		assert.NotEmpty(t, tmpl.Content.Buckets)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "templates", map[string]interface{}{
+			"id":       tmpl.ID,
+			"title":    "Test1",
+			"kind":     TemplateKindProject,
+			"owner_id": 1,
+		}, false)
+	})
+	t.Run("from task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		anchor := time.Date(2018, 12, 1, 0, 0, 0, 0, config.GetTimeZone())
+		tmpl := &Template{
+			TaskID:     5,
+			Title:      "Due date template",
+			AnchorDate: anchor,
+		}
+		can, err := tmpl.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = tmpl.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, TemplateKindTask, tmpl.Kind)
+		assert.Equal(t, "Due date template", tmpl.Title)
+		require.Len(t, tmpl.Content.Tasks, 1)
+		assert.Equal(t, "task #5 higher due date", tmpl.Content.Tasks[0].Title)
+		require.NotNil(t, tmpl.Content.Tasks[0].DueDate)
+		assert.Equal(t, int64(3*60*60+58*60+44), *tmpl.Content.Tasks[0].DueDate)
+		assert.Nil(t, tmpl.Content.Tasks[0].StartDate)
+	})
+	t.Run("without source", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{}
+		_, err := tmpl.CanCreate(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTemplateSource(err))
+	})
+	t.Run("with project and task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ProjectID: 1, TaskID: 1}
+		err := tmpl.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTemplateSource(err))
+	})
+	t.Run("no access to project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ProjectID: 2}
+		can, err := tmpl.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ProjectID: 1}
+		can, err := tmpl.CanCreate(s, &LinkSharing{ID: 1, ProjectID: 1})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTemplate_ReadOne(t *testing.T) {
+	t.Run("owner", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ID: 1}
+		can, _, err := tmpl.CanRead(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = tmpl.ReadOne(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "Prepare release notes", tmpl.Title)
+		assert.Equal(t, TemplateKindTask, tmpl.Kind)
+		assert.Equal(t, int64(1), tmpl.Owner.ID)
+		require.Len(t, tmpl.Content.Tasks, 1)
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ID: 1}
+		can, _, err := tmpl.CanRead(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ID: 9999}
+		_, _, err := tmpl.CanRead(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrTemplateDoesNotExist(err))
+	})
+}
+
+func TestTemplate_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tmpl := &Template{}
+	result, count, total, err := tmpl.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), total)
+	templates := result.([]*Template)
+	assert.Equal(t, int64(1), templates[0].ID)
+}
+
+func TestTemplate_Update(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tmpl := &Template{
+		ID:    1,
+		Title: "Updated",
+		Kind:  TemplateKindProject,
+	}
+	u := &user.User{ID: 1}
+	can, err := tmpl.CanUpdate(s, u)
+	require.NoError(t, err)
+	assert.True(t, can)
+	err = tmpl.Update(s, u)
+	require.NoError(t, err)
+	err = s.Commit()
+	require.NoError(t, err)
+
+	db.AssertExists(t, "templates", map[string]interface{}{
+		"id":    1,
+		"title": "Updated",
+		"kind":  TemplateKindTask,
+	}, false)
+}
+
+func TestTemplate_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tmpl := &Template{ID: 1}
+	u := &user.User{ID: 1}
+	can, err := tmpl.CanDelete(s, u)
+	require.NoError(t, err)
+	assert.True(t, can)
+	err = tmpl.Delete(s, u)
+	require.NoError(t, err)
+	err = s.Commit()
+	require.NoError(t, err)
+
+	db.AssertMissing(t, "templates", map[string]interface{}{"id": 1})
+}
+
+func TestTemplateInstance_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	anchor := time.Date(2020, 1, 1, 0, 0, 0, 0, config.GetTimeZone())
+
+	t.Run("task template", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TemplateInstance{
+			TemplateID: 1,
+			ProjectID:  1,
+			AnchorDate: anchor,
+		}
+		can, err := ti.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		require.NoError(t, err)
+		require.NotNil(t, ti.Task)
+		assert.Equal(t, "Prepare release notes", ti.Task.Title)
+		assert.Equal(t, int64(1), ti.Task.ProjectID)
+		assert.Equal(t, anchor.Add(24*time.Hour).Unix(), ti.Task.DueDate.Unix())
+		require.Len(t, ti.Task.Reminders, 2)
+		assert.Equal(t, anchor.Add(time.Hour).Unix(), ti.Task.Reminders[0].Reminder.Unix())
+		assert.Equal(t, int64(-7200), ti.Task.Reminders[1].RelativePeriod)
+		assert.Equal(t, ReminderRelationDueDate, ti.Task.Reminders[1].RelativeTo)
+		// Label 3 belongs to another user and is not added
+		require.Len(t, ti.Task.Labels, 1)
+		assert.Equal(t, int64(1), ti.Task.Labels[0].ID)
+	})
+	t.Run("task template without project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TemplateInstance{TemplateID: 1}
+		_, err := ti.CanCreate(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTemplateNeedsProject(err))
+	})
+	t.Run("task template in project without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TemplateInstance{TemplateID: 1, ProjectID: 2}
+		can, err := ti.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("template of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TemplateInstance{TemplateID: 2}
+		can, err := ti.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("project template", func(t *testing.T) {
+		files.InitTestFileFixtures(t)
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tmpl := &Template{ProjectID: 1}
+		err := tmpl.Create(s, u)
+		require.NoError(t, err)
+
+		shift := 24 * time.Hour
+		ti := &TemplateInstance{
+			TemplateID:      tmpl.ID,
+			AnchorDate:      tmpl.AnchorDate.Add(shift),
+			Title:           "From template",
+			ParentProjectID: 1,
+		}
+		can, err := ti.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		require.NoError(t, err)
+		require.NotNil(t, ti.Project)
+		assert.Equal(t, "From template", ti.Project.Title)
+		assert.Equal(t, int64(1), ti.Project.ParentProjectID)
+
+		originalTasks, err := s.Where("project_id = ?", 1).OrderBy("id").FindAndCount(&[]*Task{})
+		require.NoError(t, err)
+		var newTasks []*Task
+		err = s.Where("project_id = ?", ti.Project.ID).OrderBy("id").Find(&newTasks)
+		require.NoError(t, err)
+		assert.Equal(t, originalTasks, int64(len(newTasks)))
+
+		var original *Task
+		for _, nt := range newTasks {
+			assert.False(t, nt.Done, "task %d should not be done", nt.ID)
+			if nt.Title == "task #5 higher due date" {
+				original = nt
+			}
+		}
+		require.NotNil(t, original)
+		assert.Equal(t, time.Date(2018, 12, 2, 3, 58, 44, 0, config.GetTimeZone()).Unix(), original.DueDate.Unix())
+
+		originalViews, err := s.Where("project_id = ?", 1).Count(&ProjectView{})
+		require.NoError(t, err)
+		newViews, err := s.Where("project_id = ?", ti.Project.ID).Count(&ProjectView{})
+		require.NoError(t, err)
+		assert.Equal(t, originalViews, newViews)
+	})
+	t.Run("project template with parent without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TemplateInstance{TemplateID: 2, ParentProjectID: 1}
+		can, err := ti.CanCreate(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
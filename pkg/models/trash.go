@@ -0,0 +1,1135 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TrashItemKind is the kind of entity in the trash
+type TrashItemKind string
+
+// All kinds of entities which can be moved to the trash
+const (
+	TrashItemKindTask    TrashItemKind = `task`
+	TrashItemKindProject TrashItemKind = `project`
+)
+
+// TrashItem is a deleted task or project which can still be restored.
+// Deleting a task or project moves it into the trash together with everything needed to restore it. It is kept there
+// for the configured retention period and permanently deleted afterwards.
+type TrashItem struct {
+	// The unique, numeric id of this trash item.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"trashitem"`
+	// Whether this is a deleted task or project.
+	Kind TrashItemKind `xorm:"varchar(20) not null" json:"kind"`
+	// The id of the deleted task or project. It will get the same id again when it is restored, unless another task got the id of a deleted task in the meantime.
+	EntityID int64 `xorm:"bigint not null INDEX" json:"entity_id"`
+	// The title of the deleted task or project.
+	Title string `xorm:"text not null" json:"title"`
+	// The project a deleted task belonged to or the parent project of a deleted project.
+	ProjectID int64 `xorm:"bigint null" json:"project_id"`
+
+	// The owner of the project the deleted entity belonged to.
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+
+	// The user who deleted the task or project.
+	DeletedBy   *user.User `xorm:"-" json:"deleted_by"`
+	DeletedByID int64      `xorm:"bigint not null INDEX" json:"-"`
+
+	Content *trashContent `xorm:"json longtext null" json:"-"`
+
+	// A timestamp when the task or project was deleted.
+	Created time.Time `xorm:"created not null INDEX" json:"created"`
+	// A timestamp when the task or project will be permanently deleted.
+	Expires time.Time `xorm:"-" json:"expires"`
+
+	web.CRUDable    `xorm:"-" json:"-"`
+	web.Permissions `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for trash items
+func (*TrashItem) TableName() string {
+	return "trash_items"
+}
+
+// trashContent holds everything which is removed when deleting a task or project and is needed to restore it.
+// Data like comments, attachments or assignees is only referenced by the task id, it stays where it is until the
+// trash item is purged. Its task id is negated though, see moveTaskDataToTrash.
+type trashContent struct {
+	Tasks    []*trashedTask    `json:"tasks,omitempty"`
+	Projects []*trashedProject `json:"projects,omitempty"`
+}
+
+// The trashed entities keep the columns which are not exposed through the api in extra fields.
+
+type trashedTask struct {
+	Task        *Task              `json:"task"`
+	UID         string             `json:"uid"`
+	CreatedByID int64              `json:"created_by_id"`
+	Buckets     []*TaskBucket      `json:"buckets"`
+	Positions   []*TaskPosition    `json:"positions"`
+	Relations   []*trashedRelation `json:"relations"`
+	LabelIDs    []int64            `json:"label_ids"`
+}
+
+type trashedRelation struct {
+	TaskID       int64        `json:"task_id"`
+	OtherTaskID  int64        `json:"other_task_id"`
+	RelationKind RelationKind `json:"relation_kind"`
+	CreatedByID  int64        `json:"created_by_id"`
+	Created      time.Time    `json:"created"`
+}
+
+type trashedProject struct {
+	Project          *Project              `json:"project"`
+	OwnerID          int64                 `json:"owner_id"`
+	BackgroundFileID int64                 `json:"background_file_id"`
+	Views            []*ProjectView        `json:"views"`
+	Buckets          []*trashedBucket      `json:"buckets"`
+	LinkShares       []*trashedLinkShare   `json:"link_shares"`
+	Users            []*trashedProjectUser `json:"users"`
+	Teams            []*TeamProject        `json:"teams"`
+}
+
+type trashedBucket struct {
+	Bucket      *Bucket `json:"bucket"`
+	CreatedByID int64   `json:"created_by_id"`
+}
+
+type trashedLinkShare struct {
+	Share      *LinkSharing `json:"share"`
+	SharedByID int64        `json:"shared_by_id"`
+}
+
+type trashedProjectUser struct {
+	Share  *ProjectUser `json:"share"`
+	UserID int64        `json:"user_id"`
+}
+
+func getTrashRetention() time.Duration {
+	return time.Duration(config.ServiceTrashRetentionDays.GetInt()) * 24 * time.Hour
+}
+
+// addToTrash saves a new trash item. If the trash is disabled, the item is purged right away.
+func addToTrash(s *xorm.Session, a web.Auth, item *TrashItem) (err error) {
+	item.ID = 0
+	item.DeletedByID = a.GetID()
+	// The link share could be part of what was just deleted, hence we can't look it up here
+	if share, is := a.(*LinkSharing); is {
+		item.DeletedByID = share.getUserID()
+	}
+	_, err = s.Insert(item)
+	if err != nil {
+		return err
+	}
+
+	if getTrashRetention() <= 0 {
+		return item.purge(s)
+	}
+
+	return nil
+}
+
+// moveTasksToTrash removes the tasks with their buckets, positions, relations and labels from the db
+// and returns everything needed to restore them.
+func moveTasksToTrash(s *xorm.Session, taskIDs []int64) (trashed []*trashedTask, err error) {
+	trashed = []*trashedTask{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	tasks := []*Task{}
+	err = s.In("id", taskIDs).OrderBy("id asc").Find(&tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	trashedByID := make(map[int64]*trashedTask, len(tasks))
+	for _, t := range tasks {
+		tt := &trashedTask{
+			Task:        t,
+			UID:         t.UID,
+			CreatedByID: t.CreatedByID,
+			Buckets:     []*TaskBucket{},
+			Positions:   []*TaskPosition{},
+			Relations:   []*trashedRelation{},
+			LabelIDs:    []int64{},
+		}
+		trashed = append(trashed, tt)
+		trashedByID[t.ID] = tt
+	}
+
+	buckets := []*TaskBucket{}
+	err = s.In("task_id", taskIDs).Find(&buckets)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		trashedByID[b.TaskID].Buckets = append(trashedByID[b.TaskID].Buckets, b)
+	}
+
+	positions := []*TaskPosition{}
+	err = s.In("task_id", taskIDs).Find(&positions)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range positions {
+		trashedByID[p.TaskID].Positions = append(trashedByID[p.TaskID].Positions, p)
+	}
+
+	relations := []*TaskRelation{}
+	err = s.Where(builder.Or(
+		builder.In("task_id", taskIDs),
+		builder.In("other_task_id", taskIDs),
+	)).Find(&relations)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range relations {
+		tt, has := trashedByID[r.TaskID]
+		if !has {
+			tt = trashedByID[r.OtherTaskID]
+		}
+		tt.Relations = append(tt.Relations, &trashedRelation{
+			TaskID:       r.TaskID,
+			OtherTaskID:  r.OtherTaskID,
+			RelationKind: r.RelationKind,
+			CreatedByID:  r.CreatedByID,
+			Created:      r.Created,
+		})
+	}
+
+	labelTasks := []*LabelTask{}
+	err = s.In("task_id", taskIDs).Find(&labelTasks)
+	if err != nil {
+		return nil, err
+	}
+	for _, lt := range labelTasks {
+		trashedByID[lt.TaskID].LabelIDs = append(trashedByID[lt.TaskID].LabelIDs, lt.LabelID)
+	}
+
+	_, err = s.In("task_id", taskIDs).Delete(&TaskBucket{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("task_id", taskIDs).Delete(&TaskPosition{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.Where(builder.Or(
+		builder.In("task_id", taskIDs),
+		builder.In("other_task_id", taskIDs),
+	)).Delete(&TaskRelation{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("task_id", taskIDs).Delete(&LabelTask{})
+	if err != nil {
+		return nil, err
+	}
+	err = moveTaskDataToTrash(s, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("id", taskIDs).Delete(&Task{})
+	return
+}
+
+// taskDataColumn is a column of a table which references a task only by its id
+type taskDataColumn struct {
+	bean   interface{}
+	column string
+	cond   builder.Cond
+}
+
+func getTaskDataColumns() []*taskDataColumn {
+	columns := []*taskDataColumn{}
+	for _, bean := range []interface{}{
+		&TaskAttachment{},
+		&TaskAttachmentText{},
+		&TaskAssginee{},
+		&TaskComment{},
+		&TaskCommentRevision{},
+		&TaskReminder{},
+		&TaskReminderSnooze{},
+		&TaskOccurrence{},
+		&TaskActivity{},
+		&TaskTimeEntry{},
+		&TaskCustomFieldValue{},
+		&TaskReference{},
+	} {
+		columns = append(columns, &taskDataColumn{bean: bean, column: "task_id"})
+	}
+
+	return append(columns,
+		&taskDataColumn{bean: &TaskReference{}, column: "source_task_id"},
+		&taskDataColumn{bean: &Favorite{}, column: "entity_id", cond: builder.Eq{"kind": FavoriteKindTask}},
+		&taskDataColumn{bean: &Subscription{}, column: "entity_id", cond: builder.Eq{"entity_type": SubscriptionEntityTask}},
+		&taskDataColumn{bean: &Reaction{}, column: "entity_id", cond: builder.Eq{"entity_kind": ReactionKindTask}},
+	)
+}
+
+// moveTaskDataToTrash negates the task id of everything which references the tasks only by their id. This way,
+// no reader finds the data of a trashed task through its id, even if it does not check if the task exists. A new
+// task which gets the id of a trashed task does not inherit its data either.
+// Negating the ids of trashed tasks again moves their data back.
+func moveTaskDataToTrash(s *xorm.Session, taskIDs []int64) (err error) {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	for _, c := range getTaskDataColumns() {
+		_, err = s.
+			Where(builder.And(builder.In(c.column, taskIDs), c.cond)).
+			SetExpr(c.column, "-"+c.column).
+			NoAutoTime().
+			Update(c.bean)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveTaskData moves everything which references a task only by its id to another task id.
+func moveTaskData(s *xorm.Session, fromTaskID, toTaskID int64) (err error) {
+	for _, c := range getTaskDataColumns() {
+		_, err = s.
+			Where(builder.And(builder.Eq{c.column: fromTaskID}, c.cond)).
+			SetExpr(c.column, toTaskID).
+			NoAutoTime().
+			Update(c.bean)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveProjectsToTrash removes the projects with their views, buckets and shares from the db
+// and returns everything needed to restore them.
+func moveProjectsToTrash(s *xorm.Session, projects []*Project) (trashed []*trashedProject, err error) {
+	trashed = []*trashedProject{}
+	if len(projects) == 0 {
+		return
+	}
+
+	projectIDs := make([]int64, 0, len(projects))
+	trashedByID := make(map[int64]*trashedProject, len(projects))
+	for _, p := range projects {
+		tp := &trashedProject{
+			Project:          p,
+			OwnerID:          p.OwnerID,
+			BackgroundFileID: p.BackgroundFileID,
+			Views:            []*ProjectView{},
+			Buckets:          []*trashedBucket{},
+			LinkShares:       []*trashedLinkShare{},
+			Users:            []*trashedProjectUser{},
+			Teams:            []*TeamProject{},
+		}
+		trashed = append(trashed, tp)
+		trashedByID[p.ID] = tp
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	views := []*ProjectView{}
+	err = s.In("project_id", projectIDs).Find(&views)
+	if err != nil {
+		return nil, err
+	}
+	viewIDs := make([]int64, 0, len(views))
+	projectIDsByViewID := make(map[int64]int64, len(views))
+	for _, v := range views {
+		trashedByID[v.ProjectID].Views = append(trashedByID[v.ProjectID].Views, v)
+		viewIDs = append(viewIDs, v.ID)
+		projectIDsByViewID[v.ID] = v.ProjectID
+	}
+
+	buckets := []*Bucket{}
+	err = s.In("project_view_id", viewIDs).Find(&buckets)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		tp := trashedByID[projectIDsByViewID[b.ProjectViewID]]
+		tp.Buckets = append(tp.Buckets, &trashedBucket{
+			Bucket:      b,
+			CreatedByID: b.CreatedByID,
+		})
+	}
+
+	linkShares := []*LinkSharing{}
+	err = s.In("project_id", projectIDs).Find(&linkShares)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range linkShares {
+		trashedByID[share.ProjectID].LinkShares = append(trashedByID[share.ProjectID].LinkShares, &trashedLinkShare{
+			Share:      share,
+			SharedByID: share.SharedByID,
+		})
+	}
+
+	projectUsers := []*ProjectUser{}
+	err = s.In("project_id", projectIDs).Find(&projectUsers)
+	if err != nil {
+		return nil, err
+	}
+	for _, pu := range projectUsers {
+		trashedByID[pu.ProjectID].Users = append(trashedByID[pu.ProjectID].Users, &trashedProjectUser{
+			Share:  pu,
+			UserID: pu.UserID,
+		})
+	}
+
+	teamProjects := []*TeamProject{}
+	err = s.In("project_id", projectIDs).Find(&teamProjects)
+	if err != nil {
+		return nil, err
+	}
+	for _, tp := range teamProjects {
+		trashedByID[tp.ProjectID].Teams = append(trashedByID[tp.ProjectID].Teams, tp)
+	}
+
+	_, err = s.In("project_view_id", viewIDs).Delete(&Bucket{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("id", viewIDs).Delete(&ProjectView{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("project_id", projectIDs).Delete(&LinkSharing{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("project_id", projectIDs).Delete(&ProjectUser{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("project_id", projectIDs).Delete(&TeamProject{})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.In("id", projectIDs).Delete(&Project{})
+	return
+}
+
+// getProjectWithDescendants returns a project and all of its child projects, the project itself is always the first one.
+func getProjectWithDescendants(s *xorm.Session, projectID int64) (projects []*Project, err error) {
+	project, err := GetProjectSimpleByID(s, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects = []*Project{project}
+	parentIDs := []int64{project.ID}
+	for len(parentIDs) > 0 {
+		children := []*Project{}
+		err = s.In("parent_project_id", parentIDs).Find(&children)
+		if err != nil {
+			return nil, err
+		}
+
+		parentIDs = []int64{}
+		for _, child := range children {
+			projects = append(projects, child)
+			parentIDs = append(parentIDs, child.ID)
+		}
+	}
+
+	return
+}
+
+// getTrashedTaskIDs returns the ids the data of the trashed tasks is referenced by
+func (item *TrashItem) getTrashedTaskIDs() []int64 {
+	taskIDs := make([]int64, 0, len(item.Content.Tasks))
+	for _, tt := range item.Content.Tasks {
+		taskIDs = append(taskIDs, -tt.Task.ID)
+	}
+	return taskIDs
+}
+
+// purge permanently deletes everything which belonged to the trashed tasks and projects
+func (item *TrashItem) purge(s *xorm.Session) (err error) {
+	if item.Content != nil {
+		err = deleteTaskData(s, item.getTrashedTaskIDs())
+		if err != nil {
+			return err
+		}
+
+		projectIDs := make([]int64, 0, len(item.Content.Projects))
+		for _, tp := range item.Content.Projects {
+			projectIDs = append(projectIDs, tp.Project.ID)
+
+			err = deleteCustomFieldsForProject(s, tp.Project.ID)
+			if err != nil {
+				return err
+			}
+
+			if tp.BackgroundFileID == 0 {
+				continue
+			}
+			file := &files.File{ID: tp.BackgroundFileID}
+			err = file.Delete(s)
+			if err != nil && !files.IsErrFileDoesNotExist(err) {
+				return err
+			}
+		}
+
+		if len(projectIDs) > 0 {
+			_, err = s.
+				In("entity_id", projectIDs).
+				And("kind = ?", FavoriteKindProject).
+				Delete(&Favorite{})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = s.Where("id = ?", item.ID).Delete(&TrashItem{})
+	return
+}
+
+// deleteTaskData removes everything which is only referenced by the id of the tasks
+func deleteTaskData(s *xorm.Session, taskIDs []int64) (err error) {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	attachments, err := getTaskAttachmentsByTaskIDs(s, taskIDs)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		file := &files.File{ID: attachment.FileID}
+		err = file.Delete(s)
+		if err != nil && !files.IsErrFileDoesNotExist(err) {
+			return err
+		}
+	}
+
+	for _, c := range getTaskDataColumns() {
+		_, err = s.
+			Where(builder.And(builder.In(c.column, taskIDs), c.cond)).
+			Delete(c.bean)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreTasks puts the trashed tasks back and re-establishes their buckets, positions, labels and relations.
+// If the id of a task was given to another task in the meantime, it is restored with a new id.
+func restoreTasks(s *xorm.Session, a web.Auth, trashed []*trashedTask) (err error) {
+	newTaskIDs := make(map[int64]int64, len(trashed))
+	for _, tt := range trashed {
+		t := tt.Task
+		t.UID = tt.UID
+		t.CreatedByID = tt.CreatedByID
+
+		// Another task could have gotten the same index in the meantime
+		err = setNewTaskIndex(s, t)
+		if err != nil {
+			return err
+		}
+
+		oldID := t.ID
+		idTaken, err := s.Where("id = ?", t.ID).Exist(&Task{})
+		if err != nil {
+			return err
+		}
+		if idTaken {
+			t.ID = 0
+		}
+
+		_, err = s.NoAutoTime().Insert(t)
+		if err != nil {
+			return err
+		}
+		newTaskIDs[oldID] = t.ID
+
+		err = moveTaskData(s, -oldID, t.ID)
+		if err != nil {
+			return err
+		}
+
+		err = restoreTaskBucketsAndPositions(s, a, tt)
+		if err != nil {
+			return err
+		}
+
+		if len(tt.LabelIDs) > 0 {
+			labels := []*Label{}
+			err = s.In("id", tt.LabelIDs).Find(&labels)
+			if err != nil {
+				return err
+			}
+			for _, l := range labels {
+				_, err = s.Insert(&LabelTask{
+					TaskID:  t.ID,
+					LabelID: l.ID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Relations are restored after all tasks are back since they can point to each other
+	for _, tt := range trashed {
+		err = restoreTaskRelations(s, tt, newTaskIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	for _, tt := range trashed {
+		err = recordTaskActivity(s, a, &TaskActivity{
+			TaskID: tt.Task.ID,
+			Kind:   TaskActivityKindRestored,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = events.DispatchOnCommit(s, &TaskRestoredEvent{
+			Task: tt.Task,
+			Doer: doer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreTaskBucketsAndPositions(s *xorm.Session, a web.Auth, tt *trashedTask) (err error) {
+	t := tt.Task
+
+	savedBuckets := make(map[int64]int64, len(tt.Buckets))
+	for _, tb := range tt.Buckets {
+		savedBuckets[tb.ProjectViewID] = tb.BucketID
+	}
+	savedPositions := make(map[int64]float64, len(tt.Positions))
+	for _, tp := range tt.Positions {
+		savedPositions[tp.ProjectViewID] = tp.Position
+	}
+
+	views, err := getViewsForProject(s, t.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for _, view := range views {
+		position, has := savedPositions[view.ID]
+		if has {
+			_, err = s.Insert(&TaskPosition{
+				TaskID:        t.ID,
+				ProjectViewID: view.ID,
+				Position:      position,
+			})
+		} else {
+			var tp *TaskPosition
+			tp, err = calculateNewPositionForTask(s, a, t, view)
+			if err != nil {
+				return err
+			}
+			_, err = s.Insert(tp)
+		}
+		if err != nil {
+			return err
+		}
+
+		if view.ViewKind != ProjectViewKindKanban || view.BucketConfigurationMode != BucketConfigurationModeManual {
+			continue
+		}
+
+		// The bucket the task was in could have been deleted in the meantime
+		bucketID := savedBuckets[view.ID]
+		if bucketID != 0 {
+			exists, err := s.
+				Where("id = ? AND project_view_id = ?", bucketID, view.ID).
+				Exist(&Bucket{})
+			if err != nil {
+				return err
+			}
+			if !exists {
+				bucketID = 0
+			}
+		}
+		if bucketID == 0 {
+			if t.Done && view.DoneBucketID != 0 {
+				bucketID = view.DoneBucketID
+			} else {
+				bucketID, err = getDefaultBucketID(s, view)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = s.Insert(&TaskBucket{
+			BucketID:      bucketID,
+			TaskID:        t.ID,
+			ProjectViewID: view.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreTaskRelations(s *xorm.Session, tt *trashedTask, newTaskIDs map[int64]int64) (err error) {
+	for _, rel := range tt.Relations {
+		// Tasks restored together could have gotten a new id
+		if id, has := newTaskIDs[rel.TaskID]; has {
+			rel.TaskID = id
+		}
+		if id, has := newTaskIDs[rel.OtherTaskID]; has {
+			rel.OtherTaskID = id
+		}
+
+		// Relations to tasks which are gone are not restored
+		otherTaskID := rel.OtherTaskID
+		if otherTaskID == tt.Task.ID {
+			otherTaskID = rel.TaskID
+		}
+		exists, err := s.Where("id = ?", otherTaskID).Exist(&Task{})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		exists, err = s.
+			Where("task_id = ? AND other_task_id = ? AND relation_kind = ?", rel.TaskID, rel.OtherTaskID, rel.RelationKind).
+			Exist(&TaskRelation{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		_, err = s.NoAutoTime().Insert(&TaskRelation{
+			TaskID:       rel.TaskID,
+			OtherTaskID:  rel.OtherTaskID,
+			RelationKind: rel.RelationKind,
+			CreatedByID:  rel.CreatedByID,
+			Created:      rel.Created,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreProjects puts the trashed projects back with their views, buckets and shares.
+func restoreProjects(s *xorm.Session, trashed []*trashedProject) (err error) {
+	for _, tp := range trashed {
+		tp.Project.OwnerID = tp.OwnerID
+		tp.Project.BackgroundFileID = tp.BackgroundFileID
+		_, err = s.NoAutoTime().Insert(tp.Project)
+		if err != nil {
+			return err
+		}
+
+		for _, view := range tp.Views {
+			_, err = s.NoAutoTime().Insert(view)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, b := range tp.Buckets {
+			b.Bucket.CreatedByID = b.CreatedByID
+			_, err = s.NoAutoTime().Insert(b.Bucket)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, ls := range tp.LinkShares {
+			ls.Share.ProjectID = tp.Project.ID
+			ls.Share.SharedByID = ls.SharedByID
+			_, err = s.NoAutoTime().Insert(ls.Share)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Users and teams the project was shared with could have been deleted in the meantime
+		for _, pu := range tp.Users {
+			exists, err := s.Where("id = ?", pu.UserID).Exist(&user.User{})
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+
+			pu.Share.ProjectID = tp.Project.ID
+			pu.Share.UserID = pu.UserID
+			_, err = s.NoAutoTime().Insert(pu.Share)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, team := range tp.Teams {
+			exists, err := s.Where("id = ?", team.TeamID).Exist(&Team{})
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+
+			team.ProjectID = tp.Project.ID
+			_, err = s.NoAutoTime().Insert(team)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReadAll returns all trash items of the current user
+// @Summary Get all deleted tasks and projects
+// @Description Returns all tasks and projects in the trash which were either deleted by the current user or belonged to a project the user owns. They are kept until they expire and can be restored until then.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search trash items by their title."
+// @Success 200 {array} models.TrashItem "The trash items"
+// @Failure 403 {object} web.HTTPError "Link shares cannot access the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash [get]
+func (item *TrashItem) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.And(
+		builder.Or(
+			builder.Eq{"deleted_by_id": a.GetID()},
+			builder.Eq{"owner_id": a.GetID()},
+		),
+		db.ILIKE("title", search),
+	)
+
+	items := []*TrashItem{}
+	query := s.
+		Where(cond).
+		OrderBy("created desc, id desc")
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&items)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = addMoreInfoToTrashItems(s, items)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.Where(cond).Count(&TrashItem{})
+	return items, len(items), numberOfTotalItems, err
+}
+
+func addMoreInfoToTrashItems(s *xorm.Session, items []*TrashItem) (err error) {
+	userIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		userIDs = append(userIDs, item.DeletedByID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return err
+	}
+
+	retention := getTrashRetention()
+	for _, item := range items {
+		item.DeletedBy = users[item.DeletedByID]
+		item.Expires = item.Created.Add(retention)
+	}
+
+	return nil
+}
+
+func getTrashItemByID(s *xorm.Session, id int64) (item *TrashItem, err error) {
+	item = &TrashItem{}
+	exists, err := s.Where("id = ?", id).Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrTrashItemDoesNotExist{TrashItemID: id}
+	}
+
+	return item, nil
+}
+
+// Delete permanently deletes a trash item
+// @Summary Permanently delete a task or project
+// @Description Permanently deletes a task or project from the trash. It cannot be restored afterwards.
+// @tags trash
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Trash item ID"
+// @Success 200 {object} models.Message "The trash item was permanently deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the trash item."
+// @Failure 404 {object} web.HTTPError "The trash item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{id} [delete]
+func (item *TrashItem) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	fullItem, err := getTrashItemByID(s, item.ID)
+	if err != nil {
+		return err
+	}
+
+	return fullItem.purge(s)
+}
+
+// TrashItemRestore restores a deleted task or project
+type TrashItemRestore struct {
+	TrashItemID int64 `json:"-" param:"trashitem"`
+
+	// The restored task if the trash item was a task.
+	Task *Task `json:"task,omitempty"`
+	// The restored project if the trash item was a project.
+	Project *Project `json:"project,omitempty"`
+
+	web.CRUDable    `json:"-"`
+	web.Permissions `json:"-"`
+}
+
+// Create restores a trash item
+// @Summary Restore a deleted task or project
+// @Description Restores a task or project from the trash with the same id it had before. If another task got the id of a deleted task in the meantime, the task is restored with a new id. Buckets, positions, relations and labels are re-established as far as their counterparts still exist. A project is restored with all of its child projects, tasks, views and shares. Tasks can only be restored if their project still exists and the user has write access to it. If the parent of a project is gone, it is restored as a top-level project.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Trash item ID"
+// @Success 201 {object} models.TrashItemRestore "The restored task or project."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the trash item."
+// @Failure 404 {object} web.HTTPError "The trash item does not exist."
+// @Failure 412 {object} web.HTTPError "The project of the task does not exist anymore."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{id}/restore [put]
+func (r *TrashItemRestore) Create(s *xorm.Session, a web.Auth) (err error) {
+	item, err := getTrashItemByID(s, r.TrashItemID)
+	if err != nil {
+		return err
+	}
+
+	switch item.Kind {
+	case TrashItemKindTask:
+		err = r.restoreTask(s, a, item)
+	case TrashItemKindProject:
+		err = r.restoreProject(s, a, item)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", item.ID).Delete(&TrashItem{})
+	return
+}
+
+func (r *TrashItemRestore) restoreTask(s *xorm.Session, a web.Auth, item *TrashItem) (err error) {
+	project, err := GetProjectSimpleByID(s, item.ProjectID)
+	if IsErrProjectDoesNotExist(err) {
+		return &ErrTrashedTaskProjectDoesNotExist{
+			TrashItemID: item.ID,
+			ProjectID:   item.ProjectID,
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	canWrite, err := project.CanWrite(s, a)
+	if err != nil {
+		return err
+	}
+	if !canWrite {
+		return ErrGenericForbidden{}
+	}
+
+	err = restoreTasks(s, a, item.Content.Tasks)
+	if err != nil {
+		return err
+	}
+
+	r.Task = item.Content.Tasks[0].Task
+	return updateProjectLastUpdated(s, project)
+}
+
+func (r *TrashItemRestore) restoreProject(s *xorm.Session, a web.Auth, item *TrashItem) (err error) {
+	root := item.Content.Projects[0].Project
+
+	// Without access to the old parent, the project is restored on the top level
+	if root.ParentProjectID != 0 {
+		parent, err := GetProjectSimpleByID(s, root.ParentProjectID)
+		if err != nil && !IsErrProjectDoesNotExist(err) {
+			return err
+		}
+		canWrite := false
+		if parent != nil {
+			canWrite, err = parent.CanWrite(s, a)
+			if err != nil {
+				return err
+			}
+		}
+		if !canWrite {
+			root.ParentProjectID = 0
+		}
+	}
+
+	err = restoreProjects(s, item.Content.Projects)
+	if err != nil {
+		return err
+	}
+
+	err = restoreTasks(s, a, item.Content.Tasks)
+	if err != nil {
+		return err
+	}
+
+	for _, tp := range item.Content.Projects {
+		err = events.DispatchOnCommit(s, &ProjectRestoredEvent{
+			Project: tp.Project,
+			Doer:    a,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	r.Project = root
+	return nil
+}
+
+func getExpiredTrashItems(s *xorm.Session) (items []*TrashItem, err error) {
+	items = []*TrashItem{}
+	err = s.
+		Where("created < ?", time.Now().Add(-getTrashRetention())).
+		OrderBy("id asc").
+		Find(&items)
+	return
+}
+
+// RegisterTrashCleanupCron registers a cron function which permanently deletes all trash items after the retention period.
+func RegisterTrashCleanupCron() {
+	const logPrefix = "[Trash Cleanup Cron] "
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		items, err := getExpiredTrashItems(s)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not get expired trash items: %s", err)
+			return
+		}
+
+		if len(items) == 0 {
+			return
+		}
+
+		log.Debugf(logPrefix+"Permanently deleting %d expired trash items...", len(items))
+
+		for _, item := range items {
+			err = s.Begin()
+			if err != nil {
+				log.Errorf(logPrefix+"Could not start transaction: %s", err)
+				return
+			}
+
+			err = item.purge(s)
+			if err != nil {
+				_ = s.Rollback()
+				log.Errorf(logPrefix+"Could not delete trash item %d: %s", item.ID, err)
+				return
+			}
+
+			err = s.Commit()
+			if err != nil {
+				log.Errorf(logPrefix+"Could not commit transaction: %s", err)
+				return
+			}
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register trash cleanup cron: %s", err)
+	}
+}
+
+// purgeTrashItemsOwnedBy permanently deletes all trash items of projects owned by a user.
+func purgeTrashItemsOwnedBy(s *xorm.Session, ownerID int64) (err error) {
+	items := []*TrashItem{}
+	err = s.Where("owner_id = ?", ownerID).Find(&items)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = item.purge(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
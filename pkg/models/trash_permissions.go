@@ -0,0 +1,47 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/web"
+	"xorm.io/xorm"
+)
+
+func (item *TrashItem) canDoTrashItem(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares cannot access the trash
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	fullItem, err := getTrashItemByID(s, item.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return fullItem.DeletedByID == a.GetID() || fullItem.OwnerID == a.GetID(), nil
+}
+
+// CanDelete checks if a user can permanently delete a trash item
+func (item *TrashItem) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return item.canDoTrashItem(s, a)
+}
+
+// CanCreate checks if a user can restore a trash item
+func (r *TrashItemRestore) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	item := &TrashItem{ID: r.TrashItemID}
+	return item.canDoTrashItem(s, a)
+}
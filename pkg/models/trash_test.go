@@ -0,0 +1,370 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTrashItemForTest(t *testing.T, kind TrashItemKind, entityID int64) *TrashItem {
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TrashItem{}
+	exists, err := s.Where("kind = ? AND entity_id = ?", kind, entityID).Get(item)
+	require.NoError(t, err)
+	require.True(t, exists)
+	return item
+}
+
+func TestTrashItem_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashItem{}
+		result, resultCount, total, err := item.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resultCount)
+		assert.Equal(t, int64(1), total)
+
+		items := result.([]*TrashItem)
+		assert.Equal(t, int64(1), items[0].ID)
+		assert.Equal(t, TrashItemKindTask, items[0].Kind)
+		assert.Equal(t, int64(47), items[0].EntityID)
+		assert.Equal(t, "user1", items[0].DeletedBy.Username)
+		assert.Equal(t, items[0].Created.Add(30*24*time.Hour), items[0].Expires)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashItem{}
+		_, _, _, err := item.ReadAll(s, &LinkSharing{ID: 1, ProjectID: 1}, "", 0, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTrashItem_Permissions(t *testing.T) {
+	t.Run("deleted by the user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashItem{ID: 1}
+		can, err := item.CanDelete(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		restore := &TrashItemRestore{TrashItemID: 2}
+		can, err := restore.CanCreate(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashItem{ID: 9999}
+		_, err := item.CanDelete(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrTrashItemDoesNotExist(err))
+	})
+}
+
+func TestTask_DeleteToTrash(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("delete and restore", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		s.Close()
+
+		db.AssertMissing(t, "tasks", map[string]interface{}{"id": 1})
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{"task_id": 1})
+		db.AssertMissing(t, "task_relations", map[string]interface{}{"other_task_id": 1})
+		// Comments stay until the task is purged, but can't be found through the task id anymore
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": 1})
+		db.AssertExists(t, "task_comments", map[string]interface{}{"task_id": -1}, false)
+		db.AssertMissing(t, "task_assignees", map[string]interface{}{"task_id": 1})
+		db.AssertMissing(t, "task_attachments", map[string]interface{}{"task_id": 1})
+		db.AssertExists(t, "trash_items", map[string]interface{}{
+			"kind":          TrashItemKindTask,
+			"entity_id":     1,
+			"project_id":    1,
+			"owner_id":      1,
+			"deleted_by_id": 1,
+		}, false)
+
+		item := getTrashItemForTest(t, TrashItemKindTask, 1)
+
+		s = db.NewSession()
+		defer s.Close()
+		restore := &TrashItemRestore{TrashItemID: item.ID}
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), restore.Task.ID)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":            1,
+			"title":         "task #1",
+			"project_id":    1,
+			"index":         1,
+			"created_by_id": 1,
+		}, false)
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":         1,
+			"bucket_id":       1,
+			"project_view_id": 4,
+		}, false)
+		db.AssertExists(t, "task_positions", map[string]interface{}{
+			"task_id":         1,
+			"project_view_id": 1,
+			"position":        2,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  1,
+			"label_id": 4,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       1,
+			"other_task_id": 29,
+			"relation_kind": RelationKindSubtask,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       35,
+			"other_task_id": 1,
+			"relation_kind": RelationKindRelated,
+		}, false)
+		db.AssertExists(t, "task_activities", map[string]interface{}{
+			"task_id": 1,
+			"kind":    TaskActivityKindRestored,
+		}, false)
+		db.AssertExists(t, "task_comments", map[string]interface{}{"task_id": 1}, false)
+		db.AssertExists(t, "task_attachments", map[string]interface{}{"task_id": 1}, false)
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": -1})
+		db.AssertMissing(t, "trash_items", map[string]interface{}{"id": item.ID})
+	})
+	t.Run("restore when the id was given to another task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		require.NoError(t, err)
+		// Some databases reuse ids after a restart or a restore of a dump
+		_, err = s.Insert(&Task{ID: 1, Title: "new task", ProjectID: 1, Index: 100, CreatedByID: 1})
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		s.Close()
+
+		// The new task does not inherit the data of the trashed one
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": 1})
+
+		item := getTrashItemForTest(t, TrashItemKindTask, 1)
+		s = db.NewSession()
+		defer s.Close()
+		restore := &TrashItemRestore{TrashItemID: item.ID}
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		restoredID := restore.Task.ID
+		assert.NotEqual(t, int64(1), restoredID)
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 1, "title": "new task"}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": restoredID, "title": "task #1"}, false)
+		db.AssertExists(t, "task_comments", map[string]interface{}{"task_id": restoredID}, false)
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": 1})
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": -1})
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       restoredID,
+			"other_task_id": 29,
+			"relation_kind": RelationKindSubtask,
+		}, false)
+		db.AssertExists(t, "task_buckets", map[string]interface{}{"task_id": restoredID}, false)
+	})
+	t.Run("restore with missing bucket and label", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 2).Delete(&Bucket{})
+		require.NoError(t, err)
+		_, err = s.Where("id = ?", 1).Delete(&Label{})
+		require.NoError(t, err)
+
+		restore := &TrashItemRestore{TrashItemID: 1}
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         47,
+			"uid":        "uid-47",
+			"project_id": 1,
+		}, false)
+		// Falls back to the default bucket of the view
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":         47,
+			"bucket_id":       1,
+			"project_view_id": 4,
+		}, false)
+		db.AssertExists(t, "task_positions", map[string]interface{}{
+			"task_id":         47,
+			"project_view_id": 1,
+			"position":        1234,
+		}, false)
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{"task_id": 47})
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       47,
+			"other_task_id": 1,
+			"relation_kind": RelationKindRelated,
+		}, false)
+	})
+	t.Run("restore into deleted project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		project := &Project{ID: 1}
+		err := project.Delete(s, u)
+		require.NoError(t, err)
+
+		restore := &TrashItemRestore{TrashItemID: 1}
+		err = restore.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTrashedTaskProjectDoesNotExist(err))
+	})
+	t.Run("purge", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		require.NoError(t, err)
+
+		item := &TrashItem{}
+		_, err = s.Where("kind = ? AND entity_id = ?", TrashItemKindTask, 1).Get(item)
+		require.NoError(t, err)
+		err = item.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "trash_items", map[string]interface{}{"id": item.ID})
+		db.AssertMissing(t, "task_comments", map[string]interface{}{"task_id": -1})
+		db.AssertMissing(t, "task_activities", map[string]interface{}{"task_id": -1})
+	})
+}
+
+func TestProject_DeleteToTrash(t *testing.T) {
+	t.Run("delete and restore with child projects", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+
+		u := &user.User{ID: 6}
+		task := &Task{Title: "task in child project", ProjectID: 12}
+		err := task.Create(s, u)
+		require.NoError(t, err)
+
+		project := &Project{ID: 27}
+		err = project.Delete(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		s.Close()
+
+		db.AssertMissing(t, "projects", map[string]interface{}{"id": 27})
+		db.AssertMissing(t, "projects", map[string]interface{}{"id": 12})
+		db.AssertMissing(t, "project_views", map[string]interface{}{"project_id": 12})
+		db.AssertMissing(t, "projects", map[string]interface{}{"id": 25})
+		db.AssertMissing(t, "tasks", map[string]interface{}{"id": task.ID})
+
+		item := getTrashItemForTest(t, TrashItemKindProject, 27)
+		assert.Equal(t, int64(6), item.OwnerID)
+
+		s = db.NewSession()
+		defer s.Close()
+		restore := &TrashItemRestore{TrashItemID: item.ID}
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(27), restore.Project.ID)
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"id":       27,
+			"owner_id": 6,
+		}, false)
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"id":                12,
+			"parent_project_id": 27,
+		}, false)
+		db.AssertExists(t, "project_views", map[string]interface{}{"project_id": 12}, false)
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"id":                25,
+			"parent_project_id": 12,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         task.ID,
+			"project_id": 12,
+		}, false)
+		db.AssertExists(t, "task_positions", map[string]interface{}{"task_id": task.ID}, false)
+		db.AssertMissing(t, "trash_items", map[string]interface{}{"id": item.ID})
+	})
+}
+
+func TestTrashItem_Expiry(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.Table("trash_items").Where("id = ?", 1).Update(map[string]interface{}{"created": time.Now()})
+	require.NoError(t, err)
+
+	items, err := getExpiredTrashItems(s)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(2), items[0].ID)
+}
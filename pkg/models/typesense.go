@@ -120,6 +120,14 @@ func CreateTypesenseCollections() error {
 				Name: "percent_done",
 				Type: "float",
 			},
+			{
+				Name: "estimated_time",
+				Type: "int64",
+			},
+			{
+				Name: "time_spent",
+				Type: "int64",
+			},
 			{
 				Name: "identifier",
 				Type: "string",
@@ -150,11 +158,47 @@ func CreateTypesenseCollections() error {
 				Name: "created_by_id",
 				Type: "int64",
 			},
+			{
+				Name:     "created_by",
+				Type:     "string", // username of the creator
+				Optional: pointer.True(),
+			},
+			{
+				Name:     "parent_task_ids",
+				Type:     "int64[]",
+				Optional: pointer.True(),
+			},
+			{
+				Name: "has_subtasks",
+				Type: "bool",
+			},
+			{
+				Name:     "subtasks_done_percent",
+				Type:     "float",
+				Optional: pointer.True(),
+			},
+			{
+				Name: "is_blocked",
+				Type: "bool",
+			},
+			{
+				Name: "has_attachments",
+				Type: "bool",
+			},
+			{
+				Name: "comment_count",
+				Type: "int64",
+			},
 			{
 				Name:     "reminders",
 				Type:     "object[]", // TODO
 				Optional: pointer.True(),
 			},
+			{
+				Name:     "occurrences",
+				Type:     "int64[]", // unix timestamps of when each occurrence was done
+				Optional: pointer.True(),
+			},
 			{
 				Name:     "assignees",
 				Type:     "object[]", // TODO
@@ -180,6 +224,11 @@ func CreateTypesenseCollections() error {
 				Type:     "object[]", // TODO
 				Optional: pointer.True(),
 			},
+			{
+				Name:     "attachment_texts",
+				Type:     "string[]",
+				Optional: pointer.True(),
+			},
 			{
 				Name: "positions",
 				Type: "object",
@@ -192,6 +241,11 @@ func CreateTypesenseCollections() error {
 				Name: "buckets",
 				Type: "int64[]",
 			},
+			{
+				Name:     "custom_fields.field_.*",
+				Type:     "auto", // the type depends on the custom field, dates are stored as unix timestamp
+				Optional: pointer.True(),
+			},
 		},
 	}
 
@@ -199,7 +253,18 @@ func CreateTypesenseCollections() error {
 	_, _ = typesenseClient.Collection("tasks").Delete(context.Background())
 
 	_, err := typesenseClient.Collections().Create(context.Background(), taskSchema)
-	return err
+	if err != nil {
+		return err
+	}
+
+	for _, c := range globalSearchCollections {
+		err = c.createTypesenseCollection()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func ReindexAllTasks() (err error) {
@@ -266,6 +331,15 @@ func reindexTasksInTypesense(s *xorm.Session, tasks map[int64]*Task) (err error)
 		return fmt.Errorf("could not fetch more task info: %s", err.Error())
 	}
 
+	taskIDs := make([]int64, 0, len(tasks))
+	for id := range tasks {
+		taskIDs = append(taskIDs, id)
+	}
+	err = addOccurrencesToTasks(s, taskIDs, tasks)
+	if err != nil {
+		return fmt.Errorf("could not fetch task occurrences: %s", err.Error())
+	}
+
 	typesenseTasks := []interface{}{}
 
 	positionsByTask, err := getPositionsByTask(s)
@@ -278,8 +352,13 @@ func reindexTasksInTypesense(s *xorm.Session, tasks map[int64]*Task) (err error)
 		return err
 	}
 
+	attachmentTextsByTask, err := getAttachmentTextsByTask(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
 	for _, task := range tasks {
-		ttask := convertTaskToTypesenseTask(task, positionsByTask[task.ID], bucketsByTask[task.ID])
+		ttask := convertTaskToTypesenseTask(task, positionsByTask[task.ID], bucketsByTask[task.ID], attachmentTextsByTask[task.ID])
 		if ttask == nil {
 			log.Debugf("Converted typesense task %d is nil, not indexing", task.ID)
 			continue
@@ -452,6 +531,8 @@ type typesenseTask struct {
 	EndDate                *int64      `json:"end_date"`
 	HexColor               string      `json:"hex_color"`
 	PercentDone            float64     `json:"percent_done"`
+	EstimatedTime          int64       `json:"estimated_time"`
+	TimeSpent              int64       `json:"time_spent"`
 	Identifier             string      `json:"identifier"`
 	Index                  int64       `json:"index"`
 	UID                    string      `json:"uid"`
@@ -459,17 +540,27 @@ type typesenseTask struct {
 	Created                int64       `json:"created"`
 	Updated                int64       `json:"updated"`
 	CreatedByID            int64       `json:"created_by_id"`
+	CreatedBy              string      `json:"created_by"`
+	ParentTaskIDs          []int64     `json:"parent_task_ids"`
+	HasSubtasks            bool        `json:"has_subtasks"`
+	SubtasksDonePercent    *float64    `json:"subtasks_done_percent"`
+	IsBlocked              bool        `json:"is_blocked"`
+	HasAttachments         bool        `json:"has_attachments"`
+	CommentCount           int64       `json:"comment_count"`
 	Reminders              interface{} `json:"reminders"`
+	Occurrences            []int64     `json:"occurrences"`
 	Assignees              interface{} `json:"assignees"`
 	Labels                 interface{} `json:"labels"`
 	//RelatedTasks           interface{} `json:"related_tasks"` // TODO
-	Attachments interface{}        `json:"attachments"`
-	Comments    interface{}        `json:"comments"`
-	Positions   map[string]float64 `json:"positions"`
-	Buckets     []int64            `json:"buckets"`
+	Attachments     interface{}            `json:"attachments"`
+	AttachmentTexts []string               `json:"attachment_texts"`
+	Comments        interface{}            `json:"comments"`
+	Positions       map[string]float64     `json:"positions"`
+	Buckets         []int64                `json:"buckets"`
+	CustomFields    map[string]interface{} `json:"custom_fields"`
 }
 
-func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, buckets []*TaskBucket) *typesenseTask {
+func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, buckets []*TaskBucket, attachmentTexts []string) *typesenseTask {
 
 	tt := &typesenseTask{
 		ID:                     fmt.Sprintf("%d", task.ID),
@@ -486,6 +577,8 @@ func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, b
 		EndDate:                pointer.Int64(task.EndDate.UTC().Unix()),
 		HexColor:               task.HexColor,
 		PercentDone:            task.PercentDone,
+		EstimatedTime:          task.EstimatedTime,
+		TimeSpent:              task.TimeSpent,
 		Identifier:             task.Identifier,
 		Index:                  task.Index,
 		UID:                    task.UID,
@@ -493,13 +586,22 @@ func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, b
 		Created:                task.Created.UTC().Unix(),
 		Updated:                task.Updated.UTC().Unix(),
 		CreatedByID:            task.CreatedByID,
+		ParentTaskIDs:          make([]int64, 0, len(task.RelatedTasks[RelationKindParenttask])),
+		HasSubtasks:            len(task.RelatedTasks[RelationKindSubtask]) > 0,
+		IsBlocked:              task.IsBlocked,
+		HasAttachments:         len(task.Attachments) > 0,
+		CommentCount:           int64(len(task.Comments)),
 		Reminders:              task.Reminders,
 		Assignees:              task.Assignees,
 		Labels:                 task.Labels,
 		//RelatedTasks:           task.RelatedTasks,
-		Attachments: task.Attachments,
-		Positions:   make(map[string]float64, len(positions)),
-		Buckets:     make([]int64, 0, len(buckets)),
+		Attachments:     task.Attachments,
+		AttachmentTexts: attachmentTexts,
+		Comments:        task.Comments,
+		Positions:       make(map[string]float64, len(positions)),
+		Buckets:         make([]int64, 0, len(buckets)),
+		Occurrences:     make([]int64, 0, len(task.Occurrences)),
+		CustomFields:    make(map[string]interface{}, len(task.CustomFields)),
 	}
 
 	if task.DoneAt.IsZero() {
@@ -515,6 +617,25 @@ func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, b
 		tt.EndDate = nil
 	}
 
+	if task.CreatedBy != nil {
+		tt.CreatedBy = task.CreatedBy.Username
+	}
+
+	for _, parent := range task.RelatedTasks[RelationKindParenttask] {
+		tt.ParentTaskIDs = append(tt.ParentTaskIDs, parent.ID)
+	}
+
+	if tt.HasSubtasks {
+		var done int
+		for _, subtask := range task.RelatedTasks[RelationKindSubtask] {
+			if subtask.Done {
+				done++
+			}
+		}
+		percent := 100 * float64(done) / float64(len(task.RelatedTasks[RelationKindSubtask]))
+		tt.SubtasksDonePercent = &percent
+	}
+
 	for _, position := range positions {
 		pos := position.TaskPosition.Position
 		if pos == 0 {
@@ -527,6 +648,17 @@ func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, b
 		tt.Buckets = append(tt.Buckets, bucket.BucketID)
 	}
 
+	for _, occurrence := range task.Occurrences {
+		tt.Occurrences = append(tt.Occurrences, occurrence.DoneAt.UTC().Unix())
+	}
+
+	for fieldID, value := range task.CustomFields {
+		if date, is := value.(time.Time); is {
+			value = date.UTC().Unix()
+		}
+		tt.CustomFields["field_"+strconv.FormatInt(fieldID, 10)] = value
+	}
+
 	return tt
 }
 
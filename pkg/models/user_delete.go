@@ -149,6 +149,22 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 		}
 	}
 
+	// The projects were only moved to the trash, they must be gone for good
+	err = purgeTrashItemsOwnedBy(s, u.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&notifications.WebPushSubscription{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("notifiable_id = ?", u.ID).Delete(&notifications.DigestItem{})
+	if err != nil {
+		return err
+	}
+
 	_, err = s.Where("id = ?", u.ID).Delete(&user.User{})
 	if err != nil {
 		return err
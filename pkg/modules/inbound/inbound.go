@@ -0,0 +1,118 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"net/mail"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/c2h5oh/datasize"
+)
+
+var server *Server
+
+// IsEnabled checks if inbound mails are enabled and configured
+func IsEnabled() bool {
+	return config.InboundEnabled.GetBool() && config.InboundAddress.GetString() != ""
+}
+
+func splitAddress(address string) (local, domain string, ok bool) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", "", false
+	}
+	at := strings.LastIndex(parsed.Address, "@")
+	if at == -1 {
+		return "", "", false
+	}
+	return parsed.Address[:at], parsed.Address[at+1:], true
+}
+
+// GetAddress returns the inbound address for a token, built from the configured
+// base address with plus addressing: vikunja+token@example.com
+func GetAddress(token string) string {
+	local, domain, ok := splitAddress(config.InboundAddress.GetString())
+	if !ok {
+		return ""
+	}
+	return local + "+" + token + "@" + domain
+}
+
+// GetTokenFromAddress returns the token of an address created with GetAddress.
+func GetTokenFromAddress(address string) (token string, ok bool) {
+	baseLocal, baseDomain, ok := splitAddress(config.InboundAddress.GetString())
+	if !ok {
+		return "", false
+	}
+	local, domain, ok := splitAddress(address)
+	if !ok || !strings.EqualFold(domain, baseDomain) {
+		return "", false
+	}
+	local, token, found := strings.Cut(local, "+")
+	if !found || token == "" || !strings.EqualFold(local, baseLocal) {
+		return "", false
+	}
+	return token, true
+}
+
+// Start starts the smtp receiver for inbound mails in the background if it is enabled.
+func Start(handler Handler) {
+	if !IsEnabled() {
+		return
+	}
+
+	var maxSize datasize.ByteSize
+	err := maxSize.UnmarshalText([]byte(config.InboundMaxSize.GetString()))
+	if err != nil {
+		log.Fatalf("Could not parse inbound.maxsize: %s", err)
+	}
+
+	_, hostname, _ := splitAddress(config.InboundAddress.GetString())
+
+	server = &Server{
+		Addr:     config.InboundInterface.GetString(),
+		Hostname: hostname,
+		MaxSize:  int64(maxSize.Bytes()),
+		AcceptRecipient: func(address string) bool {
+			_, ok := GetTokenFromAddress(address)
+			return ok
+		},
+		Handler: handler,
+	}
+
+	go func() {
+		log.Infof("Receiving inbound mails for %s on %s", config.InboundAddress.GetString(), server.Addr)
+		err := server.ListenAndServe()
+		if err != nil {
+			log.Errorf("Inbound mail receiver stopped: %s", err)
+		}
+	}()
+}
+
+// Stop stops the smtp receiver for inbound mails if it was started.
+func Stop() {
+	if server == nil {
+		return
+	}
+	err := server.Close()
+	if err != nil {
+		log.Errorf("Could not stop inbound mail receiver: %s", err)
+	}
+}
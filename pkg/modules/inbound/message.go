@@ -0,0 +1,202 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMultipartDepth limits how deep multipart bodies can be nested to prevent abuse
+const maxMultipartDepth = 10
+
+// Message is a parsed inbound email
+type Message struct {
+	// The sender as stated in the From header of the mail
+	From *mail.Address
+	// The decoded subject
+	Subject string
+	// The plain text body, if the mail has one
+	Text string
+	// The html body, if the mail has one. This is not sanitized.
+	HTML string
+	// All non-inline parts of the mail
+	Attachments []*Attachment
+
+	MessageID  string
+	InReplyTo  string
+	References []string
+
+	Header mail.Header
+}
+
+// Attachment is a file attached to an inbound email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+var wordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	},
+}
+
+// ParseMessage parses a raw email as defined in RFC 5322 and its mime parts.
+func ParseMessage(r io.Reader) (msg *Message, err error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	msg = &Message{
+		Header:     m.Header,
+		Subject:    decodeHeader(m.Header.Get("Subject")),
+		MessageID:  strings.TrimSpace(m.Header.Get("Message-Id")),
+		InReplyTo:  strings.TrimSpace(m.Header.Get("In-Reply-To")),
+		References: strings.Fields(m.Header.Get("References")),
+	}
+
+	addressParser := &mail.AddressParser{WordDecoder: wordDecoder}
+	if from := m.Header.Get("From"); from != "" {
+		msg.From, err = addressParser.Parse(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from header: %w", err)
+		}
+	}
+
+	err = msg.parsePart(textproto.MIMEHeader(m.Header), m.Body, 0)
+	return msg, err
+}
+
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+func (msg *Message) parsePart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxMultipartDepth {
+		return errors.New("mime parts are nested too deep")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = msg.parsePart(part.Header, part, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if err != nil {
+		disposition = ""
+	}
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = decodeHeader(filename)
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition == "attachment" || filename != "" || !isText {
+		if filename == "" && !strings.HasPrefix(mediaType, "message/") {
+			// Parts without a name are usually signatures or other parts which are not
+			// useful on their own.
+			return nil
+		}
+		if filename == "" {
+			filename = "message.eml"
+		}
+		msg.Attachments = append(msg.Attachments, &Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Content:     content,
+		})
+		return nil
+	}
+
+	text := decodeCharset(content, params["charset"])
+	if mediaType == "text/html" {
+		if msg.HTML == "" {
+			msg.HTML = text
+		}
+		return nil
+	}
+	if msg.Text == "" {
+		msg.Text = text
+	}
+	return nil
+}
+
+// decodeCharset converts a text body to utf-8. Only utf-8 compatible charsets and
+// latin-1 are supported, everything else is kept as is.
+func decodeCharset(content []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		if utf8.Valid(content) {
+			return string(content)
+		}
+		buf := bytes.Buffer{}
+		for _, b := range content {
+			buf.WriteRune(rune(b))
+		}
+		return buf.String()
+	default:
+		return string(content)
+	}
+}
@@ -0,0 +1,99 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMultipartMessage = "From: =?utf-8?q?J=C3=BCrgen?= <user1@example.com>\r\n" +
+	"To: vikunja+abc@example.com\r\n" +
+	"Subject: =?utf-8?q?Fwd:_Gr=C3=BC=C3=9Fe?=\r\n" +
+	"Message-ID: <1234@example.com>\r\n" +
+	"In-Reply-To: <1233@example.com>\r\n" +
+	"References: <1232@example.com> <1233@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Hello =C3=BCber world\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Hello world</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain; name=\"notes.txt\"\r\n" +
+	"Content-Disposition: attachment; filename=\"notes.txt\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"c29tZSBub3Rl\r\n" +
+	"--outer--\r\n"
+
+func TestParseMessage(t *testing.T) {
+	t.Run("multipart", func(t *testing.T) {
+		msg, err := ParseMessage(strings.NewReader(testMultipartMessage))
+		require.NoError(t, err)
+
+		require.NotNil(t, msg.From)
+		assert.Equal(t, "user1@example.com", msg.From.Address)
+		assert.Equal(t, "Jürgen", msg.From.Name)
+		assert.Equal(t, "Fwd: Grüße", msg.Subject)
+		assert.Equal(t, "<1234@example.com>", msg.MessageID)
+		assert.Equal(t, "<1233@example.com>", msg.InReplyTo)
+		assert.Equal(t, []string{"<1232@example.com>", "<1233@example.com>"}, msg.References)
+		assert.Equal(t, "Hello über world", strings.TrimSpace(msg.Text))
+		assert.Equal(t, "<p>Hello world</p>", strings.TrimSpace(msg.HTML))
+		require.Len(t, msg.Attachments, 1)
+		assert.Equal(t, "notes.txt", msg.Attachments[0].Filename)
+		assert.Equal(t, "text/plain", msg.Attachments[0].ContentType)
+		assert.Equal(t, "some note", string(msg.Attachments[0].Content))
+	})
+	t.Run("plain text", func(t *testing.T) {
+		msg, err := ParseMessage(strings.NewReader("From: user1@example.com\r\n" +
+			"Subject: Test\r\n" +
+			"\r\n" +
+			"Line one\r\nLine two\r\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "Test", msg.Subject)
+		assert.Equal(t, "Line one\r\nLine two\r\n", msg.Text)
+		assert.Empty(t, msg.HTML)
+		assert.Empty(t, msg.Attachments)
+	})
+	t.Run("latin-1", func(t *testing.T) {
+		msg, err := ParseMessage(strings.NewReader("From: user1@example.com\r\n" +
+			"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+			"\r\n" +
+			"Gr\xfc\xdfe"))
+		require.NoError(t, err)
+		assert.Equal(t, "Grüße", msg.Text)
+	})
+	t.Run("invalid from", func(t *testing.T) {
+		_, err := ParseMessage(strings.NewReader("From: not an address\r\n\r\nbody"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var (
+	// Lines which introduce the quoted original message, like "On Mon, 1 Jan 2024, Jane <jane@example.com> wrote:"
+	replyHeaderRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)^on\b.+\bwrote:$`),
+		regexp.MustCompile(`(?i)^am\b.+\bschrieb.*:$`),
+		regexp.MustCompile(`(?i)^le\b.+\ba écrit\s*:$`),
+		regexp.MustCompile(`(?i)^-+\s*original message\s*-+$`),
+		regexp.MustCompile(`^_{20,}$`),
+	}
+	// Signatures added by mobile mail clients
+	mobileSignatureRegex = regexp.MustCompile(`(?i)^sent from my \S+`)
+	// Outlook quotes the original message with a header block like "From: ...\nSent: ..."
+	outlookFromRegex = regexp.MustCompile(`(?i)^\*?from:\*?\s`)
+	outlookSentRegex = regexp.MustCompile(`(?i)^\*?(sent|date):\*?\s`)
+
+	htmlQuoteRegex     = regexp.MustCompile(`(?is)<blockquote.*</blockquote>|<div[^>]+class="[^"]*(gmail_quote|moz-cite-prefix)[^"]*".*`)
+	htmlLineBreakRegex = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</h[1-6]>`)
+	blankLinesRegex    = regexp.MustCompile(`\n{3,}`)
+)
+
+// ReplyText returns the text of a reply without the quoted original message and signatures.
+func (msg *Message) ReplyText() string {
+	text := msg.Text
+	if strings.TrimSpace(text) == "" && msg.HTML != "" {
+		text = htmlToText(msg.HTML)
+	}
+	return StripReply(text)
+}
+
+func htmlToText(content string) string {
+	content = htmlQuoteRegex.ReplaceAllString(content, "")
+	content = htmlLineBreakRegex.ReplaceAllString(content, "\n")
+	content = html.UnescapeString(bluemonday.StrictPolicy().Sanitize(content))
+	return blankLinesRegex.ReplaceAllString(content, "\n\n")
+}
+
+// StripReply removes quoted text and signatures from the plain text of a reply mail.
+func StripReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if line == "-- " || trimmed == "--" || mobileSignatureRegex.MatchString(trimmed) {
+			break
+		}
+
+		if isReplyHeader(trimmed) {
+			break
+		}
+		// Some clients wrap the reply header over two lines
+		if i+1 < len(lines) && isReplyHeader(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			break
+		}
+		if outlookFromRegex.MatchString(trimmed) && i+1 < len(lines) && outlookSentRegex.MatchString(strings.TrimSpace(lines[i+1])) {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func isReplyHeader(line string) bool {
+	for _, r := range replyHeaderRegexes {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
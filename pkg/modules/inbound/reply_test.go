@@ -0,0 +1,87 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripReply(t *testing.T) {
+	t.Run("quoted text", func(t *testing.T) {
+		text := "Sounds good!\r\n" +
+			"\r\n" +
+			"On Mon, 1 Jan 2024 at 10:00, Vikunja <vikunja@example.com> wrote:\r\n" +
+			"> user2 commented on Task #1\r\n" +
+			"> Are we done?\r\n"
+		assert.Equal(t, "Sounds good!", StripReply(text))
+	})
+	t.Run("wrapped reply header", func(t *testing.T) {
+		text := "Sounds good!\n" +
+			"\n" +
+			"On Mon, 1 Jan 2024 at 10:00, Vikunja\n" +
+			"<vikunja@example.com> wrote:\n" +
+			"\n" +
+			"Are we done?\n"
+		assert.Equal(t, "Sounds good!", StripReply(text))
+	})
+	t.Run("german reply header", func(t *testing.T) {
+		text := "Passt!\n\nAm 01.01.2024 um 10:00 schrieb Vikunja <vikunja@example.com>:\n> Are we done?\n"
+		assert.Equal(t, "Passt!", StripReply(text))
+	})
+	t.Run("outlook", func(t *testing.T) {
+		text := "Sounds good!\n" +
+			"\n" +
+			"From: Vikunja <vikunja@example.com>\n" +
+			"Sent: Monday, January 1, 2024 10:00 AM\n" +
+			"Subject: Task #1\n" +
+			"\n" +
+			"Are we done?\n"
+		assert.Equal(t, "Sounds good!", StripReply(text))
+	})
+	t.Run("signature", func(t *testing.T) {
+		text := "First line\n" +
+			"> quoted inline\n" +
+			"Second line\n" +
+			"-- \n" +
+			"Jane Doe\n" +
+			"ACME Inc.\n"
+		assert.Equal(t, "First line\nSecond line", StripReply(text))
+	})
+	t.Run("mobile signature", func(t *testing.T) {
+		assert.Equal(t, "Yes", StripReply("Yes\n\nSent from my iPhone\n"))
+	})
+	t.Run("keeps from lines in the reply", func(t *testing.T) {
+		assert.Equal(t, "From: tomorrow on, this is done", StripReply("From: tomorrow on, this is done\n"))
+	})
+}
+
+func TestMessage_ReplyText(t *testing.T) {
+	msg := &Message{
+		HTML: `<div dir="ltr">Sounds <b>good</b>!<br>See you &amp; bye</div>` +
+			`<div class="gmail_quote"><div>On Mon, 1 Jan 2024 Vikunja wrote:</div>` +
+			`<blockquote>Are we done?</blockquote></div>`,
+	}
+	assert.Equal(t, "Sounds good!\nSee you & bye", msg.ReplyText())
+
+	msg = &Message{
+		Text: "Plain reply\n> quoted",
+		HTML: "<p>Html reply</p>",
+	}
+	assert.Equal(t, "Plain reply", msg.ReplyText())
+}
@@ -0,0 +1,349 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+)
+
+const (
+	maxRecipients  = 100
+	commandTimeout = 5 * time.Minute
+)
+
+// Envelope holds the addresses a mail was sent from and to in the smtp session,
+// which can be different from the addresses in its headers.
+type Envelope struct {
+	From       string
+	Recipients []string
+}
+
+// Handler processes a received mail. If it returns an ErrRejected, the mail is
+// permanently rejected, all other errors are reported as temporary failures so
+// that the sending server tries again later.
+type Handler func(envelope *Envelope, msg *Message) error
+
+// ErrRejected is returned by a Handler to permanently reject a mail
+type ErrRejected struct {
+	Reason string
+}
+
+func (err *ErrRejected) Error() string {
+	return fmt.Sprintf("Mail rejected [Reason: %s]", err.Reason)
+}
+
+// IsErrRejected checks if an error is ErrRejected.
+func IsErrRejected(err error) bool {
+	var errRejected *ErrRejected
+	return errors.As(err, &errRejected)
+}
+
+// Server is a minimal smtp server as defined in RFC 5321 which passes every
+// received mail to a Handler. It does not support TLS or authentication and is
+// meant to run behind a regular mail server.
+type Server struct {
+	// The address to listen on, like :2525
+	Addr string
+	// The hostname the server announces itself with
+	Hostname string
+	// The maximum size of a mail in bytes
+	MaxSize int64
+	// Called for every recipient address, the recipient is rejected if it returns false.
+	AcceptRecipient func(address string) bool
+	Handler         Handler
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// ListenAndServe listens on the configured address and serves smtp sessions until the server is closed.
+func (srv *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts smtp sessions on the listener until the server is closed.
+func (srv *Server) Serve(l net.Listener) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		return net.ErrClosed
+	}
+	srv.listener = l
+	srv.conns = make(map[net.Conn]struct{})
+	srv.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		srv.mu.Lock()
+		srv.conns[conn] = struct{}{}
+		srv.mu.Unlock()
+
+		srv.wg.Add(1)
+		go func() {
+			defer srv.wg.Done()
+			srv.handleConn(conn)
+
+			srv.mu.Lock()
+			delete(srv.conns, conn)
+			srv.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops the server and closes all open sessions.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	srv.closed = true
+	var err error
+	if srv.listener != nil {
+		err = srv.listener.Close()
+	}
+	for conn := range srv.conns {
+		_ = conn.Close()
+	}
+	srv.mu.Unlock()
+
+	srv.wg.Wait()
+	return err
+}
+
+type session struct {
+	srv  *Server
+	conn net.Conn
+	text *textproto.Conn
+
+	helo     bool
+	envelope *Envelope
+}
+
+func (srv *Server) handleConn(conn net.Conn) {
+	s := &session{
+		srv:  srv,
+		conn: conn,
+		text: textproto.NewConn(conn),
+	}
+	defer s.text.Close()
+
+	s.reply(220, srv.Hostname+" ESMTP Vikunja")
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(commandTimeout))
+		line, err := s.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+		command = strings.ToUpper(command)
+		arg = strings.TrimSpace(arg)
+
+		switch command {
+		case "HELO":
+			s.helo = true
+			s.envelope = nil
+			s.reply(250, srv.Hostname)
+		case "EHLO":
+			s.helo = true
+			s.envelope = nil
+			s.reply(250, srv.Hostname, "8BITMIME", "SIZE "+strconv.FormatInt(srv.MaxSize, 10))
+		case "MAIL":
+			s.handleMail(arg)
+		case "RCPT":
+			s.handleRcpt(arg)
+		case "DATA":
+			s.handleData()
+		case "RSET":
+			s.envelope = nil
+			s.reply(250, "OK")
+		case "NOOP":
+			s.reply(250, "OK")
+		case "VRFY":
+			s.reply(252, "Cannot verify user")
+		case "QUIT":
+			s.reply(221, "Bye")
+			return
+		default:
+			s.reply(502, "Command not implemented")
+		}
+	}
+}
+
+func (s *session) reply(code int, lines ...string) {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		err := s.text.PrintfLine("%d%s%s", code, separator, line)
+		if err != nil {
+			log.Debugf("[Inbound Mail] Could not send reply: %s", err)
+			return
+		}
+	}
+}
+
+// parsePath parses arguments like FROM:<mail@example.com> SIZE=1234
+func parsePath(arg, prefix string) (address string, params map[string]string, ok bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+	end := strings.Index(arg, ">")
+	if end == -1 {
+		return "", nil, false
+	}
+
+	address = arg[1:end]
+	params = make(map[string]string)
+	for _, param := range strings.Fields(arg[end+1:]) {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = value
+	}
+	return address, params, true
+}
+
+func (s *session) handleMail(arg string) {
+	if !s.helo {
+		s.reply(503, "Send HELO or EHLO first")
+		return
+	}
+	if s.envelope != nil {
+		s.reply(503, "Sender already specified")
+		return
+	}
+	from, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		s.reply(501, "Syntax: MAIL FROM:<address>")
+		return
+	}
+	if size, err := strconv.ParseInt(params["SIZE"], 10, 64); err == nil && size > s.srv.MaxSize {
+		s.reply(552, "Message exceeds the maximum size")
+		return
+	}
+
+	s.envelope = &Envelope{From: from}
+	s.reply(250, "OK")
+}
+
+func (s *session) handleRcpt(arg string) {
+	if s.envelope == nil {
+		s.reply(503, "Send MAIL first")
+		return
+	}
+	to, _, ok := parsePath(arg, "TO:")
+	if !ok {
+		s.reply(501, "Syntax: RCPT TO:<address>")
+		return
+	}
+	if len(s.envelope.Recipients) >= maxRecipients {
+		s.reply(452, "Too many recipients")
+		return
+	}
+	if _, err := mail.ParseAddress(to); err != nil {
+		s.reply(553, "Invalid address")
+		return
+	}
+	if s.srv.AcceptRecipient != nil && !s.srv.AcceptRecipient(to) {
+		s.reply(550, "No such user")
+		return
+	}
+
+	s.envelope.Recipients = append(s.envelope.Recipients, to)
+	s.reply(250, "OK")
+}
+
+func (s *session) handleData() {
+	if s.envelope == nil || len(s.envelope.Recipients) == 0 {
+		s.reply(503, "Send RCPT first")
+		return
+	}
+	envelope := s.envelope
+	s.envelope = nil
+
+	s.reply(354, "End data with <CR><LF>.<CR><LF>")
+
+	dr := s.text.DotReader()
+	data, err := io.ReadAll(io.LimitReader(dr, s.srv.MaxSize+1))
+	if err != nil {
+		s.reply(451, "Could not read message")
+		return
+	}
+	if int64(len(data)) > s.srv.MaxSize {
+		_, _ = io.Copy(io.Discard, dr)
+		s.reply(552, "Message exceeds the maximum size")
+		return
+	}
+
+	msg, err := ParseMessage(bytes.NewReader(data))
+	if err != nil {
+		log.Debugf("[Inbound Mail] Could not parse message from %s: %s", envelope.From, err)
+		s.reply(550, "Could not parse message")
+		return
+	}
+
+	if s.srv.Handler == nil {
+		s.reply(451, "Not ready to accept mails")
+		return
+	}
+
+	err = s.srv.Handler(envelope, msg)
+	if err != nil {
+		var errRejected *ErrRejected
+		if errors.As(err, &errRejected) {
+			log.Debugf("[Inbound Mail] Rejected message from %s: %s", envelope.From, errRejected.Reason)
+			s.reply(550, errRejected.Reason)
+			return
+		}
+		log.Errorf("[Inbound Mail] Could not process message from %s: %s", envelope.From, err)
+		s.reply(451, "Could not process message, try again later")
+		return
+	}
+
+	s.reply(250, "OK")
+}
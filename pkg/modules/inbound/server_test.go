@@ -0,0 +1,129 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServer(t *testing.T, handler Handler) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &Server{
+		Hostname: "example.com",
+		MaxSize:  1024,
+		AcceptRecipient: func(address string) bool {
+			return strings.HasSuffix(address, "@example.com")
+		},
+		Handler: handler,
+	}
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	return l.Addr().String()
+}
+
+func TestServer(t *testing.T) {
+	body := "From: user1@example.com\r\n" +
+		"Subject: Test\r\n" +
+		"\r\n" +
+		"Hello\r\n" +
+		".leading dot\r\n"
+
+	t.Run("normal", func(t *testing.T) {
+		var received *Message
+		var receivedEnvelope *Envelope
+		addr := startTestServer(t, func(envelope *Envelope, msg *Message) error {
+			receivedEnvelope = envelope
+			received = msg
+			return nil
+		})
+
+		err := smtp.SendMail(addr, nil, "sender@example.org", []string{"vikunja+abc@example.com", "vikunja+def@example.com"}, []byte(body))
+		require.NoError(t, err)
+		require.NotNil(t, received)
+		assert.Equal(t, "sender@example.org", receivedEnvelope.From)
+		assert.Equal(t, []string{"vikunja+abc@example.com", "vikunja+def@example.com"}, receivedEnvelope.Recipients)
+		assert.Equal(t, "Test", received.Subject)
+		assert.Equal(t, "Hello\n.leading dot\n", received.Text)
+	})
+	t.Run("unknown recipient", func(t *testing.T) {
+		called := false
+		addr := startTestServer(t, func(_ *Envelope, _ *Message) error {
+			called = true
+			return nil
+		})
+
+		err := smtp.SendMail(addr, nil, "sender@example.org", []string{"someone@example.org"}, []byte(body))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "550")
+		assert.False(t, called)
+	})
+	t.Run("rejected by handler", func(t *testing.T) {
+		addr := startTestServer(t, func(_ *Envelope, _ *Message) error {
+			return &ErrRejected{Reason: "Nope"}
+		})
+
+		err := smtp.SendMail(addr, nil, "sender@example.org", []string{"vikunja+abc@example.com"}, []byte(body))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "550")
+		assert.Contains(t, err.Error(), "Nope")
+	})
+	t.Run("too large", func(t *testing.T) {
+		called := false
+		addr := startTestServer(t, func(_ *Envelope, _ *Message) error {
+			called = true
+			return nil
+		})
+
+		err := smtp.SendMail(addr, nil, "sender@example.org", []string{"vikunja+abc@example.com"}, []byte(body+strings.Repeat("a", 2048)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "552")
+		assert.False(t, called)
+	})
+}
+
+func TestGetTokenFromAddress(t *testing.T) {
+	config.InboundAddress.Set("vikunja@example.com")
+	defer config.InboundAddress.Set("")
+
+	assert.Equal(t, "vikunja+abc@example.com", GetAddress("abc"))
+
+	token, ok := GetTokenFromAddress("Vikunja+abc@EXAMPLE.com")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", token)
+
+	_, ok = GetTokenFromAddress("vikunja@example.com")
+	assert.False(t, ok)
+	_, ok = GetTokenFromAddress("other+abc@example.com")
+	assert.False(t, ok)
+	_, ok = GetTokenFromAddress("vikunja+abc@example.org")
+	assert.False(t, ok)
+}
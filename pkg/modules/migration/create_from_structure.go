@@ -136,6 +136,7 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 	originalBackgroundInformation := project.BackgroundInformation
 	needsDefaultBucket := false
 	oldViews := project.Views
+	originalCustomFields := project.CustomFields
 
 	// Saving the archived status to archive the project again after creating it
 	var wasArchived bool
@@ -270,6 +271,19 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 
 	}
 
+	// Create all custom fields, the values of the tasks reference them by their old id
+	customFieldsByOldID := make(map[int64]*models.ProjectCustomField, len(originalCustomFields))
+	for _, field := range originalCustomFields {
+		oldID := field.ID
+		field.ProjectID = project.ID
+		err = field.Create(s, user)
+		if err != nil {
+			return
+		}
+		customFieldsByOldID[oldID] = field
+		log.Debugf("[creating structure] Created custom field %d, old ID was %d", field.ID, oldID)
+	}
+
 	log.Debugf("[creating structure] Creating %d tasks", len(tasks))
 
 	setBucketOrDefault := func(task *models.Task) (err error) {
@@ -307,6 +321,18 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 		t.ProjectID = project.ID
 		originalBucketID := t.BucketID
 		t.BucketID = 0
+
+		customFields := make(map[int64]interface{}, len(t.CustomFields))
+		for oldFieldID, value := range t.CustomFields {
+			field, has := customFieldsByOldID[oldFieldID]
+			// Users are not migrated, a user field value would reference a user which might not exist
+			if !has || field.Type == models.ProjectCustomFieldTypeUser {
+				continue
+			}
+			customFields[field.ID] = value
+		}
+		t.CustomFields = customFields
+
 		err = t.Create(s, user)
 		if err != nil && models.IsErrTaskCannotBeEmpty(err) {
 			continue
@@ -448,13 +474,18 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 		}
 
 		// Comments
+		newCommentIDs := make(map[int64]int64, len(t.Comments))
 		for _, comment := range t.Comments {
+			oldID := comment.ID
 			comment.TaskID = t.ID
 			comment.ID = 0
+			// Replies to comments which were not imported (yet) become regular comments
+			comment.ParentID = newCommentIDs[comment.ParentID]
 			err = comment.CreateWithTimestamps(s, user)
 			if err != nil {
 				return
 			}
+			newCommentIDs[oldID] = comment.ID
 			log.Debugf("[creating structure] Created new comment %d", comment.ID)
 		}
 	}
@@ -0,0 +1,351 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dates without a time are set to this hour.
+const defaultHour = 12
+
+// dateAt returns the date days after t at the default hour.
+func dateAt(t time.Time, days int) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+days, defaultHour, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(now time.Time, weekStart time.Weekday) time.Time {
+	return dateAt(now, -((int(now.Weekday()) - int(weekStart) + 7) % 7))
+}
+
+var months = map[string]time.Month{
+	"jan": time.January,
+	"feb": time.February,
+	"mar": time.March,
+	"apr": time.April,
+	"may": time.May,
+	"jun": time.June,
+	"jul": time.July,
+	"aug": time.August,
+	"sep": time.September,
+	"oct": time.October,
+	"nov": time.November,
+	"dec": time.December,
+}
+
+const monthPattern = `jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t|tember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?`
+
+func parseMonth(s string) time.Month {
+	return months[strings.ToLower(s)[:3]]
+}
+
+// absoluteDate builds a date from its parts. If the year is missing, the next date with that day and month is
+// used. It returns false if the date does not exist.
+func absoluteDate(now time.Time, year string, month time.Month, day string) (time.Time, bool) {
+	d, err := strconv.Atoi(day)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	y := now.Year()
+	if year != "" {
+		y, err = strconv.Atoi(year)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if y < 100 {
+			y += 2000
+		}
+	}
+
+	date := time.Date(y, month, d, defaultHour, 0, 0, 0, now.Location())
+	if date.Day() != d || date.Month() != month {
+		return time.Time{}, false
+	}
+
+	if year == "" && date.Before(dateAt(now, 0)) {
+		date = date.AddDate(1, 0, 0)
+	}
+
+	return date, true
+}
+
+func parseMonthNumber(s string) (time.Month, bool) {
+	m, err := strconv.Atoi(s)
+	if err != nil || m < 1 || m > 12 {
+		return 0, false
+	}
+	return time.Month(m), true
+}
+
+// group returns the text of a submatch, or an empty string if the group did not match.
+func group(text string, match []int, n int) string {
+	if match[2*n] == -1 {
+		return ""
+	}
+	return text[match[2*n]:match[2*n+1]]
+}
+
+type dateMatcher struct {
+	regex *regexp.Regexp
+	// parse gets the text and the submatch indexes of the regex. It returns false if the match is not a valid date.
+	parse func(text string, match []int, opts *Options) (time.Time, bool)
+}
+
+const ordinalPattern = `(?:st|nd|rd|th)?`
+
+var dateMatchers = []*dateMatcher{
+	{
+		// in 3 days, in a week, in 2 hours
+		regex: wordRegex(`in\s+(` + numberPattern + `)\s+(hour|day|week|month|year)s?`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			amount := int(parseNumber(group(text, match, 2)))
+			if amount < 1 {
+				return time.Time{}, false
+			}
+			switch RepeatUnit(strings.ToLower(group(text, match, 3))) {
+			case RepeatUnitHour:
+				return opts.Now.Add(time.Duration(amount) * time.Hour).Truncate(time.Minute), true
+			case RepeatUnitDay:
+				return dateAt(opts.Now, amount), true
+			case RepeatUnitWeek:
+				return dateAt(opts.Now, amount*7), true
+			case RepeatUnitMonth:
+				return dateAt(opts.Now, 0).AddDate(0, amount, 0), true
+			default:
+				return dateAt(opts.Now, 0).AddDate(amount, 0, 0), true
+			}
+		},
+	},
+	{
+		// 2021-02-17
+		regex: wordRegex(`(?:on\s+)?(\d{4})-(\d{1,2})-(\d{1,2})`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			month, ok := parseMonthNumber(group(text, match, 3))
+			if !ok {
+				return time.Time{}, false
+			}
+			return absoluteDate(opts.Now, group(text, match, 2), month, group(text, match, 4))
+		},
+	},
+	{
+		// 17.02.2021 or 17.02.
+		regex: wordRegex(`(?:on\s+)?(\d{1,2})\.(\d{1,2})\.(\d{2}|\d{4})?`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			month, ok := parseMonthNumber(group(text, match, 3))
+			if !ok {
+				return time.Time{}, false
+			}
+			return absoluteDate(opts.Now, group(text, match, 4), month, group(text, match, 2))
+		},
+	},
+	{
+		// 02/17/2021 or 02/17
+		regex: wordRegex(`(?:on\s+)?(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			month, ok := parseMonthNumber(group(text, match, 2))
+			if !ok {
+				return time.Time{}, false
+			}
+			return absoluteDate(opts.Now, group(text, match, 4), month, group(text, match, 3))
+		},
+	},
+	{
+		// Feb 17, February 17th 2021
+		regex: wordRegex(`(?:on\s+)?(` + monthPattern + `)\.?\s+(\d{1,2})` + ordinalPattern + `(?:,?\s+(\d{4}))?`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			return absoluteDate(opts.Now, group(text, match, 4), parseMonth(group(text, match, 2)), group(text, match, 3))
+		},
+	},
+	{
+		// 17 Feb, 17th of February 2021
+		regex: wordRegex(`(?:on\s+(?:the\s+)?)?(\d{1,2})` + ordinalPattern + `(?:\s+of)?\s+(` + monthPattern + `)\.?(?:,?\s+(\d{4}))?`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			return absoluteDate(opts.Now, group(text, match, 4), parseMonth(group(text, match, 3)), group(text, match, 2))
+		},
+	},
+	{
+		regex: wordRegex(`today`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			return dateAt(opts.Now, 0), true
+		},
+	},
+	{
+		regex: wordRegex(`tomorrow`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			return dateAt(opts.Now, 1), true
+		},
+	},
+	{
+		// The last day of the current week
+		regex: wordRegex(`later\s+this\s+week|end\s+of\s+(?:the\s+)?week`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			return startOfWeek(opts.Now, opts.WeekStart).AddDate(0, 0, 6), true
+		},
+	},
+	{
+		// The last day of the next week
+		regex: wordRegex(`later\s+next\s+week|end\s+of\s+next\s+week`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			return startOfWeek(opts.Now, opts.WeekStart).AddDate(0, 0, 13), true
+		},
+	},
+	{
+		// The first day of the next week
+		regex: wordRegex(`next\s+week`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			return startOfWeek(opts.Now, opts.WeekStart).AddDate(0, 0, 7), true
+		},
+	},
+	{
+		// The coming saturday, or today if it is already weekend
+		regex: wordRegex(`this\s+weekend`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			if opts.Now.Weekday() == time.Sunday {
+				return dateAt(opts.Now, 0), true
+			}
+			return dateAt(opts.Now, int(time.Saturday-opts.Now.Weekday())), true
+		},
+	},
+	{
+		regex: wordRegex(`end\s+of\s+(?:the\s+)?month`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			y, m, _ := opts.Now.Date()
+			return time.Date(y, m+1, 0, defaultHour, 0, 0, 0, opts.Now.Location()), true
+		},
+	},
+	{
+		// The first day of the next month
+		regex: wordRegex(`next\s+month`),
+		parse: func(_ string, _ []int, opts *Options) (time.Time, bool) {
+			y, m, _ := opts.Now.Date()
+			return time.Date(y, m+1, 1, defaultHour, 0, 0, 0, opts.Now.Location()), true
+		},
+	},
+	{
+		// monday, next friday, on tuesday: always the next one after today
+		regex: wordRegex(`(?:on\s+|next\s+|this\s+)?(` + weekdayPattern + `)`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			weekday := weekdays[strings.ToLower(group(text, match, 2))]
+			days := (int(weekday) - int(opts.Now.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return dateAt(opts.Now, days), true
+		},
+	},
+	{
+		// the 17th: the next 17th of a month
+		regex: wordRegex(`(?:on\s+)?(?:the\s+)?(\d{1,2})(?:st|nd|rd|th)`),
+		parse: func(text string, match []int, opts *Options) (time.Time, bool) {
+			day, _ := strconv.Atoi(group(text, match, 2))
+			for i := 0; i < 12; i++ {
+				date := dateAt(opts.Now, 0)
+				date = time.Date(date.Year(), date.Month()+time.Month(i), day, defaultHour, 0, 0, 0, date.Location())
+				if date.Day() == day && !date.Before(dateAt(opts.Now, 0)) {
+					return date, true
+				}
+			}
+			return time.Time{}, false
+		},
+	},
+}
+
+var timeMatchers = []*regexp.Regexp{
+	// at 15:00, at 3pm, at 3:30 pm
+	wordRegex(`at\s+(\d{1,2})(?::(\d{2}))?\s*(am|pm)?`),
+	// 3pm, 3:30pm
+	wordRegex(`(\d{1,2})(?::(\d{2}))?\s*(am|pm)`),
+}
+
+func parseTime(text string, match []int) (hour, minute int, ok bool) {
+	hour, err := strconv.Atoi(group(text, match, 2))
+	if err != nil {
+		return 0, 0, false
+	}
+	if m := group(text, match, 3); m != "" {
+		minute, err = strconv.Atoi(m)
+		if err != nil || minute > 59 {
+			return 0, 0, false
+		}
+	}
+
+	switch strings.ToLower(group(text, match, 4)) {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, false
+		}
+	}
+
+	return hour, minute, true
+}
+
+// extractDate removes the first date and time it finds from the text. A time without a date is today.
+func extractDate(text string, opts *Options) (rest string, date time.Time) {
+	for _, matcher := range dateMatchers {
+		match := matcher.regex.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		d, ok := matcher.parse(text, match, opts)
+		if !ok {
+			continue
+		}
+		date = d
+		text = cut(text, match[2], match[3])
+		break
+	}
+
+	for _, regex := range timeMatchers {
+		match := regex.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		hour, minute, ok := parseTime(text, match)
+		if !ok {
+			continue
+		}
+
+		text = cut(text, match[2], match[3])
+		if date.IsZero() {
+			date = dateAt(opts.Now, 0)
+		}
+		y, m, d := date.Date()
+		date = time.Date(y, m, d, hour, minute, 0, 0, date.Location())
+		break
+	}
+
+	return text, date
+}
@@ -0,0 +1,219 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package quickadd parses a single line of text like "Buy milk tomorrow *groceries @alice +Home !3" into the parts
+// of a task. It only parses, resolving labels, users and projects is up to the caller.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mode selects the prefixes used to mark labels, projects and assignees.
+type Mode string
+
+const (
+	// ModeVikunja uses *label, +project, @assignee and !priority.
+	ModeVikunja Mode = "vikunja"
+	// ModeTodoist uses @label, #project, +assignee and !priority.
+	ModeTodoist Mode = "todoist"
+	// ModeDisabled does not parse anything, the whole text becomes the title.
+	ModeDisabled Mode = "disabled"
+)
+
+// Prefixes holds the characters which mark the different parts of the text.
+type Prefixes struct {
+	Label    string
+	Project  string
+	Assignee string
+	Priority string
+}
+
+var modePrefixes = map[Mode]*Prefixes{
+	ModeVikunja: {Label: "*", Project: "+", Assignee: "@", Priority: "!"},
+	ModeTodoist: {Label: "@", Project: "#", Assignee: "+", Priority: "!"},
+}
+
+// IsValid checks if the mode is one of the known modes.
+func (m Mode) IsValid() bool {
+	return m == ModeVikunja || m == ModeTodoist || m == ModeDisabled
+}
+
+// Prefixes returns the prefixes of the mode, nil for ModeDisabled.
+func (m Mode) Prefixes() *Prefixes {
+	return modePrefixes[m]
+}
+
+// Options configure how the text is parsed.
+type Options struct {
+	Mode Mode
+	// The current time in the time zone of the user. All relative dates are calculated from it.
+	Now time.Time
+	// The first day of the week of the user, used for things like "next week".
+	WeekStart time.Weekday
+}
+
+// Result holds all parts found in the text.
+type Result struct {
+	// The text with everything that was parsed removed, except for the assignees. They are only removed once
+	// the caller found the users, see RemoveAssignees.
+	Title   string
+	Labels  []string
+	Project string
+	// The usernames of the assignees.
+	Assignees []string
+	// 0 if no priority was found.
+	Priority int64
+	// Zero if no date was found.
+	Date   time.Time
+	Repeat *Repeat
+}
+
+// Parse parses the text. Everything which was found is removed from the title, except for the assignees.
+func Parse(text string, opts *Options) *Result {
+	result := &Result{}
+
+	prefixes := opts.Mode.Prefixes()
+	if prefixes == nil {
+		result.Title = strings.TrimSpace(text)
+		return result
+	}
+
+	text, result.Labels = extractPrefixed(text, prefixes.Label)
+
+	var projects []string
+	text, projects = extractPrefixed(text, prefixes.Project)
+	if len(projects) > 0 {
+		result.Project = projects[0]
+	}
+
+	text, result.Priority = extractPriority(text, prefixes.Priority)
+	_, result.Assignees = extractPrefixed(text, prefixes.Assignee)
+	text, result.Repeat = extractRepeat(text)
+	text, result.Date = extractDate(text, opts)
+
+	if result.Repeat != nil && result.Date.IsZero() {
+		result.Date = result.Repeat.firstDate(opts.Now)
+	}
+
+	result.Title = cleanup(text)
+	return result
+}
+
+// RemoveAssignees removes the assignees with the given usernames from the title.
+func RemoveAssignees(title string, mode Mode, usernames []string) string {
+	prefixes := mode.Prefixes()
+	if prefixes == nil || len(usernames) == 0 {
+		return title
+	}
+
+	remove := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		remove[username] = true
+	}
+
+	reg := prefixedRegex(prefixes.Assignee)
+	offset := 0
+	for {
+		match := reg.FindStringSubmatchIndex(title[offset:])
+		if match == nil {
+			return cleanup(title)
+		}
+		for i := range match {
+			match[i] += offset
+		}
+
+		if !remove[strings.Trim(title[match[4]:match[5]], `"'`)] {
+			offset = match[3]
+			continue
+		}
+		title = cut(title, match[2], match[3])
+		offset = match[2]
+	}
+}
+
+func cleanup(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// cut removes the part between start and end from the text.
+func cut(text string, start, end int) string {
+	return text[:start] + " " + text[end:]
+}
+
+func prefixedRegex(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|\s)(` + regexp.QuoteMeta(prefix) + `("[^"]+"|'[^']+'|[^\s"']+))`)
+}
+
+// extractPrefixed removes all words starting with prefix from the text. Values with spaces can be quoted with " or '.
+func extractPrefixed(text string, prefix string) (rest string, values []string) {
+	reg := prefixedRegex(prefix)
+	for {
+		match := reg.FindStringSubmatchIndex(text)
+		if match == nil {
+			return text, values
+		}
+
+		value := strings.Trim(text[match[4]:match[5]], `"'`)
+		values = append(values, value)
+		text = cut(text, match[2], match[3])
+	}
+}
+
+func extractPriority(text string, prefix string) (rest string, priority int64) {
+	reg := regexp.MustCompile(`(?:^|\s)(` + regexp.QuoteMeta(prefix) + `([1-5]))(?:$|\s)`)
+	match := reg.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text, 0
+	}
+
+	priority, _ = strconv.ParseInt(text[match[4]:match[5]], 10, 64)
+	return cut(text, match[2], match[3]), priority
+}
+
+// wordRegex builds a case-insensitive regex which only matches pattern as whole words. The first group always
+// contains the whole match without the surrounding whitespace.
+func wordRegex(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|\s)(` + pattern + `)(?:$|[\s,;!?]|\.(?:$|\s))`)
+}
+
+var numberWords = map[string]int64{
+	"a":     1,
+	"an":    1,
+	"one":   1,
+	"two":   2,
+	"three": 3,
+	"four":  4,
+	"five":  5,
+	"six":   6,
+	"seven": 7,
+	"eight": 8,
+	"nine":  9,
+	"ten":   10,
+}
+
+const numberPattern = `\d+|a|an|one|two|three|four|five|six|seven|eight|nine|ten`
+
+func parseNumber(s string) int64 {
+	if n, has := numberWords[strings.ToLower(s)]; has {
+		return n
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
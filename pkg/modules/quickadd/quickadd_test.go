@@ -0,0 +1,331 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testLocation = time.FixedZone("UTC+2", 2*60*60)
+	// A thursday
+	testNow = time.Date(2021, 6, 24, 15, 3, 0, 0, testLocation)
+)
+
+func testDate(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, testLocation)
+}
+
+func parse(text string, mode Mode) *Result {
+	return Parse(text, &Options{Mode: mode, Now: testNow, WeekStart: time.Monday})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("everything", func(t *testing.T) {
+		result := parse("Buy milk tomorrow *groceries @alice +Home !3", ModeVikunja)
+		assert.Equal(t, "Buy milk @alice", result.Title)
+		assert.Equal(t, []string{"groceries"}, result.Labels)
+		assert.Equal(t, []string{"alice"}, result.Assignees)
+		assert.Equal(t, "Home", result.Project)
+		assert.Equal(t, int64(3), result.Priority)
+		assert.Equal(t, testDate(2021, 6, 25, 12, 0), result.Date)
+		assert.Nil(t, result.Repeat)
+	})
+	t.Run("nothing", func(t *testing.T) {
+		result := parse("  Buy milk  ", ModeVikunja)
+		assert.Equal(t, "Buy milk", result.Title)
+		assert.Empty(t, result.Labels)
+		assert.Empty(t, result.Assignees)
+		assert.Empty(t, result.Project)
+		assert.Zero(t, result.Priority)
+		assert.True(t, result.Date.IsZero())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		result := parse("Buy milk tomorrow *groceries @alice +Home !3", ModeDisabled)
+		assert.Equal(t, "Buy milk tomorrow *groceries @alice +Home !3", result.Title)
+		assert.Empty(t, result.Labels)
+		assert.True(t, result.Date.IsZero())
+	})
+	t.Run("todoist", func(t *testing.T) {
+		result := parse("Buy milk @groceries +alice #Home !3", ModeTodoist)
+		assert.Equal(t, "Buy milk +alice", result.Title)
+		assert.Equal(t, []string{"groceries"}, result.Labels)
+		assert.Equal(t, []string{"alice"}, result.Assignees)
+		assert.Equal(t, "Home", result.Project)
+		assert.Equal(t, int64(3), result.Priority)
+	})
+}
+
+func TestParse_Labels(t *testing.T) {
+	tests := map[string]struct {
+		text   string
+		title  string
+		labels []string
+	}{
+		"multiple": {
+			text:   "*one Lorem *two ipsum *three",
+			title:  "Lorem ipsum",
+			labels: []string{"one", "two", "three"},
+		},
+		"quoted with spaces": {
+			text:   `Lorem *"label one" ipsum *'label two'`,
+			title:  "Lorem ipsum",
+			labels: []string{"label one", "label two"},
+		},
+		"not in the middle of a word": {
+			text:  "2*3 is six",
+			title: "2*3 is six",
+		},
+		"label looking like a date": {
+			text:   "Lorem *today",
+			title:  "Lorem",
+			labels: []string{"today"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := parse(test.text, ModeVikunja)
+			assert.Equal(t, test.title, result.Title)
+			assert.Equal(t, test.labels, result.Labels)
+			assert.True(t, result.Date.IsZero())
+		})
+	}
+}
+
+func TestParse_Assignees(t *testing.T) {
+	t.Run("multiple", func(t *testing.T) {
+		result := parse("Lorem @user1 @user2 ipsum", ModeVikunja)
+		assert.Equal(t, "Lorem @user1 @user2 ipsum", result.Title)
+		assert.Equal(t, []string{"user1", "user2"}, result.Assignees)
+	})
+	t.Run("with date", func(t *testing.T) {
+		result := parse("Lorem @monday monday", ModeVikunja)
+		assert.Equal(t, "Lorem @monday", result.Title)
+		assert.Equal(t, []string{"monday"}, result.Assignees)
+		assert.Equal(t, testDate(2021, 6, 28, 12, 0), result.Date)
+	})
+	t.Run("email addresses are not assignees", func(t *testing.T) {
+		result := parse("Mail user@example.com", ModeVikunja)
+		assert.Equal(t, "Mail user@example.com", result.Title)
+		assert.Empty(t, result.Assignees)
+	})
+}
+
+func TestParse_Project(t *testing.T) {
+	t.Run("quoted", func(t *testing.T) {
+		result := parse(`Lorem +"Some Project" ipsum`, ModeVikunja)
+		assert.Equal(t, "Lorem ipsum", result.Title)
+		assert.Equal(t, "Some Project", result.Project)
+	})
+	t.Run("only the first one is used", func(t *testing.T) {
+		result := parse("Lorem +one +two", ModeVikunja)
+		assert.Equal(t, "Lorem", result.Title)
+		assert.Equal(t, "one", result.Project)
+	})
+}
+
+func TestParse_Priority(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		title    string
+		priority int64
+	}{
+		"lowest":         {text: "Lorem !1", title: "Lorem", priority: 1},
+		"highest":        {text: "!5 Lorem", title: "Lorem", priority: 5},
+		"out of range":   {text: "Lorem !6", title: "Lorem !6"},
+		"not a priority": {text: "Lorem !!", title: "Lorem !!"},
+		"in a word":      {text: "Lorem!3", title: "Lorem!3"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := parse(test.text, ModeVikunja)
+			assert.Equal(t, test.title, result.Title)
+			assert.Equal(t, test.priority, result.Priority)
+		})
+	}
+}
+
+func TestParse_Dates(t *testing.T) {
+	tests := map[string]struct {
+		text  string
+		title string
+		date  time.Time
+	}{
+		"today":                  {text: "Lorem today", date: testDate(2021, 6, 24, 12, 0)},
+		"uppercase":              {text: "Lorem Today", date: testDate(2021, 6, 24, 12, 0)},
+		"tomorrow":               {text: "Lorem tomorrow", date: testDate(2021, 6, 25, 12, 0)},
+		"in the middle":          {text: "Lorem tomorrow ipsum", title: "Lorem ipsum", date: testDate(2021, 6, 25, 12, 0)},
+		"followed by a comma":    {text: "Lorem tomorrow, ipsum", title: "Lorem , ipsum", date: testDate(2021, 6, 25, 12, 0)},
+		"next week":              {text: "Lorem next week", date: testDate(2021, 6, 28, 12, 0)},
+		"later this week":        {text: "Lorem later this week", date: testDate(2021, 6, 27, 12, 0)},
+		"end of week":            {text: "Lorem end of week", date: testDate(2021, 6, 27, 12, 0)},
+		"later next week":        {text: "Lorem later next week", date: testDate(2021, 7, 4, 12, 0)},
+		"this weekend":           {text: "Lorem this weekend", date: testDate(2021, 6, 26, 12, 0)},
+		"next month":             {text: "Lorem next month", date: testDate(2021, 7, 1, 12, 0)},
+		"end of month":           {text: "Lorem end of month", date: testDate(2021, 6, 30, 12, 0)},
+		"in days":                {text: "Lorem in 3 days", date: testDate(2021, 6, 27, 12, 0)},
+		"in a week":              {text: "Lorem in a week", date: testDate(2021, 7, 1, 12, 0)},
+		"in two weeks":           {text: "Lorem in two weeks", date: testDate(2021, 7, 8, 12, 0)},
+		"in months":              {text: "Lorem in 2 months", date: testDate(2021, 8, 24, 12, 0)},
+		"in a year":              {text: "Lorem in one year", date: testDate(2022, 6, 24, 12, 0)},
+		"in hours":               {text: "Lorem in 2 hours", date: testDate(2021, 6, 24, 17, 3)},
+		"weekday":                {text: "Lorem monday", date: testDate(2021, 6, 28, 12, 0)},
+		"on weekday":             {text: "Lorem on saturday", date: testDate(2021, 6, 26, 12, 0)},
+		"next weekday":           {text: "Lorem next friday", date: testDate(2021, 6, 25, 12, 0)},
+		"today's weekday":        {text: "Lorem thursday", date: testDate(2021, 7, 1, 12, 0)},
+		"iso":                    {text: "Lorem 2021-07-02", date: testDate(2021, 7, 2, 12, 0)},
+		"iso in the past":        {text: "Lorem 2020-07-02", date: testDate(2020, 7, 2, 12, 0)},
+		"dotted":                 {text: "Lorem 02.07.2021", date: testDate(2021, 7, 2, 12, 0)},
+		"dotted short year":      {text: "Lorem 02.07.22", date: testDate(2022, 7, 2, 12, 0)},
+		"dotted without year":    {text: "Lorem 02.07.", date: testDate(2021, 7, 2, 12, 0)},
+		"dotted passed":          {text: "Lorem 02.01.", date: testDate(2022, 1, 2, 12, 0)},
+		"slashes":                {text: "Lorem 07/02/2021", date: testDate(2021, 7, 2, 12, 0)},
+		"slashes without year":   {text: "Lorem on 07/02", date: testDate(2021, 7, 2, 12, 0)},
+		"month name":             {text: "Lorem Jul 2", date: testDate(2021, 7, 2, 12, 0)},
+		"full month name":        {text: "Lorem on July 2nd", date: testDate(2021, 7, 2, 12, 0)},
+		"month name with year":   {text: "Lorem July 2, 2023", date: testDate(2023, 7, 2, 12, 0)},
+		"month name passed":      {text: "Lorem Feb 17", date: testDate(2022, 2, 17, 12, 0)},
+		"day before month":       {text: "Lorem 2 July", date: testDate(2021, 7, 2, 12, 0)},
+		"day of month":           {text: "Lorem on the 2nd of july", date: testDate(2021, 7, 2, 12, 0)},
+		"ordinal":                {text: "Lorem the 28th", date: testDate(2021, 6, 28, 12, 0)},
+		"ordinal passed":         {text: "Lorem on the 3rd", date: testDate(2021, 7, 3, 12, 0)},
+		"ordinal in short month": {text: "Lorem the 31st", date: testDate(2021, 7, 31, 12, 0)},
+		"invalid date":           {text: "Lorem 2021-02-30", title: "Lorem 2021-02-30"},
+		"invalid month":          {text: "Lorem 30.13.2021", title: "Lorem 30.13.2021"},
+		"only a number":          {text: "Buy 3 apples", title: "Buy 3 apples"},
+		"version number":         {text: "Release v1.2", title: "Release v1.2"},
+		"in a word":              {text: "Todays news", title: "Todays news"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := parse(test.text, ModeVikunja)
+			title := test.title
+			if title == "" {
+				title = "Lorem"
+			}
+			assert.Equal(t, title, result.Title)
+			assert.Equal(t, test.date, result.Date)
+		})
+	}
+}
+
+func TestParse_Times(t *testing.T) {
+	tests := map[string]struct {
+		text  string
+		title string
+		date  time.Time
+	}{
+		"date with time":     {text: "Lorem tomorrow at 15:00", date: testDate(2021, 6, 25, 15, 0)},
+		"time before date":   {text: "Lorem at 9:30 tomorrow", date: testDate(2021, 6, 25, 9, 30)},
+		"only time":          {text: "Lorem at 17:30", date: testDate(2021, 6, 24, 17, 30)},
+		"pm":                 {text: "Lorem monday at 3pm", date: testDate(2021, 6, 28, 15, 0)},
+		"pm without at":      {text: "Lorem monday 3:15 pm", date: testDate(2021, 6, 28, 15, 15)},
+		"am":                 {text: "Lorem at 9am", date: testDate(2021, 6, 24, 9, 0)},
+		"12 am is midnight":  {text: "Lorem tomorrow at 12am", date: testDate(2021, 6, 25, 0, 0)},
+		"12 pm is noon":      {text: "Lorem tomorrow at 12pm", date: testDate(2021, 6, 25, 12, 0)},
+		"hour out of range":  {text: "Lorem at 25:00", title: "Lorem at 25:00"},
+		"am out of range":    {text: "Lorem 13am", title: "Lorem 13am"},
+		"minute in a word":   {text: "Lorem at7", title: "Lorem at7"},
+		"date in a sentence": {text: "Meet Alice at 4pm today", title: "Meet Alice", date: testDate(2021, 6, 24, 16, 0)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := parse(test.text, ModeVikunja)
+			title := test.title
+			if title == "" {
+				title = "Lorem"
+			}
+			assert.Equal(t, title, result.Title)
+			assert.Equal(t, test.date, result.Date)
+		})
+	}
+}
+
+func TestParse_WeekStart(t *testing.T) {
+	result := Parse("Lorem next week", &Options{Mode: ModeVikunja, Now: testNow, WeekStart: time.Sunday})
+	assert.Equal(t, testDate(2021, 6, 27, 12, 0), result.Date)
+
+	result = Parse("Lorem later this week", &Options{Mode: ModeVikunja, Now: testNow, WeekStart: time.Sunday})
+	assert.Equal(t, testDate(2021, 6, 26, 12, 0), result.Date)
+}
+
+func TestParse_Repeats(t *testing.T) {
+	monday := time.Monday
+	tests := map[string]struct {
+		text   string
+		title  string
+		repeat *Repeat
+		date   time.Time
+	}{
+		"every day":        {text: "Lorem every day", repeat: &Repeat{Amount: 1, Unit: RepeatUnitDay}, date: testDate(2021, 6, 24, 12, 0)},
+		"every 3 days":     {text: "Lorem every 3 days", repeat: &Repeat{Amount: 3, Unit: RepeatUnitDay}, date: testDate(2021, 6, 24, 12, 0)},
+		"every two weeks":  {text: "Lorem every two weeks", repeat: &Repeat{Amount: 2, Unit: RepeatUnitWeek}, date: testDate(2021, 6, 24, 12, 0)},
+		"every other week": {text: "Lorem every other week", repeat: &Repeat{Amount: 2, Unit: RepeatUnitWeek}, date: testDate(2021, 6, 24, 12, 0)},
+		"every month":      {text: "Lorem every month", repeat: &Repeat{Amount: 1, Unit: RepeatUnitMonth}, date: testDate(2021, 6, 24, 12, 0)},
+		"every 2 years":    {text: "Lorem every 2 years", repeat: &Repeat{Amount: 2, Unit: RepeatUnitYear}, date: testDate(2021, 6, 24, 12, 0)},
+		"every 4 hours":    {text: "Lorem every 4 hours", repeat: &Repeat{Amount: 4, Unit: RepeatUnitHour}, date: testDate(2021, 6, 24, 15, 3)},
+		"daily":            {text: "Lorem daily", repeat: &Repeat{Amount: 1, Unit: RepeatUnitDay}, date: testDate(2021, 6, 24, 12, 0)},
+		"weekly":           {text: "Weekly Lorem", repeat: &Repeat{Amount: 1, Unit: RepeatUnitWeek}, date: testDate(2021, 6, 24, 12, 0)},
+		"annually":         {text: "Lorem annually", repeat: &Repeat{Amount: 1, Unit: RepeatUnitYear}, date: testDate(2021, 6, 24, 12, 0)},
+		"every monday":     {text: "Lorem every monday", repeat: &Repeat{Amount: 1, Unit: RepeatUnitWeek, Weekday: &monday}, date: testDate(2021, 6, 28, 12, 0)},
+		"with date":        {text: "Lorem every week starting next month", title: "Lorem starting", repeat: &Repeat{Amount: 1, Unit: RepeatUnitWeek}, date: testDate(2021, 7, 1, 12, 0)},
+		"with time":        {text: "Lorem every day at 8am", repeat: &Repeat{Amount: 1, Unit: RepeatUnitDay}, date: testDate(2021, 6, 24, 8, 0)},
+		"every 0 days":     {text: "Lorem every 0 days", title: "Lorem every 0 days"},
+		"every alone":      {text: "Lorem every", title: "Lorem every"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := parse(test.text, ModeVikunja)
+			title := test.title
+			if title == "" {
+				title = "Lorem"
+			}
+			assert.Equal(t, title, result.Title)
+			assert.Equal(t, test.repeat, result.Repeat)
+			assert.Equal(t, test.date, result.Date)
+		})
+	}
+}
+
+func TestRepeat_Duration(t *testing.T) {
+	assert.Equal(t, 4*time.Hour, (&Repeat{Amount: 4, Unit: RepeatUnitHour}).Duration())
+	assert.Equal(t, 48*time.Hour, (&Repeat{Amount: 2, Unit: RepeatUnitDay}).Duration())
+	assert.Equal(t, 14*24*time.Hour, (&Repeat{Amount: 2, Unit: RepeatUnitWeek}).Duration())
+	assert.Zero(t, (&Repeat{Amount: 1, Unit: RepeatUnitMonth}).Duration())
+}
+
+func TestRemoveAssignees(t *testing.T) {
+	t.Run("only the given ones", func(t *testing.T) {
+		title := RemoveAssignees("Lorem @user1 @user2 ipsum @user3", ModeVikunja, []string{"user1", "user3"})
+		assert.Equal(t, "Lorem @user2 ipsum", title)
+	})
+	t.Run("quoted", func(t *testing.T) {
+		title := RemoveAssignees(`Lorem @"user 1" ipsum`, ModeVikunja, []string{"user 1"})
+		assert.Equal(t, "Lorem ipsum", title)
+	})
+	t.Run("todoist", func(t *testing.T) {
+		title := RemoveAssignees("Lorem +user1 @user1", ModeTodoist, []string{"user1"})
+		assert.Equal(t, "Lorem @user1", title)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		title := RemoveAssignees("Lorem @user1", ModeDisabled, []string{"user1"})
+		assert.Equal(t, "Lorem @user1", title)
+	})
+}
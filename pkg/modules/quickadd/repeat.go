@@ -0,0 +1,130 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package quickadd
+
+import (
+	"strings"
+	"time"
+)
+
+// RepeatUnit is the unit of a repeat interval.
+type RepeatUnit string
+
+const (
+	RepeatUnitHour  RepeatUnit = "hour"
+	RepeatUnitDay   RepeatUnit = "day"
+	RepeatUnitWeek  RepeatUnit = "week"
+	RepeatUnitMonth RepeatUnit = "month"
+	RepeatUnitYear  RepeatUnit = "year"
+)
+
+// Repeat is a repeat interval like "every 2 weeks" or "every monday".
+type Repeat struct {
+	Amount int64
+	Unit   RepeatUnit
+	// Set for repeats on a weekday like "every monday", the unit is RepeatUnitWeek then.
+	Weekday *time.Weekday
+}
+
+// Duration returns the interval as duration. Months and years don't have a fixed length, so it is only meaningful
+// for hours, days and weeks.
+func (r *Repeat) Duration() time.Duration {
+	switch r.Unit {
+	case RepeatUnitHour:
+		return time.Duration(r.Amount) * time.Hour
+	case RepeatUnitDay:
+		return time.Duration(r.Amount) * 24 * time.Hour
+	case RepeatUnitWeek:
+		return time.Duration(r.Amount) * 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// firstDate returns the date of the first occurrence if the text did not contain a date.
+func (r *Repeat) firstDate(now time.Time) time.Time {
+	if r.Weekday != nil {
+		return dateAt(now, (int(*r.Weekday)-int(now.Weekday())+7)%7)
+	}
+	if r.Unit == RepeatUnitHour {
+		return now.Truncate(time.Minute)
+	}
+	return dateAt(now, 0)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+const weekdayPattern = `sunday|monday|tuesday|wednesday|thursday|friday|saturday`
+
+var repeatShorthands = map[string]RepeatUnit{
+	"hourly":   RepeatUnitHour,
+	"daily":    RepeatUnitDay,
+	"weekly":   RepeatUnitWeek,
+	"monthly":  RepeatUnitMonth,
+	"yearly":   RepeatUnitYear,
+	"annually": RepeatUnitYear,
+}
+
+var (
+	repeatIntervalRegex  = wordRegex(`every\s+(other\s+)?(?:(` + numberPattern + `)\s+)?(hour|day|week|month|year)s?`)
+	repeatWeekdayRegex   = wordRegex(`every\s+(` + weekdayPattern + `)`)
+	repeatShorthandRegex = wordRegex(`hourly|daily|weekly|monthly|yearly|annually`)
+)
+
+func extractRepeat(text string) (rest string, repeat *Repeat) {
+	if match := repeatIntervalRegex.FindStringSubmatchIndex(text); match != nil {
+		repeat = &Repeat{
+			Amount: 1,
+			Unit:   RepeatUnit(strings.ToLower(text[match[8]:match[9]])),
+		}
+		if match[6] != -1 {
+			repeat.Amount = parseNumber(text[match[6]:match[7]])
+		}
+		if match[4] != -1 {
+			repeat.Amount *= 2
+		}
+		if repeat.Amount < 1 {
+			return text, nil
+		}
+		return cut(text, match[2], match[3]), repeat
+	}
+
+	if match := repeatWeekdayRegex.FindStringSubmatchIndex(text); match != nil {
+		weekday := weekdays[strings.ToLower(text[match[4]:match[5]])]
+		return cut(text, match[2], match[3]), &Repeat{
+			Amount:  1,
+			Unit:    RepeatUnitWeek,
+			Weekday: &weekday,
+		}
+	}
+
+	if match := repeatShorthandRegex.FindStringSubmatchIndex(text); match != nil {
+		return cut(text, match[2], match[3]), &Repeat{
+			Amount: 1,
+			Unit:   repeatShorthands[strings.ToLower(text[match[2]:match[3]])],
+		}
+	}
+
+	return text, nil
+}
@@ -0,0 +1,117 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"xorm.io/xorm"
+)
+
+// DigestItem is a notification which was held back to be sent with the next digest mail instead.
+type DigestItem struct {
+	ID           int64  `xorm:"bigint autoincr not null unique pk" json:"id"`
+	NotifiableID int64  `xorm:"bigint not null INDEX" json:"-"`
+	Name         string `xorm:"varchar(250) not null" json:"name"`
+	// The subject the mail would have had.
+	Subject string `xorm:"text not null" json:"subject"`
+	// Where the action of the mail would have linked to.
+	URL string `xorm:"text null" json:"url"`
+
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for digest items
+func (*DigestItem) TableName() string {
+	return "notification_digest_items"
+}
+
+// NotifiableWithDigest is a notifiable which can get mails batched in a digest instead of one by one.
+type NotifiableWithDigest interface {
+	Notifiable
+	// ShouldDigest returns true if a notification which would be sent via mail should be held back
+	// for the next digest instead.
+	ShouldDigest(notification Notification) (should bool, err error)
+}
+
+var digestibleNotifications = map[string]bool{}
+
+// RegisterDigestibleNotification allows the mails of notifications with these names to be batched in digests.
+// Time critical notifications like reminders should not be registered.
+func RegisterDigestibleNotification(names ...string) {
+	notificationRegistryLock.Lock()
+	defer notificationRegistryLock.Unlock()
+	for _, name := range names {
+		digestibleNotifications[name] = true
+	}
+}
+
+// IsDigestibleNotification checks if the mails of a notification can be batched in digests.
+func IsDigestibleNotification(name string) bool {
+	notificationRegistryLock.RLock()
+	defer notificationRegistryLock.RUnlock()
+	return digestibleNotifications[name]
+}
+
+// addToDigest holds a mail back for the next digest if the notifiable wants that.
+func addToDigest(notifiable Notifiable, notification Notification, mail *Mail) (added bool, err error) {
+	n, is := notifiable.(NotifiableWithDigest)
+	if !is || !IsDigestibleNotification(notification.Name()) {
+		return false, nil
+	}
+
+	should, err := n.ShouldDigest(notification)
+	if err != nil || !should {
+		return false, err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err = s.Insert(&DigestItem{
+		NotifiableID: notifiable.RouteForDB(),
+		Name:         notification.Name(),
+		Subject:      mail.subject,
+		URL:          mail.actionURL,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetDigestItems returns all notifications which were held back for the next digest of a notifiable, oldest first.
+func GetDigestItems(s *xorm.Session, notifiableID int64) (items []*DigestItem, err error) {
+	items = []*DigestItem{}
+	err = s.
+		Where("notifiable_id = ?", notifiableID).
+		OrderBy("id ASC").
+		Find(&items)
+	return
+}
+
+// DeleteDigestItems removes all items of a notifiable up to and including the one with the given id,
+// usually after they were sent in a digest.
+func DeleteDigestItems(s *xorm.Session, notifiableID, upToID int64) (err error) {
+	_, err = s.
+		Where("notifiable_id = ? AND id <= ?", notifiableID, upToID).
+		Delete(&DigestItem{})
+	return
+}
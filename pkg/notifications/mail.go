@@ -28,6 +28,7 @@ import (
 type Mail struct {
 	from        string
 	to          string
+	replyTo     string
 	subject     string
 	actionText  string
 	actionURL   string
@@ -59,6 +60,12 @@ func (m *Mail) To(to string) *Mail {
 	return m
 }
 
+// ReplyTo sets the address replies to the mail message are sent to
+func (m *Mail) ReplyTo(replyTo string) *Mail {
+	m.replyTo = replyTo
+	return m
+}
+
 // Subject sets the subject of the mail message
 func (m *Mail) Subject(subject string) *Mail {
 	m.subject = subject
@@ -190,6 +190,7 @@ func RenderMail(m *Mail, lang string) (mailOpts *mail.Opts, err error) {
 	mailOpts = &mail.Opts{
 		From:        m.from,
 		To:          m.to,
+		ReplyTo:     m.replyTo,
 		Subject:     m.subject,
 		ContentType: mail.ContentTypeMultipart,
 		Message:     plainContent.String(),
@@ -86,6 +86,19 @@ func TestNewMail(t *testing.T) {
 		assert.Equal(t, "This should be an outro line", mail.introLines[2].Text)
 		assert.Equal(t, "And one more, because why not?", mail.introLines[3].Text)
 	})
+	t.Run("Reply to", func(t *testing.T) {
+		mail := NewMail().
+			To("test@otherdomain.com").
+			ReplyTo("reply@example.com").
+			Subject("Testmail").
+			Line("This is a line")
+
+		assert.Equal(t, "reply@example.com", mail.replyTo)
+
+		opts, err := RenderMail(mail, "en")
+		require.NoError(t, err)
+		assert.Equal(t, "reply@example.com", opts.ReplyTo)
+	})
 }
 
 func TestRenderMail(t *testing.T) {
@@ -20,10 +20,12 @@ import (
 	"encoding/json"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/log"
 )
 
 // Notification is a notification which can be sent via mail or db.
+// Notifications implementing NotificationWithPush are also sent to push channels.
 type Notification interface {
 	ToMail(lang string) *Mail
 	ToDB() interface{}
@@ -39,6 +41,13 @@ type NotificationWithSubject interface {
 	SubjectID
 }
 
+// NotificationWithReplyTo is a notification whose mails can be answered.
+// ReplyTo returns the address replies of the notifiable should go to or an empty string.
+type NotificationWithReplyTo interface {
+	Notification
+	ReplyTo(notifiable Notifiable) string
+}
+
 // Notifiable is an entity which can be notified. Usually a user.
 type Notifiable interface {
 	// RouteForMail should return the email address this notifiable has.
@@ -46,12 +55,18 @@ type Notifiable interface {
 	// RouteForDB should return the id of the notifiable entity to save it in the database.
 	RouteForDB() int64
 	// ShouldNotify provides a last-minute way to cancel a notification. It will be called immediately before
-	// sending a notification.
-	ShouldNotify() (should bool, err error)
+	// sending a notification through each channel.
+	ShouldNotify(notification Notification, channel Channel) (should bool, err error)
 	// Lang provides the language which should be used for translations in the mail.
 	Lang() string
 }
 
+var channelSenders = map[Channel]func(notifiable Notifiable, notification Notification) error{
+	ChannelMail:  notifyMail,
+	ChannelInApp: notifyDB,
+	ChannelPush:  notifyAllPush,
+}
+
 // Notify notifies a notifiable of a notification
 func Notify(notifiable Notifiable, notification Notification) (err error) {
 	if isUnderTest {
@@ -59,18 +74,33 @@ func Notify(notifiable Notifiable, notification Notification) (err error) {
 		return nil
 	}
 
-	should, err := notifiable.ShouldNotify()
-	if err != nil || !should {
-		log.Debugf("Not notifying user %d because they are disabled", notifiable.RouteForDB())
-		return err
+	for _, channel := range Channels {
+		var should bool
+		should, err = notifiable.ShouldNotify(notification, channel)
+		if err != nil {
+			return err
+		}
+		if !should {
+			log.Debugf("Not notifying notifiable %d of %s via %s because they disabled it", notifiable.RouteForDB(), notification.Name(), channel)
+			continue
+		}
+
+		err = channelSenders[channel](notifiable, notification)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = notifyMail(notifiable, notification)
+	return nil
+}
+
+func notifyAllPush(notifiable Notifiable, notification Notification) error {
+	err := notifyPush(notifiable, notification)
 	if err != nil {
-		return
+		return err
 	}
 
-	return notifyDB(notifiable, notification)
+	return notifyWebPush(notifiable, notification)
 }
 
 func notifyMail(notifiable Notifiable, notification Notification) error {
@@ -79,6 +109,19 @@ func notifyMail(notifiable Notifiable, notification Notification) error {
 		return nil
 	}
 
+	added, err := addToDigest(notifiable, notification, mail)
+	if err != nil || added {
+		return err
+	}
+
+	if n, is := notification.(NotificationWithReplyTo); is {
+		if replyTo := n.ReplyTo(notifiable); replyTo != "" {
+			mail.
+				ReplyTo(replyTo).
+				FooterLine(i18n.T(notifiable.Lang(), "notifications.common.reply_to_comment"))
+		}
+	}
+
 	to, err := notifiable.RouteForMail()
 	if err != nil {
 		return err
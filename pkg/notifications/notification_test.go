@@ -53,6 +53,7 @@ func (n *testNotification) Name() string {
 type testNotifiable struct {
 	ShouldSendNotification bool
 	Language               string
+	DisabledChannels       []Channel
 }
 
 // RouteForMail routes a test notification for mail
@@ -65,7 +66,12 @@ func (t *testNotifiable) RouteForDB() int64 {
 	return 42
 }
 
-func (t *testNotifiable) ShouldNotify() (should bool, err error) {
+func (t *testNotifiable) ShouldNotify(_ Notification, channel Channel) (should bool, err error) {
+	for _, disabled := range t.DisabledChannels {
+		if disabled == channel {
+			return false, nil
+		}
+	}
 	return t.ShouldSendNotification, nil
 }
 
@@ -73,6 +79,14 @@ func (t *testNotifiable) Lang() string {
 	return t.Language
 }
 
+type testDigestNotifiable struct {
+	testNotifiable
+}
+
+func (t *testDigestNotifiable) ShouldDigest(_ Notification) (bool, error) {
+	return true, nil
+}
+
 func TestNotify(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 
@@ -125,6 +139,63 @@ func TestNotify(t *testing.T) {
 			Language:               "en",
 		}
 
+		err = Notify(tnf, tn)
+		require.NoError(t, err)
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"notifiable_id": 42,
+		})
+	})
+	t.Run("digest", func(t *testing.T) {
+		RegisterDigestibleNotification("test.notification")
+
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from notification_digest_items")
+		require.NoError(t, err)
+
+		tn := &testNotification{
+			Test:       "somethingsomething",
+			OtherValue: 42,
+		}
+		tnf := &testDigestNotifiable{testNotifiable: testNotifiable{
+			ShouldSendNotification: true,
+			Language:               "en",
+		}}
+
+		err = Notify(tnf, tn)
+		require.NoError(t, err)
+		db.AssertExists(t, "notification_digest_items", map[string]interface{}{
+			"notifiable_id": 42,
+			"name":          "test.notification",
+			"subject":       "Test Notification",
+		}, false)
+
+		items, err := GetDigestItems(s, 42)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		err = DeleteDigestItems(s, 42, items[0].ID)
+		require.NoError(t, err)
+		db.AssertMissing(t, "notification_digest_items", map[string]interface{}{
+			"notifiable_id": 42,
+		})
+	})
+	t.Run("disabled channel", func(t *testing.T) {
+
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from notifications")
+		require.NoError(t, err)
+
+		tn := &testNotification{
+			Test:       "somethingsomething",
+			OtherValue: 42,
+		}
+		tnf := &testNotifiable{
+			ShouldSendNotification: true,
+			Language:               "en",
+			DisabledChannels:       []Channel{ChannelInApp},
+		}
+
 		err = Notify(tnf, tn)
 		require.NoError(t, err)
 		db.AssertMissing(t, "notifications", map[string]interface{}{
@@ -0,0 +1,177 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Channel is a way a notification is delivered to a notifiable.
+type Channel string
+
+const (
+	ChannelMail  Channel = "mail"
+	ChannelInApp Channel = "in_app"
+	// ChannelPush covers both the push channels configured by a user and web push.
+	ChannelPush Channel = "push"
+)
+
+// Channels contains all channels in the order notifications are sent through them.
+var Channels = []Channel{ChannelMail, ChannelInApp, ChannelPush}
+
+// Preferences holds over which channels notifications should be sent, by notification name.
+// A missing entry means the default applies.
+type Preferences map[string]map[Channel]bool
+
+var (
+	configurableNotifications = map[string]bool{}
+	notificationRegistryLock  sync.RWMutex
+)
+
+// RegisterConfigurableNotification allows users to choose over which channels the notifications
+// with these names are sent. All other notifications, like password resets, are always sent.
+func RegisterConfigurableNotification(names ...string) {
+	notificationRegistryLock.Lock()
+	defer notificationRegistryLock.Unlock()
+	for _, name := range names {
+		configurableNotifications[name] = true
+	}
+}
+
+// IsConfigurableNotification checks if users can choose the channels of a notification.
+func IsConfigurableNotification(name string) bool {
+	notificationRegistryLock.RLock()
+	defer notificationRegistryLock.RUnlock()
+	return configurableNotifications[name]
+}
+
+// GetConfigurableNotifications returns the sorted names of all configurable notifications.
+func GetConfigurableNotifications() (names []string) {
+	notificationRegistryLock.RLock()
+	defer notificationRegistryLock.RUnlock()
+	names = make([]string, 0, len(configurableNotifications))
+	for name := range configurableNotifications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Get returns if a notification should be sent through a channel and whether that was set at all.
+func (p Preferences) Get(name string, channel Channel) (enabled bool, has bool) {
+	channels, has := p[name]
+	if !has {
+		return false, false
+	}
+	enabled, has = channels[channel]
+	return
+}
+
+// Validate checks that the preferences only contain configurable notifications and known channels.
+func (p Preferences) Validate() error {
+	for name, channels := range p {
+		if !IsConfigurableNotification(name) {
+			return fmt.Errorf("notification %q is unknown or cannot be configured", name)
+		}
+		for channel := range channels {
+			if !isKnownChannel(channel) {
+				return fmt.Errorf("channel %q is unknown", channel)
+			}
+		}
+	}
+	return nil
+}
+
+// Normalize returns the preferences with notification names restored which lost their dots on the
+// way through a client. The web frontend converts all keys to camelCase and back to snake_case, which
+// turns `task.comment.reply` into `task_comment_reply`. Names which match no configurable notification
+// are kept as they are so Validate can reject them.
+func (p Preferences) Normalize() Preferences {
+	if p == nil {
+		return nil
+	}
+
+	names := make(map[string]string)
+	for _, name := range GetConfigurableNotifications() {
+		names[strings.ReplaceAll(name, ".", "_")] = name
+	}
+
+	normalized := make(Preferences, len(p))
+	for name, channels := range p {
+		if !IsConfigurableNotification(name) {
+			if original, has := names[name]; has {
+				name = original
+			}
+		}
+		if normalized[name] == nil {
+			normalized[name] = make(map[Channel]bool, len(channels))
+		}
+		for channel, enabled := range channels {
+			normalized[name][channel] = enabled
+		}
+	}
+	return normalized
+}
+
+// ParsePreferences converts preferences read from the config to Preferences. Because the config
+// treats dots as separators, a notification name like `task.comment` arrives as nested maps,
+// which are joined back together until a level with channel names is found.
+func ParsePreferences(raw map[string]interface{}) Preferences {
+	prefs := Preferences{}
+	parsePreferencesLevel(prefs, "", raw)
+	return prefs
+}
+
+func parsePreferencesLevel(prefs Preferences, prefix string, raw map[string]interface{}) {
+	for key, value := range raw {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if enabled, is := value.(bool); is && isKnownChannel(Channel(key)) && prefix != "" {
+			if prefs[prefix] == nil {
+				prefs[prefix] = map[Channel]bool{}
+			}
+			prefs[prefix][Channel(key)] = enabled
+			continue
+		}
+
+		switch nested := value.(type) {
+		case map[string]interface{}:
+			parsePreferencesLevel(prefs, name, nested)
+		case map[interface{}]interface{}:
+			converted := make(map[string]interface{}, len(nested))
+			for k, v := range nested {
+				converted[fmt.Sprint(k)] = v
+			}
+			parsePreferencesLevel(prefs, name, converted)
+		}
+	}
+}
+
+func isKnownChannel(channel Channel) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
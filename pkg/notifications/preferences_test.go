@@ -0,0 +1,80 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreferences(t *testing.T) {
+	// This is how the config returns `task.comment: {mail: false}` and `project.created: {push: true}`
+	raw := map[string]interface{}{
+		"task": map[string]interface{}{
+			"comment": map[string]interface{}{
+				"mail": false,
+			},
+		},
+		"project": map[interface{}]interface{}{
+			"created": map[interface{}]interface{}{
+				"push":    true,
+				"unknown": false,
+			},
+		},
+	}
+
+	prefs := ParsePreferences(raw)
+	assert.Equal(t, Preferences{
+		"task.comment":    {ChannelMail: false},
+		"project.created": {ChannelPush: true},
+	}, prefs)
+
+	enabled, has := prefs.Get("task.comment", ChannelMail)
+	assert.True(t, has)
+	assert.False(t, enabled)
+	_, has = prefs.Get("task.comment", ChannelPush)
+	assert.False(t, has)
+}
+
+func TestPreferences_Validate(t *testing.T) {
+	RegisterConfigurableNotification("test.notification")
+
+	require.NoError(t, Preferences{"test.notification": {ChannelMail: false, ChannelInApp: true}}.Validate())
+	require.Error(t, Preferences{"password.reset": {ChannelMail: false}}.Validate())
+	require.Error(t, Preferences{"test.notification": {"pigeon": false}}.Validate())
+}
+
+func TestPreferences_Normalize(t *testing.T) {
+	RegisterConfigurableNotification("test.notification.reply")
+
+	// This is how the frontend sends back the keys after converting them to camelCase and snake_case
+	prefs := Preferences{
+		"test_notification_reply": {ChannelMail: false, ChannelInApp: true},
+		"unknown_notification":    {ChannelMail: false},
+	}.Normalize()
+	assert.Equal(t, Preferences{
+		"test.notification.reply": {ChannelMail: false, ChannelInApp: true},
+		"unknown_notification":    {ChannelMail: false},
+	}, prefs)
+	require.Error(t, prefs.Validate())
+
+	prefs = Preferences{"test.notification.reply": {ChannelPush: true}}.Normalize()
+	assert.Equal(t, Preferences{"test.notification.reply": {ChannelPush: true}}, prefs)
+	require.NoError(t, prefs.Validate())
+}
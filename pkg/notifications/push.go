@@ -0,0 +1,253 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/version"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// PushMessage is the short form of a notification which is sent to push channels.
+type PushMessage struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	// A link which should be opened when the push notification is clicked.
+	URL string `json:"url,omitempty"`
+	// The priority of the message, from 1 (lowest) to 5 (highest). 0 means the service default.
+	Priority int `json:"priority,omitempty"`
+}
+
+// NotificationWithPush is a notification which can also be sent to push channels.
+type NotificationWithPush interface {
+	Notification
+	ToPush(lang string) *PushMessage
+}
+
+// NotifiableWithPush is a notifiable which can have push channels configured.
+type NotifiableWithPush interface {
+	Notifiable
+	// RouteForPush should return all push channels this notifiable wants to be notified on.
+	RouteForPush() ([]*PushChannel, error)
+}
+
+// PushChannel is a destination for push notifications configured by a user.
+type PushChannel struct {
+	// The kind of push service. Built-in types are `ntfy`, `gotify` and `http`.
+	Type string `json:"type"`
+	// The url of the push service. For ntfy and gotify this is the base url of the server,
+	// for http the url the notification is posted to.
+	URL string `json:"url"`
+	// The ntfy topic. Ignored for other types.
+	Topic string `json:"topic,omitempty"`
+	// The ntfy access token, the gotify application token or a bearer token sent with http push requests.
+	Token string `json:"token,omitempty"`
+	// Whether notifications should be sent to this channel.
+	Enabled bool `json:"enabled"`
+}
+
+// PushProvider sends push messages to one kind of push service.
+type PushProvider interface {
+	// Validate checks if the channel config is usable with this provider.
+	Validate(channel *PushChannel) error
+	// Send sends the message of notification to the channel.
+	Send(channel *PushChannel, notification Notification, message *PushMessage) error
+}
+
+var (
+	pushProviders     = map[string]PushProvider{}
+	pushProvidersLock sync.RWMutex
+
+	pushClient     *http.Client
+	pushClientOnce sync.Once
+)
+
+// RegisterPushProvider makes a push provider available as channel type.
+func RegisterPushProvider(kind string, provider PushProvider) {
+	pushProvidersLock.Lock()
+	defer pushProvidersLock.Unlock()
+	pushProviders[kind] = provider
+}
+
+func getPushProvider(kind string) (provider PushProvider, has bool) {
+	pushProvidersLock.RLock()
+	defer pushProvidersLock.RUnlock()
+	provider, has = pushProviders[kind]
+	return
+}
+
+// ValidatePushChannel checks if a push channel has a known type and a valid config for it.
+func ValidatePushChannel(channel *PushChannel) error {
+	provider, has := getPushProvider(channel.Type)
+	if !has {
+		return fmt.Errorf("unknown push channel type %q", channel.Type)
+	}
+
+	return provider.Validate(channel)
+}
+
+func notifyPush(notifiable Notifiable, notification Notification) error {
+	if !config.PushEnabled.GetBool() {
+		return nil
+	}
+
+	n, is := notification.(NotificationWithPush)
+	if !is {
+		return nil
+	}
+
+	target, is := notifiable.(NotifiableWithPush)
+	if !is {
+		return nil
+	}
+
+	message := n.ToPush(notifiable.Lang())
+	if message == nil {
+		return nil
+	}
+
+	channels, err := target.RouteForPush()
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		if !channel.Enabled {
+			continue
+		}
+
+		provider, has := getPushProvider(channel.Type)
+		if !has {
+			log.Warningf("Unknown push channel type %s for notifiable %d, skipping", channel.Type, notifiable.RouteForDB())
+			continue
+		}
+
+		// A broken channel should neither keep the other channels from being notified
+		// nor fail the whole notification, so we only log the error.
+		err = provider.Send(channel, notification, message)
+		if err != nil {
+			log.Errorf("Could not send %s notification to %s push channel of notifiable %d: %s", notification.Name(), channel.Type, notifiable.RouteForDB(), err)
+			continue
+		}
+
+		log.Debugf("Sent %s notification to %s push channel of notifiable %d", notification.Name(), channel.Type, notifiable.RouteForDB())
+	}
+
+	return nil
+}
+
+func getPushHTTPClient() *http.Client {
+	pushClientOnce.Do(func() {
+		pushClient = &http.Client{
+			Timeout: time.Duration(config.PushTimeoutSeconds.GetInt()) * time.Second,
+		}
+
+		// Push channel urls are provided by users, so we route them through the same
+		// egress proxy as webhooks if one is configured.
+		if config.WebhooksProxyURL.GetString() == "" || config.WebhooksProxyPassword.GetString() == "" {
+			return
+		}
+
+		proxyURL, _ := url.Parse(config.WebhooksProxyURL.GetString())
+		pushClient.Transport = &http.Transport{
+			Proxy: http.ProxyURL(proxyURL),
+			ProxyConnectHeader: http.Header{
+				"Proxy-Authorization": []string{"Basic " + base64.StdEncoding.EncodeToString([]byte("vikunja:"+config.WebhooksProxyPassword.GetString()))},
+				"User-Agent":          []string{"Vikunja/" + version.Version},
+			},
+		}
+	})
+
+	return pushClient
+}
+
+func postPushJSON(target string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("User-Agent", "Vikunja/"+version.Version)
+	req.Header.Add("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Add(key, value)
+	}
+
+	res, err := getPushHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 399 {
+		responseBody, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("push service responded with status %d: %s", res.StatusCode, responseBody)
+	}
+
+	return nil
+}
+
+func validatePushURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url must not be empty")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https url")
+	}
+
+	return nil
+}
+
+// PushText converts html content like task descriptions or comments to plain text short enough
+// to be shown in a push notification.
+func PushText(content string, maxLength int) string {
+	text := html.UnescapeString(bluemonday.StrictPolicy().Sanitize(content))
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if maxLength > 0 && len(runes) > maxLength {
+		return strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+	}
+
+	return text
+}
@@ -0,0 +1,127 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	PushChannelTypeNtfy   = "ntfy"
+	PushChannelTypeGotify = "gotify"
+	PushChannelTypeHTTP   = "http"
+)
+
+func init() {
+	RegisterPushProvider(PushChannelTypeNtfy, &ntfyProvider{})
+	RegisterPushProvider(PushChannelTypeGotify, &gotifyProvider{})
+	RegisterPushProvider(PushChannelTypeHTTP, &httpPushProvider{})
+}
+
+// ntfyProvider publishes messages to a topic on an ntfy server using its json api.
+// See https://docs.ntfy.sh/publish/#publish-as-json
+type ntfyProvider struct{}
+
+func (p *ntfyProvider) Validate(channel *PushChannel) error {
+	if channel.Topic == "" {
+		return fmt.Errorf("ntfy channels need a topic")
+	}
+	return validatePushURL(channel.URL)
+}
+
+func (p *ntfyProvider) Send(channel *PushChannel, _ Notification, message *PushMessage) error {
+	payload := map[string]interface{}{
+		"topic":   channel.Topic,
+		"title":   message.Title,
+		"message": message.Message,
+	}
+	if message.URL != "" {
+		payload["click"] = message.URL
+	}
+	if message.Priority > 0 {
+		payload["priority"] = message.Priority
+	}
+
+	headers := map[string]string{}
+	if channel.Token != "" {
+		headers["Authorization"] = "Bearer " + channel.Token
+	}
+
+	return postPushJSON(strings.TrimSuffix(channel.URL, "/"), headers, payload)
+}
+
+// gotifyProvider sends messages to a gotify server with an application token.
+// See https://gotify.net/api-docs#/message/createMessage
+type gotifyProvider struct{}
+
+func (p *gotifyProvider) Validate(channel *PushChannel) error {
+	if channel.Token == "" {
+		return fmt.Errorf("gotify channels need an application token")
+	}
+	return validatePushURL(channel.URL)
+}
+
+func (p *gotifyProvider) Send(channel *PushChannel, _ Notification, message *PushMessage) error {
+	payload := map[string]interface{}{
+		"title":   message.Title,
+		"message": message.Message,
+	}
+	// Gotify priorities range from 0 to 10
+	if message.Priority > 0 {
+		payload["priority"] = message.Priority * 2
+	}
+	if message.URL != "" {
+		payload["extras"] = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": message.URL},
+			},
+		}
+	}
+
+	return postPushJSON(strings.TrimSuffix(channel.URL, "/")+"/message", map[string]string{
+		"X-Gotify-Key": channel.Token,
+	}, payload)
+}
+
+// httpPushPayload is what gets posted to generic http push channels.
+type httpPushPayload struct {
+	EventName string `json:"event_name"`
+	*PushMessage
+	// The full notification as it is stored in the database.
+	Data interface{} `json:"data"`
+}
+
+// httpPushProvider posts the message and the notification content as json to an arbitrary url.
+type httpPushProvider struct{}
+
+func (p *httpPushProvider) Validate(channel *PushChannel) error {
+	return validatePushURL(channel.URL)
+}
+
+func (p *httpPushProvider) Send(channel *PushChannel, notification Notification, message *PushMessage) error {
+	headers := map[string]string{}
+	if channel.Token != "" {
+		headers["Authorization"] = "Bearer " + channel.Token
+	}
+
+	return postPushJSON(channel.URL, headers, &httpPushPayload{
+		EventName:   notification.Name(),
+		PushMessage: message,
+		Data:        notification.ToDB(),
+	})
+}
@@ -0,0 +1,163 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedPush struct {
+	Path    string
+	Headers http.Header
+	Body    map[string]interface{}
+}
+
+// newPushServer starts a local stand-in for a push service which records all requests it gets.
+func newPushServer(t *testing.T, status int) (server *httptest.Server, received *[]*receivedPush) {
+	received = &[]*receivedPush{}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.NoError(t, err)
+		*received = append(*received, &receivedPush{
+			Path:    r.URL.Path,
+			Headers: r.Header,
+			Body:    body,
+		})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return
+}
+
+// ToPush returns the push message for testNotification
+func (n *testNotification) ToPush(_ string) *PushMessage {
+	return &PushMessage{
+		Title:    "Test Notification",
+		Message:  n.Test,
+		URL:      "https://vikunja.example/tasks/1",
+		Priority: 4,
+	}
+}
+
+type testPushNotifiable struct {
+	testNotifiable
+	Channels []*PushChannel
+}
+
+func (t *testPushNotifiable) RouteForPush() ([]*PushChannel, error) {
+	return t.Channels, nil
+}
+
+func TestNotifyPush(t *testing.T) {
+	notification := &testNotification{Test: "Lorem Ipsum", OtherValue: 42}
+
+	t.Run("ntfy", func(t *testing.T) {
+		server, received := newPushServer(t, http.StatusOK)
+		err := notifyPush(&testPushNotifiable{Channels: []*PushChannel{
+			{Type: PushChannelTypeNtfy, URL: server.URL + "/", Topic: "vikunja", Token: "tk_secret", Enabled: true},
+		}}, notification)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		push := (*received)[0]
+		assert.Equal(t, "/", push.Path)
+		assert.Equal(t, "Bearer tk_secret", push.Headers.Get("Authorization"))
+		assert.Equal(t, "vikunja", push.Body["topic"])
+		assert.Equal(t, "Test Notification", push.Body["title"])
+		assert.Equal(t, "Lorem Ipsum", push.Body["message"])
+		assert.Equal(t, "https://vikunja.example/tasks/1", push.Body["click"])
+		assert.InDelta(t, 4, push.Body["priority"], 0)
+	})
+	t.Run("gotify", func(t *testing.T) {
+		server, received := newPushServer(t, http.StatusOK)
+		err := notifyPush(&testPushNotifiable{Channels: []*PushChannel{
+			{Type: PushChannelTypeGotify, URL: server.URL, Token: "app-token", Enabled: true},
+		}}, notification)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		push := (*received)[0]
+		assert.Equal(t, "/message", push.Path)
+		assert.Equal(t, "app-token", push.Headers.Get("X-Gotify-Key"))
+		assert.Equal(t, "Lorem Ipsum", push.Body["message"])
+		assert.InDelta(t, 8, push.Body["priority"], 0)
+		assert.Contains(t, push.Body, "extras")
+	})
+	t.Run("http", func(t *testing.T) {
+		server, received := newPushServer(t, http.StatusOK)
+		err := notifyPush(&testPushNotifiable{Channels: []*PushChannel{
+			{Type: PushChannelTypeHTTP, URL: server.URL + "/hook", Enabled: true},
+		}}, notification)
+		require.NoError(t, err)
+
+		require.Len(t, *received, 1)
+		push := (*received)[0]
+		assert.Equal(t, "/hook", push.Path)
+		assert.Empty(t, push.Headers.Get("Authorization"))
+		assert.Equal(t, "test.notification", push.Body["event_name"])
+		assert.Equal(t, "Lorem Ipsum", push.Body["message"])
+		assert.Equal(t, map[string]interface{}{"test": "Lorem Ipsum", "other_value": float64(42)}, push.Body["data"])
+	})
+	t.Run("skips disabled channels", func(t *testing.T) {
+		server, received := newPushServer(t, http.StatusOK)
+		err := notifyPush(&testPushNotifiable{Channels: []*PushChannel{
+			{Type: PushChannelTypeHTTP, URL: server.URL, Enabled: false},
+		}}, notification)
+		require.NoError(t, err)
+		assert.Empty(t, *received)
+	})
+	t.Run("failing channel does not stop others", func(t *testing.T) {
+		failing, failed := newPushServer(t, http.StatusInternalServerError)
+		working, received := newPushServer(t, http.StatusOK)
+		err := notifyPush(&testPushNotifiable{Channels: []*PushChannel{
+			{Type: PushChannelTypeHTTP, URL: failing.URL, Enabled: true},
+			{Type: "unknown", URL: working.URL, Enabled: true},
+			{Type: PushChannelTypeHTTP, URL: working.URL, Enabled: true},
+		}}, notification)
+		require.NoError(t, err)
+		assert.Len(t, *failed, 1)
+		assert.Len(t, *received, 1)
+	})
+	t.Run("notifiable without push", func(t *testing.T) {
+		err := notifyPush(&testNotifiable{}, notification)
+		require.NoError(t, err)
+	})
+}
+
+func TestValidatePushChannel(t *testing.T) {
+	require.NoError(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeNtfy, URL: "https://ntfy.sh", Topic: "tasks"}))
+	require.NoError(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeGotify, URL: "https://gotify.example", Token: "token"}))
+	require.NoError(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeHTTP, URL: "http://localhost:8080/push"}))
+
+	require.Error(t, ValidatePushChannel(&PushChannel{Type: "pigeon", URL: "https://example.com"}))
+	require.Error(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeNtfy, URL: "https://ntfy.sh"}))
+	require.Error(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeGotify, URL: "https://gotify.example"}))
+	require.Error(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeHTTP, URL: "ftp://example.com"}))
+	require.Error(t, ValidatePushChannel(&PushChannel{Type: PushChannelTypeHTTP}))
+}
+
+func TestPushText(t *testing.T) {
+	assert.Equal(t, "Hello & welcome to the task", PushText("<p>Hello &amp; <b>welcome</b></p>\n<p>to the task</p>", 0))
+	assert.Equal(t, "Lorem…", PushText("<p>Lorem ipsum</p>", 6))
+}
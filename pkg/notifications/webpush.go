@@ -0,0 +1,274 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/version"
+
+	"xorm.io/xorm"
+)
+
+const (
+	// Push services only accept messages up to 4096 bytes. This leaves room for
+	// the encryption header, the auth tag and the padding delimiter.
+	webPushMaxPayloadSize = 4096 - webPushHeaderSize - 16 - 1
+	webPushTTL            = 24 * time.Hour
+)
+
+// WebPushSubscription is a browser push subscription of one device of a user.
+type WebPushSubscription struct {
+	// The unique, numeric id of this subscription.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"subscription"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The push service endpoint as returned by the browser.
+	Endpoint string `xorm:"text not null" json:"endpoint" valid:"required"`
+	// The keys of the subscription as returned by the browser.
+	Keys *WebPushSubscriptionKeys `xorm:"json not null" json:"keys" valid:"required"`
+
+	// A timestamp when this subscription was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// WebPushSubscriptionKeys holds the client keys used to encrypt messages for a subscription.
+type WebPushSubscriptionKeys struct {
+	// The base64url encoded P-256 public key of the browser.
+	P256dh string `json:"p256dh"`
+	// The base64url encoded authentication secret of the browser.
+	Auth string `json:"auth"`
+}
+
+// TableName returns the table name for web push subscriptions
+func (*WebPushSubscription) TableName() string {
+	return "web_push_subscriptions"
+}
+
+// webPushPayload is the json message the service worker of the frontend receives.
+type webPushPayload struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+	URL     string `json:"url,omitempty"`
+	// The content of the notification as it is saved in the database. Omitted if the
+	// message would otherwise be too large for push services.
+	Notification json.RawMessage `json:"notification,omitempty"`
+}
+
+// ValidateWebPushSubscription checks if a subscription has a usable endpoint and keys.
+func ValidateWebPushSubscription(sub *WebPushSubscription) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("endpoint must be an https url")
+	}
+
+	if sub.Keys == nil {
+		return fmt.Errorf("keys are required")
+	}
+
+	p256dh, err := decodeWebPushKey(sub.Keys.P256dh)
+	if err != nil || len(p256dh) != 65 {
+		return fmt.Errorf("p256dh must be an uncompressed P-256 public key")
+	}
+
+	auth, err := decodeWebPushKey(sub.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("auth must be a 16 byte secret")
+	}
+
+	return nil
+}
+
+// AddWebPushSubscription saves a subscription for a user. The endpoint identifies the device,
+// so an existing subscription with the same endpoint is replaced, even if it belongs to another
+// user. This makes sure a device only gets notifications of the user who subscribed it last.
+func AddWebPushSubscription(s *xorm.Session, userID int64, sub *WebPushSubscription) (err error) {
+	err = ValidateWebPushSubscription(sub)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("endpoint = ?", sub.Endpoint).
+		Delete(&WebPushSubscription{})
+	if err != nil {
+		return err
+	}
+
+	sub.ID = 0
+	sub.UserID = userID
+	_, err = s.Insert(sub)
+	return
+}
+
+// GetWebPushSubscriptions returns all push subscriptions of a user.
+func GetWebPushSubscriptions(s *xorm.Session, userID int64) (subs []*WebPushSubscription, err error) {
+	subs = []*WebPushSubscription{}
+	err = s.
+		Where("user_id = ?", userID).
+		OrderBy("id ASC").
+		Find(&subs)
+	return
+}
+
+// DeleteWebPushSubscription removes a push subscription of a user. It returns false if the user
+// has no subscription with that id.
+func DeleteWebPushSubscription(s *xorm.Session, userID, id int64) (deleted bool, err error) {
+	count, err := s.
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&WebPushSubscription{})
+	return count > 0, err
+}
+
+func notifyWebPush(notifiable Notifiable, notification Notification) (err error) {
+	if !isWebPushEnabled() {
+		return nil
+	}
+
+	dbContent := notification.ToDB()
+	if dbContent == nil {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	subs, err := GetWebPushSubscriptions(s, notifiable.RouteForDB())
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	payload, err := getWebPushPayload(notifiable, notification, dbContent)
+	if err != nil {
+		return err
+	}
+
+	urgency := "normal"
+	if n, is := notification.(NotificationWithPush); is {
+		if message := n.ToPush(notifiable.Lang()); message != nil && message.Priority >= 4 {
+			urgency = "high"
+		}
+	}
+
+	for _, sub := range subs {
+		status, err := sendWebPush(sub, payload, urgency)
+		if err != nil {
+			log.Errorf("Could not send %s notification to web push subscription %d: %s", notification.Name(), sub.ID, err)
+			continue
+		}
+
+		// The subscription expired or the user revoked the permission
+		if status == http.StatusNotFound || status == http.StatusGone {
+			log.Debugf("Web push subscription %d is gone, removing it", sub.ID)
+			_, err = s.ID(sub.ID).Delete(&WebPushSubscription{})
+			if err != nil {
+				log.Errorf("Could not remove web push subscription %d: %s", sub.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getWebPushPayload(notifiable Notifiable, notification Notification, dbContent interface{}) ([]byte, error) {
+	content, err := json.Marshal(dbContent)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &webPushPayload{
+		Name:         notification.Name(),
+		Notification: content,
+	}
+
+	if n, is := notification.(NotificationWithPush); is {
+		if message := n.ToPush(notifiable.Lang()); message != nil {
+			payload.Title = message.Title
+			payload.Message = message.Message
+			payload.URL = message.URL
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil || len(body) <= webPushMaxPayloadSize {
+		return body, err
+	}
+
+	// The frontend has to fetch the full notification from the api.
+	payload.Notification = nil
+	body, err = json.Marshal(payload)
+	if err != nil || len(body) <= webPushMaxPayloadSize {
+		return body, err
+	}
+
+	payload.Message = PushText(payload.Message, 500)
+	return json.Marshal(payload)
+}
+
+func sendWebPush(sub *WebPushSubscription, payload []byte, urgency string) (status int, err error) {
+	body, err := encryptWebPushPayload(sub.Keys, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	authorization, err := getVapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Add("User-Agent", "Vikunja/"+version.Version)
+	req.Header.Add("Content-Type", "application/octet-stream")
+	req.Header.Add("Content-Encoding", "aes128gcm")
+	req.Header.Add("TTL", strconv.Itoa(int(webPushTTL.Seconds())))
+	req.Header.Add("Urgency", urgency)
+	req.Header.Add("Authorization", authorization)
+
+	res, err := getPushHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 399 && res.StatusCode != http.StatusNotFound && res.StatusCode != http.StatusGone {
+		responseBody, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return res.StatusCode, fmt.Errorf("push service responded with status %d: %s", res.StatusCode, responseBody)
+	}
+
+	return res.StatusCode, nil
+}
+
+func decodeWebPushKey(key string) ([]byte, error) {
+	// Browsers use unpadded base64url, but some libraries add padding
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
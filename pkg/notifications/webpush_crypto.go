@@ -0,0 +1,252 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// salt (16) + record size (4) + key id length (1) + key id (65)
+	webPushHeaderSize = 16 + 4 + 1 + 65
+	webPushRecordSize = 4096
+)
+
+var (
+	vapidKey     *ecdsa.PrivateKey
+	vapidKeyLock sync.RWMutex
+)
+
+// GenerateVapidKeys generates a new VAPID key pair. Both keys are returned base64url encoded,
+// the public key as uncompressed P-256 point and the private key as raw scalar.
+func GenerateVapidKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		nil
+}
+
+// InitWebPush loads the VAPID keys from the config. If none are configured, a temporary
+// key pair is generated which means all subscriptions stop working after a restart.
+func InitWebPush() {
+	if !config.WebPushEnabled.GetBool() {
+		return
+	}
+
+	if config.WebPushVapidPrivateKey.GetString() == "" {
+		publicKey, privateKey, err := GenerateVapidKeys()
+		if err != nil {
+			log.Errorf("Could not generate VAPID keys, web push is disabled: %s", err)
+			return
+		}
+
+		config.WebPushVapidPublicKey.Set(publicKey)
+		config.WebPushVapidPrivateKey.Set(privateKey)
+		log.Warning("No VAPID keys configured, using a temporary key pair for web push. Browser subscriptions will stop working after a restart. Run `vikunja webpush generate-keys` and add the keys to your config to prevent that.")
+	}
+
+	key, err := parseVapidPrivateKey(config.WebPushVapidPrivateKey.GetString())
+	if err != nil {
+		log.Errorf("Invalid VAPID private key, web push is disabled: %s", err)
+		return
+	}
+
+	vapidKeyLock.Lock()
+	vapidKey = key
+	vapidKeyLock.Unlock()
+
+	// Always derive the public key from the private key so both can't get out of sync
+	config.WebPushVapidPublicKey.Set(encodeVapidPublicKey(key))
+}
+
+// GetVapidPublicKey returns the public key clients need to subscribe to web push notifications.
+// It is empty if web push is disabled.
+func GetVapidPublicKey() string {
+	if !isWebPushEnabled() {
+		return ""
+	}
+	return config.WebPushVapidPublicKey.GetString()
+}
+
+func isWebPushEnabled() bool {
+	vapidKeyLock.RLock()
+	defer vapidKeyLock.RUnlock()
+	return config.WebPushEnabled.GetBool() && vapidKey != nil
+}
+
+func parseVapidPrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeWebPushKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	public := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:65]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+func encodeVapidPublicKey(key *ecdsa.PrivateKey) string {
+	public, _ := key.PublicKey.ECDH()
+	return base64.RawURLEncoding.EncodeToString(public.Bytes())
+}
+
+// getVapidAuthorization returns the Authorization header value for a push service as described in RFC 8292.
+func getVapidAuthorization(endpoint string) (string, error) {
+	vapidKeyLock.RLock()
+	key := vapidKey
+	vapidKeyLock.RUnlock()
+	if key == nil {
+		return "", fmt.Errorf("web push is not initialized")
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	subject := config.WebPushSubject.GetString()
+	if subject == "" {
+		subject = "mailto:" + config.MailerFromEmail.GetString()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + signed + ", k=" + encodeVapidPublicKey(key), nil
+}
+
+// encryptWebPushPayload encrypts a message for a subscription as described in RFC 8291,
+// using the aes128gcm content encoding from RFC 8188 with a single record.
+func encryptWebPushPayload(keys *WebPushSubscriptionKeys, payload []byte) ([]byte, error) {
+	if len(payload) > webPushMaxPayloadSize {
+		return nil, fmt.Errorf("payload is %d bytes, the maximum is %d", len(payload), webPushMaxPayloadSize)
+	}
+
+	uaPublicRaw, err := decodeWebPushKey(keys.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeWebPushKey(keys.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	// key_info = "WebPush: info" || 0x00 || ua_public || as_public
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicRaw...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdfBytes(ecdhSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, webPushHeaderSize)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func hkdfBytes(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	_, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+	return out, err
+}
@@ -0,0 +1,256 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBrowser holds the keys a browser generates when subscribing to web push.
+type testBrowser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &testBrowser{key: key, auth: auth}
+}
+
+func (b *testBrowser) keys() *WebPushSubscriptionKeys {
+	return &WebPushSubscriptionKeys{
+		P256dh: base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt does what the browser does when receiving a message as described in RFC 8291.
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), webPushHeaderSize)
+	salt := body[:16]
+	assert.Equal(t, uint32(webPushRecordSize), binary.BigEndian.Uint32(body[16:20]))
+	require.Equal(t, byte(65), body[20])
+	asPublicRaw := body[21:86]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	require.NoError(t, err)
+	ecdhSecret, err := b.key.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), b.key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm, err := hkdfBytes(ecdhSecret, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	require.NoError(t, err)
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[webPushHeaderSize:], nil)
+	require.NoError(t, err)
+
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func initTestWebPush(t *testing.T) {
+	publicKey, privateKey, err := GenerateVapidKeys()
+	require.NoError(t, err)
+	config.WebPushVapidPrivateKey.Set(privateKey)
+	InitWebPush()
+	require.Equal(t, publicKey, GetVapidPublicKey())
+}
+
+func TestEncryptWebPushPayload(t *testing.T) {
+	browser := newTestBrowser(t)
+
+	body, err := encryptWebPushPayload(browser.keys(), []byte(`{"name":"test"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"test"}`, string(browser.decrypt(t, body)))
+
+	_, err = encryptWebPushPayload(browser.keys(), make([]byte, webPushMaxPayloadSize+1))
+	require.Error(t, err)
+}
+
+func TestGetVapidAuthorization(t *testing.T) {
+	initTestWebPush(t)
+
+	authorization, err := getVapidAuthorization("https://push.example.com/send/abc")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+
+	parts := strings.Split(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+	require.Len(t, parts, 2)
+	assert.Equal(t, GetVapidPublicKey(), parts[1])
+
+	token, err := jwt.Parse(parts[0], func(_ *jwt.Token) (interface{}, error) {
+		vapidKeyLock.RLock()
+		defer vapidKeyLock.RUnlock()
+		return &vapidKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	aud, err := token.Claims.GetAudience()
+	require.NoError(t, err)
+	assert.Equal(t, jwt.ClaimStrings{"https://push.example.com"}, aud)
+}
+
+func TestWebPushSubscriptions(t *testing.T) {
+	browser := newTestBrowser(t)
+
+	t.Run("add replaces same endpoint", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from web_push_subscriptions")
+		require.NoError(t, err)
+
+		err = AddWebPushSubscription(s, 1, &WebPushSubscription{Endpoint: "https://push.example.com/1", Keys: browser.keys()})
+		require.NoError(t, err)
+		err = AddWebPushSubscription(s, 1, &WebPushSubscription{Endpoint: "https://push.example.com/1", Keys: browser.keys()})
+		require.NoError(t, err)
+
+		subs, err := GetWebPushSubscriptions(s, 1)
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+
+		deleted, err := DeleteWebPushSubscription(s, 2, subs[0].ID)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		deleted, err = DeleteWebPushSubscription(s, 1, subs[0].ID)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+	})
+	t.Run("add moves the same endpoint to the latest user", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from web_push_subscriptions")
+		require.NoError(t, err)
+
+		err = AddWebPushSubscription(s, 1, &WebPushSubscription{Endpoint: "https://push.example.com/1", Keys: browser.keys()})
+		require.NoError(t, err)
+		err = AddWebPushSubscription(s, 2, &WebPushSubscription{Endpoint: "https://push.example.com/1", Keys: browser.keys()})
+		require.NoError(t, err)
+
+		subs, err := GetWebPushSubscriptions(s, 1)
+		require.NoError(t, err)
+		assert.Empty(t, subs)
+		subs, err = GetWebPushSubscriptions(s, 2)
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, "https://push.example.com/1", subs[0].Endpoint)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		require.Error(t, ValidateWebPushSubscription(&WebPushSubscription{Endpoint: "http://push.example.com/1", Keys: browser.keys()}))
+		require.Error(t, ValidateWebPushSubscription(&WebPushSubscription{Endpoint: "https://push.example.com/1"}))
+		require.Error(t, ValidateWebPushSubscription(&WebPushSubscription{
+			Endpoint: "https://push.example.com/1",
+			Keys:     &WebPushSubscriptionKeys{P256dh: browser.keys().P256dh, Auth: "c2hvcnQ"},
+		}))
+	})
+}
+
+func TestNotifyWebPush(t *testing.T) {
+	initTestWebPush(t)
+	browser := newTestBrowser(t)
+
+	var received [][]byte
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received = append(received, body)
+		headers = append(headers, r.Header)
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	s := db.NewSession()
+	defer s.Close()
+	_, err := s.Exec("delete from web_push_subscriptions")
+	require.NoError(t, err)
+	// Inserted directly because the stand-in push service does not use https
+	_, err = s.Insert(
+		&WebPushSubscription{UserID: 42, Endpoint: server.URL + "/active", Keys: browser.keys()},
+		&WebPushSubscription{UserID: 42, Endpoint: server.URL + "/gone", Keys: browser.keys()},
+	)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit())
+
+	err = notifyWebPush(&testNotifiable{}, &testNotification{Test: "Lorem Ipsum", OtherValue: 42})
+	require.NoError(t, err)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "aes128gcm", headers[0].Get("Content-Encoding"))
+	assert.Equal(t, "high", headers[0].Get("Urgency"))
+	assert.True(t, strings.HasPrefix(headers[0].Get("Authorization"), "vapid t="))
+
+	payload := &webPushPayload{}
+	err = json.Unmarshal(browser.decrypt(t, received[0]), payload)
+	require.NoError(t, err)
+	assert.Equal(t, "test.notification", payload.Name)
+	assert.Equal(t, "Test Notification", payload.Title)
+	assert.JSONEq(t, `{"test":"Lorem Ipsum","other_value":42}`, string(payload.Notification))
+
+	// The gone subscription was pruned
+	subs, err := GetWebPushSubscriptions(s, 42)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, server.URL+"/active", subs[0].Endpoint)
+}
+
+func TestGetWebPushPayload(t *testing.T) {
+	notification := &testNotification{Test: strings.Repeat("a", 5000)}
+
+	body, err := getWebPushPayload(&testNotifiable{}, notification, notification.ToDB())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(body), webPushMaxPayloadSize)
+
+	payload := &webPushPayload{}
+	err = json.Unmarshal(body, payload)
+	require.NoError(t, err)
+	assert.Empty(t, payload.Notification)
+	assert.Equal(t, "test.notification", payload.Name)
+}
@@ -0,0 +1,75 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	auth2 "code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// The number of results per kind of entity when the limit is not specified
+const defaultGlobalSearchLimit = 10
+
+// GlobalSearch searches everything the current user has access to
+// @Summary Search everything
+// @Description Searches projects, tasks, comments, labels, saved filters and teams the user has access to. The results are grouped by kind and ordered by how well they match the search. Link shares only get results from the shared project.
+// @tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "The search term."
+// @Param limit query int false "The maximum number of results per kind. Defaults to 10, can not be more than the configured maximum items per page."
+// @Security JWTKeyAuth
+// @Success 200 {object} models.GlobalSearchResult "The search results."
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /search [get]
+func GlobalSearch(c echo.Context) error {
+	limit := defaultGlobalSearchLimit
+	if l := c.QueryParam("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit.")
+		}
+	}
+	if limit > config.ServiceMaxItemsPerPage.GetInt() {
+		limit = config.ServiceMaxItemsPerPage.GetInt()
+	}
+
+	auth, err := auth2.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	result, err := models.GlobalSearch(s, auth, c.QueryParam("q"), limit)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -27,6 +27,7 @@ import (
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/avatar"
+	"code.vikunja.io/api/pkg/notifications"
 	user2 "code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/web/handler"
 )
@@ -64,6 +65,16 @@ type UserSettings struct {
 	FrontendSettings interface{} `json:"frontend_settings"`
 	// Additional settings links as provided by openid
 	ExtraSettingsLinks map[string]any `json:"extra_settings_links"`
+	// The push services (ntfy, gotify or a generic http endpoint) notifications are sent to in addition to email.
+	PushChannels []*notifications.PushChannel `json:"push_channels"`
+	// Over which channels (`mail`, `in_app` and `push`) each kind of notification is sent, by notification name.
+	// Contains all notifications which can be configured, with the instance defaults applied.
+	NotificationPreferences notifications.Preferences `json:"notification_preferences"`
+	// If set to `daily` or `weekly`, notification emails about comments, assignments and similar are batched
+	// into one digest email instead. Weekly digests are sent on the first day of the user's week.
+	DigestMode user2.DigestMode `json:"digest_mode"`
+	// The time in the user's time zone when the digest email will be sent, in the format hh:mm.
+	DigestTime string `json:"digest_time"`
 }
 
 // GetUserAvatarProvider returns the currently set user avatar
@@ -216,6 +227,12 @@ func UpdateGeneralUserSettings(c echo.Context) error {
 	user.Timezone = us.Timezone
 	user.OverdueTasksRemindersTime = us.OverdueTasksRemindersTime
 	user.FrontendSettings = us.FrontendSettings
+	user.PushChannels = us.PushChannels
+	user.DigestMode = us.DigestMode
+	user.DigestTime = us.DigestTime
+	if us.NotificationPreferences != nil {
+		user.SetNotificationPreferences(us.NotificationPreferences)
+	}
 
 	_, err = user2.UpdateUser(s, user, true)
 	if err != nil {
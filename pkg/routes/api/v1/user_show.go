@@ -81,6 +81,10 @@ func UserShow(c echo.Context) error {
 			OverdueTasksRemindersTime:    u.OverdueTasksRemindersTime,
 			FrontendSettings:             u.FrontendSettings,
 			ExtraSettingsLinks:           u.ExtraSettingsLinks,
+			PushChannels:                 u.PushChannels,
+			NotificationPreferences:      u.GetNotificationPreferences(),
+			DigestMode:                   u.DigestMode,
+			DigestTime:                   u.GetDigestTime(),
 		},
 		DeletionScheduledAt: u.DeletionScheduledAt,
 		IsLocalUser:         u.Issuer == user.IssuerLocal,
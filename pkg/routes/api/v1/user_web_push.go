@@ -0,0 +1,158 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AddWebPushSubscription is the handler to register a browser push subscription
+// @Summary Register a web push subscription
+// @Description Registers the push subscription of a browser for the current user. The body is the json representation of the browser's `PushSubscription`. Subscribing an endpoint which is already subscribed replaces that subscription, even if it belonged to another user. Use the `web_push_public_key` from `/info` as `applicationServerKey` when subscribing.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param subscription body notifications.WebPushSubscription true "The push subscription"
+// @Success 201 {object} notifications.WebPushSubscription
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 412 {object} web.HTTPError "Web push is disabled."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webpush [put]
+func AddWebPushSubscription(c echo.Context) error {
+	if notifications.GetVapidPublicKey() == "" {
+		return handler.HandleHTTPError(&user.ErrWebPushDisabled{})
+	}
+
+	sub := &notifications.WebPushSubscription{}
+	err := c.Bind(sub)
+	if err != nil {
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid model provided. Error was: %s", he.Message)).SetInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid model provided.").SetInternal(err)
+	}
+
+	err = notifications.ValidateWebPushSubscription(sub)
+	if err != nil {
+		return handler.HandleHTTPError(&user.ErrInvalidWebPushSubscription{Reason: err.Error()})
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = notifications.AddWebPushSubscription(s, u.ID, sub)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, sub)
+}
+
+// GetWebPushSubscriptions is the handler to return all push subscriptions of the current user
+// @Summary Return all web push subscriptions
+// @Description Returns the push subscriptions of all devices of the current user.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} notifications.WebPushSubscription
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webpush [get]
+func GetWebPushSubscriptions(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	subs, err := notifications.GetWebPushSubscriptions(s, u.ID)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, subs)
+}
+
+// DeleteWebPushSubscription is the handler to remove a push subscription
+// @Summary Remove a web push subscription
+// @Description Removes the push subscription of a device, for example when the user logs out.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param subscription path int true "Subscription ID"
+// @Success 200 {object} models.Message
+// @Failure 404 {object} web.HTTPError "The subscription does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webpush/{subscription} [delete]
+func DeleteWebPushSubscription(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err)
+	}
+
+	id, err := strconv.ParseInt(c.Param("subscription"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid subscription id.").SetInternal(err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	deleted, err := notifications.DeleteWebPushSubscription(s, u.ID, id)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err)
+	}
+	if !deleted {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user.ErrWebPushSubscriptionDoesNotExist{ID: id})
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, &models.Message{Message: "The subscription was deleted successfully."})
+}
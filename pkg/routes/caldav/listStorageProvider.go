@@ -347,6 +347,14 @@ func (vcls *VikunjaCaldavProjectStorage) UpdateResource(rpath, content string) (
 	// At this point, we already have the right task in vcls.task, so we can use that ID directly
 	vTask.ID = vcls.task.ID
 
+	// Tasks without a start date are exported with the start of their repeat series as DTSTART,
+	// which must not become the start date of the task when the client sends it back.
+	if vcls.task.StartDate.IsZero() &&
+		vTask.RepeatRule == vcls.task.RepeatRule &&
+		vTask.StartDate.Equal(vcls.task.RepeatRuleStart) {
+		vTask.StartDate = time.Time{}
+	}
+
 	// Explicitly set the ProjectID in case the task now belongs to a different project:
 	vTask.ProjectID = vcls.project.ID
 	vcls.task.ProjectID = vcls.project.ID
@@ -483,3 +483,60 @@ END:VCALENDAR`
 		assert.Equal(t, "uid-caldav-test-child-task-2", formerSiblingSubTask.UID)
 	})
 }
+
+func TestRepeatRule_Update(t *testing.T) {
+	u := &user.User{
+		ID:       15,
+		Username: "user15",
+		Email:    "user15@example.com",
+	}
+
+	t.Run("series start sent back as DTSTART", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		const taskUID = "uid-caldav-test-child-task"
+		tasks, err := models.GetTasksByUIDs(s, []string{taskUID}, u)
+		require.NoError(t, err)
+		task := tasks[0]
+
+		task.RepeatRule = "FREQ=DAILY"
+		task.RepeatRuleStart = task.DueDate
+		_, err = s.ID(task.ID).Cols("repeat_rule", "repeat_rule_start").Update(task)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		// Clients send the DTSTART we exported for the series back
+		taskContent := `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:Project 36 for Caldav tests
+PRODID:-//Vikunja Todo App//EN
+BEGIN:VTODO
+UID:uid-caldav-test-child-task
+DTSTAMP:20230301T073337Z
+SUMMARY:Child task for Caldav Test
+DTSTART:` + task.RepeatRuleStart.UTC().Format("20060102T150405Z") + `
+DUE:` + task.DueDate.UTC().Format("20060102T150405Z") + `
+RRULE:FREQ=DAILY
+CREATED:20230301T073337Z
+LAST-MODIFIED:20230301T073337Z
+END:VTODO
+END:VCALENDAR`
+		storage := &VikunjaCaldavProjectStorage{
+			project: &models.ProjectWithTasksAndBuckets{Project: models.Project{ID: 36}},
+			task:    task,
+			user:    u,
+		}
+
+		_, err = storage.UpdateResource(taskUID, taskContent)
+		require.NoError(t, err)
+
+		tasks, err = models.GetTasksByUIDs(s, []string{taskUID}, u)
+		require.NoError(t, err)
+		assert.True(t, tasks[0].StartDate.IsZero())
+		assert.Equal(t, "FREQ=DAILY", tasks[0].RepeatRule)
+	})
+}
@@ -328,6 +328,9 @@ func registerAPIRoutes(a *echo.Group) {
 	u.PUT("/settings/token/caldav", apiv1.GenerateCaldavToken)
 	u.GET("/settings/token/caldav", apiv1.GetCaldavTokens)
 	u.DELETE("/settings/token/caldav/:id", apiv1.DeleteCaldavToken)
+	u.PUT("/settings/webpush", apiv1.AddWebPushSubscription)
+	u.GET("/settings/webpush", apiv1.GetWebPushSubscriptions)
+	u.DELETE("/settings/webpush/:subscription", apiv1.DeleteWebPushSubscription)
 
 	if config.ServiceEnableTotp.GetBool() {
 		u.GET("/settings/totp", apiv1.UserTOTP)
@@ -344,6 +347,8 @@ func registerAPIRoutes(a *echo.Group) {
 		u.POST("/deletion/cancel", apiv1.UserCancelDeletion)
 	}
 
+	a.GET("/search", apiv1.GlobalSearch)
+
 	projectHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Project{}
@@ -393,6 +398,14 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.PUT("/projects/:projectid/duplicate", projectDuplicateHandler.CreateWeb)
 
+	projectInboundEmailHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectInboundEmail{}
+		},
+	}
+	a.GET("/projects/:project/inbound-email", projectInboundEmailHandler.ReadOneWeb)
+	a.PUT("/projects/:project/inbound-email", projectInboundEmailHandler.CreateWeb)
+
 	taskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Task{}
@@ -404,6 +417,13 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/tasks/:projecttask", taskHandler.DeleteWeb)
 	a.POST("/tasks/:projecttask", taskHandler.UpdateWeb)
 
+	quickAddTaskHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.QuickAddTask{}
+		},
+	}
+	a.PUT("/projects/:project/tasks/quick", quickAddTaskHandler.CreateWeb)
+
 	taskPositionHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskPosition{}
@@ -418,6 +438,20 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.POST("/tasks/bulk", bulkTaskHandler.UpdateWeb)
 
+	bulkTaskActionHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.BulkTaskAction{}
+		},
+	}
+	a.POST("/tasks/bulk/actions", bulkTaskActionHandler.CreateWeb)
+
+	taskReminderSnoozeHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskReminderSnooze{}
+		},
+	}
+	a.PUT("/tasks/:task/reminders/snooze", taskReminderSnoozeHandler.CreateWeb)
+
 	assigneeTaskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskAssginee{}
@@ -458,6 +492,47 @@ func registerAPIRoutes(a *echo.Group) {
 	a.PUT("/tasks/:task/relations", taskRelationHandler.CreateWeb)
 	a.DELETE("/tasks/:task/relations/:relationKind/:otherTask", taskRelationHandler.DeleteWeb)
 
+	taskOccurrenceHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskOccurrence{}
+		},
+	}
+	a.GET("/tasks/:task/occurrences", taskOccurrenceHandler.ReadAllWeb)
+
+	taskActivityHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskActivity{}
+		},
+	}
+	a.GET("/tasks/:task/activity", taskActivityHandler.ReadAllWeb)
+
+	taskTimeEntryHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTimeEntry{}
+		},
+	}
+	a.GET("/tasks/:task/time_entries", taskTimeEntryHandler.ReadAllWeb)
+	a.PUT("/tasks/:task/time_entries", taskTimeEntryHandler.CreateWeb)
+	a.POST("/tasks/:task/time_entries/:entry", taskTimeEntryHandler.UpdateWeb)
+	a.DELETE("/tasks/:task/time_entries/:entry", taskTimeEntryHandler.DeleteWeb)
+
+	taskTimerHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTimer{}
+		},
+	}
+	a.GET("/tasks/:task/timer", taskTimerHandler.ReadOneWeb)
+	a.PUT("/tasks/:task/timer", taskTimerHandler.CreateWeb)
+	a.POST("/tasks/:task/timer/stop", taskTimerHandler.UpdateWeb)
+
+	timeReportHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TimeReport{}
+		},
+	}
+	a.GET("/projects/:project/time_report", timeReportHandler.ReadOneWeb)
+	a.GET("/time_report", timeReportHandler.ReadOneWeb)
+
 	if config.ServiceEnableTaskAttachments.GetBool() {
 		taskAttachmentHandler := &handler.WebHandler{
 			EmptyStruct: func() handler.CObject {
@@ -481,6 +556,13 @@ func registerAPIRoutes(a *echo.Group) {
 		a.DELETE("/tasks/:task/comments/:commentid", taskCommentHandler.DeleteWeb)
 		a.POST("/tasks/:task/comments/:commentid", taskCommentHandler.UpdateWeb)
 		a.GET("/tasks/:task/comments/:commentid", taskCommentHandler.ReadOneWeb)
+
+		taskCommentRevisionHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.TaskCommentRevision{}
+			},
+		}
+		a.GET("/tasks/:task/comments/:commentid/revisions", taskCommentRevisionHandler.ReadAllWeb)
 	}
 
 	labelHandler := &handler.WebHandler{
@@ -524,6 +606,39 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/filters/:filter", savedFiltersHandler.DeleteWeb)
 	a.POST("/filters/:filter", savedFiltersHandler.UpdateWeb)
 
+	templateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.Template{}
+		},
+	}
+	a.GET("/templates", templateHandler.ReadAllWeb)
+	a.GET("/templates/:template", templateHandler.ReadOneWeb)
+	a.PUT("/templates", templateHandler.CreateWeb)
+	a.POST("/templates/:template", templateHandler.UpdateWeb)
+	a.DELETE("/templates/:template", templateHandler.DeleteWeb)
+
+	templateInstanceHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TemplateInstance{}
+		},
+	}
+	a.PUT("/templates/:template/instantiate", templateInstanceHandler.CreateWeb)
+
+	trashHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashItem{}
+		},
+	}
+	a.GET("/trash", trashHandler.ReadAllWeb)
+	a.DELETE("/trash/:trashitem", trashHandler.DeleteWeb)
+
+	trashRestoreHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashItemRestore{}
+		},
+	}
+	a.PUT("/trash/:trashitem/restore", trashRestoreHandler.CreateWeb)
+
 	teamHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Team{}
@@ -638,6 +753,25 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/projects/:project/views/:view", projectViewProvider.DeleteWeb)
 	a.POST("/projects/:project/views/:view", projectViewProvider.UpdateWeb)
 
+	projectViewScheduleHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectViewSchedule{}
+		},
+	}
+	a.GET("/projects/:project/views/:view/schedule", projectViewScheduleHandler.ReadOneWeb)
+	a.POST("/projects/:project/views/:view/schedule", projectViewScheduleHandler.UpdateWeb)
+
+	customFieldHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectCustomField{}
+		},
+	}
+	a.GET("/projects/:project/custom_fields", customFieldHandler.ReadAllWeb)
+	a.PUT("/projects/:project/custom_fields", customFieldHandler.CreateWeb)
+	a.GET("/projects/:project/custom_fields/:customfield", customFieldHandler.ReadOneWeb)
+	a.POST("/projects/:project/custom_fields/:customfield", customFieldHandler.UpdateWeb)
+	a.DELETE("/projects/:project/custom_fields/:customfield", customFieldHandler.DeleteWeb)
+
 	// Kanban Task Bucket Relation
 	taskBucketProvider := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
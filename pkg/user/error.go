@@ -714,3 +714,165 @@ func (err ErrTokenUserMismatch) HTTPError() web.HTTPError {
 		Message:  "This deletion token does not belong to your account.",
 	}
 }
+
+// ErrInvalidPushChannel represents an error where a push channel config is invalid
+type ErrInvalidPushChannel struct {
+	Type   string
+	Reason string
+}
+
+// IsErrInvalidPushChannel checks if an error is a ErrInvalidPushChannel.
+func IsErrInvalidPushChannel(err error) bool {
+	_, ok := err.(*ErrInvalidPushChannel)
+	return ok
+}
+
+func (err *ErrInvalidPushChannel) Error() string {
+	return fmt.Sprintf("Invalid push channel [Type: %s, Reason: %s]", err.Type, err.Reason)
+}
+
+// ErrorCodeInvalidPushChannel holds the unique world-error code of this error
+const ErrorCodeInvalidPushChannel = 1030
+
+// HTTPError holds the http error description
+func (err *ErrInvalidPushChannel) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrorCodeInvalidPushChannel,
+		Message:  fmt.Sprintf("The %s push channel is invalid: %s", err.Type, err.Reason),
+	}
+}
+
+// ErrInvalidWebPushSubscription represents an error where a web push subscription is invalid
+type ErrInvalidWebPushSubscription struct {
+	Reason string
+}
+
+// IsErrInvalidWebPushSubscription checks if an error is a ErrInvalidWebPushSubscription.
+func IsErrInvalidWebPushSubscription(err error) bool {
+	_, ok := err.(*ErrInvalidWebPushSubscription)
+	return ok
+}
+
+func (err *ErrInvalidWebPushSubscription) Error() string {
+	return fmt.Sprintf("Invalid web push subscription [Reason: %s]", err.Reason)
+}
+
+// ErrorCodeInvalidWebPushSubscription holds the unique world-error code of this error
+const ErrorCodeInvalidWebPushSubscription = 1031
+
+// HTTPError holds the http error description
+func (err *ErrInvalidWebPushSubscription) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrorCodeInvalidWebPushSubscription,
+		Message:  fmt.Sprintf("The web push subscription is invalid: %s", err.Reason),
+	}
+}
+
+// ErrWebPushSubscriptionDoesNotExist represents an error where a web push subscription does not exist
+type ErrWebPushSubscriptionDoesNotExist struct {
+	ID int64
+}
+
+// IsErrWebPushSubscriptionDoesNotExist checks if an error is a ErrWebPushSubscriptionDoesNotExist.
+func IsErrWebPushSubscriptionDoesNotExist(err error) bool {
+	_, ok := err.(*ErrWebPushSubscriptionDoesNotExist)
+	return ok
+}
+
+func (err *ErrWebPushSubscriptionDoesNotExist) Error() string {
+	return fmt.Sprintf("Web push subscription does not exist [ID: %d]", err.ID)
+}
+
+// ErrorCodeWebPushSubscriptionDoesNotExist holds the unique world-error code of this error
+const ErrorCodeWebPushSubscriptionDoesNotExist = 1032
+
+// HTTPError holds the http error description
+func (err *ErrWebPushSubscriptionDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrorCodeWebPushSubscriptionDoesNotExist,
+		Message:  "This web push subscription does not exist.",
+	}
+}
+
+// ErrWebPushDisabled represents an error where web push is disabled on this instance
+type ErrWebPushDisabled struct{}
+
+// IsErrWebPushDisabled checks if an error is a ErrWebPushDisabled.
+func IsErrWebPushDisabled(err error) bool {
+	_, ok := err.(*ErrWebPushDisabled)
+	return ok
+}
+
+func (err *ErrWebPushDisabled) Error() string {
+	return "Web push is disabled"
+}
+
+// ErrorCodeWebPushDisabled holds the unique world-error code of this error
+const ErrorCodeWebPushDisabled = 1033
+
+// HTTPError holds the http error description
+func (err *ErrWebPushDisabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrorCodeWebPushDisabled,
+		Message:  "Web push notifications are disabled on this instance.",
+	}
+}
+
+// ErrInvalidNotificationPreferences represents an error where the notification preferences are invalid
+type ErrInvalidNotificationPreferences struct {
+	Reason string
+}
+
+// IsErrInvalidNotificationPreferences checks if an error is a ErrInvalidNotificationPreferences.
+func IsErrInvalidNotificationPreferences(err error) bool {
+	_, ok := err.(*ErrInvalidNotificationPreferences)
+	return ok
+}
+
+func (err *ErrInvalidNotificationPreferences) Error() string {
+	return fmt.Sprintf("Invalid notification preferences [Reason: %s]", err.Reason)
+}
+
+// ErrorCodeInvalidNotificationPreferences holds the unique world-error code of this error
+const ErrorCodeInvalidNotificationPreferences = 1034
+
+// HTTPError holds the http error description
+func (err *ErrInvalidNotificationPreferences) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrorCodeInvalidNotificationPreferences,
+		Message:  fmt.Sprintf("The notification preferences are invalid: %s", err.Reason),
+	}
+}
+
+// ErrInvalidDigestMode represents an error where the digest mode or time is invalid
+type ErrInvalidDigestMode struct {
+	Mode DigestMode
+	Time string
+}
+
+// IsErrInvalidDigestMode checks if an error is a ErrInvalidDigestMode.
+func IsErrInvalidDigestMode(err error) bool {
+	_, ok := err.(*ErrInvalidDigestMode)
+	return ok
+}
+
+func (err *ErrInvalidDigestMode) Error() string {
+	return fmt.Sprintf("Invalid digest settings [Mode: %s, Time: %s]", err.Mode, err.Time)
+}
+
+// ErrorCodeInvalidDigestMode holds the unique world-error code of this error
+const ErrorCodeInvalidDigestMode = 1035
+
+// HTTPError holds the http error description
+func (err *ErrInvalidDigestMode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrorCodeInvalidDigestMode,
+		Message:  "The digest mode must be empty, daily or weekly and the digest time must be in the format hh:mm.",
+	}
+}
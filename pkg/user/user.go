@@ -71,6 +71,18 @@ const (
 	StatusDisabled
 )
 
+// DigestMode is how often a user gets a digest mail
+type DigestMode string
+
+const (
+	DigestModeOff    DigestMode = ""
+	DigestModeDaily  DigestMode = "daily"
+	DigestModeWeekly DigestMode = "weekly"
+)
+
+// DefaultDigestTime is used when a user did not choose a time for their digest.
+const DefaultDigestTime = "08:00"
+
 // User holds information about an user
 type User struct {
 	// The unique, numeric id of this user.
@@ -108,6 +120,14 @@ type User struct {
 	FrontendSettings   interface{}    `xorm:"json null" json:"-"`
 	ExtraSettingsLinks map[string]any `xorm:"json null" json:"-"`
 
+	PushChannels []*notifications.PushChannel `xorm:"json null" json:"-"`
+	// Over which channels the user wants to receive which notifications. Missing entries use the instance defaults.
+	NotificationPreferences notifications.Preferences `xorm:"json null" json:"-"`
+	// If set to `daily` or `weekly`, mails are batched in a digest sent at DigestTime.
+	DigestMode DigestMode `xorm:"varchar(10) null" json:"-"`
+	// When to send the digest, in the user's time zone. Empty means DefaultDigestTime.
+	DigestTime string `xorm:"varchar(5) null" json:"-"`
+
 	ExportFileID int64 `xorm:"bigint null" json:"-"`
 
 	// A timestamp when this task was created. You cannot change this value.
@@ -139,7 +159,55 @@ func (u *User) RouteForDB() int64 {
 	return u.ID
 }
 
-func (u *User) ShouldNotify() (bool, error) {
+// RouteForPush returns all push channels the user has configured
+func (u *User) RouteForPush() ([]*notifications.PushChannel, error) {
+	if u.PushChannels == nil {
+		s := db.NewSession()
+		defer s.Close()
+		user, err := getUser(s, &User{ID: u.ID}, true)
+		if err != nil {
+			return nil, err
+		}
+		return user.PushChannels, nil
+	}
+
+	return u.PushChannels, nil
+}
+
+// ShouldNotify checks if the user is enabled and wants to get a notification through a channel
+func (u *User) ShouldNotify(notification notifications.Notification, channel notifications.Channel) (bool, error) {
+	s := db.NewSession()
+	defer s.Close()
+	user, err := getUser(s, &User{ID: u.ID}, true)
+	if err != nil {
+		return false, err
+	}
+
+	if user.Status == StatusDisabled {
+		return false, nil
+	}
+
+	return user.wantsNotification(notification.Name(), channel), nil
+}
+
+func (u *User) wantsNotification(name string, channel notifications.Channel) bool {
+	if !notifications.IsConfigurableNotification(name) {
+		return true
+	}
+
+	if enabled, has := u.NotificationPreferences.Get(name, channel); has {
+		return enabled
+	}
+
+	if enabled, has := getDefaultNotificationPreferences().Get(name, channel); has {
+		return enabled
+	}
+
+	return true
+}
+
+// ShouldDigest checks if the user wants to get a notification mail with their next digest instead of right away
+func (u *User) ShouldDigest(_ notifications.Notification) (bool, error) {
 	s := db.NewSession()
 	defer s.Close()
 	user, err := getUser(s, &User{ID: u.ID}, true)
@@ -147,7 +215,50 @@ func (u *User) ShouldNotify() (bool, error) {
 		return false, err
 	}
 
-	return user.Status != StatusDisabled, err
+	return user.DigestMode != DigestModeOff, nil
+}
+
+// GetDigestTime returns the time of day the user wants to get their digest at, in the format 15:04.
+func (u *User) GetDigestTime() string {
+	if u.DigestTime == "" {
+		return DefaultDigestTime
+	}
+	return u.DigestTime
+}
+
+// GetNotificationPreferences returns the effective channel preferences of the user for all
+// configurable notifications, with the instance defaults applied.
+func (u *User) GetNotificationPreferences() notifications.Preferences {
+	prefs := notifications.Preferences{}
+	for _, name := range notifications.GetConfigurableNotifications() {
+		prefs[name] = make(map[notifications.Channel]bool, len(notifications.Channels))
+		for _, channel := range notifications.Channels {
+			prefs[name][channel] = u.wantsNotification(name, channel)
+		}
+	}
+	return prefs
+}
+
+// SetNotificationPreferences only keeps the preferences which differ from the instance defaults,
+// so users keep following the defaults for everything they did not change.
+func (u *User) SetNotificationPreferences(prefs notifications.Preferences) {
+	defaults := &User{}
+	u.NotificationPreferences = notifications.Preferences{}
+	for name, channels := range prefs.Normalize() {
+		for channel, enabled := range channels {
+			if defaults.wantsNotification(name, channel) == enabled {
+				continue
+			}
+			if u.NotificationPreferences[name] == nil {
+				u.NotificationPreferences[name] = map[notifications.Channel]bool{}
+			}
+			u.NotificationPreferences[name][channel] = enabled
+		}
+	}
+}
+
+func getDefaultNotificationPreferences() notifications.Preferences {
+	return notifications.ParsePreferences(config.DefaultSettingsNotificationPreferences.GetStringMap())
 }
 
 func (u *User) Lang() string {
@@ -582,6 +693,29 @@ func UpdateUser(s *xorm.Session, user *User, forceOverride bool) (updatedUser *U
 		return nil, &ErrInvalidTimezone{Name: user.Timezone, LoadError: err}
 	}
 
+	if user.DigestMode != DigestModeOff && user.DigestMode != DigestModeDaily && user.DigestMode != DigestModeWeekly {
+		return nil, &ErrInvalidDigestMode{Mode: user.DigestMode}
+	}
+
+	if user.DigestTime == "" {
+		user.DigestTime = theUser.DigestTime
+	}
+	if _, err := time.Parse("15:04", user.GetDigestTime()); err != nil {
+		return nil, &ErrInvalidDigestMode{Mode: user.DigestMode, Time: user.DigestTime}
+	}
+
+	err = user.NotificationPreferences.Validate()
+	if err != nil {
+		return nil, &ErrInvalidNotificationPreferences{Reason: err.Error()}
+	}
+
+	for _, channel := range user.PushChannels {
+		err = notifications.ValidatePushChannel(channel)
+		if err != nil {
+			return nil, &ErrInvalidPushChannel{Type: channel.Type, Reason: err.Error()}
+		}
+	}
+
 	frontendSettingsJSON, err := json.Marshal(user.FrontendSettings)
 	if err != nil {
 		return nil, err
@@ -609,6 +743,10 @@ func UpdateUser(s *xorm.Session, user *User, forceOverride bool) (updatedUser *U
 			"overdue_tasks_reminders_time",
 			"frontend_settings",
 			"extra_settings_links",
+			"push_channels",
+			"notification_preferences",
+			"digest_mode",
+			"digest_time",
 		).
 		Update(user)
 	if err != nil {
@@ -19,7 +19,9 @@ package user
 import (
 	"testing"
 
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -361,6 +363,78 @@ func TestUpdateUser(t *testing.T) {
 		require.Error(t, err)
 		assert.True(t, IsErrUserDoesNotExist(err))
 	})
+	t.Run("push channels", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		uuser, err := UpdateUser(s, &User{
+			ID: 1,
+			PushChannels: []*notifications.PushChannel{
+				{Type: notifications.PushChannelTypeNtfy, URL: "https://ntfy.sh", Topic: "vikunja", Enabled: true},
+			},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, uuser.PushChannels, 1)
+		assert.Equal(t, "vikunja", uuser.PushChannels[0].Topic)
+	})
+	t.Run("invalid push channel", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID: 1,
+			PushChannels: []*notifications.PushChannel{
+				{Type: notifications.PushChannelTypeNtfy, URL: "https://ntfy.sh"},
+			},
+		}, false)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidPushChannel(err))
+	})
+	t.Run("digest", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		uuser, err := UpdateUser(s, &User{
+			ID:         1,
+			DigestMode: DigestModeWeekly,
+			DigestTime: "18:30",
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, DigestModeWeekly, uuser.DigestMode)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":          1,
+			"digest_mode": "weekly",
+			"digest_time": "18:30",
+		}, false)
+	})
+	t.Run("invalid digest mode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID:         1,
+			DigestMode: "hourly",
+		}, false)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidDigestMode(err))
+	})
+	t.Run("invalid digest time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID:         1,
+			DigestMode: DigestModeDaily,
+			DigestTime: "25:00",
+		}, false)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidDigestMode(err))
+	})
 }
 
 func TestUpdateUserPassword(t *testing.T) {
@@ -656,3 +730,111 @@ func TestConfirmDeletion(t *testing.T) {
 		})
 	})
 }
+
+func TestUser_ShouldNotify(t *testing.T) {
+	notifications.RegisterConfigurableNotification("test.configurable")
+	configurable := &testNamedNotification{name: "test.configurable"}
+	mandatory := &testNamedNotification{name: "test.mandatory"}
+
+	t.Run("enabled by default", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		should, err := (&User{ID: 1}).ShouldNotify(configurable, notifications.ChannelMail)
+		require.NoError(t, err)
+		assert.True(t, should)
+	})
+	t.Run("disabled by the user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID: 1,
+			NotificationPreferences: notifications.Preferences{
+				"test.configurable": {notifications.ChannelMail: false},
+			},
+		}, false)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		should, err := (&User{ID: 1}).ShouldNotify(configurable, notifications.ChannelMail)
+		require.NoError(t, err)
+		assert.False(t, should)
+		should, err = (&User{ID: 1}).ShouldNotify(configurable, notifications.ChannelInApp)
+		require.NoError(t, err)
+		assert.True(t, should)
+	})
+	t.Run("instance default", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		config.DefaultSettingsNotificationPreferences.Set(map[string]interface{}{
+			"test": map[string]interface{}{
+				"configurable": map[string]interface{}{"push": false},
+			},
+		})
+		defer config.DefaultSettingsNotificationPreferences.Set(nil)
+
+		should, err := (&User{ID: 1}).ShouldNotify(configurable, notifications.ChannelPush)
+		require.NoError(t, err)
+		assert.False(t, should)
+
+		u := &User{ID: 1}
+		assert.False(t, u.GetNotificationPreferences()["test.configurable"][notifications.ChannelPush])
+
+		// Preferences equal to the defaults are not stored
+		u.SetNotificationPreferences(notifications.Preferences{
+			"test.configurable": {notifications.ChannelPush: false, notifications.ChannelMail: false},
+		})
+		assert.Equal(t, notifications.Preferences{
+			"test.configurable": {notifications.ChannelMail: false},
+		}, u.NotificationPreferences)
+	})
+	t.Run("mandatory notifications are always sent", func(t *testing.T) {
+		u := &User{NotificationPreferences: notifications.Preferences{
+			"test.mandatory": {notifications.ChannelMail: false},
+		}}
+		assert.True(t, u.wantsNotification(mandatory.Name(), notifications.ChannelMail))
+	})
+	t.Run("disabled user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SetUserStatus(s, &User{ID: 1}, StatusDisabled)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		should, err := (&User{ID: 1}).ShouldNotify(mandatory, notifications.ChannelMail)
+		require.NoError(t, err)
+		assert.False(t, should)
+	})
+	t.Run("invalid preferences", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID: 1,
+			NotificationPreferences: notifications.Preferences{
+				"test.mandatory": {notifications.ChannelMail: false},
+			},
+		}, false)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationPreferences(err))
+	})
+}
+
+type testNamedNotification struct {
+	name string
+}
+
+func (n *testNamedNotification) ToMail(_ string) *notifications.Mail {
+	return nil
+}
+
+func (n *testNamedNotification) ToDB() interface{} {
+	return nil
+}
+
+func (n *testNamedNotification) Name() string {
+	return n.name
+}
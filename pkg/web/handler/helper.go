@@ -40,6 +40,10 @@ type CObject interface {
 // HandleHTTPError does what it says
 func HandleHTTPError(err error) *echo.HTTPError {
 	log.Error(err.Error())
+	if a, has := err.(web.HTTPErrorWithDetailsProcessor); has {
+		errDetails := a.HTTPErrorWithDetails()
+		return echo.NewHTTPError(errDetails.HTTPCode, errDetails).SetInternal(err)
+	}
 	if a, has := err.(web.HTTPErrorProcessor); has {
 		errDetails := a.HTTPError()
 		return echo.NewHTTPError(errDetails.HTTPCode, errDetails).SetInternal(err)
@@ -55,6 +55,12 @@ type HTTPErrorWithDetails struct {
 	Details interface{} `json:"details"`
 }
 
+// HTTPErrorWithDetailsProcessor is implemented by errors which need to send more information to the user than the
+// error message, like the outcome of a partially applied action.
+type HTTPErrorWithDetailsProcessor interface {
+	HTTPErrorWithDetails() HTTPErrorWithDetails
+}
+
 // Auth defines the interface used to retrieve authentication information
 type Auth interface {
 	// Most of the time, we need an ID from the auth object only. Having this method saves the need to cast it.
@@ -0,0 +1,83 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package webtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// convertKeysLikeFrontend converts all keys the way the frontend does when it converts a response to camelCase
+// and the next request back to snake_case: every separator, including dots, becomes an underscore.
+func convertKeysLikeFrontend(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			converted[strings.ReplaceAll(key, ".", "_")] = convertKeysLikeFrontend(nested)
+		}
+		return converted
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = convertKeysLikeFrontend(nested)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func TestUserSettingsGeneral(t *testing.T) {
+	t.Run("Save the settings as sent by the frontend", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.UserShow, &testuser1, "", nil, nil)
+		require.NoError(t, err)
+
+		shown := map[string]interface{}{}
+		err = json.Unmarshal(rec.Body.Bytes(), &shown)
+		require.NoError(t, err)
+
+		settings := convertKeysLikeFrontend(shown["settings"]).(map[string]interface{})
+		prefs := settings["notification_preferences"].(map[string]interface{})
+		require.Contains(t, prefs, "task_comment_reply")
+		prefs["task_comment_reply"].(map[string]interface{})["mail"] = false
+
+		payload, err := json.Marshal(settings)
+		require.NoError(t, err)
+
+		rec, err = newTestRequestWithUser(t, http.MethodPost, apiv1.UpdateGeneralUserSettings, &testuser1, string(payload), nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `The settings were updated successfully.`)
+
+		s := db.NewSession()
+		defer s.Close()
+		u, err := user.GetUserByID(s, testuser1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, notifications.Preferences{
+			"task.comment.reply": {notifications.ChannelMail: false},
+		}, u.NotificationPreferences)
+	})
+}